@@ -0,0 +1,172 @@
+package configs
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+// Cloud is one entry of the clouds section of clouds.yaml, see
+// https://docs.openstack.org/openstacksdk/latest/user/config/configuration.html
+type Cloud struct {
+	Auth struct {
+		AuthUrl                     string `yaml:"auth_url"`
+		Username                    string `yaml:"username"`
+		Password                    string `yaml:"password"`
+		ProjectName                 string `yaml:"project_name"`
+		ProjectId                   string `yaml:"project_id"`
+		ProjectDomainName           string `yaml:"project_domain_name"`
+		ProjectDomainId             string `yaml:"project_domain_id"`
+		UserDomainName              string `yaml:"user_domain_name"`
+		UserDomainId                string `yaml:"user_domain_id"`
+		DomainName                  string `yaml:"domain_name"`
+		DomainId                    string `yaml:"domain_id"`
+		ApplicationCredentialId     string `yaml:"application_credential_id"`
+		ApplicationCredentialName   string `yaml:"application_credential_name"`
+		ApplicationCredentialSecret string `yaml:"application_credential_secret"`
+		Token                       string `yaml:"token"`
+		SystemScope                 string `yaml:"system_scope"`
+		IdentityProvider            string `yaml:"identity_provider"`
+		Protocol                    string `yaml:"protocol"`
+		AccessToken                 string `yaml:"access_token"`
+		IdentityProviderUrl         string `yaml:"identity_provider_url"`
+	} `yaml:"auth"`
+	AuthType   string `yaml:"auth_type"`
+	RegionName string `yaml:"region_name"`
+	Interface  string `yaml:"interface"`
+	CACert     string `yaml:"cacert"`
+	Verify     *bool  `yaml:"verify"`
+
+	ComputeApiVersion string `yaml:"compute_api_version"`
+	VolumeApiVersion  string `yaml:"volume_api_version"`
+}
+
+type cloudsFile struct {
+	Clouds map[string]yaml.Node `yaml:"clouds"`
+}
+
+// cloudsDirs lists the directories searched for clouds.yaml and secure.yaml,
+// in the order of the python openstack client.
+func cloudsDirs() []string {
+	dirs := []string{"."}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "openstack"))
+	}
+	return append(dirs, "/etc/openstack")
+}
+
+func findCloudsFile(env, name string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	for _, dir := range cloudsDirs() {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func readCloudsFile(path string) (cloudsFile, error) {
+	var clouds cloudsFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return clouds, err
+	}
+	if err = yaml.Unmarshal(data, &clouds); err != nil {
+		return clouds, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return clouds, nil
+}
+
+// LoadCloud reads the named cloud from clouds.yaml, with the secrets of
+// secure.yaml laid over it. OS_CLIENT_CONFIG_FILE and OS_CLIENT_SECURE_FILE
+// point to the files explicitly.
+func LoadCloud(name string) (Cloud, error) {
+	var cloud Cloud
+	path := findCloudsFile("OS_CLIENT_CONFIG_FILE", "clouds.yaml")
+	if path == "" {
+		return cloud, fmt.Errorf("cloud %s: no clouds.yaml found", name)
+	}
+	clouds, err := readCloudsFile(path)
+	if err != nil {
+		return cloud, err
+	}
+	node, ok := clouds.Clouds[name]
+	if !ok {
+		return cloud, fmt.Errorf("cloud %s not found in %s", name, path)
+	}
+	if err = node.Decode(&cloud); err != nil {
+		return cloud, fmt.Errorf("parse cloud %s in %s: %w", name, path, err)
+	}
+
+	if securePath := findCloudsFile("OS_CLIENT_SECURE_FILE", "secure.yaml"); securePath != "" {
+		secure, err := readCloudsFile(securePath)
+		if err != nil {
+			return cloud, err
+		}
+		if node, ok := secure.Clouds[name]; ok {
+			if err = node.Decode(&cloud); err != nil {
+				return cloud, fmt.Errorf("parse cloud %s in %s: %w", name, securePath, err)
+			}
+		}
+	}
+	return cloud, nil
+}
+
+// Apply copies the settings of the cloud that are set into CONF. Like the
+// python openstack client, domain_id and domain_name are the default domain
+// of the user and project when the cloud has a project, the scope of the
+// token otherwise.
+func (c Cloud) Apply() {
+	var domainId, domainName string
+	if c.Auth.ProjectName == "" && c.Auth.ProjectId == "" {
+		domainId, domainName = c.Auth.DomainId, c.Auth.DomainName
+	}
+	values := map[string]string{
+		"OS_AUTH_URL":                      c.Auth.AuthUrl,
+		"OS_USERNAME":                      c.Auth.Username,
+		"OS_PASSWORD":                      c.Auth.Password,
+		"OS_PROJECT_NAME":                  c.Auth.ProjectName,
+		"OS_PROJECT_ID":                    c.Auth.ProjectId,
+		"OS_PROJECT_DOMAIN_NAME":           firstNonEmpty(c.Auth.ProjectDomainName, c.Auth.DomainName),
+		"OS_PROJECT_DOMAIN_ID":             firstNonEmpty(c.Auth.ProjectDomainId, c.Auth.DomainId),
+		"OS_USER_DOMAIN_NAME":              firstNonEmpty(c.Auth.UserDomainName, c.Auth.DomainName),
+		"OS_USER_DOMAIN_ID":                firstNonEmpty(c.Auth.UserDomainId, c.Auth.DomainId),
+		"OS_APPLICATION_CREDENTIAL_ID":     c.Auth.ApplicationCredentialId,
+		"OS_APPLICATION_CREDENTIAL_NAME":   c.Auth.ApplicationCredentialName,
+		"OS_APPLICATION_CREDENTIAL_SECRET": c.Auth.ApplicationCredentialSecret,
+		"OS_TOKEN":                         c.Auth.Token,
+		"OS_DOMAIN_ID":                     domainId,
+		"OS_DOMAIN_NAME":                   domainName,
+		"OS_SYSTEM_SCOPE":                  c.Auth.SystemScope,
+		"OS_IDENTITY_PROVIDER":             c.Auth.IdentityProvider,
+		"OS_PROTOCOL":                      c.Auth.Protocol,
+		"OS_ACCESS_TOKEN":                  c.Auth.AccessToken,
+		"OS_IDENTITY_PROVIDER_URL":         c.Auth.IdentityProviderUrl,
+		"OS_AUTH_TYPE":                     c.AuthType,
+		"OS_REGION_NAME":                   c.RegionName,
+		"OS_INTERFACE":                     c.Interface,
+		"OS_CACERT":                        c.CACert,
+		"OS_COMPUTE_API_VERSION":           c.ComputeApiVersion,
+		"OS_VOLUME_API_VERSION":            c.VolumeApiVersion,
+	}
+	if c.Verify != nil {
+		values["OS_INSECURE"] = fmt.Sprint(!*c.Verify)
+	}
+	ApplyEnv(func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok && v != ""
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package configs
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const cloudsYaml = `
+clouds:
+  lab:
+    auth:
+      auth_url: https://keystone.lab.example.com:5000/v3
+      username: demo
+      project_name: demo
+      user_domain_name: Default
+      project_domain_name: Default
+    region_name: RegionOne
+    interface: internal
+    verify: false
+`
+
+const secureYaml = `
+clouds:
+  lab:
+    auth:
+      password: secret
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	clouds := filepath.Join(dir, "clouds.yaml")
+	secure := filepath.Join(dir, "secure.yaml")
+	_ = os.WriteFile(clouds, []byte(cloudsYaml), 0600)
+	_ = os.WriteFile(secure, []byte(secureYaml), 0600)
+	t.Setenv("OS_CLIENT_CONFIG_FILE", clouds)
+	t.Setenv("OS_CLIENT_SECURE_FILE", secure)
+	t.Setenv("OS_REGION_NAME", "RegionTwo")
+
+	conf := CONF
+	defer func() { CONF = conf }()
+	CONF = Server{}
+	ApplyEnv(func(key string) (string, bool) {
+		v, ok := parseExports("export OS_INTERFACE=public\nexport OS_PROJECT_NAME='rc'\n")[key]
+		return v, ok
+	})
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := BindFlags(fs)
+	if err := fs.Parse([]string{"--os-cloud", "lab", "--os-project-name", "flag"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if CONF.Host != "keystone.lab.example.com" || CONF.UserPassword != "secret" || !CONF.Insecure {
+		t.Fatalf("cloud not applied: %+v", CONF.Openstack)
+	}
+	if CONF.Interface != "internal" {
+		t.Fatalf("clouds.yaml should override the keystonerc, got %s", CONF.Interface)
+	}
+	if CONF.Region != "RegionTwo" {
+		t.Fatalf("env should override clouds.yaml, got %s", CONF.Region)
+	}
+	if CONF.ProjectName != "flag" {
+		t.Fatalf("flags should override everything, got %s", CONF.ProjectName)
+	}
+}
+
+func TestCloudScopeAndFederation(t *testing.T) {
+	const clouds = `
+clouds:
+  sso:
+    auth_type: v3oidcaccesstoken
+    auth:
+      auth_url: https://keystone.example.com:5000/v3
+      identity_provider: sso
+      protocol: openid
+      access_token: access
+      system_scope: all
+  domain:
+    auth:
+      username: admin
+      domain_id: d1
+  project:
+    auth:
+      username: demo
+      project_name: demo
+      domain_id: d1
+  saml:
+    auth_type: v3samlpassword
+    auth:
+      username: demo
+      identity_provider: adfs
+      identity_provider_url: https://idp.example.com/ecp
+`
+	dir := t.TempDir()
+	path, secure := filepath.Join(dir, "clouds.yaml"), filepath.Join(dir, "secure.yaml")
+	_ = os.WriteFile(path, []byte(clouds), 0600)
+	_ = os.WriteFile(secure, []byte("clouds: {}\n"), 0600)
+	t.Setenv("OS_CLIENT_CONFIG_FILE", path)
+	t.Setenv("OS_CLIENT_SECURE_FILE", secure)
+
+	tests := []struct {
+		cloud string
+		want  Openstack
+	}{
+		{"sso", Openstack{
+			Host: "keystone.example.com", AuthUrl: "https://keystone.example.com:5000/v3", AuthType: "v3oidcaccesstoken",
+			IdentityProvider: "sso", Protocol: "openid", AccessToken: "access", SystemScope: "all",
+		}},
+		{"domain", Openstack{UserName: "admin", DomainId: "d1", ProjectDomainId: "d1", UserDomainId: "d1"}},
+		{"project", Openstack{UserName: "demo", ProjectName: "demo", ProjectDomainId: "d1", UserDomainId: "d1"}},
+		{"saml", Openstack{UserName: "demo", AuthType: "v3samlpassword", IdentityProvider: "adfs", IdentityProviderUrl: "https://idp.example.com/ecp"}},
+	}
+	conf := CONF
+	defer func() { CONF = conf }()
+	for _, tt := range tests {
+		CONF = Server{}
+		cloud, err := LoadCloud(tt.cloud)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cloud.Apply()
+		if !reflect.DeepEqual(CONF.Openstack, tt.want) {
+			t.Errorf("cloud %s: got %+v\nwant %+v", tt.cloud, CONF.Openstack, tt.want)
+		}
+	}
+
+	CONF = Server{}
+	ApplyEnv(func(key string) (string, bool) {
+		v, ok := map[string]string{"OS_DOMAIN_NAME": "Default", "OS_SYSTEM_SCOPE": "all", "OS_PROTOCOL": "saml2"}[key]
+		return v, ok
+	})
+	if CONF.DomainName != "Default" || CONF.SystemScope != "all" || CONF.Protocol != "saml2" {
+		t.Fatalf("env not applied: %+v", CONF.Openstack)
+	}
+}
@@ -10,6 +10,47 @@ type Openstack struct {
 	ExternalNetwork       string
 	ImageId               string
 	FlavorId              string
+	// AuthUrl overrides the keystone endpoint, e.g. https://keystone.example.com:5000/v3
+	AuthUrl               string
+	// Interface and Region select the catalog endpoints, Interface is one of
+	// public, internal or admin and defaults to public
+	Interface             string
+	Region                string
+	// Endpoints overrides the catalog per service, keyed by consts.NOVA etc.
+	Endpoints             map[string]string
+	ProjectId             string
+	ProjectDomainName     string
+	ProjectDomainId       string
+	UserDomainName        string
+	UserDomainId          string
+	// AuthType is the keystoneauth plugin name, e.g. password or v3applicationcredential
+	AuthType              string
+	ApplicationCredentialId     string
+	ApplicationCredentialName   string
+	ApplicationCredentialSecret string
+	// Token is an existing token to re-scope with AuthType token
+	Token                 string
+	// DomainId and DomainName request a domain scoped token, SystemScope a
+	// system scoped one, e.g. all
+	DomainId              string
+	DomainName            string
+	SystemScope           string
+	// IdentityProvider and Protocol select the federation of AuthType
+	// v3oidcaccesstoken, with AccessToken, or v3samlpassword, with the ECP
+	// endpoint IdentityProviderUrl of the identity provider
+	IdentityProvider      string
+	Protocol              string
+	AccessToken           string
+	IdentityProviderUrl   string
+	// CACert is a PEM bundle to verify TLS endpoints with, Insecure skips the verification
+	CACert                string
+	Insecure              bool
+	// PageSize is the limit of each page of list requests, 0 leaves it to the services
+	PageSize              int
+	// ComputeApiVersion and VolumeApiVersion pin the microversion of nova and
+	// cinder, e.g. 2.79, "latest" negotiates the newest the service supports
+	ComputeApiVersion     string
+	VolumeApiVersion      string
 }
 
 type SDN struct {
@@ -0,0 +1,99 @@
+package configs
+
+import (
+	"log"
+	"net/url"
+	"os"
+	"request_openstack/consts"
+	"strconv"
+	"strings"
+)
+
+// ApplyEnv copies the OS_* variables found by lookup into CONF, unset
+// variables keep the current value.
+func ApplyEnv(lookup func(key string) (string, bool)) {
+	set := func(target *string, keys ...string) {
+		for _, key := range keys {
+			if v, ok := lookup(key); ok {
+				*target = v
+				return
+			}
+		}
+	}
+	if authUrl, ok := lookup("OS_AUTH_URL"); ok {
+		CONF.AuthUrl = authUrl
+		if u, err := url.Parse(authUrl); err == nil && u.Hostname() != "" {
+			CONF.Host = u.Hostname()
+		}
+	}
+	set(&CONF.ProjectName, "OS_PROJECT_NAME", "OS_TENANT_NAME")
+	set(&CONF.ProjectId, "OS_PROJECT_ID", "OS_TENANT_ID")
+	set(&CONF.ProjectDomainName, "OS_PROJECT_DOMAIN_NAME", "OS_DEFAULT_DOMAIN_NAME")
+	set(&CONF.ProjectDomainId, "OS_PROJECT_DOMAIN_ID", "OS_DEFAULT_DOMAIN_ID")
+	set(&CONF.UserDomainName, "OS_USER_DOMAIN_NAME", "OS_DEFAULT_DOMAIN_NAME")
+	set(&CONF.UserDomainId, "OS_USER_DOMAIN_ID", "OS_DEFAULT_DOMAIN_ID")
+	set(&CONF.Region, "OS_REGION_NAME")
+	set(&CONF.Interface, "OS_INTERFACE", "OS_ENDPOINT_TYPE")
+	set(&CONF.AuthType, "OS_AUTH_TYPE", "OS_AUTH_PLUGIN")
+	set(&CONF.ApplicationCredentialId, "OS_APPLICATION_CREDENTIAL_ID")
+	set(&CONF.ApplicationCredentialName, "OS_APPLICATION_CREDENTIAL_NAME")
+	set(&CONF.ApplicationCredentialSecret, "OS_APPLICATION_CREDENTIAL_SECRET")
+	set(&CONF.Token, "OS_TOKEN")
+	set(&CONF.DomainId, "OS_DOMAIN_ID")
+	set(&CONF.DomainName, "OS_DOMAIN_NAME")
+	set(&CONF.SystemScope, "OS_SYSTEM_SCOPE")
+	set(&CONF.IdentityProvider, "OS_IDENTITY_PROVIDER")
+	set(&CONF.Protocol, "OS_PROTOCOL")
+	set(&CONF.AccessToken, "OS_ACCESS_TOKEN")
+	set(&CONF.IdentityProviderUrl, "OS_IDENTITY_PROVIDER_URL")
+	set(&CONF.CACert, "OS_CACERT")
+	set(&CONF.ComputeApiVersion, "OS_COMPUTE_API_VERSION")
+	set(&CONF.VolumeApiVersion, "OS_VOLUME_API_VERSION")
+	if v, ok := lookup("OS_INSECURE"); ok {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Printf("Ignore invalid OS_INSECURE %q", v)
+		} else {
+			CONF.Insecure = insecure
+		}
+	}
+
+	// the admin password is kept apart, the tool authenticates both as
+	// admin and as the test user
+	set(&CONF.UserName, "OS_USERNAME")
+	if password, ok := lookup("OS_PASSWORD"); ok {
+		if CONF.UserName == consts.ADMIN {
+			CONF.AdminPassword = password
+		} else {
+			CONF.UserPassword = password
+		}
+	}
+}
+
+// LoadEnv applies the OS_* variables of the process environment.
+func LoadEnv() {
+	ApplyEnv(os.LookupEnv)
+}
+
+// parseExports reads the export lines of an openrc file, e.g.
+// export OS_AUTH_URL="https://keystone.example.com:5000/v3"
+func parseExports(content string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(key, "OS_") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values
+}
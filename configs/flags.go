@@ -0,0 +1,79 @@
+package configs
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// osFlags are the --os-* flags of the python openstack client understood by
+// Flags, each overriding the OS_* variable of the same name.
+var osFlags = []string{
+	"os-auth-url", "os-username", "os-password", "os-project-name", "os-project-id",
+	"os-project-domain-name", "os-project-domain-id", "os-user-domain-name", "os-user-domain-id",
+	"os-region-name", "os-interface", "os-auth-type", "os-token", "os-cacert",
+	"os-application-credential-id", "os-application-credential-name", "os-application-credential-secret",
+	"os-domain-id", "os-domain-name", "os-system-scope",
+	"os-identity-provider", "os-protocol", "os-access-token", "os-identity-provider-url",
+	"os-compute-api-version", "os-volume-api-version",
+}
+
+// Flags binds the --os-cloud and --os-* flags to a flag set.
+type Flags struct {
+	fs       *flag.FlagSet
+	cloud    *string
+	insecure *bool
+	values   map[string]*string
+}
+
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		fs:       fs,
+		cloud:    fs.String("os-cloud", "", "Cloud name in clouds.yaml (Env: OS_CLOUD)"),
+		insecure: fs.Bool("os-insecure", false, "Disable TLS verification (Env: OS_INSECURE)"),
+		values:   make(map[string]*string),
+	}
+	for _, name := range osFlags {
+		f.values[name] = fs.String(name, "", fmt.Sprintf("(Env: %s)", envName(name)))
+	}
+	return f
+}
+
+func envName(flagName string) string {
+	return strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Load applies, in increasing precedence, the cloud selected by --os-cloud or
+// OS_CLOUD, the OS_* environment and the --os-* flags set on the command
+// line on top of what is already in CONF, e.g. from openstack.yaml or a
+// keystonerc. Call it after the flag set was parsed.
+func (f *Flags) Load() error {
+	cloud := *f.cloud
+	if cloud == "" {
+		cloud = os.Getenv("OS_CLOUD")
+	}
+	if cloud != "" {
+		c, err := LoadCloud(cloud)
+		if err != nil {
+			return err
+		}
+		c.Apply()
+	}
+	LoadEnv()
+
+	set := make(map[string]string)
+	f.fs.Visit(func(fl *flag.Flag) {
+		if v, ok := f.values[fl.Name]; ok {
+			set[envName(fl.Name)] = *v
+		}
+		if fl.Name == "os-insecure" {
+			set["OS_INSECURE"] = fmt.Sprint(*f.insecure)
+		}
+	})
+	ApplyEnv(func(key string) (string, bool) {
+		v, ok := set[key]
+		return v, ok
+	})
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"log"
 	"os"
 	"regexp"
-	"request_openstack/consts"
 )
 
 // [root@con01 ~]# cat /etc/kolla/admin-openrc.sh
@@ -32,22 +31,16 @@ func Parse(pattern, content string) string {
 	return ""
 }
 
+// ParseKeystonerc applies the OS_* exports of an openrc file like the one
+// above to CONF.
 func ParseKeystonerc(keystonerc string) {
 	data, err := os.ReadFile(keystonerc)
 	if err != nil {
 		log.Fatalln("Failed to read keystonerc file", err)
 	}
-	content := string(data)
-	userName := Parse("OS_USERNAME=(.*?)\n", content)
-	projectName := Parse("OS_PROJECT_NAME=(.*?)\n", content)
-	password := Parse("OS_PASSWORD=(.*?)\n", content)
-	host := Parse("OS_AUTH_URL=http://(.*?):", content)
-	CONF.UserName = userName
-	CONF.ProjectName = projectName
-	if userName == consts.ADMIN {
-		CONF.AdminPassword = password
-	} else {
-		CONF.UserPassword = password
-	}
-	CONF.Host = host
+	values := parseExports(string(data))
+	ApplyEnv(func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	})
 }
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"fmt"
+	"log"
 	"github.com/spf13/viper"
 	"os"
 	"path"
@@ -25,6 +26,11 @@ func Viper() *viper.Viper {
 	// linux path
 	currentPath, _ := os.Getwd()
 	config := fmt.Sprintf("%s/openstack.yaml", currentPath)
+	// clouds.yaml or the OS_* environment may carry the whole configuration
+	if _, err := os.Stat(config); os.IsNotExist(err) {
+		log.Printf("No %s, relying on clouds.yaml and the OS_* environment", config)
+		return v
+	}
 	v.SetConfigFile(config)
 
 	v.SetConfigType("yaml")
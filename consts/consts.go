@@ -21,9 +21,16 @@ const (
     PATCH                      = "PATCH"
     ContentTypeJson            = "application/json"
     ImagePatchJson             = "application/openstack-images-v2.1-json-patch"
+    ContentTypePaos            = "application/vnd.paos+xml"
+    PaosHeader                 = `ver="urn:liberty:paos:2003-08";"urn:oasis:names:tc:SAML:2.0:profiles:SSO:ecp"`
 
     NOVA                       = "nova"
     CINDER                     = "cinder"
+    NEUTRON                    = "neutron"
+    GLANCE                     = "glance"
+    OCTAVIA                    = "octavia"
+    KEYSTONE                   = "keystone"
+    SDN                        = "sdn"
 
     VOLUME	                   = "volume"
     VOLUMES	                   = "volumes"
@@ -37,6 +44,7 @@ const (
     PORTS                      = "ports"
     QOS_POLICIES               = "qos_policies"
     QOS_POLICY                 = "qos_policy"
+    POLICY                     = "policy"
     BANDWIDTH_LIMIT_RULES      = "bandwidth_limit_rules"
     BANDWIDTH_LIMIT_RULE       = "bandwidth_limit_rule"
     DSCP_MARKING_RULES         = "dscp_marking_rules"
@@ -76,9 +84,13 @@ const (
     VPNSERVICE                 = "vpnservice"
     VPNSERVICES                = "vpnservices"
     ENDPOINTGROUPS             = "endpoint_groups"
+    ENDPOINTGROUP              = "endpoint_group"
     IKEPOLICIES                = "ikepolicies"
+    IKEPOLICY                  = "ikepolicy"
     IPSECPOLICIES              = "ipsecpolicies"
+    IPSECPOLICY                = "ipsecpolicy"
     IPSECCONNECTIONS           = "ipsec_site_connections"
+    IPSECCONNECTION            = "ipsec_site_connection"
     LOADBALANCERS              = "loadbalancers"
     LOADBALANCER               = "loadbalancer"
     LISTENERS                  = "listeners"
@@ -93,8 +105,14 @@ const (
     L7POLICY                   = "l7policy"
     L7RULES                    = "l7rules"
     L7RULE                     = "l7rule"
+    RULE                       = "rule"
     VpcConnections             = "vpc_connections"
     VpcConnection              = "vpc_connection"
+    VpnService                 = "vpn_service"
+    EndpointGroup              = "endpoint_group"
+    IkePolicy                  = "ike_policy"
+    IpsecPolicy                = "ipsec_policy"
+    IpsecConnection            = "ipsec_connection"
     Images                     = "images"
     Snats                      = "snats"
     Snat                       = "snat"
@@ -116,6 +134,8 @@ const (
 
     ADMIN                      = "admin"
 	AuthToken                  = "X-Auth-Token"
+	ApiVersionHeader           = "OpenStack-API-Version"
+	NovaApiVersionHeader       = "X-OpenStack-Nova-API-Version"
 	Timeout                    = 2 * 60 * time.Second
 	IntervalTime               = 5 * time.Second
 
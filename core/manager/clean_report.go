@@ -0,0 +1,236 @@
+package manager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"request_openstack/internal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CleanReport is the structured report of a Cleaner run, see Cleaner.Report.
+// In a dry run the succeeded resources are those that would be deleted.
+type CleanReport struct {
+	DryRun   bool            `json:"dry_run"`
+	Projects []ProjectReport `json:"projects"`
+}
+
+// ProjectReport is the report of a project, its types in the order of
+// OrderResources. Error is why the project could not be cleaned at all.
+type ProjectReport struct {
+	Project string       `json:"project"`
+	Seconds float64      `json:"duration_seconds"`
+	Error   string       `json:"error,omitempty"`
+	Types   []TypeReport `json:"types"`
+}
+
+// TypeReport is the report of the resources of a type. Error is why the
+// type was not cleaned, e.g. its resources could not be listed or one of
+// the types deleted before it failed.
+type TypeReport struct {
+	Type      string           `json:"type"`
+	Totals    int              `json:"totals"`
+	Succeeded []string         `json:"succeeded"`
+	Failed    []FailedResource `json:"failed"`
+	Seconds   float64          `json:"duration_seconds"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// FailedResource is a resource left behind, StatusCode and Fault are those
+// of the answer of OpenStack, when it answered.
+type FailedResource struct {
+	Id         string `json:"id"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Fault      string `json:"fault,omitempty"`
+	Error      string `json:"error"`
+}
+
+// outputId returns the id of the resource of a delete output, the value of
+// its only parameter or its parameters as k=v pairs, e.g. those of a router
+// interface.
+func outputId(params map[string]string) string {
+	if len(params) == 1 {
+		for _, value := range params {
+			return value
+		}
+	}
+	pairs := make([]string, 0, len(params))
+	for key, value := range params {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// failedResource reports a failed delete output, its Response is the error.
+func failedResource(output internal.Output) FailedResource {
+	failed := FailedResource{Id: outputId(output.ParametersMap)}
+	err, ok := output.Response.(error)
+	if !ok {
+		failed.Error = fmt.Sprint(output.Response)
+		return failed
+	}
+	failed.Error = err.Error()
+	var apiErr *internal.APIError
+	if errors.As(err, &apiErr) {
+		failed.StatusCode, failed.Fault = apiErr.StatusCode, apiErr.Fault
+	}
+	return failed
+}
+
+func seconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+// Failures counts the resources left behind and the types and projects not
+// cleaned.
+func (r *CleanReport) Failures() (resources, errs int) {
+	for _, project := range r.Projects {
+		if project.Error != "" {
+			errs++
+		}
+		for _, resourceType := range project.Types {
+			resources += len(resourceType.Failed)
+			if resourceType.Error != "" {
+				errs++
+			}
+		}
+	}
+	return resources, errs
+}
+
+// Exit code policies of a clean run, see CleanReport.ExitCode.
+const (
+	// FailOnFailure fails a run leaving any resource behind
+	FailOnFailure = "failure"
+	// FailOnError only fails a run that could not clean a type or a project
+	FailOnError = "error"
+	FailOnNever = "never"
+)
+
+// ExitCleanFailed is the exit code of a clean run its policy fails, apart
+// from 1 for a run that could not start.
+const ExitCleanFailed = 3
+
+// ParseFailOn checks an exit code policy, "" is FailOnFailure.
+func ParseFailOn(s string) (string, error) {
+	switch s {
+	case "":
+		return FailOnFailure, nil
+	case FailOnFailure, FailOnError, FailOnNever:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid fail-on policy %q, expected %s, %s or %s", s, FailOnFailure, FailOnError, FailOnNever)
+}
+
+// ExitCode returns the exit code of the run by policy, 0 or ExitCleanFailed.
+func (r *CleanReport) ExitCode(policy string) int {
+	resources, errs := r.Failures()
+	switch {
+	case policy == FailOnNever:
+		return 0
+	case errs > 0, policy == FailOnFailure && resources > 0:
+		return ExitCleanFailed
+	}
+	return 0
+}
+
+// JSON formats the report as indented JSON.
+func (r *CleanReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit formats the report as JUnit XML, a test suite per project with a
+// test case per resource, classname <project>.<type>, failed when it was
+// left behind and skipped in a dry run. A type not cleaned is a test case
+// in error named after it.
+func (r *CleanReport) JUnit() ([]byte, error) {
+	suites := junitSuites{Name: "clean"}
+	for _, project := range r.Projects {
+		suite := junitSuite{Name: project.Project, Time: project.Seconds}
+		if project.Error != "" {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name: project.Project, Classname: project.Project,
+				Error: &junitMessage{Message: project.Error},
+			})
+			suite.Errors++
+		}
+		for _, resourceType := range project.Types {
+			classname := project.Project + "." + resourceType.Type
+			if resourceType.Error != "" {
+				suite.Cases = append(suite.Cases, junitCase{
+					Name: resourceType.Type, Classname: classname, Time: resourceType.Seconds,
+					Error: &junitMessage{Message: resourceType.Error},
+				})
+				suite.Errors++
+			}
+			for _, id := range resourceType.Succeeded {
+				testCase := junitCase{Name: id, Classname: classname}
+				if r.DryRun {
+					testCase.Skipped = &junitMessage{Message: "would delete"}
+					suite.Skipped++
+				}
+				suite.Cases = append(suite.Cases, testCase)
+			}
+			for _, failed := range resourceType.Failed {
+				message := failed.Fault
+				if message == "" {
+					message = failed.Error
+				}
+				failure := &junitMessage{Message: message, Text: failed.Error}
+				if failed.StatusCode != 0 {
+					failure.Type = fmt.Sprintf("HTTP %d", failed.StatusCode)
+				}
+				suite.Cases = append(suite.Cases, junitCase{Name: failed.Id, Classname: classname, Failure: failure})
+				suite.Failures++
+			}
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"request_openstack/internal"
+	"testing"
+)
+
+// testCleanReport has a project with a type cleaned, left behind a
+// resource and one not cleaned, and a project not cleaned at all.
+func testCleanReport(dryRun bool) *CleanReport {
+	return &CleanReport{
+		DryRun: dryRun,
+		Projects: []ProjectReport{
+			{
+				Project: "p1",
+				Seconds: 1.5,
+				Types: []TypeReport{
+					{
+						Type:      "port",
+						Totals:    3,
+						Succeeded: []string{"a", "b"},
+						Failed:    []FailedResource{{Id: "c", StatusCode: 409, Fault: "port in use", Error: "neutron DELETE ports/c: 409 port in use"}},
+						Seconds:   0.25,
+					},
+					{Type: "network", Succeeded: []string{}, Failed: []FailedResource{}, Error: "list failed"},
+				},
+			},
+			{Project: "p2", Error: "no token", Types: []TypeReport{}},
+		},
+	}
+}
+
+func TestCleanReportJUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		suites [3]int // tests, failures, errors of the run
+		p1     [4]int // tests, failures, errors, skipped of p1
+	}{
+		{"clean", false, [3]int{5, 1, 2}, [4]int{4, 1, 1, 0}},
+		{"dry run", true, [3]int{5, 1, 2}, [4]int{4, 1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := testCleanReport(tt.dryRun).JUnit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var suites junitSuites
+			if err = xml.Unmarshal(data, &suites); err != nil {
+				t.Fatalf("%s: %s", err, data)
+			}
+			if got := [3]int{suites.Tests, suites.Failures, suites.Errors}; got != tt.suites {
+				t.Errorf("tests, failures, errors %v, want %v", got, tt.suites)
+			}
+			if len(suites.Suites) != 2 {
+				t.Fatalf("%d suites, want 2", len(suites.Suites))
+			}
+			p1 := suites.Suites[0]
+			if got := [4]int{p1.Tests, p1.Failures, p1.Errors, p1.Skipped}; got != tt.p1 {
+				t.Errorf("p1 tests, failures, errors, skipped %v, want %v", got, tt.p1)
+			}
+			cases := make(map[string]junitCase)
+			for _, c := range p1.Cases {
+				cases[c.Name] = c
+			}
+			if c := cases["c"]; c.Classname != "p1.port" || c.Failure == nil || c.Failure.Type != "HTTP 409" || c.Failure.Message != "port in use" {
+				t.Errorf("failed case %+v, failure %+v", c, c.Failure)
+			}
+			if c := cases["network"]; c.Error == nil || c.Error.Message != "list failed" {
+				t.Errorf("type not cleaned %+v", c)
+			}
+			for _, id := range []string{"a", "b"} {
+				skipped := cases[id].Skipped
+				if (skipped != nil) != tt.dryRun || skipped != nil && skipped.Message != "would delete" {
+					t.Errorf("case %s skipped %+v in a dry run %v", id, skipped, tt.dryRun)
+				}
+			}
+			if p2 := suites.Suites[1]; p2.Tests != 1 || p2.Errors != 1 || p2.Cases[0].Error.Message != "no token" {
+				t.Errorf("project not cleaned %+v", p2)
+			}
+		})
+	}
+}
+
+func TestCleanReportJSON(t *testing.T) {
+	report := testCleanReport(true)
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded CleanReport
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, report) {
+		t.Fatalf("round trip %+v\nwant %+v", decoded, *report)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["dry_run"] != true {
+		t.Fatalf("dry_run %v", fields["dry_run"])
+	}
+}
+
+func TestCleanReportExitCode(t *testing.T) {
+	clean := TypeReport{Type: "port", Succeeded: []string{"a"}}
+	leftBehind := TypeReport{Type: "port", Failed: []FailedResource{{Id: "c", Error: "in use"}}}
+	notCleaned := TypeReport{Type: "port", Error: "list failed"}
+	tests := []struct {
+		name    string
+		project ProjectReport
+		want    map[string]int
+	}{
+		{"clean", ProjectReport{Types: []TypeReport{clean}}, map[string]int{FailOnFailure: 0, FailOnError: 0, FailOnNever: 0}},
+		{"resource left behind", ProjectReport{Types: []TypeReport{clean, leftBehind}}, map[string]int{FailOnFailure: ExitCleanFailed, FailOnError: 0, FailOnNever: 0}},
+		{"type not cleaned", ProjectReport{Types: []TypeReport{notCleaned}}, map[string]int{FailOnFailure: ExitCleanFailed, FailOnError: ExitCleanFailed, FailOnNever: 0}},
+		{"project not cleaned", ProjectReport{Error: "no token"}, map[string]int{FailOnFailure: ExitCleanFailed, FailOnError: ExitCleanFailed, FailOnNever: 0}},
+	}
+	for _, tt := range tests {
+		report := &CleanReport{Projects: []ProjectReport{tt.project}}
+		for policy, want := range tt.want {
+			if got := report.ExitCode(policy); got != want {
+				t.Errorf("%s: exit code %d with policy %s, want %d", tt.name, got, policy, want)
+			}
+		}
+	}
+	if _, err := ParseFailOn("sometimes"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
+
+func TestFailedResource(t *testing.T) {
+	apiErr := &internal.APIError{StatusCode: 409, Service: "neutron", Fault: "port in use"}
+	tests := []struct {
+		name   string
+		output internal.Output
+		want   FailedResource
+	}{
+		{
+			"api error",
+			internal.Output{ParametersMap: map[string]string{"id": "c"}, Response: fmt.Errorf("delete: %w", apiErr)},
+			FailedResource{Id: "c", StatusCode: 409, Fault: "port in use", Error: fmt.Errorf("delete: %w", apiErr).Error()},
+		},
+		{
+			"other error",
+			internal.Output{ParametersMap: map[string]string{"router_id": "r", "subnet_id": "s"}, Response: errors.New("timeout")},
+			FailedResource{Id: "router_id=r,subnet_id=s", Error: "timeout"},
+		},
+	}
+	for _, tt := range tests {
+		if got := failedResource(tt.output); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
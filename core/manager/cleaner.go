@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"reflect"
 	"request_openstack/configs"
@@ -14,105 +16,188 @@ import (
 
 
 type Cleaner struct {
+	// Workers bounds the resource types deleted at a time in a project, 0
+	// for no bound
+	Workers                        int
+	// DryRun lists the resources per type in the order they would be deleted
+	// and reports them as would delete, no request changing them is sent
+	DryRun                         bool
+	// Filter selects the resources deleted, all of them when nil
+	Filter                         *CleanFilter
 	adminManager                   *Manager
 	token                          string
 	runners                        []*ProjectRunner
+	lastReport                     *CleanReport
 	wg                             sync.WaitGroup
 }
 
-func initProjectRunners(keystone *internal.Keystone, token string, projects []string) []*ProjectRunner {
-	toDeleteProjects := checkProjectExist(keystone, projects)
+func initProjectRunners(keystone *internal.Keystone, auth *internal.TokenProvider, projects []string) ([]*ProjectRunner, error) {
+	toDeleteProjects, err := checkProjectExist(keystone, projects)
+	if err != nil {
+		return nil, err
+	}
 	runners := make([]*ProjectRunner, 0)
+	endpoints, err := resolveEndpoints(keystone)
+	if err != nil {
+		return nil, err
+	}
 	for _, projectName := range toDeleteProjects {
-		projectRunner := NewProjectRunner(projectName, token)
+		projectRunner, err := NewProjectRunner(projectName, auth, endpoints)
+		if err != nil {
+			return nil, err
+		}
 		runners = append(runners, projectRunner)
 	}
-	return runners
+	return runners, nil
 }
 
-func NewCleaner(projects []string) *Cleaner {
+func NewCleaner(projects []string) (*Cleaner, error) {
 	client := internal.NewClient()
 	keystone := internal.NewKeystone(client)
-	token := keystone.GetToken(consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
-	keystone.SetHeader(consts.AuthToken, token)
+	auth, token, err := newTokenProvider(keystone, consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
+	if err != nil {
+		return nil, err
+	}
 	adminManager := &Manager{
 		Keystone: keystone,
 	}
+	runners, err := initProjectRunners(keystone, auth, projects)
+	if err != nil {
+		return nil, err
+	}
 	return &Cleaner{
 		adminManager: adminManager,
-		runners: initProjectRunners(keystone, token, projects),
+		runners: runners,
 		token: token,
-	}
+	}, nil
 }
 
-func checkProjectExist(keystone *internal.Keystone, projects []string) []string {
+func checkProjectExist(keystone *internal.Keystone, projects []string) ([]string, error) {
 	toDeleteProjects := make([]string, 0)
 	for _, projectName := range projects {
-		if keystone.GetProjectId(projectName) == "" {
+		projectId, err := keystone.GetProjectId(projectName)
+		if err != nil {
+			return nil, err
+		}
+		if projectId == "" {
 		    log.Printf("@@@@@@@@@@@@@@@Project %s not exist, not to delete resources\n", projectName)
 		} else {
 			toDeleteProjects = append(toDeleteProjects, projectName)
 		}
 	}
-	return toDeleteProjects
+	return toDeleteProjects, nil
 }
 
 func (c *Cleaner) Run() {
+	_ = c.RunContext(context.Background())
+}
+
+// RunContext cleans the projects with ctx bound to their managers, once ctx
+// is done the remaining deletions fail fast instead of waiting on OpenStack.
+func (c *Cleaner) RunContext(ctx context.Context) error {
+	if err := c.Filter.Validate(); err != nil {
+		return err
+	}
 	for _, runner := range c.runners {
-    	c.wg.Add(1)
-    	go runner.Run(&c.wg)
+		runner.manager.SetContext(ctx)
+		runner.workers = c.Workers
+		runner.dryRun = c.DryRun
+		runner.manager.SetDryRun(c.DryRun)
+		runner.filter = c.Filter
+		runner.manager.SetFilter(c.Filter.resources())
+        c.wg.Add(1)
+        go runner.run(ctx, &c.wg)
 	}
 	c.wg.Wait()
     c.report()
+	return ctx.Err()
 }
 
 func (c *Cleaner) report() {
+	c.lastReport = &CleanReport{DryRun: c.DryRun}
 	for _, runner := range c.runners {
-		runner.makeReport()
+		c.lastReport.Projects = append(c.lastReport.Projects, runner.makeReport())
 	}
 }
 
+// Report returns the report of the last run, nil before any.
+func (c *Cleaner) Report() *CleanReport {
+	return c.lastReport
+}
+
 type ProjectRunner struct {
 	projectName        string
 	manager            *Manager
-	depNodes           map[string]*Node
-	completedChannel   chan struct{}
+	workers            int
+	dryRun             bool
+	filter             *CleanFilter
+	// errors and durations of the types, err of the project, see report
+	errors             map[string]error
+	durations          map[string]time.Duration
+	duration           time.Duration
+	err                error
+	mu                 sync.Mutex
 }
 
-func NewProjectRunner(projectName string, token string) *ProjectRunner {
+func NewProjectRunner(projectName string, auth *internal.TokenProvider, endpoints map[string]string) (*ProjectRunner, error) {
+	token, err := auth.Token()
+	if err != nil {
+		return nil, err
+	}
 	client := internal.NewClient()
 	keystone := internal.NewKeystone(client)
 	keystone.SetHeader(consts.AuthToken, token)
-	projectId := keystone.GetProjectId(projectName)
-	adminProjectId := keystone.GetProjectId(consts.ADMIN)
+	keystone.Auth = auth
+	projectId, err := keystone.GetProjectId(projectName)
+	if err != nil {
+		return nil, err
+	}
+	adminProjectId, err := keystone.GetProjectId(consts.ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	glance := internal.NewGlance(token, projectId, client)
+	glance.UrlPrefix, glance.Auth = endpoints[consts.GLANCE], auth
+	octavia := internal.NewLB(token, client)
+	octavia.UrlPrefix, octavia.Auth = endpoints[consts.OCTAVIA], auth
 	m := &Manager{
 		Keystone: keystone,
 		Nova: internal.NewNova(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(novaUri, defaultClient)),
+			internal.WithEndpoint(endpoints[consts.NOVA], defaultClient),
+			internal.WithAuth(auth)),
 		Neutron: internal.NewNeutron(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(neutronUri, defaultClient)),
+			internal.WithEndpoint(endpoints[consts.NEUTRON], defaultClient),
+			internal.WithAuth(auth)),
 		Cinder: internal.NewCinder(
 			internal.WithAdminProjectId(adminProjectId),
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(cinderUri, defaultClient)),
-		Glance:   internal.NewGlance(token, projectId, client),
-		Octavia:  internal.NewLB(token, client),
+			internal.WithEndpoint(endpoints[consts.CINDER], defaultClient),
+			internal.WithAuth(auth)),
+		Glance:   glance,
+		Octavia:  octavia,
 	}
-	depNodes := InitNodes()
 	return &ProjectRunner{
 		projectName: projectName,
 		manager: m,
-		depNodes: depNodes,
-		completedChannel: make(chan struct{}, len(depNodes)),
-	}
+	}, nil
+}
+
+// deleteMethods are the Delete methods of the manager not named after their
+// type by getMethodName.
+var deleteMethods = map[string]string{
+	consts.L7POLICY: "DeleteL7Policies",
+	consts.L7RULE:   "DeleteL7Rules",
 }
 
 func (p *ProjectRunner) getMethodName(resourceType string) string {
+	if method, ok := deleteMethods[resourceType]; ok {
+		return method
+	}
 	resourceType = utils.Pluralize(resourceType)
 	var res = "Delete"
 	for _, s := range strings.Split(resourceType, "_") {
@@ -121,37 +206,71 @@ func (p *ProjectRunner) getMethodName(resourceType string) string {
 	return res
 }
 
-func (p *ProjectRunner) callNode(node *Node) {
-	methodName := p.getMethodName(node.resourceType)
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("call error occur", err)
+// graph returns the resource types to delete as a DAG, a type is deleted
+// after the types depending on it in ResourceDependencies. A failed type does
+// not stop the others, what it left is reported.
+func (p *ProjectRunner) graph() *DAG {
+	requiredBy := make(map[string][]string)
+	for _, resourceType := range OrderResources {
+		for _, dep := range ResourceDependencies[resourceType] {
+			requiredBy[dep] = append(requiredBy[dep], resourceType)
 		}
-		for _, dep := range node.dependencies {
-			p.depNodes[dep.resourceType].monitorDeleteChannel <- struct{}{}
-			log.Printf("%s %s completed, notify %s", node.resourceType, methodName, dep.resourceType)
+	}
+	dag := NewDAG(p.workers)
+	for _, resourceType := range OrderResources {
+		dag.AddAfter(resourceType, requiredBy[resourceType], p.deleter(resourceType))
+	}
+	dag.OnDone = func(resourceType string, err error) {
+		if err != nil {
+			log.Printf("Cleaning %s failed: %v", resourceType, err)
 		}
-		log.Printf("%s call completed", node.resourceType)
-		p.completedChannel <- struct{}{}
-	}()
+		log.Printf("%s call completed", resourceType)
+	}
+	return dag
+}
 
-	if len(node.monitorDeleteChannel) != cap(node.monitorDeleteChannel) {
-		for len(node.monitorDeleteChannel) != cap(node.monitorDeleteChannel) {}
+// deleter returns the node deleting all resources of resourceType by the
+// Delete method of the manager named after it, e.g. DeleteFloatingips.
+func (p *ProjectRunner) deleter(resourceType string) func(ctx context.Context) error {
+	methodName := p.getMethodName(resourceType)
+	return func(ctx context.Context) error {
+		if !p.filter.cleans(resourceType) {
+			log.Printf("Cleaning %s is skipped by the filter", resourceType)
+			return nil
+		}
+		method := reflect.ValueOf(p.manager).MethodByName(methodName)
+		if !method.IsValid() {
+			return fmt.Errorf("manager has no method %s", methodName)
+		}
+		log.Printf("Cleaning %s is in progress", resourceType)
+		start := time.Now()
+		defer func() {
+			p.mu.Lock()
+			p.durations[resourceType] = time.Since(start)
+			p.mu.Unlock()
+		}()
+		results := method.Call([]reflect.Value{})
+		if len(results) == 1 && !results[0].IsNil() {
+			return results[0].Interface().(error)
+		}
+		return nil
 	}
-	log.Printf("Cleaning %s is in progress", node.resourceType)
-	reflect.ValueOf(p.manager).MethodByName(methodName).Call([]reflect.Value{})
 }
 
 func (p *ProjectRunner) Run(wg *sync.WaitGroup) {
-	defer wg.Done()
+	p.run(context.Background(), wg)
+}
 
-	for resourceType, _ := range p.depNodes {
-		go p.callNode(p.depNodes[resourceType])
-	}
+func (p *ProjectRunner) run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
 
-	for len(p.completedChannel) != cap(p.completedChannel) {
-		time.Sleep(2 * time.Second)
-		log.Println("waiting for completed...")
+	start := time.Now()
+	p.durations = make(map[string]time.Duration)
+	p.errors, p.err = p.graph().Run(ctx)
+	p.duration = time.Since(start)
+	if p.err != nil {
+		log.Printf("@@@@@@@@@@@@@@@Clean project %s failed: %v", p.projectName, p.err)
+		return
 	}
 	//p.manager.DeleteUserByName(p.projectName)
 	//p.manager.DeleteProjectByName(p.projectName)
@@ -172,16 +291,28 @@ func mergeMaps(map1, map2 map[string]chan internal.Output) map[string]chan inter
 	return mergedMap
 }
 
-func (p *ProjectRunner) makeReport() {
+// drain receives the outputs the delete calls of the run left in ch, it is
+// not closed as the next run of the manager may send on it again.
+func drain(ch chan internal.Output) []internal.Output {
+	outputs := make([]internal.Output, 0, len(ch))
+	for len(ch) > 0 {
+		outputs = append(outputs, <-ch)
+	}
+	return outputs
+}
+
+// makeReport logs the outputs of the delete calls of each type and returns
+// them as the report of the project.
+func (p *ProjectRunner) makeReport() ProjectReport {
 	outputs := mergeMaps(p.manager.Neutron.DeleteChannels, p.manager.Cinder.DeleteChannels)
 	outputs = mergeMaps(outputs, p.manager.Nova.DeleteChannels)
 	outputs = mergeMaps(outputs, p.manager.Octavia.DeleteChannels)
 	reporters := make(map[string]reporter)
 	for resourceType, ch := range outputs {
-		totals := len(ch)
-		close(ch)
+		drained := drain(ch)
+		totals := len(drained)
 		failed, succeed := make([]internal.Output, 0), make([]map[string]string, 0)
-		for output := range ch {
+		for _, output := range drained {
 			if !output.Success {
 				failed = append(failed, output)
 			} else {
@@ -196,12 +327,31 @@ func (p *ProjectRunner) makeReport() {
 		}
 		reporters[resourceType] = r
 	}
-	log.Printf("Project %s reported:***********************************************\n", p.projectName)
+	if p.dryRun {
+		log.Printf("Project %s reported, dry run:**************************************\n", p.projectName)
+	} else {
+		log.Printf("Project %s reported:***********************************************\n", p.projectName)
+	}
+	report := ProjectReport{Project: p.projectName, Seconds: seconds(p.duration)}
+	if p.err != nil {
+		report.Error = p.err.Error()
+	}
 	for _, resourceType := range OrderResources {
+		if !p.filter.cleans(resourceType) {
+			continue
+		}
 		output := reporters[resourceType]
-        log.Printf("Resource %-*s-----> totals %d, succeed %s, failed %+v\n",
-        	25, resourceType, output.totals, output.succeed, output.failed)
+		output.resourceType, output.dryRun = resourceType, p.dryRun
+		log.Println(output.String())
+		// a type is not cleaned when the project could not be, it is
+		// reported failed with the error of the project
+		err := p.errors[resourceType]
+		if err == nil {
+			err = p.err
+		}
+		report.Types = append(report.Types, output.typeReport(err, p.durations[resourceType]))
 	}
+	return report
 }
 
 type reporter struct {
@@ -209,5 +359,91 @@ type reporter struct {
 	totals                  int
 	failed                  []internal.Output
 	succeed                 []map[string]string
+	// dryRun marks the succeeded resources as would delete
+	dryRun                  bool
 }
 
+// typeReport returns the report of the type, err is the error of its node.
+func (r reporter) typeReport(err error, duration time.Duration) TypeReport {
+	report := TypeReport{
+		Type:      r.resourceType,
+		Totals:    r.totals,
+		Succeeded: make([]string, 0, len(r.succeed)),
+		Failed:    make([]FailedResource, 0, len(r.failed)),
+		Seconds:   seconds(duration),
+	}
+	for _, params := range r.succeed {
+		report.Succeeded = append(report.Succeeded, outputId(params))
+	}
+	for _, output := range r.failed {
+		report.Failed = append(report.Failed, failedResource(output))
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+func (r reporter) String() string {
+	succeed := "succeed"
+	if r.dryRun {
+		succeed = "would delete"
+	}
+	return fmt.Sprintf("Resource %-*s-----> totals %d, %s %s, failed %+v",
+		25, r.resourceType, r.totals, succeed, r.succeed, r.failed)
+}
+
+// CleanFilter selects what the cleaner deletes, the resource types and,
+// among their resources, those the rules of internal.ResourceFilter select,
+// e.g. the leftovers of test runs in a long-lived project.
+type CleanFilter struct {
+	// Types are the resource types deleted, all of OrderResources when empty
+	Types        []string
+	// ExcludeTypes are the resource types left alone
+	ExcludeTypes []string
+	internal.ResourceFilter
+}
+
+// Validate checks that the types of the filter are resource types of the
+// cleaner.
+func (f *CleanFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, resourceType := range append(append([]string{}, f.Types...), f.ExcludeTypes...) {
+		if _, ok := ResourceDependencies[resourceType]; !ok {
+			return fmt.Errorf("unknown resource type %q to clean", resourceType)
+		}
+	}
+	return nil
+}
+
+// cleans tells whether the resources of resourceType are deleted.
+func (f *CleanFilter) cleans(resourceType string) bool {
+	if f == nil {
+		return true
+	}
+	for _, excluded := range f.ExcludeTypes {
+		if excluded == resourceType {
+			return false
+		}
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, included := range f.Types {
+		if included == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// resources returns the rules selecting the resources, nil when there are
+// none so that the clients list no parents to filter by.
+func (f *CleanFilter) resources() *internal.ResourceFilter {
+	if f == nil || f.Name == nil && len(f.Tags) == 0 && f.OlderThan == 0 && len(f.Protected) == 0 {
+		return nil
+	}
+	return &f.ResourceFilter
+}
@@ -0,0 +1,67 @@
+package manager
+
+import (
+	"errors"
+	"request_openstack/consts"
+	"request_openstack/internal"
+	"testing"
+)
+
+func TestMakeReportRunsAgain(t *testing.T) {
+	neutron := &internal.Neutron{DeleteChannels: make(map[string]chan internal.Output)}
+	runner := &ProjectRunner{
+		projectName: "p",
+		manager:     &Manager{Neutron: neutron, Cinder: &internal.Cinder{}, Nova: &internal.Nova{}, Octavia: &internal.Octavia{}},
+	}
+	ch := neutron.MakeDeleteChannel(consts.NETWORK, 2)
+	// the delete channels of the manager outlive a run, a second run sends
+	// on them and reports again
+	for run := 0; run < 2; run++ {
+		ch <- internal.Output{ParametersMap: map[string]string{"network_id": "n"}, Success: true}
+		report := runner.makeReport()
+		for _, typeReport := range report.Types {
+			if typeReport.Type == consts.NETWORK && typeReport.Totals != 1 {
+				t.Fatalf("run %d: %d networks reported, want 1", run, typeReport.Totals)
+			}
+		}
+	}
+	if len(ch) != 0 {
+		t.Fatalf("%d outputs left in the channel", len(ch))
+	}
+}
+
+func TestMakeReportProjectFailed(t *testing.T) {
+	typeErr := errors.New("list failed")
+	runner := &ProjectRunner{
+		projectName: "p",
+		manager:     &Manager{Neutron: &internal.Neutron{}, Cinder: &internal.Cinder{}, Nova: &internal.Nova{}, Octavia: &internal.Octavia{}},
+		filter:      &CleanFilter{ExcludeTypes: []string{OrderResources[1]}},
+		errors:      map[string]error{OrderResources[0]: typeErr},
+		err:         errors.New("project failed"),
+	}
+	report := runner.makeReport()
+	if report.Error != "project failed" {
+		t.Fatalf("project error %q", report.Error)
+	}
+	// every type cleaned is reported, failed with the error of the project
+	// unless it has its own
+	if len(report.Types) != len(OrderResources)-1 {
+		t.Fatalf("%d types reported, want %d", len(report.Types), len(OrderResources)-1)
+	}
+	for _, typeReport := range report.Types {
+		want := "project failed"
+		if typeReport.Type == OrderResources[0] {
+			want = typeErr.Error()
+		}
+		if typeReport.Error != want {
+			t.Errorf("%s error %q, want %q", typeReport.Type, typeReport.Error, want)
+		}
+	}
+	clean := &CleanReport{Projects: []ProjectReport{report}}
+	if _, errs := clean.Failures(); errs != len(report.Types)+1 {
+		t.Errorf("%d errors, want %d", errs, len(report.Types)+1)
+	}
+	if code := clean.ExitCode(FailOnError); code != ExitCleanFailed {
+		t.Errorf("exit code %d, want %d", code, ExitCleanFailed)
+	}
+}
@@ -6,27 +6,45 @@ import (
 	"request_openstack/internal/entity"
 )
 
-func (m *Manager) CreateAndSetAggregate(flavorKey, flavorVal string) {
+func (m *Manager) CreateAndSetAggregate(flavorKey, flavorVal string) error {
 	createAggregateOpts := entity.CreateAggregateOpts{Name: flavorKey}
-	aggregateId := m.CreateAggregate(createAggregateOpts)
+	aggregateId, err := m.CreateAggregate(createAggregateOpts)
+	if err != nil {
+		return err
+	}
 
-	hosts := m.GetComputeHosts()
+	hosts, err := m.GetComputeHosts()
+	if err != nil {
+		return err
+	}
 	for _, host := range hosts {
 		addHostOpts := entity.AddHostOpts{
 			Host: host,
 		}
-		m.AggregateAddHost(aggregateId, addHostOpts)
+		if _, err := m.AggregateAddHost(aggregateId, addHostOpts); err != nil {
+			return err
+		}
 	}
 	metadataOpts := entity.SetMetadataOpts{Metadata: map[string]interface{}{flavorKey: flavorVal}}
-    m.AggregateSetMetadata(aggregateId, metadataOpts)
+    if _, err := m.AggregateSetMetadata(aggregateId, metadataOpts); err != nil {
+        return err
+    }
+	return nil
 }
 
-func (m *Manager) CreateInstanceForTest() {
+func (m *Manager) CreateInstanceForTest() error {
 	name := "k8s"
-	netId, _, routerId := m.CreateVpc()
+	netId, _, routerId, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 	updateRouterOpts := &entity.UpdateRouterOpts{GatewayInfo: &entity.GatewayInfo{NetworkID: configs.CONF.ExternalNetwork}}
-	m.UpdateRouter(routerId, updateRouterOpts)
-	m.EnsureSgExist(configs.CONF.UserName)
+	if _, err := m.UpdateRouter(routerId, updateRouterOpts); err != nil {
+		return err
+	}
+	if err := m.EnsureSgExist(configs.CONF.UserName); err != nil {
+		return err
+	}
 	instanceOpts := entity.CreateInstanceOpts{
 		FlavorRef:      configs.CONF.FlavorId,
 		ImageRef:       configs.CONF.ImageId,
@@ -39,19 +57,31 @@ func (m *Manager) CreateInstanceForTest() {
 			DestinationType: "volume", VolumeSize: 30, DeleteOnTermination: true,
 		}},
 	}
-	m.CreateInstance(&instanceOpts)
-	m.CreateFloatingipHelper()
+	if _, err := m.CreateInstance(&instanceOpts); err != nil {
+		return err
+	}
+	if _, err := m.CreateFloatingipHelper(); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) GetInstancesSysDisk(instanceId string) string {
-	instance := m.GetInstanceDetail(instanceId)
+func (m *Manager) GetInstancesSysDisk(instanceId string) (string, error) {
+	instance, err := m.GetInstanceDetail(instanceId)
+	if err != nil {
+		return "", err
+	}
 	val := instance.Server.OsExtendedVolumesVolumesAttached
 	for _, v := range val {
 		volumeId := v.(map[string]interface{})["id"].(string)
-		if m.CheckSysOrDataDisk(volumeId) {
-			return volumeId
+		isSysDisk, err := m.CheckSysOrDataDisk(volumeId)
+		if err != nil {
+			return "", err
+		}
+		if isSysDisk {
+			return volumeId, nil
 		}
 	}
 	log.Println("==============Not found sys disk", instanceId)
-	return ""
+	return "", nil
 }
\ No newline at end of file
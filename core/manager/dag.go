@@ -0,0 +1,199 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrDependencyFailed is wrapped by the error of a node that did not run
+// because a node it depends on failed.
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// DAG runs a function per node once the nodes it depends on completed, with
+// at most Workers nodes running at a time, 0 for no bound.
+type DAG struct {
+	Workers int
+	// OnDone is called once per node, with its error, as soon as it completed
+	// or was skipped, calls do not overlap
+	OnDone func(name string, err error)
+	nodes  map[string]*dagNode
+	names  []string
+}
+
+type dagNode struct {
+	deps []string
+	run  func(ctx context.Context) error
+	// always runs the node even when one of its dependencies failed
+	always bool
+}
+
+func NewDAG(workers int) *DAG {
+	return &DAG{Workers: workers, nodes: make(map[string]*dagNode)}
+}
+
+// Add adds a node running run once all nodes in deps succeeded, it fails
+// with ErrDependencyFailed without running when one of them failed. A nil
+// run only orders the nodes around it.
+func (d *DAG) Add(name string, deps []string, run func(ctx context.Context) error) {
+	d.add(name, deps, run, false)
+}
+
+// AddAfter adds a node running run once all nodes in deps completed, whether
+// they failed or not.
+func (d *DAG) AddAfter(name string, deps []string, run func(ctx context.Context) error) {
+	d.add(name, deps, run, true)
+}
+
+func (d *DAG) add(name string, deps []string, run func(ctx context.Context) error, always bool) {
+	if _, ok := d.nodes[name]; !ok {
+		d.names = append(d.names, name)
+	}
+	d.nodes[name] = &dagNode{deps: deps, run: run, always: always}
+}
+
+// Validate checks that every dependency is a node and that the nodes have no
+// cycle, the error of a cycle names its nodes in order.
+func (d *DAG) Validate() error {
+	for _, name := range d.names {
+		for _, dep := range d.nodes[name].deps {
+			if _, ok := d.nodes[dep]; !ok {
+				return fmt.Errorf("%s depends on unknown %s", name, dep)
+			}
+		}
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(d.nodes))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			i := len(path) - 1
+			for path[i] != name {
+				i--
+			}
+			return fmt.Errorf("dependency cycle %s -> %s", strings.Join(path[i:], " -> "), name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		deps := append([]string(nil), d.nodes[name].deps...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	names := append([]string(nil), d.names...)
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type dagResult struct {
+	name string
+	err  error
+}
+
+// Run runs all nodes and returns their errors, nil for the nodes that
+// succeeded. Once ctx is done the nodes not started yet fail with its error.
+// A panic of a node is its error.
+func (d *DAG) Run(ctx context.Context) (map[string]error, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	waiting := make(map[string]int, len(d.nodes))
+	dependents := make(map[string][]string, len(d.nodes))
+	var ready []string
+	for _, name := range d.names {
+		deps := d.nodes[name].deps
+		waiting[name] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], name)
+		}
+		if len(deps) == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	results := make(map[string]error, len(d.nodes))
+	done := make(chan dagResult)
+	running := 0
+	complete := func(name string, err error) {
+		results[name] = err
+		if d.OnDone != nil {
+			d.OnDone(name, err)
+		}
+		for _, dependent := range dependents[name] {
+			if waiting[dependent]--; waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	for len(results) < len(d.nodes) {
+		for len(ready) > 0 && (d.Workers <= 0 || running < d.Workers) {
+			name := ready[0]
+			ready = ready[1:]
+			if err := d.skip(ctx, name, results); err != nil {
+				complete(name, err)
+				continue
+			}
+			running++
+			go func() {
+				done <- dagResult{name: name, err: d.call(ctx, name)}
+			}()
+		}
+		if running == 0 {
+			continue
+		}
+		result := <-done
+		running--
+		complete(result.name, result.err)
+	}
+	return results, nil
+}
+
+// skip returns why a ready node must not run, if it must not.
+func (d *DAG) skip(ctx context.Context, name string, results map[string]error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	node := d.nodes[name]
+	if node.always {
+		return nil
+	}
+	for _, dep := range node.deps {
+		if results[dep] != nil {
+			return fmt.Errorf("%w: %s of %s", ErrDependencyFailed, dep, name)
+		}
+	}
+	return nil
+}
+
+func (d *DAG) call(ctx context.Context, name string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic %v", p)
+		}
+	}()
+	if run := d.nodes[name].run; run != nil {
+		return run(ctx)
+	}
+	return nil
+}
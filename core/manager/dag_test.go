@@ -0,0 +1,165 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDAGValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   map[string][]string
+		wantErr string
+	}{
+		{"acyclic", map[string][]string{"a": nil, "b": {"a"}, "c": {"a", "b"}}, ""},
+		{"unknown", map[string][]string{"a": {"x"}}, "a depends on unknown x"},
+		{"self", map[string][]string{"a": {"a"}}, "dependency cycle a -> a"},
+		{"cycle", map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}, "d": {"a"}}, "dependency cycle a -> c -> b -> a"},
+		{"cycle behind a node", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"d"}, "d": {"b"}}, "dependency cycle b -> c -> d -> b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dag := NewDAG(0)
+			for name, deps := range tt.nodes {
+				dag.Add(name, deps, nil)
+			}
+			err := dag.Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("got %v, want %q", err, tt.wantErr)
+			}
+			if _, runErr := dag.Run(context.Background()); (runErr == nil) != (err == nil) {
+				t.Fatalf("Run returned %v, Validate %v", runErr, err)
+			}
+		})
+	}
+}
+
+func TestDAGRunOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	dag := NewDAG(0)
+	for name, deps := range map[string][]string{"a": nil, "b": {"a"}, "c": {"a"}, "d": {"b", "c"}} {
+		name := name
+		dag.Add(name, deps, func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		})
+	}
+	var done []string
+	dag.OnDone = func(name string, err error) {
+		done = append(done, name)
+	}
+	results, err := dag.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || len(done) != 4 {
+		t.Fatalf("results %v, done %v", results, done)
+	}
+	if order[0] != "a" || order[3] != "d" {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+
+func TestDAGWorkers(t *testing.T) {
+	for _, workers := range []int{1, 2, 3} {
+		var running, max int32
+		dag := NewDAG(workers)
+		for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+			dag.Add(name, nil, func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}
+		if _, err := dag.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if max != int32(workers) {
+			t.Fatalf("%d workers ran %d nodes at a time", workers, max)
+		}
+	}
+}
+
+func TestDAGFailures(t *testing.T) {
+	failure := errors.New("boom")
+	ran := make(map[string]bool)
+	var mu sync.Mutex
+	run := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			ran[name] = true
+			mu.Unlock()
+			return err
+		}
+	}
+	dag := NewDAG(0)
+	dag.Add("a", nil, run("a", failure))
+	dag.Add("b", []string{"a"}, run("b", nil))
+	dag.Add("c", []string{"b"}, run("c", nil))
+	dag.AddAfter("cleanup", []string{"a"}, run("cleanup", nil))
+	dag.Add("panics", nil, func(ctx context.Context) error {
+		panic("oops")
+	})
+	results, err := dag.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["a"] != failure {
+		t.Fatalf("a: %v", results["a"])
+	}
+	for _, name := range []string{"b", "c"} {
+		if !errors.Is(results[name], ErrDependencyFailed) || ran[name] {
+			t.Fatalf("expected %s to be skipped, got %v", name, results[name])
+		}
+	}
+	if results["cleanup"] != nil || !ran["cleanup"] {
+		t.Fatalf("expected cleanup to run after a failed, got %v", results["cleanup"])
+	}
+	if err := results["panics"]; err == nil || err.Error() != "panic oops" {
+		t.Fatalf("expected the panic as error, got %v", err)
+	}
+}
+
+func TestDAGCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ran int32
+	dag := NewDAG(1)
+	dag.Add("a", nil, func(ctx context.Context) error {
+		atomic.AddInt32(&ran, 1)
+		cancel()
+		return nil
+	})
+	for _, name := range []string{"b", "c", "d"} {
+		dag.Add(name, []string{"a"}, func(ctx context.Context) error {
+			atomic.AddInt32(&ran, 1)
+			return nil
+		})
+	}
+	results, err := dag.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ran != 1 || results["a"] != nil {
+		t.Fatalf("expected only a to run, ran %d: %v", ran, results)
+	}
+	for _, name := range []string{"b", "c", "d"} {
+		if !errors.Is(results[name], context.Canceled) {
+			t.Fatalf("%s: %v", name, results[name])
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package manager
 
 import (
-	"log"
+	"fmt"
 	"request_openstack/consts"
+	"sort"
 )
 
 
@@ -29,12 +30,17 @@ var ResourceDependencies = map[string][]string{
 	consts.FIREWALLPOLICY: []string{consts.FIREWALLRULE},
 	consts.FIREWALL: []string{consts.FIREWALLPOLICY, consts.ROUTER},
 	consts.VpcConnection: []string{consts.ROUTERINTERFACE, consts.ROUTERGATEWAY, consts.FIREWALL},
+	consts.VpnService: []string{consts.ROUTER, consts.SUBNET},
+	consts.EndpointGroup: []string{consts.SUBNET},
+	consts.IkePolicy: []string{},
+	consts.IpsecPolicy: []string{},
+	consts.IpsecConnection: []string{consts.VpnService, consts.EndpointGroup, consts.IkePolicy, consts.IpsecPolicy},
 	consts.LOADBALANCER: []string{consts.SUBNET},
 	consts.LISTENER: []string{consts.LOADBALANCER},
 	consts.POOL: []string{consts.LISTENER},
-	consts.MEMBER: []string{consts.POOL},
+	consts.MEMBER: []string{consts.POOL, consts.SERVER},
 	consts.HEALTHMONITOR: []string{consts.POOL},
-	consts.L7POLICY: []string{},
+	consts.L7POLICY: []string{consts.LISTENER, consts.POOL},
 	consts.L7RULE: []string{consts.L7POLICY},
 }
 
@@ -61,6 +67,11 @@ var OrderResources = [...]string{
 	consts.FIREWALLPOLICY,
 	consts.FIREWALL,
 	consts.VpcConnection,
+	consts.VpnService,
+	consts.EndpointGroup,
+	consts.IkePolicy,
+	consts.IpsecPolicy,
+	consts.IpsecConnection,
 	consts.LOADBALANCER,
 	consts.LISTENER,
 	consts.POOL,
@@ -70,123 +81,120 @@ var OrderResources = [...]string{
 	consts.L7RULE,
 }
 
-type Dependency struct {
-	resourceName        string
-	dependents          []string
-	requiredBy          []string
-    beDependentChannel  chan struct{}
-}
-
-type Node struct {
-	resourceType             string
-	dependencies             []*Node
-	requiredBy               []*Node
-	monitorDeleteChannel     chan struct{}
-	monitorCreateChannel     chan struct{}
-	resources                []string
-}
-
-func InitNodes() map[string]*Node {
-	nodeMap := make(map[string]*Node)
-	for _, resource := range OrderResources {
-		depNodes := make([]*Node, 0)
-		deps := ResourceDependencies[resource]
-		if len(deps) > 0 {
-			for _, resourceType := range deps {
-				if node, ok := nodeMap[resourceType]; ok {
-					depNodes = append(depNodes, node)
+// addImplicitDependencies makes each resource depend on the resources of
+// the types it is created after, see resourceType.after, it does not refer
+// to. Only the resources on one of its networks or routers are added, so
+// that independent networks are created in parallel, and none depending on
+// it, e.g. a port the template creates for a server.
+func addImplicitDependencies(resources map[string]Resource) {
+	scopes := make(map[string]map[string]bool, len(resources))
+	for name := range resources {
+		scope(name, resources, scopes)
+	}
+	for _, name := range sortedResourceNames(resources) {
+		resource := resources[name]
+		for _, afterType := range resourceTypes[resource.Type].after {
+			for _, other := range sortedResourceNames(resources) {
+				if resources[other].Type != afterType || other == name {
+					continue
+				}
+				if _, ok := resource.Dependencies[other]; ok || !overlap(scopes[name], scopes[other]) {
+					continue
+				}
+				if dependsOnResource(resources, other, name) {
+					continue
 				}
+				if resource.Dependencies == nil {
+					resource.Dependencies = make(map[string]string)
+					resources[name] = resource
+				}
+				resource.Dependencies[other] = ""
 			}
 		}
-		nodeMap[resource] = &Node{
-			resourceType: resource,
-			dependencies: depNodes,
-			resources: make([]string, 0),
-		}
 	}
+}
 
-	for resourceType, node := range nodeMap {
-		for _, dep := range node.dependencies {
-			dep.requiredBy = append(dep.requiredBy, nodeMap[resourceType])
-			nodeMap[dep.resourceType] = dep
+// dependsOnResource tells whether name depends on target, directly or not.
+func dependsOnResource(resources map[string]Resource, name, target string) bool {
+	seen := make(map[string]bool)
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		if name == target {
+			return true
 		}
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+		for dep := range resources[name].Dependencies {
+			if visit(dep) {
+				return true
+			}
+		}
+		return false
 	}
-
-	for _, node := range nodeMap {
-		node.monitorDeleteChannel = make(chan struct{}, len(node.requiredBy))
-		node.monitorCreateChannel = make(chan struct{}, len(node.dependencies))
-	}
-
-	return nodeMap
+	return visit(name)
 }
 
-func nodeAssociateResources(nodeMap map[string]*Node) map[string]*Node {
-	nodes := make(map[string]*Node)
-	for resourceType, node := range nodeMap {
-		nodes[resourceType] = nodeMap[resourceType]
-		for key, resource := range ResourcesMap {
-			if node.resourceType == resource.Type {
-				nodes[resourceType].resources = append(nodes[resourceType].resources, key)
+// scope returns the networks and routers of the template a resource is on,
+// those among itself and the resources it depends on, directly or not.
+func scope(name string, resources map[string]Resource, scopes map[string]map[string]bool) map[string]bool {
+	if res, ok := scopes[name]; ok {
+		return res
+	}
+	res := make(map[string]bool)
+	// a cycle ends here, checkDependencies reports it
+	scopes[name] = res
+	resource := resources[name]
+	if resource.Type == consts.NETWORK || resource.Type == consts.ROUTER {
+		res[name] = true
+	}
+	for dep := range resource.Dependencies {
+		if _, ok := resources[dep]; ok {
+			for s := range scope(dep, resources, scopes) {
+				res[s] = true
 			}
 		}
 	}
-	return nodes
+	return res
 }
 
-//func addExtraDeps() {
-//	for key, resource := range ResourcesMap {
-//		if resource.Type == consts.SERVER {
-//			for _, dep := range resource.Dependencies {
-//			    if ResourcesMap[dep].Type == consts.NETWORK {
-//
-//				}
-//			}
-//		}
-//
-//		if resource.Type == consts.FLOATINGIP {
-//			for _, dep := range resource.Dependencies {
-//                if ResourcesMap[dep].Type == consts.SERVER {
-//
-//				}
-//			}
-//		}
-//	}
-//}
-
-func initDependencies() map[string]Dependency {
-	dependencies := make(map[string]Dependency)
-	beDependentMap := make(map[string][]string)
-	for key, resource := range ResourcesMap {
-		dependency := Dependency{
-			resourceName: key,
-			dependents: make([]string, 0),
-		}
-		for dep, _ := range resource.Dependencies {
-			beDependentMap[dep] = append(beDependentMap[dep], key)
-			dependency.dependents = append(dependency.dependents, dep)
+// overlap tells whether two scopes share a network or router, a resource
+// on none may be on any.
+func overlap(a, b map[string]bool) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for s := range a {
+		if b[s] {
+			return true
 		}
-		dependencies[key] = dependency
 	}
+	return false
+}
 
-	for key, res := range ResourcesMap {
-		dependency := dependencies[key]
-		if deps, ok := beDependentMap[key]; ok {
-			dependency.requiredBy = deps
-		}
-		if res.Type == consts.NETWORK {
-			channelLen := 0
-			for _, dep := range beDependentMap[key] {
-				if ResourcesMap[dep].Type == consts.SUBNET {
-					channelLen++
-				}
+// checkDependencies checks that every dependency is a resource of the
+// template and that the resources have no dependency cycle.
+func checkDependencies(resources map[string]Resource) error {
+	dag := NewDAG(0)
+	for _, name := range sortedResourceNames(resources) {
+		deps := make([]string, 0, len(resources[name].Dependencies))
+		for dep := range resources[name].Dependencies {
+			if _, ok := resources[dep]; !ok {
+				return fmt.Errorf("resource %s refers to unknown resource %s", name, dep)
 			}
-			dependency.beDependentChannel = make(chan struct{}, channelLen)
-		} else {
-			dependency.beDependentChannel = make(chan struct{}, len(dependency.requiredBy))
+			deps = append(deps, dep)
 		}
-		dependencies[key] = dependency
-		log.Printf("%+v===", dependency)
-		log.Printf("%+v===", cap(dependency.beDependentChannel))
+		dag.Add(name, deps, nil)
+	}
+	return dag.Validate()
+}
+
+func sortedResourceNames(resources map[string]Resource) []string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
 	}
-	return dependencies
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestImplicitDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string][]string
+	}{
+		{
+			name: "server after the subnets and ports of its network",
+			src: `
+resources:
+  net: {type: network}
+  other_net: {type: network}
+  subnet: {type: subnet, properties: {network_id: net, cidr: 10.0.0.0/24, ip_version: 4}}
+  other_subnet: {type: subnet, properties: {network_id: other_net, cidr: 10.1.0.0/24, ip_version: 4}}
+  port: {type: port, properties: {network_id: net}}
+  server: {type: server, properties: {imageRef: local, flavorRef: local, networks: [{uuid: net}]}}
+`,
+			want: map[string][]string{"server": {"net", "port", "subnet"}},
+		},
+		{
+			name: "no cycle with a port after the server",
+			src: `
+resources:
+  net: {type: network}
+  subnet: {type: subnet, properties: {network_id: net, cidr: 10.0.0.0/24, ip_version: 4}}
+  port: {type: port, properties: {network_id: net, description: {get_resource: server}}}
+  server: {type: server, properties: {imageRef: local, flavorRef: local, networks: [{uuid: net}]}}
+`,
+			want: map[string][]string{"server": {"net", "subnet"}, "port": {"net", "server"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := resolveTestTemplate(t, tt.src)
+			for name, want := range tt.want {
+				got := make([]string, 0)
+				for dep := range template.Resources[name].Dependencies {
+					got = append(got, dep)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s after %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInstanceDependencies(t *testing.T) {
+	template, err := ResolveTemplate(yamlToMap("../../configs/templates/instance.yaml"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := template.Resources["instance1"].Dependencies; !reflect.DeepEqual(got, map[string]string{"network": "Networks[0]/UUID", "subnet": ""}) {
+		t.Errorf("instance1 depends on %v", got)
+	}
+}
+
+func TestResolveTemplateCycle(t *testing.T) {
+	_, err := ResolveTemplate(parseTemplate(t, `
+resources:
+  a:
+    type: network
+    properties: {description: {get_resource: b}}
+  b:
+    type: network
+    properties: {description: {get_resource: a}}
+`), nil)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle a -> b -> a") {
+		t.Fatalf("expected the cycle a -> b -> a, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Actions of a FailurePolicy.
+const (
+	OnFailureKeep     = "keep"
+	OnFailureRollback = "rollback"
+	OnFailureRetry    = "retry"
+)
+
+// FailurePolicy is what a stack run does when resources fail to be created:
+// keep what was created, roll back, i.e. delete, the resources created by the
+// run in reverse dependency order, or retry the failed resources and the ones
+// depending on them up to Retries times.
+type FailurePolicy struct {
+	Action  string
+	Retries int
+}
+
+func (p FailurePolicy) String() string {
+	if p.Action == OnFailureRetry {
+		return fmt.Sprintf("%s %d", p.Action, p.Retries)
+	}
+	if p.Action == "" {
+		return OnFailureKeep
+	}
+	return p.Action
+}
+
+// ParseFailurePolicy parses "keep", "rollback" or "retry N", "retry=N" and
+// "retry:N" are accepted as well.
+func ParseFailurePolicy(s string) (FailurePolicy, error) {
+	fields := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool {
+		return r == ' ' || r == '=' || r == ':'
+	})
+	if len(fields) == 0 {
+		return FailurePolicy{Action: OnFailureKeep}, nil
+	}
+	switch fields[0] {
+	case OnFailureKeep, OnFailureRollback:
+		if len(fields) == 1 {
+			return FailurePolicy{Action: fields[0]}, nil
+		}
+	case OnFailureRetry:
+		if len(fields) == 1 {
+			return FailurePolicy{Action: OnFailureRetry, Retries: 1}, nil
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 && len(fields) == 2 {
+			return FailurePolicy{Action: OnFailureRetry, Retries: n}, nil
+		}
+	}
+	return FailurePolicy{}, fmt.Errorf("invalid on-failure policy %q, expected keep, rollback or retry N", s)
+}
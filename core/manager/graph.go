@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats of an exported graph.
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// GraphNode is a resource, or a resource type, of a dependency graph.
+// Status and Duration are those of its creation, see ResourceResult.
+type GraphNode struct {
+	Name     string
+	Type     string
+	Status   string
+	Duration time.Duration
+}
+
+// GraphEdge goes from a dependency to the node created after it.
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// Graph is a dependency graph in the order of creation, its nodes and edges
+// are sorted so that its exports are stable.
+type Graph struct {
+	Name  string
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// TypeGraph returns the graph of the resource types in ResourceDependencies,
+// the order the cleaner deletes them in reversed.
+func TypeGraph() *Graph {
+	graph := &Graph{Name: "resource types"}
+	types := make([]string, 0, len(ResourceDependencies))
+	for resourceType := range ResourceDependencies {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	for _, resourceType := range types {
+		graph.Nodes = append(graph.Nodes, GraphNode{Name: resourceType})
+		for _, dep := range ResourceDependencies[resourceType] {
+			graph.Edges = append(graph.Edges, GraphEdge{From: dep, To: resourceType})
+		}
+	}
+	graph.sortEdges()
+	return graph
+}
+
+// TemplateGraph returns the graph of the resources of a resolved template,
+// all pending.
+func TemplateGraph(template *Template) *Graph {
+	graph := &Graph{Name: "resources"}
+	for _, name := range sortedResourceNames(template.Resources) {
+		resource := template.Resources[name]
+		graph.Nodes = append(graph.Nodes, GraphNode{Name: name, Type: resource.Type, Status: StatusPending})
+		for dep := range resource.Dependencies {
+			graph.Edges = append(graph.Edges, GraphEdge{From: dep, To: name})
+		}
+	}
+	graph.sortEdges()
+	return graph
+}
+
+func (g *Graph) sortEdges() {
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+}
+
+// Annotate sets the status and duration of the nodes of results, e.g. those
+// of Scheduler.Results after a run.
+func (g *Graph) Annotate(results map[string]ResourceResult) {
+	for i, node := range g.Nodes {
+		if result, ok := results[node.Name]; ok {
+			g.Nodes[i].Status, g.Nodes[i].Duration = result.Status, result.Duration
+		}
+	}
+}
+
+// Export formats the graph as DOT or Mermaid.
+func (g *Graph) Export(format string) (string, error) {
+	switch format {
+	case GraphDOT:
+		return g.DOT(), nil
+	case GraphMermaid:
+		return g.Mermaid(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q, expected %s or %s", format, GraphDOT, GraphMermaid)
+}
+
+// label is the text of a node, its name, type, status and duration.
+func (n GraphNode) label(separator string) string {
+	lines := []string{n.Name}
+	if n.Type != "" {
+		lines = append(lines, n.Type)
+	}
+	if n.Status != "" {
+		status := n.Status
+		if n.Duration > 0 {
+			status += " " + n.Duration.Round(time.Millisecond).String()
+		}
+		lines = append(lines, status)
+	}
+	return strings.Join(lines, separator)
+}
+
+// statusColors are the fill colors of the nodes by status.
+var statusColors = map[string]string{
+	StatusPending: "#eeeeee",
+	StatusCreated: "#c8e6c9",
+	StatusFailed:  "#ffcdd2",
+}
+
+// DOT formats the graph for Graphviz, e.g. dot -Tsvg.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", g.Name)
+	b.WriteString("  rankdir=LR;\n  node [shape=box, style=filled, fillcolor=\"#ffffff\"];\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q", node.Name, node.label("\n"))
+		if color, ok := statusColors[node.Status]; ok {
+			fmt.Fprintf(&b, ", fillcolor=%q", color)
+		}
+		b.WriteString("];\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid formats the graph as a Mermaid flowchart, its nodes are named n0,
+// n1... since resource names like net[0] are not valid Mermaid ids.
+func (g *Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, node := range g.Nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(node.label("<br/>"), `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]", ids[node.Name], label)
+		if _, ok := statusColors[node.Status]; ok {
+			b.WriteString(":::" + node.Status)
+		}
+		b.WriteString("\n")
+	}
+	for _, edge := range g.Edges {
+		from, to := ids[edge.From], ids[edge.To]
+		if from == "" || to == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+	}
+	for _, status := range []string{StatusPending, StatusCreated, StatusFailed} {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", status, statusColors[status])
+	}
+	return b.String()
+}
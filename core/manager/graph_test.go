@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const graphTemplate = `
+resources:
+  net: {type: network, count: 1}
+  subnet:
+    type: subnet
+    properties: {network_id: "net[0]", cidr: 10.0.0.0/24, ip_version: 4}
+`
+
+func TestGraphExport(t *testing.T) {
+	graph := TemplateGraph(resolveTestTemplate(t, graphTemplate))
+	graph.Annotate(map[string]ResourceResult{
+		"net[0]": {Status: StatusCreated, Duration: 1500 * time.Millisecond},
+		"subnet": {Status: StatusFailed, Error: "boom"},
+	})
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{GraphDOT, `digraph "resources" {
+  rankdir=LR;
+  node [shape=box, style=filled, fillcolor="#ffffff"];
+  "net[0]" [label="net[0]\nnetwork\ncreated 1.5s", fillcolor="#c8e6c9"];
+  "subnet" [label="subnet\nsubnet\nfailed", fillcolor="#ffcdd2"];
+  "net[0]" -> "subnet";
+}
+`},
+		{GraphMermaid, `flowchart LR
+  n0["net[0]<br/>network<br/>created 1.5s"]:::created
+  n1["subnet<br/>subnet<br/>failed"]:::failed
+  n0 --> n1
+  classDef pending fill:#eeeeee
+  classDef created fill:#c8e6c9
+  classDef failed fill:#ffcdd2
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := graph.Export(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	if _, err := graph.Export("svg"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestMermaidQuotes(t *testing.T) {
+	graph := &Graph{Nodes: []GraphNode{{Name: `say "hi"`}}, Edges: []GraphEdge{{From: "unknown", To: `say "hi"`}}}
+	got := graph.Mermaid()
+	if !strings.Contains(got, `n0["say #quot;hi#quot;"]`) {
+		t.Fatalf("quotes not escaped:\n%s", got)
+	}
+	if strings.Contains(got, "-->") {
+		t.Fatalf("edge from an unknown node exported:\n%s", got)
+	}
+}
+
+func TestTypeGraph(t *testing.T) {
+	graph := TypeGraph()
+	if len(graph.Nodes) != len(ResourceDependencies) {
+		t.Fatalf("%d nodes for %d types", len(graph.Nodes), len(ResourceDependencies))
+	}
+	dot := graph.DOT()
+	for _, edge := range []string{`"network" -> "subnet";`, `"pool" -> "member";`} {
+		if !strings.Contains(dot, edge) {
+			t.Errorf("missing edge %s", edge)
+		}
+	}
+	for i := 1; i < len(graph.Edges); i++ {
+		a, b := graph.Edges[i-1], graph.Edges[i]
+		if a.From > b.From || a.From == b.From && a.To > b.To {
+			t.Fatalf("edges not sorted: %v before %v", a, b)
+		}
+	}
+}
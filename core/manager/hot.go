@@ -0,0 +1,690 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"request_openstack/consts"
+	"sort"
+	"strings"
+)
+
+// HeatTemplateVersion is the key of Heat HOT templates, ImportHOT converts
+// them to templates of the scheduler.
+const HeatTemplateVersion = "heat_template_version"
+
+// ResourceGroup replicates the resource of its resource_def count times, it
+// is imported as a resource with count.
+const ResourceGroup = "OS::Heat::ResourceGroup"
+
+// IsHOT tells whether template is a Heat HOT template.
+func IsHOT(template map[string]interface{}) bool {
+	_, ok := template[HeatTemplateVersion]
+	return ok
+}
+
+// ImportReport lists what ImportHOT left out of a HOT template.
+type ImportReport struct {
+	// Types are the resources left out, by their unsupported type
+	Types map[string]string
+	// Properties are the properties, resource keys and intrinsic functions
+	// left out of each resource, output or parameter
+	Properties map[string][]string
+	// Sections are the sections left out, e.g. conditions
+	Sections []string
+}
+
+func newImportReport() *ImportReport {
+	return &ImportReport{Types: make(map[string]string), Properties: make(map[string][]string)}
+}
+
+func (r *ImportReport) leftOut(owner, what string) {
+	r.Properties[owner] = append(r.Properties[owner], what)
+}
+
+// Empty tells whether the whole template was imported.
+func (r *ImportReport) Empty() bool {
+	return len(r.Types) == 0 && len(r.Properties) == 0 && len(r.Sections) == 0
+}
+
+// Lines formats the report, a line per thing left out, none for a nil
+// report.
+func (r *ImportReport) Lines() []string {
+	if r == nil {
+		return nil
+	}
+	var lines []string
+	for _, section := range r.Sections {
+		lines = append(lines, fmt.Sprintf("section %s is not supported", section))
+	}
+	for _, name := range sortedStringKeys(r.Types) {
+		lines = append(lines, fmt.Sprintf("resource %s: type %s is not supported", name, r.Types[name]))
+	}
+	owners := make([]string, 0, len(r.Properties))
+	for owner := range r.Properties {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		props := append([]string(nil), r.Properties[owner]...)
+		sort.Strings(props)
+		for _, prop := range props {
+			lines = append(lines, fmt.Sprintf("%s: %s is not supported", owner, prop))
+		}
+	}
+	return lines
+}
+
+// hotType is how the resources of a Heat type are imported.
+type hotType struct {
+	kind string
+	// props maps the Heat properties to ours, the other properties are left
+	// out and reported, those mapped to "" are left out silently
+	props map[string]string
+	// items maps the keys of the maps, or of the maps of a list, of a
+	// property the same way
+	items map[string]map[string]string
+	// convert rewrites the imported properties of the resource name and
+	// returns the resources it adds, e.g. the rules of a security group
+	convert func(name string, props map[string]interface{}) map[string]interface{}
+}
+
+// props returns the mapping of Heat properties "heat:ours", or "same" when
+// both are named alike.
+func props(names ...string) map[string]string {
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		if i := strings.Index(name, ":"); i >= 0 {
+			m[name[:i]] = name[i+1:]
+			continue
+		}
+		m[name] = name
+	}
+	return m
+}
+
+var hotTypes = map[string]hotType{
+	"OS::Neutron::Net": {
+		kind:  consts.NETWORK,
+		props: props("name", "admin_state_up", "shared", "availability_zone_hints", "tenant_id"),
+	},
+	"OS::Neutron::Subnet": {
+		kind: consts.SUBNET,
+		props: props("network:network_id", "network_id", "cidr", "name", "ip_version", "gateway_ip",
+			"enable_dhcp", "dns_nameservers", "allocation_pools", "host_routes", "ipv6_address_mode",
+			"ipv6_ra_mode", "subnetpool:subnetpool_id", "prefixlen", "tenant_id"),
+	},
+	"OS::Neutron::Port": {
+		kind:  consts.PORT,
+		props: props("network:network_id", "network_id", "name", "admin_state_up", "fixed_ips"),
+		items: map[string]map[string]string{
+			"fixed_ips": props("subnet:subnet_id", "subnet_id", "ip_address"),
+		},
+	},
+	"OS::Neutron::Router": {
+		kind:  consts.ROUTER,
+		props: props("name", "admin_state_up", "distributed", "external_gateway_info", "availability_zone_hints"),
+		items: map[string]map[string]string{
+			"external_gateway_info": props("network:network_id", "enable_snat"),
+		},
+	},
+	"OS::Neutron::RouterInterface": {
+		kind:  consts.ROUTERINTERFACE,
+		props: props("router:router_id", "router_id", "subnet:subnet_id", "subnet_id"),
+	},
+	"OS::Neutron::FloatingIP": {
+		kind: consts.FLOATINGIP,
+		props: props("floating_network:floating_network_id", "floating_network_id", "floating_subnet:subnet_id",
+			"port_id", "fixed_ip_address", "floating_ip_address"),
+	},
+	"OS::Neutron::FloatingIPPortForward": {
+		kind: consts.PORTFORWARDING,
+		props: props("floatingip:floatingip_id", "internal_port:internal_port_id",
+			"internal_port_number:internal_port", "internal_ip_address", "external_port", "protocol", "description"),
+	},
+	"OS::Nova::Server": {
+		kind: consts.SERVER,
+		props: props("name", "image:imageRef", "flavor:flavorRef", "admin_pass:adminPass", "user_data",
+			"availability_zone", "metadata", "config_drive", "tags", "networks", "security_groups",
+			"block_device_mapping_v2"),
+		items: map[string]map[string]string{
+			"networks":                props("network:uuid", "uuid"),
+			"block_device_mapping_v2": props("image", "volume_id", "boot_index", "volume_size", "delete_on_termination"),
+		},
+		convert: convertServer,
+	},
+	"OS::Cinder::Volume": {
+		kind: consts.VOLUME,
+		props: props("size", "name", "description", "volume_type", "availability_zone", "image:imageRef",
+			"snapshot_id", "source_volid", "multiattach", "metadata"),
+	},
+	"OS::Neutron::SecurityGroup": {
+		kind:    consts.SECURITYGROUP,
+		props:   props("name", "description", "rules"),
+		items:   map[string]map[string]string{"rules": securityGroupRuleProps},
+		convert: convertSecurityGroup,
+	},
+	"OS::Neutron::SecurityGroupRule": {
+		kind:  consts.SECURITYGROUPRULE,
+		props: securityGroupRuleProps,
+		convert: func(name string, props map[string]interface{}) map[string]interface{} {
+			defaultRule(props)
+			return nil
+		},
+	},
+	"OS::Neutron::QoSPolicy": {
+		kind:  consts.QOS_POLICY,
+		props: props("name", "description", "shared", "is_default"),
+	},
+	"OS::Neutron::QoSBandwidthLimitRule": {
+		kind:  consts.BANDWIDTH_LIMIT_RULE,
+		props: props("policy:qos_policy_id", "max_kbps", "max_burst_kbps", "direction"),
+	},
+	"OS::Neutron::QoSDscpMarkingRule": {
+		kind:  consts.DSCP_MARKING_RULE,
+		props: props("policy:qos_policy_id", "dscp_mark"),
+	},
+	"OS::Neutron::QoSMinimumBandwidthRule": {
+		kind:  consts.MINIMUM_BANDWIDTH_RULE,
+		props: props("policy:qos_policy_id", "min_kbps", "direction"),
+	},
+	"OS::Neutron::FirewallRule": {
+		kind: consts.FIREWALLRULE,
+		props: props("name", "description", "shared", "protocol", "ip_version", "source_ip_address",
+			"destination_ip_address", "source_port", "destination_port", "action", "enabled"),
+	},
+	"OS::Neutron::FirewallPolicy": {
+		kind:  consts.FIREWALLPOLICY,
+		props: props("name", "description", "shared", "audited", "firewall_rules"),
+	},
+	"OS::Neutron::Firewall": {
+		kind:  consts.FIREWALL,
+		props: props("name", "description", "admin_state_up", "shared", "firewall_policy_id"),
+	},
+	"OS::Octavia::LoadBalancer": {
+		kind: consts.LOADBALANCER,
+		props: props("name", "description", "vip_subnet:vip_subnet_id", "vip_address", "admin_state_up",
+			"provider", "flavor:flavor_id", "availability_zone"),
+	},
+	"OS::Octavia::Listener": {
+		kind: consts.LISTENER,
+		props: props("name", "description", "loadbalancer:loadbalancer_id", "default_pool:default_pool_id",
+			"protocol", "protocol_port", "connection_limit", "admin_state_up", "default_tls_container_ref",
+			"sni_container_refs", "timeout_client_data", "timeout_member_data", "timeout_member_connect",
+			"timeout_tcp_inspect", "allowed_cidrs", "tags"),
+	},
+	"OS::Octavia::Pool": {
+		kind: consts.POOL,
+		props: props("name", "description", "lb_algorithm", "protocol", "listener:listener_id",
+			"loadbalancer:loadbalancer_id", "admin_state_up", "session_persistence", "tags"),
+	},
+	"OS::Octavia::PoolMember": {
+		kind: consts.MEMBER,
+		props: props("pool:pool_id", "address", "protocol_port", "weight", "subnet:subnet_id", "admin_state_up",
+			"monitor_address", "monitor_port", "name", "tags"),
+	},
+	"OS::Octavia::HealthMonitor": {
+		kind: consts.HEALTHMONITOR,
+		props: props("pool:pool_id", "type", "delay", "timeout", "max_retries", "max_retries_down", "url_path",
+			"http_method", "expected_codes", "admin_state_up", "name"),
+	},
+	"OS::Octavia::L7Policy": {
+		kind: consts.L7POLICY,
+		props: props("listener:listener_id", "action", "position", "redirect_pool:redirect_pool_id",
+			"redirect_url", "redirect_prefix", "redirect_http_code", "name", "description", "admin_state_up"),
+	},
+	"OS::Octavia::L7Rule": {
+		kind:  consts.L7RULE,
+		props: props("l7policy:l7policy_id", "type", "compare_type", "value", "key", "invert", "admin_state_up"),
+	},
+}
+
+// securityGroupRuleProps are the properties of OS::Neutron::SecurityGroupRule
+// and of the rules of OS::Neutron::SecurityGroup, remote_mode follows from
+// the remote property set.
+var securityGroupRuleProps = props("security_group:security_group_id", "direction", "ethertype",
+	"port_range_min", "port_range_max", "protocol", "remote_group:remote_group_id", "remote_group_id",
+	"remote_ip_prefix", "description", "remote_mode:")
+
+// defaultRule sets the defaults Heat gives the direction and ethertype of a
+// security group rule.
+func defaultRule(props map[string]interface{}) {
+	if _, ok := props["direction"]; !ok {
+		props["direction"] = "ingress"
+	}
+	if _, ok := props["ethertype"]; !ok {
+		props["ethertype"] = "IPv4"
+	}
+}
+
+// convertSecurityGroup moves the rules of a security group to rule
+// resources named <name>_rule_<i>.
+func convertSecurityGroup(name string, props map[string]interface{}) map[string]interface{} {
+	rules, _ := props["rules"].([]interface{})
+	delete(props, "rules")
+	added := make(map[string]interface{}, len(rules))
+	for i, rule := range rules {
+		ruleProps, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ruleProps["security_group_id"] = map[string]interface{}{GetResource: name}
+		defaultRule(ruleProps)
+		added[fmt.Sprintf("%s_rule_%d", name, i)] = map[string]interface{}{
+			"type":       consts.SECURITYGROUPRULE,
+			"properties": ruleProps,
+		}
+	}
+	return added
+}
+
+// convertServer turns the security group names of a server into maps and
+// its block devices into those of an image or a volume.
+func convertServer(name string, props map[string]interface{}) map[string]interface{} {
+	if groups, ok := props["security_groups"].([]interface{}); ok {
+		for i, group := range groups {
+			groups[i] = map[string]interface{}{"name": group}
+		}
+	}
+	devices, _ := props["block_device_mapping_v2"].([]interface{})
+	for _, device := range devices {
+		d, ok := device.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, source := range map[string]string{"image": "image", "volume_id": "volume"} {
+			if id, ok := d[key]; ok {
+				delete(d, key)
+				d["uuid"], d["source_type"], d["destination_type"] = id, source, "volume"
+			}
+		}
+	}
+	return nil
+}
+
+// hotFunctions are the Heat intrinsic functions ImportHOT knows, by what
+// they are imported as, "" for those it leaves out.
+var hotFunctions = map[string]string{
+	GetResource:           GetResource,
+	GetAttr:               GetAttr,
+	GetParam:              GetParam,
+	ListJoin:              ListJoin,
+	StrReplace:            StrReplace,
+	"str_replace_strict":  StrReplace,
+	"str_replace_vstrict": StrReplace,
+	"get_file":            "",
+	"repeat":              "",
+	"digest":              "",
+	"resource_facade":     "",
+	"str_split":           "",
+	"map_merge":           "",
+	"map_replace":         "",
+	"yaql":                "",
+	"equals":              "",
+	"if":                  "",
+	"not":                 "",
+	"and":                 "",
+	"or":                  "",
+	"filter":              "",
+	"make_url":            "",
+	"list_concat":         "",
+	"list_concat_unique":  "",
+	"contains":            "",
+}
+
+// groupMember is a get_attr path of a member of a ResourceGroup, e.g.
+// resource.0.
+var groupMember = regexp.MustCompile(`^resource\.(\d+)$`)
+
+// hotImporter converts the resources and outputs of a HOT template.
+type hotImporter struct {
+	report *ImportReport
+	// groups are the ResourceGroup resources of the template
+	groups map[string]bool
+}
+
+// ImportHOT converts a Heat HOT template to a template of the scheduler:
+// the OS::* types of hotTypes become our types and their properties ours,
+// OS::Heat::ResourceGroup a resource with count. What it cannot convert is
+// left out and reported, references to it fail when the template is
+// resolved.
+func ImportHOT(hot map[string]interface{}) (map[string]interface{}, *ImportReport, error) {
+	if !IsHOT(hot) {
+		return nil, nil, fmt.Errorf("not a HOT template, it has no %s", HeatTemplateVersion)
+	}
+	im := &hotImporter{report: newImportReport(), groups: make(map[string]bool)}
+	resources, ok := hot["resources"].(map[string]interface{})
+	if !ok && hot["resources"] != nil {
+		return nil, nil, fmt.Errorf("resources are not a map")
+	}
+	for name, resource := range resources {
+		if definition, ok := resource.(map[string]interface{}); ok && definition["type"] == ResourceGroup {
+			im.groups[name] = true
+		}
+	}
+
+	template := make(map[string]interface{})
+	for _, section := range sortedKeys(hot) {
+		switch section {
+		case HeatTemplateVersion, "description", "parameter_groups":
+		case "parameters":
+			parameters, err := im.parameters(hot[section])
+			if err != nil {
+				return nil, nil, err
+			}
+			template[section] = parameters
+		case "resources":
+			converted := make(map[string]interface{}, len(resources))
+			for _, name := range sortedKeys(resources) {
+				definition, ok := resources[name].(map[string]interface{})
+				if !ok {
+					return nil, nil, fmt.Errorf("resource %s: not a map of type and properties", name)
+				}
+				resource, added := im.resource(name, definition)
+				if resource == nil {
+					continue
+				}
+				converted[name] = resource
+				for addedName, addedResource := range added {
+					converted[addedName] = addedResource
+				}
+			}
+			template[section] = converted
+		case "outputs":
+			outputs, _ := hot[section].(map[string]interface{})
+			converted := make(map[string]interface{}, len(outputs))
+			for _, name := range sortedKeys(outputs) {
+				output, ok := outputs[name].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				value, ok := im.value("output "+name, "value", output["value"])
+				if !ok {
+					continue
+				}
+				converted[name] = map[string]interface{}{"description": output["description"], "value": value}
+			}
+			template[section] = converted
+		default:
+			im.report.Sections = append(im.report.Sections, section)
+		}
+	}
+	return template, im.report, nil
+}
+
+// parameters keeps the parameters of a HOT template without the custom
+// constraints we do not check, e.g. glance.image.
+func (im *hotImporter) parameters(section interface{}) (map[string]interface{}, error) {
+	parameters, ok := section.(map[string]interface{})
+	if !ok && section != nil {
+		return nil, fmt.Errorf("parameters are not a map")
+	}
+	res := make(map[string]interface{}, len(parameters))
+	for name, parameter := range parameters {
+		p, ok := parameter.(map[string]interface{})
+		if !ok {
+			res[name] = parameter
+			continue
+		}
+		constraints, _ := p["constraints"].([]interface{})
+		kept := make([]interface{}, 0, len(constraints))
+		for _, constraint := range constraints {
+			c, _ := constraint.(map[string]interface{})
+			if custom, ok := c["custom_constraint"].(string); ok {
+				if _, known := customConstraints[custom]; !known {
+					im.report.leftOut("parameter "+name, "custom_constraint "+custom)
+					continue
+				}
+			}
+			kept = append(kept, constraint)
+		}
+		p = copyWith(p, "constraints", kept)
+		if len(kept) == 0 {
+			delete(p, "constraints")
+		}
+		res[name] = p
+	}
+	return res, nil
+}
+
+// resource converts a resource of a HOT template, nil when its type is not
+// supported, and returns the resources its conversion adds.
+func (im *hotImporter) resource(name string, definition map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	owner := "resource " + name
+	heatType, _ := definition["type"].(string)
+	res := make(map[string]interface{})
+	for _, key := range sortedKeys(definition) {
+		switch key {
+		case "type", "properties":
+		case DependsOn:
+			res[key] = definition[key]
+		default:
+			im.report.leftOut(owner, key)
+		}
+	}
+	heatProps, _ := definition["properties"].(map[string]interface{})
+	if heatType == ResourceGroup {
+		return im.group(name, res, heatProps)
+	}
+	t, ok := hotTypes[heatType]
+	if !ok {
+		im.report.Types[name] = heatType
+		return nil, nil
+	}
+	res["type"] = t.kind
+	converted := im.properties(owner, t, heatProps)
+	var added map[string]interface{}
+	if t.convert != nil {
+		added = t.convert(name, converted)
+	}
+	if len(converted) > 0 {
+		res["properties"] = converted
+	}
+	return res, added
+}
+
+// group converts a ResourceGroup to its resource_def with count, the
+// index_var of its properties becomes %index%.
+func (im *hotImporter) group(name string, res, heatProps map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	owner := "resource " + name
+	for _, key := range sortedKeys(heatProps) {
+		switch key {
+		case "count", "resource_def", "index_var":
+		default:
+			im.report.leftOut(owner, key)
+		}
+	}
+	definition, _ := heatProps["resource_def"].(map[string]interface{})
+	heatType, _ := definition["type"].(string)
+	t, ok := hotTypes[heatType]
+	if !ok {
+		im.report.Types[name] = fmt.Sprintf("%s of %s", heatType, ResourceGroup)
+		return nil, nil
+	}
+	count, ok := im.value(owner, "count", heatProps["count"])
+	if !ok {
+		return nil, nil
+	}
+	if heatProps["count"] == nil {
+		// the default count of Heat
+		count = 1
+	}
+	memberProps, _ := definition["properties"].(map[string]interface{})
+	if indexVar, ok := heatProps["index_var"].(string); ok && indexVar != IndexVar {
+		memberProps = replaceIndexVar(memberProps, indexVar).(map[string]interface{})
+	}
+	res["type"], res[Count] = t.kind, count
+	converted := im.properties(owner, t, memberProps)
+	if t.convert != nil {
+		if added := t.convert(name, converted); len(added) > 0 {
+			im.report.leftOut(owner, "resources added to "+heatType+" in "+ResourceGroup)
+		}
+	}
+	if len(converted) > 0 {
+		res["properties"] = converted
+	}
+	return res, nil
+}
+
+// replaceIndexVar replaces the index_var of a ResourceGroup by %index%.
+func replaceIndexVar(value interface{}, indexVar string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return strings.ReplaceAll(v, indexVar, IndexVar)
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = replaceIndexVar(item, indexVar)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = replaceIndexVar(item, indexVar)
+		}
+		return res
+	}
+	return value
+}
+
+// properties converts the Heat properties of a resource of type t.
+func (im *hotImporter) properties(owner string, t hotType, heatProps map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(heatProps))
+	for _, prop := range sortedKeys(heatProps) {
+		ours, ok := t.props[prop]
+		if !ok {
+			im.report.leftOut(owner, "property "+prop)
+			continue
+		}
+		if ours == "" {
+			continue
+		}
+		value, ok := im.value(owner, prop, heatProps[prop])
+		if !ok {
+			continue
+		}
+		if items, ok := t.items[prop]; ok {
+			value = im.items(owner, prop, items, value)
+		}
+		res[ours] = value
+	}
+	return res
+}
+
+// items renames the keys of the map, or maps of the list, value.
+func (im *hotImporter) items(owner, prop string, items map[string]string, value interface{}) interface{} {
+	rename := func(m map[string]interface{}) map[string]interface{} {
+		res := make(map[string]interface{}, len(m))
+		for _, key := range sortedKeys(m) {
+			ours, ok := items[key]
+			if !ok {
+				im.report.leftOut(owner, fmt.Sprintf("property %s.%s", prop, key))
+				continue
+			}
+			if ours != "" {
+				res[ours] = m[key]
+			}
+		}
+		return res
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, _, ok := intrinsic(v); !ok {
+			return rename(v)
+		}
+	case []interface{}:
+		for i, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				v[i] = rename(m)
+			}
+		}
+	}
+	return value
+}
+
+// value converts the intrinsic functions of the value of prop, false when
+// it uses one that is not supported.
+func (im *hotImporter) value(owner, prop string, value interface{}) (interface{}, bool) {
+	var unsupported []string
+	converted := im.convertFunctions(value, &unsupported)
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		im.report.leftOut(owner, fmt.Sprintf("property %s using %s", prop, strings.Join(unsupported, ", ")))
+		return nil, false
+	}
+	return converted, true
+}
+
+func (im *hotImporter) convertFunctions(value interface{}, unsupported *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for function, arg := range v {
+				ours, ok := hotFunctions[function]
+				if !ok {
+					break
+				}
+				if ours == "" {
+					*unsupported = append(*unsupported, function)
+					return nil
+				}
+				arg = im.convertFunctions(arg, unsupported)
+				switch ours {
+				case GetParam:
+					if name, _, err := splitArgument(GetParam, arg); err == nil && strings.HasPrefix(name, "OS::") {
+						*unsupported = append(*unsupported, "get_param "+name)
+						return nil
+					}
+				case GetAttr:
+					return im.groupAttr(arg)
+				}
+				return map[string]interface{}{ours: arg}
+			}
+		}
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = im.convertFunctions(item, unsupported)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = im.convertFunctions(item, unsupported)
+		}
+		return res
+	}
+	return value
+}
+
+// groupAttr converts a get_attr of a ResourceGroup: resource.N is its
+// member N and refs the ids of its members.
+func (im *hotImporter) groupAttr(arg interface{}) interface{} {
+	path, ok := arg.([]interface{})
+	if !ok || len(path) < 2 {
+		return map[string]interface{}{GetAttr: arg}
+	}
+	name, _ := path[0].(string)
+	if !im.groups[name] {
+		return map[string]interface{}{GetAttr: arg}
+	}
+	attr, _ := path[1].(string)
+	if attr == "refs" && len(path) == 2 {
+		return map[string]interface{}{GetResource: name}
+	}
+	if m := groupMember.FindStringSubmatch(attr); m != nil {
+		member := fmt.Sprintf("%s[%s]", name, m[1])
+		if len(path) == 2 {
+			return map[string]interface{}{GetResource: member}
+		}
+		return map[string]interface{}{GetAttr: append([]interface{}{member}, path[2:]...)}
+	}
+	return map[string]interface{}{GetAttr: arg}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
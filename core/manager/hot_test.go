@@ -0,0 +1,141 @@
+package manager
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportHOT(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want are the imported resources, or outputs, with their properties
+		want  map[string]interface{}
+		lines []string
+	}{
+		{
+			name: "properties renamed",
+			src: `
+resources:
+  net: {type: OS::Neutron::Net, properties: {name: n}}
+  subnet:
+    type: OS::Neutron::Subnet
+    properties: {network: {get_resource: net}, cidr: 10.0.0.0/24}
+`,
+			want: map[string]interface{}{
+				"net":    map[string]interface{}{"type": "network", "properties": map[string]interface{}{"name": "n"}},
+				"subnet": map[string]interface{}{"type": "subnet", "properties": map[string]interface{}{"network_id": map[string]interface{}{GetResource: "net"}, "cidr": "10.0.0.0/24"}},
+			},
+		},
+		{
+			name: "security group rules",
+			src: `
+resources:
+  sg:
+    type: OS::Neutron::SecurityGroup
+    properties: {rules: [{protocol: icmp}]}
+`,
+			want: map[string]interface{}{
+				"sg": map[string]interface{}{"type": "security_group"},
+				"sg_rule_0": map[string]interface{}{"type": "security_group_rule", "properties": map[string]interface{}{
+					"protocol": "icmp", "direction": "ingress", "ethertype": "IPv4",
+					"security_group_id": map[string]interface{}{GetResource: "sg"},
+				}},
+			},
+		},
+		{
+			name: "resource group",
+			src: `
+resources:
+  nets:
+    type: OS::Heat::ResourceGroup
+    properties:
+      count: 3
+      index_var: "%i%"
+      resource_def: {type: OS::Neutron::Net, properties: {name: "net_%i%"}}
+`,
+			want: map[string]interface{}{
+				"nets": map[string]interface{}{"type": "network", Count: 3, "properties": map[string]interface{}{"name": "net_%index%"}},
+			},
+		},
+		{
+			name: "left out",
+			src: `
+conditions: {}
+parameters:
+  image: {type: string, constraints: [{custom_constraint: glance.image}]}
+resources:
+  net:
+    type: OS::Neutron::Net
+    update_policy: {}
+    properties: {name: {get_file: name.txt}, dhcp_agent_ids: [a]}
+  wait: {type: OS::Heat::WaitCondition}
+`,
+			want: map[string]interface{}{
+				"net": map[string]interface{}{"type": "network"},
+			},
+			lines: []string{
+				"section conditions is not supported",
+				"resource wait: type OS::Heat::WaitCondition is not supported",
+				"parameter image: custom_constraint glance.image is not supported",
+				"resource net: property dhcp_agent_ids is not supported",
+				"resource net: property name using get_file is not supported",
+				"resource net: update_policy is not supported",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hot := parseTemplate(t, "heat_template_version: 2018-08-31\n"+tt.src)
+			template, report, err := ImportHOT(hot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resources := template["resources"].(map[string]interface{})
+			if !reflect.DeepEqual(resources, tt.want) {
+				t.Fatalf("got %#v\nwant %#v", resources, tt.want)
+			}
+			if lines := report.Lines(); !reflect.DeepEqual(lines, tt.lines) {
+				t.Fatalf("report %q\nwant %q", lines, tt.lines)
+			}
+			if report.Empty() != (len(tt.lines) == 0) {
+				t.Fatalf("Empty() = %v with %d lines", report.Empty(), len(tt.lines))
+			}
+		})
+	}
+}
+
+func TestImportHOTNotHOT(t *testing.T) {
+	if _, _, err := ImportHOT(parseTemplate(t, "resources: {}")); err == nil {
+		t.Fatal("expected an error for a template without heat_template_version")
+	}
+}
+
+func TestLoadHOTTemplate(t *testing.T) {
+	template, err := LoadTemplate("../../configs/templates/hot_instance.yaml", map[string]interface{}{"server_count": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"net", "subnet", "router", "router_interface", "web_sg", "web_sg_rule_0", "web_sg_rule_1", "servers[0]", "servers[2]", "port", "fip"} {
+		if _, ok := template.Resources[name]; !ok {
+			t.Errorf("missing resource %s", name)
+		}
+	}
+	if _, ok := template.Resources["fip"].Dependencies["router_interface"]; !ok {
+		t.Errorf("fip depends on %v, want router_interface", template.Resources["fip"].Dependencies)
+	}
+	// get_attr [servers, refs] and [servers, resource.0, ...] of the group
+	// refer to its members
+	ids := template.Outputs["server_ids"].(map[string]interface{})["value"]
+	if members, _ := ids.([]interface{}); len(members) != 3 {
+		t.Errorf("server_ids %v", ids)
+	}
+	addresses := template.Outputs["first_server_addresses"].(map[string]interface{})["value"]
+	if want := map[string]interface{}{GetAttr: []interface{}{"servers[0]", "addresses"}}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("first_server_addresses %v", addresses)
+	}
+	if name := template.Properties["servers[1]"]["name"]; !strings.HasSuffix(name.(string), "_1") {
+		t.Errorf("servers[1] named %v", name)
+	}
+}
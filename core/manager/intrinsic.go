@@ -0,0 +1,294 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Intrinsic functions of templates, e.g. {get_attr: [fip1, floating_ip_address]}.
+// list_join and str_replace are evaluated with the parameters, their
+// arguments cannot use get_resource or get_attr.
+const (
+	GetResource = "get_resource"
+	GetAttr     = "get_attr"
+	GetParam    = "get_param"
+	ListJoin    = "list_join"
+	StrReplace  = "str_replace"
+)
+
+// intrinsicResolver gives the values of get_resource and get_attr.
+type intrinsicResolver interface {
+	resourceId(name string) (string, error)
+	attribute(name string, path []interface{}) (interface{}, error)
+}
+
+// intrinsic returns the function and argument of a {function: argument} value.
+func intrinsic(value interface{}) (string, interface{}, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+	for function, arg := range m {
+		switch function {
+		case GetResource, GetAttr, GetParam, ListJoin, StrReplace:
+			return function, arg, true
+		}
+	}
+	return "", nil, false
+}
+
+// walkIntrinsics returns a copy of value with every intrinsic function
+// replaced by what replace returns for it.
+func walkIntrinsics(value interface{}, replace func(function string, arg interface{}) (interface{}, error)) (interface{}, error) {
+	if function, arg, ok := intrinsic(value); ok {
+		return replace(function, arg)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := walkIntrinsics(item, replace)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			res[key] = resolved
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := walkIntrinsics(item, replace)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			res[i] = resolved
+		}
+		return res, nil
+	}
+	return value, nil
+}
+
+// substituteParams replaces get_param by the value of the parameter and
+// evaluates list_join and str_replace, the other functions are left for
+// resolveIntrinsics.
+func substituteParams(value interface{}, params map[string]interface{}) (interface{}, error) {
+	return walkIntrinsics(value, func(function string, arg interface{}) (interface{}, error) {
+		if function == ListJoin || function == StrReplace {
+			arg, err := substituteParams(arg, params)
+			if err != nil {
+				return nil, err
+			}
+			if refs, _ := references(arg); len(refs) > 0 {
+				return nil, fmt.Errorf("%s cannot use %s or %s of %s", function, GetResource, GetAttr, refs[0])
+			}
+			if function == ListJoin {
+				return listJoin(arg)
+			}
+			return strReplace(arg)
+		}
+		if function != GetParam {
+			return map[string]interface{}{function: arg}, nil
+		}
+		name, path, err := splitArgument(function, arg)
+		if err != nil {
+			return nil, err
+		}
+		param, ok := params[name]
+		if !ok || param == nil {
+			return nil, fmt.Errorf("parameter %s has no value", name)
+		}
+		return lookupPath(param, path)
+	})
+}
+
+// listJoin joins the items of lists, {list_join: [", ", [a, b], [c]]} is
+// "a, b, c".
+func listJoin(arg interface{}) (string, error) {
+	args, ok := arg.([]interface{})
+	if !ok || len(args) < 2 {
+		return "", fmt.Errorf("invalid argument %v of %s", arg, ListJoin)
+	}
+	delimiter, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid delimiter %v of %s", args[0], ListJoin)
+	}
+	var items []string
+	for _, list := range args[1:] {
+		values, ok := list.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("%v of %s is not a list", list, ListJoin)
+		}
+		for _, value := range values {
+			items = append(items, fmt.Sprint(value))
+		}
+	}
+	return strings.Join(items, delimiter), nil
+}
+
+// strReplace replaces the keys of params in template by their values, the
+// longest keys first, {str_replace: {template: "$x!", params: {$x: hi}}}.
+func strReplace(arg interface{}) (string, error) {
+	args, _ := arg.(map[string]interface{})
+	template, ok := args["template"].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid template %v of %s", args["template"], StrReplace)
+	}
+	params, ok := args["params"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid params %v of %s", args["params"], StrReplace)
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j]) || len(keys[i]) == len(keys[j]) && keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key, fmt.Sprint(params[key]))
+	}
+	return strings.NewReplacer(pairs...).Replace(template), nil
+}
+
+// references returns the resources value refers to by get_resource or get_attr.
+func references(value interface{}) ([]string, error) {
+	var names []string
+	_, err := walkIntrinsics(value, func(function string, arg interface{}) (interface{}, error) {
+		name, _, err := splitArgument(function, arg)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		return nil, nil
+	})
+	return names, err
+}
+
+// resolveIntrinsics replaces get_resource by the id and get_attr by the
+// attribute of the created resource.
+func resolveIntrinsics(value interface{}, resolver intrinsicResolver) (interface{}, error) {
+	return walkIntrinsics(value, func(function string, arg interface{}) (interface{}, error) {
+		name, path, err := splitArgument(function, arg)
+		if err != nil {
+			return nil, err
+		}
+		if function == GetResource {
+			return resolver.resourceId(name)
+		}
+		return resolver.attribute(name, path)
+	})
+}
+
+// splitArgument splits the argument of a function into the resource or
+// parameter it names and the path below it, get_attr needs an attribute.
+func splitArgument(function string, arg interface{}) (string, []interface{}, error) {
+	switch a := arg.(type) {
+	case string:
+		if function != GetAttr {
+			return a, nil, nil
+		}
+	case []interface{}:
+		if len(a) > 0 && (function != GetAttr || len(a) > 1) {
+			if name, ok := a[0].(string); ok && (function != GetResource || len(a) == 1) {
+				return name, a[1:], nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("invalid argument %v of %s", arg, function)
+}
+
+// lookupPath walks into value by map keys and list indices.
+func lookupPath(value interface{}, path []interface{}) (interface{}, error) {
+	for _, key := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			k := fmt.Sprint(key)
+			item, ok := v[k]
+			if !ok {
+				return nil, fmt.Errorf("no attribute %s", k)
+			}
+			value = item
+		case []interface{}:
+			i, err := strconv.Atoi(fmt.Sprint(key))
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("invalid index %v of a list of %d", key, len(v))
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("no attribute %v in %v", key, value)
+		}
+	}
+	return value, nil
+}
+
+// placeholderResolver stands in the name of the resource for its id and
+// attributes, the opts built from it have the shape and dependencies of
+// the resource before anything is created.
+type placeholderResolver struct{}
+
+func (placeholderResolver) resourceId(name string) (string, error) {
+	return name, nil
+}
+
+func (placeholderResolver) attribute(name string, path []interface{}) (interface{}, error) {
+	return name, nil
+}
+
+// stackResolver resolves get_resource and get_attr against the resources
+// created by a scheduler, resources are those of its template.
+type stackResolver struct {
+	manager       *Manager
+	resources     map[string]Resource
+	completedOuts *sync.Map
+}
+
+func (s stackResolver) resourceId(name string) (string, error) {
+	out, ok := s.completedOuts.Load(name)
+	if !ok || !out.(Output).IsSuccess {
+		return "", fmt.Errorf("resource %s was not created", name)
+	}
+	return out.(Output).Resp, nil
+}
+
+func (s stackResolver) attribute(name string, path []interface{}) (interface{}, error) {
+	id, err := s.resourceId(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 && path[0] == "id" {
+		return id, nil
+	}
+	resource := s.resources[name]
+	kind := resourceTypes[resource.Type]
+	if kind.show == nil {
+		return nil, fmt.Errorf("%s resource %s has no attributes", resource.Type, name)
+	}
+	attrs, err := kind.show(s.manager, resource.PropsObj, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := lookupPath(attrs, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s of %s: %w", GetAttr, name, err)
+	}
+	return value, nil
+}
+
+// transmittedResolver leaves get_resource of a dependency with a field path
+// to the Transmitter, which knows its type, e.g. that a floating ip needs
+// the port of a server rather than its id.
+type transmittedResolver struct {
+	stackResolver
+	dependencies map[string]string
+}
+
+func (t transmittedResolver) resourceId(name string) (string, error) {
+	if t.dependencies[name] != "" {
+		return name, nil
+	}
+	return t.stackResolver.resourceId(name)
+}
@@ -0,0 +1,214 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"request_openstack/consts"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// parseTemplate decodes a template written inline in a test.
+func parseTemplate(t *testing.T, src string) map[string]interface{} {
+	t.Helper()
+	var template map[string]interface{}
+	if err := yaml.Unmarshal([]byte(src), &template); err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+// fakeResolver resolves get_resource and get_attr from ids and attributes
+// of resources given by name.
+type fakeResolver struct {
+	ids   map[string]string
+	attrs map[string]map[string]interface{}
+}
+
+func (f fakeResolver) resourceId(name string) (string, error) {
+	id, ok := f.ids[name]
+	if !ok {
+		return "", fmt.Errorf("resource %s was not created", name)
+	}
+	return id, nil
+}
+
+func (f fakeResolver) attribute(name string, path []interface{}) (interface{}, error) {
+	if _, err := f.resourceId(name); err != nil {
+		return nil, err
+	}
+	return lookupPath(f.attrs[name], path)
+}
+
+func TestResolveIntrinsics(t *testing.T) {
+	resolver := fakeResolver{
+		ids: map[string]string{"net": "net-id", "fip": "fip-id"},
+		attrs: map[string]map[string]interface{}{
+			"fip": {"floating_ip_address": "172.24.4.10", "port_details": map[string]interface{}{"fixed_ips": []interface{}{"10.0.0.5"}}},
+		},
+	}
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"plain", "net", "net", ""},
+		{"get_resource", map[string]interface{}{GetResource: "net"}, "net-id", ""},
+		{"get_attr", map[string]interface{}{GetAttr: []interface{}{"fip", "floating_ip_address"}}, "172.24.4.10", ""},
+		{"get_attr path", map[string]interface{}{GetAttr: []interface{}{"fip", "port_details", "fixed_ips", 0}}, "10.0.0.5", ""},
+		{
+			"nested",
+			map[string]interface{}{"networks": []interface{}{map[string]interface{}{"uuid": map[string]interface{}{GetResource: "net"}}}},
+			map[string]interface{}{"networks": []interface{}{map[string]interface{}{"uuid": "net-id"}}},
+			"",
+		},
+		{"unknown resource", map[string]interface{}{GetResource: "router"}, nil, "resource router was not created"},
+		{"unknown attribute", map[string]interface{}{GetAttr: []interface{}{"fip", "status"}}, nil, "no attribute status"},
+		{"index out of range", map[string]interface{}{GetAttr: []interface{}{"fip", "port_details", "fixed_ips", 1}}, nil, "invalid index 1 of a list of 1"},
+		{"get_attr without attribute", map[string]interface{}{GetAttr: "fip"}, nil, "invalid argument fip of get_attr"},
+		{"error path", map[string]interface{}{"ports": []interface{}{map[string]interface{}{GetResource: "x"}}}, nil, "ports: [0]: resource x was not created"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveIntrinsics(tt.value, resolver)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withShow replaces the show of a resource type for the duration of a test.
+func withShow(t *testing.T, typ string, attrs map[string]interface{}) {
+	saved := resourceTypes[typ]
+	kind := saved
+	kind.show = func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+		return attrs, nil
+	}
+	resourceTypes[typ] = kind
+	t.Cleanup(func() { resourceTypes[typ] = saved })
+}
+
+// completedOutputs are the outputs of resources created with the given ids,
+// or failed when the id is "".
+func completedOutputs(ids map[string]string) *sync.Map {
+	var outs sync.Map
+	for name, id := range ids {
+		outs.Store(name, Output{Type: name, IsSuccess: id != "", Resp: id})
+	}
+	return &outs
+}
+
+func TestStackResolver(t *testing.T) {
+	withShow(t, consts.NETWORK, map[string]interface{}{"mtu": 1450})
+	resources := map[string]Resource{
+		"net":    {Name: "net", Type: consts.NETWORK},
+		"failed": {Name: "failed", Type: consts.NETWORK},
+		"image":  {Name: "image", Type: "image"},
+	}
+	resolver := stackResolver{
+		manager:       &Manager{},
+		resources:     resources,
+		completedOuts: completedOutputs(map[string]string{"net": "net-id", "failed": "", "image": "image-id"}),
+	}
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"id", map[string]interface{}{GetResource: "net"}, "net-id", ""},
+		{"id attribute", map[string]interface{}{GetAttr: []interface{}{"net", "id"}}, "net-id", ""},
+		{"attribute", map[string]interface{}{GetAttr: []interface{}{"net", "mtu"}}, 1450, ""},
+		{"unknown attribute", map[string]interface{}{GetAttr: []interface{}{"net", "status"}}, nil, "get_attr of net: no attribute status"},
+		{"failed resource", map[string]interface{}{GetResource: "failed"}, nil, "resource failed was not created"},
+		{"unknown resource", map[string]interface{}{GetAttr: []interface{}{"subnet", "cidr"}}, nil, "resource subnet was not created"},
+		{"no attributes", map[string]interface{}{GetAttr: []interface{}{"image", "size"}}, nil, "image resource image has no attributes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveIntrinsics(tt.value, resolver)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	// a dependency with a field path is left to the Transmitter
+	transmitted := transmittedResolver{stackResolver: resolver, dependencies: map[string]string{"net": "NetworkID", "failed": ""}}
+	if id, err := transmitted.resourceId("net"); err != nil || id != "net" {
+		t.Fatalf("got %q, %v, want the name", id, err)
+	}
+	if _, err := transmitted.resourceId("failed"); err == nil {
+		t.Fatal("expected the error of the failed resource")
+	}
+}
+
+func TestSchedulerOutputs(t *testing.T) {
+	withShow(t, consts.FLOATINGIP, map[string]interface{}{"floating_ip_address": "172.24.4.10"})
+	template, err := ResolveTemplate(parseTemplate(t, `
+resources:
+  net: {type: network}
+  fip: {type: floatingip, properties: {floating_network_id: net}}
+outputs:
+  net_id:
+    description: id of the network
+    value: {get_resource: net}
+  addresses:
+    value: [{get_attr: [fip, floating_ip_address]}]
+  missing:
+    value: {get_attr: [fip, status]}
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := &Scheduler{
+		Manager:     &Manager{},
+		OutputsFile: filepath.Join(t.TempDir(), "outputs.json"),
+		resources:   template.Resources,
+		outputs:     template.Outputs,
+	}
+	for name, out := range map[string]string{"net": "net-id", "fip": "fip-id"} {
+		scheduler.completedOuts.Store(name, Output{Type: name, IsSuccess: true, Resp: out})
+	}
+	err = scheduler.exportOutputs()
+	if err == nil || !strings.Contains(err.Error(), "output missing: get_attr of fip: no attribute status") {
+		t.Fatalf("expected the error of output missing, got %v", err)
+	}
+	data, err := ioutil.ReadFile(scheduler.OutputsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported map[string]interface{}
+	if err = json.Unmarshal(data, &exported); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"net_id": "net-id", "addresses": []interface{}{"172.24.4.10"}}
+	if !reflect.DeepEqual(exported, want) {
+		t.Fatalf("exported %s, want %v", data, want)
+	}
+}
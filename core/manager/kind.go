@@ -0,0 +1,190 @@
+package manager
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"request_openstack/consts"
+	"strings"
+	"unicode"
+)
+
+// Kinds of kind-based templates ImportKind supports.
+var templateKinds = map[string]bool{
+	consts.IpsecConnection: true,
+}
+
+// kindAliases are the resources of dependences that are not their type in
+// CamelCase, as Router is router, e.g. Vpn for vpn_service.
+var kindAliases = map[string]string{
+	"Vpn": consts.VpnService,
+}
+
+// kindLinks are the properties a resource refers to its dependences of a
+// type by, a dependence of another type is only created before it.
+var kindLinks = map[string]map[string]string{
+	consts.IpsecConnection: {
+		consts.VpnService:  "vpnservice_id",
+		consts.IkePolicy:   "ikepolicy_id",
+		consts.IpsecPolicy: "ipsecpolicy_id",
+	},
+	consts.VpnService: {
+		consts.ROUTER: "router_id",
+		consts.SUBNET: "subnet_id",
+	},
+	consts.ROUTERINTERFACE: {
+		consts.ROUTER: "router_id",
+		consts.SUBNET: "subnet_id",
+	},
+	consts.SUBNET: {
+		consts.NETWORK: "network_id",
+	},
+	consts.PORT: {
+		consts.NETWORK: "network_id",
+	},
+}
+
+// IsKind tells whether template is a kind-based template.
+func IsKind(template map[string]interface{}) bool {
+	_, ok := template["kind"]
+	return ok
+}
+
+// ImportKind converts a kind-based template to a template of the scheduler.
+// The resource of the kind is named after it and has the metadata as
+// properties, each dependence is a resource named <type>_<name>, or <type>
+// without name, created before the resource depending on it, which refers
+// to it by the property of kindLinks. An endpoint group of type subnet is
+// the local group of an ipsec connection and has its subnet dependences as
+// subnets, one of type cidr its peer group.
+func ImportKind(template map[string]interface{}) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var configMap ConfigMap
+	if err = yaml.Unmarshal(data, &configMap); err != nil {
+		return nil, err
+	}
+	if !templateKinds[configMap.Kind] {
+		return nil, fmt.Errorf("unsupported kind %q", configMap.Kind)
+	}
+	im := &kindImporter{resources: make(map[string]interface{}), defined: make(map[string]bool)}
+	root := Dependence{
+		Resource:    configMap.Kind,
+		Properties:  configMap.Metadata,
+		Dependences: configMap.Dependences,
+	}
+	if _, err = im.add(root, nil); err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{"resources": im.resources}
+	if configMap.Parameters != nil {
+		res["parameters"] = configMap.Parameters
+	}
+	if configMap.Outputs != nil {
+		res["outputs"] = configMap.Outputs
+	}
+	return res, nil
+}
+
+type kindImporter struct {
+	resources map[string]interface{}
+	// defined are the resources whose properties or dependences were given
+	defined map[string]bool
+}
+
+// add adds the resource of dependence and its dependences and returns its
+// name, path are the names of the resources depending on it.
+func (im *kindImporter) add(dependence Dependence, path []string) (string, error) {
+	resourceType, err := kindType(dependence.Resource)
+	if err != nil {
+		return "", err
+	}
+	name := resourceType
+	if dependence.Name != "" {
+		name += "_" + dependence.Name
+	}
+	for _, dependent := range path {
+		if dependent == name {
+			return "", fmt.Errorf("dependence cycle %s -> %s", strings.Join(path, " -> "), name)
+		}
+	}
+	props := make(map[string]interface{}, len(dependence.Properties))
+	for key, value := range dependence.Properties {
+		props[key] = value
+	}
+	if _, ok := props["name"]; !ok && dependence.Name != "" {
+		// the name of the resource too, when its type has one
+		if checkProperties(resourceTypes[resourceType], name, map[string]interface{}{"name": nil}) == nil {
+			props["name"] = dependence.Name
+		}
+	}
+	var dependsOn []interface{}
+	for _, dep := range dependence.Dependences {
+		depName, err := im.add(dep, append(path, name))
+		if err != nil {
+			return "", err
+		}
+		ref := map[string]interface{}{GetResource: depName}
+		depType := im.resources[depName].(map[string]interface{})["type"].(string)
+		switch link := kindLinks[resourceType][depType]; {
+		case resourceType == consts.IpsecConnection && depType == consts.EndpointGroup:
+			groupProps, _ := im.resources[depName].(map[string]interface{})["properties"].(map[string]interface{})
+			if groupProps["type"] == "subnet" {
+				props["local_ep_group_id"] = ref
+			} else {
+				props["peer_ep_group_id"] = ref
+			}
+		case resourceType == consts.EndpointGroup && depType == consts.SUBNET:
+			subnets, _ := props["subnets"].([]interface{})
+			props["subnets"] = append(subnets, ref)
+		case link != "":
+			props[link] = ref
+		default:
+			dependsOn = append(dependsOn, depName)
+		}
+	}
+
+	resource := map[string]interface{}{"type": resourceType}
+	if len(props) > 0 {
+		resource["properties"] = props
+	}
+	if len(dependsOn) > 0 {
+		resource[DependsOn] = dependsOn
+	}
+	if len(dependence.Properties) > 0 || len(dependence.Dependences) > 0 {
+		if im.defined[name] {
+			return "", fmt.Errorf("resource %s is defined twice", name)
+		}
+		im.defined[name] = true
+	} else if _, ok := im.resources[name]; ok {
+		// a dependence of the same type and name without properties nor
+		// dependences refers to the resource defined elsewhere
+		return name, nil
+	}
+	im.resources[name] = resource
+	return name, nil
+}
+
+// kindType returns the type of the resource of a dependence, e.g. router for
+// Router.
+func kindType(resource string) (string, error) {
+	if resourceType, ok := kindAliases[resource]; ok {
+		return resourceType, nil
+	}
+	var b strings.Builder
+	for i, r := range resource {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	resourceType := b.String()
+	if _, ok := resourceTypes[resourceType]; !ok {
+		return "", fmt.Errorf("unsupported resource %q", resource)
+	}
+	return resourceType, nil
+}
@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportKind(t *testing.T) {
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{GetResource: name}
+	}
+	tests := []struct {
+		name    string
+		src     string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "links and depends_on",
+			src: `
+kind: ipsec_connection
+metadata: {psk: secret}
+dependences:
+  - resource: Vpn
+    dependences: {resource: Router}
+  - resource: Port
+    name: a
+    dependences: {resource: Network, name: a}
+`,
+			want: map[string]interface{}{
+				"router":      map[string]interface{}{"type": "router"},
+				"vpn_service": map[string]interface{}{"type": "vpn_service", "properties": map[string]interface{}{"router_id": ref("router")}},
+				"network_a":   map[string]interface{}{"type": "network", "properties": map[string]interface{}{"name": "a"}},
+				"port_a":      map[string]interface{}{"type": "port", "properties": map[string]interface{}{"name": "a", "network_id": ref("network_a")}},
+				"ipsec_connection": map[string]interface{}{
+					"type":       "ipsec_connection",
+					"properties": map[string]interface{}{"psk": "secret", "vpnservice_id": ref("vpn_service")},
+					DependsOn:    []interface{}{"port_a"},
+				},
+			},
+		},
+		{
+			name: "endpoint groups",
+			src: `
+kind: ipsec_connection
+dependences:
+  - resource: EndpointGroup
+    name: local
+    properties: {type: subnet}
+    dependences: [{resource: Subnet, name: a}, {resource: Subnet, name: b}]
+  - resource: EndpointGroup
+    name: peer
+    properties: {type: cidr}
+`,
+			want: map[string]interface{}{
+				"subnet_a": map[string]interface{}{"type": "subnet", "properties": map[string]interface{}{"name": "a"}},
+				"subnet_b": map[string]interface{}{"type": "subnet", "properties": map[string]interface{}{"name": "b"}},
+				"endpoint_group_local": map[string]interface{}{"type": "endpoint_group", "properties": map[string]interface{}{
+					"name": "local", "type": "subnet", "subnets": []interface{}{ref("subnet_a"), ref("subnet_b")},
+				}},
+				"endpoint_group_peer": map[string]interface{}{"type": "endpoint_group", "properties": map[string]interface{}{"name": "peer", "type": "cidr"}},
+				"ipsec_connection": map[string]interface{}{"type": "ipsec_connection", "properties": map[string]interface{}{
+					"local_ep_group_id": ref("endpoint_group_local"), "peer_ep_group_id": ref("endpoint_group_peer"),
+				}},
+			},
+		},
+		{
+			name: "same type and name refers to the resource",
+			src: `
+kind: ipsec_connection
+dependences:
+  - resource: RouterInterface
+    dependences: [{resource: Router, name: r}, {resource: Subnet, name: s}]
+  - resource: Vpn
+    dependences: [{resource: Router, name: r, properties: {admin_state_up: true}}, {resource: Subnet, name: s}]
+`,
+			want: map[string]interface{}{
+				"router_r":         map[string]interface{}{"type": "router", "properties": map[string]interface{}{"name": "r", "admin_state_up": true}},
+				"subnet_s":         map[string]interface{}{"type": "subnet", "properties": map[string]interface{}{"name": "s"}},
+				"router_interface": map[string]interface{}{"type": "router_interface", "properties": map[string]interface{}{"router_id": ref("router_r"), "subnet_id": ref("subnet_s")}},
+				"vpn_service":      map[string]interface{}{"type": "vpn_service", "properties": map[string]interface{}{"router_id": ref("router_r"), "subnet_id": ref("subnet_s")}},
+				"ipsec_connection": map[string]interface{}{"type": "ipsec_connection", DependsOn: []interface{}{"router_interface"}, "properties": map[string]interface{}{"vpnservice_id": ref("vpn_service")}},
+			},
+		},
+		{name: "unsupported kind", src: "kind: network", wantErr: `unsupported kind "network"`},
+		{name: "unsupported resource", src: "kind: ipsec_connection\ndependences: {resource: Bucket}", wantErr: `unsupported resource "Bucket"`},
+		{
+			name:    "cycle",
+			src:     "kind: ipsec_connection\ndependences: {resource: Router, name: x, dependences: {resource: Router, name: x}}",
+			wantErr: "dependence cycle ipsec_connection -> router_x -> router_x",
+		},
+		{
+			name: "defined twice",
+			src: `
+kind: ipsec_connection
+dependences:
+  - {resource: Network, name: a, properties: {mtu: 1400}}
+  - {resource: Network, name: a, properties: {mtu: 9000}}
+`,
+			wantErr: "resource network_a is defined twice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := parseTemplate(t, tt.src)
+			if !IsKind(template) {
+				t.Fatal("not a kind-based template")
+			}
+			imported, err := ImportKind(template)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resources := imported["resources"]; !reflect.DeepEqual(resources, tt.want) {
+				t.Fatalf("got %#v\nwant %#v", resources, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKindTemplate(t *testing.T) {
+	if IsKind(parseTemplate(t, stackV1)) {
+		t.Fatal("a template of the scheduler is not kind-based")
+	}
+	template, err := LoadTemplate("../../configs/templates/ipsec_connection.yaml", map[string]interface{}{"peer_cidr": "10.30.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		resource   string
+		dependency string
+	}{
+		{"ipsec_connection", "vpn_service_dx"},
+		{"ipsec_connection", "ike_policy"},
+		{"ipsec_connection", "ipsec_policy"},
+		{"ipsec_connection", "endpoint_group_local"},
+		{"ipsec_connection", "endpoint_group_peer"},
+		{"ipsec_connection", "router_interface_dx"},
+		{"vpn_service_dx", "router_dx"},
+		{"endpoint_group_local", "subnet_dx"},
+		{"subnet_dx", "network_dx"},
+		{"router_interface_dx", "router_dx"},
+		{"router_interface_dx", "subnet_dx"},
+	}
+	for _, tt := range tests {
+		resource, ok := template.Resources[tt.resource]
+		if !ok {
+			t.Fatalf("missing resource %s", tt.resource)
+		}
+		if _, ok := resource.Dependencies[tt.dependency]; !ok {
+			t.Errorf("%s depends on %v, want %s", tt.resource, resource.Dependencies, tt.dependency)
+		}
+	}
+	if n := len(template.Resources); n != 10 {
+		t.Errorf("expected 10 resources, got %d", n)
+	}
+	if endpoints := template.Properties["endpoint_group_peer"]["endpoints"]; !reflect.DeepEqual(endpoints, []interface{}{"10.30.0.0/24"}) {
+		t.Errorf("peer endpoints %v", endpoints)
+	}
+}
@@ -1,18 +1,22 @@
 package manager
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"reflect"
 	"request_openstack/configs"
 	"request_openstack/consts"
+	"request_openstack/internal"
 	"request_openstack/internal/entity"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -23,15 +27,21 @@ type Worker struct {
     concurrency      int
 }
 
-func NewWorker(userName string) *Worker {
-	manager := NewAdminManager()
-	projectId := manager.GetProjectId(userName)
+func NewWorker(userName string) (*Worker, error) {
+	manager, err := NewAdminManager()
+	if err != nil {
+		return nil, err
+	}
+	projectId, err := manager.GetProjectId(userName)
+	if err != nil {
+		return nil, err
+	}
 	return &Worker{
 		UserName: userName,
 		AdminManager: manager,
 		projectId: projectId,
 		concurrency: 1,
-	}
+	}, nil
 }
 
 type fipAssoc struct {
@@ -57,58 +67,40 @@ type L3RelatedResource struct {
 	Fips                 map[string]fipAssoc         `json:"fips"`
 }
 
-func (w *Worker) listRouters(projectId string) entity.Routers {
-	var urlSuffix string
+// projectOpts scopes the lists of the admin client to the project, an admin
+// worker looks at every project.
+func (w *Worker) projectOpts(projectId string) entity.ListOpts {
 	if w.UserName == consts.ADMIN {
-		urlSuffix = consts.ROUTERS
-	} else {
-		urlSuffix = fmt.Sprintf("routers?project_id=%s", projectId)
+		return entity.ListOpts{}
 	}
-	resp := w.AdminManager.Neutron.List(w.AdminManager.Neutron.Headers, urlSuffix)
-	var routers entity.Routers
-	_ = json.Unmarshal(resp, &routers)
-	log.Println("==============List routers success, there had", routers.Count)
-	return routers
+	return entity.ListOpts{ProjectId: projectId}
 }
 
-func (w *Worker) listFIPs(projectId string) entity.Fips {
-	var urlSuffix string
-	if w.UserName == consts.ADMIN {
-		urlSuffix = consts.FLOATINGIPS
-	} else {
-		urlSuffix = fmt.Sprintf("floatingips?project_id=%s", projectId)
-	}
-
-	resp := w.AdminManager.Neutron.List(w.AdminManager.Neutron.Headers, urlSuffix)
-	var fs entity.Fips
-	_ = json.Unmarshal(resp, &fs)
-	log.Println("==============List fip success, there had", fs.Count)
-	return fs
+func (w *Worker) listRouters(projectId string) (entity.Routers, error) {
+	return w.AdminManager.Neutron.ListRouters(entity.ListRoutersOpts{ListOpts: w.projectOpts(projectId)})
 }
 
+func (w *Worker) listFIPs(projectId string) (entity.Fips, error) {
+	return w.AdminManager.Neutron.ListFIPs(entity.ListFipsOpts{ListOpts: w.projectOpts(projectId)})
+}
 
-func (w *Worker) listRouterFIPs(routerId string) entity.Fips {
-	var urlSuffix string
-	if w.UserName == consts.ADMIN {
-		urlSuffix = consts.FLOATINGIPS
-	} else {
-		urlSuffix = fmt.Sprintf("floatingips?router_id=%s", routerId)
-	}
-
-	resp := w.AdminManager.Neutron.List(w.AdminManager.Neutron.Headers, urlSuffix)
-	var fs entity.Fips
-	_ = json.Unmarshal(resp, &fs)
-	log.Println("==============List fip success, there had", fs.Count)
-	return fs
+func (w *Worker) listRouterFIPs(routerId string) (entity.Fips, error) {
+	return w.AdminManager.Neutron.ListFIPs(entity.ListFipsOpts{RouterId: routerId})
 }
 
-func (w *Worker) generateL3RelatedResObjs() L3RelatedResource {
+func (w *Worker) generateL3RelatedResObjs() (L3RelatedResource, error) {
 	var lrr = L3RelatedResource{}
 
 	lrr.Routers = make(map[string]routerAssoc)
-	routers := w.listRouters(w.projectId)
+	routers, err := w.listRouters(w.projectId)
+	if err != nil {
+		return lrr, err
+	}
 	for _, router := range routers.Rs {
-		fips := w.listRouterFIPs(router.Id)
+		fips, err := w.listRouterFIPs(router.Id)
+		if err != nil {
+			return lrr, err
+		}
 		fipIds := make([]string, 0)
 		for _, fip := range fips.Fs {
 			fipIds = append(fipIds, fip.Id)
@@ -122,10 +114,19 @@ func (w *Worker) generateL3RelatedResObjs() L3RelatedResource {
 	}
 
 	lrr.Fips = make(map[string]fipAssoc)
-	fips := w.listFIPs(w.projectId)
+	fips, err := w.listFIPs(w.projectId)
+	if err != nil {
+		return lrr, err
+	}
 	for _, fip := range fips.Fs {
-		pfs := w.AdminManager.ListPortForwarding(fip.Id)
-		fipPort := w.AdminManager.GetFloatingipPort(fip.Id)
+		pfs, err := w.AdminManager.ListPortForwarding(fip.Id)
+		if err != nil {
+			return lrr, err
+		}
+		fipPort, err := w.AdminManager.GetFloatingipPort(fip.Id)
+		if err != nil {
+			return lrr, err
+		}
 		var qosPolicyId string
 		if fipPort.QosPolicyId != nil {
 			qosPolicyId = fipPort.QosPolicyId.(string)
@@ -147,7 +148,7 @@ func (w *Worker) generateL3RelatedResObjs() L3RelatedResource {
 		lrr.Fips[fip.Id] = fipAssoc
 	}
 	log.Printf("==============Generate l3 related resource %+v", lrr)
-	return lrr
+	return lrr, nil
 }
 
 func (w *Worker) exportToJsonFile(lrr L3RelatedResource) {
@@ -168,93 +169,99 @@ func (w *Worker) exportToJsonFile(lrr L3RelatedResource) {
 	log.Println("==============Export to json file success", fileName)
 }
 
-func (w *Worker) deleteFipResources(fip fipAssoc, wg *sync.WaitGroup, msg *string) {
-	defer func() {
-		if err := recover(); err != nil {
-			errInfo := fmt.Sprintf("fip disassociated port %s error %v", fip.FipId, err)
-			log.Println("************************catch error：", errInfo)
-			*msg += "\n" + errInfo
-		}
-		wg.Done()
-	}()
+func (w *Worker) deleteFipResources(fip fipAssoc) error {
 	for _, pf := range fip.PortForwardings.Pfs {
-		w.AdminManager.DeletePortForwarding(fip.FipId, pf.Id)
+		if output := w.AdminManager.DeletePortForwarding(fip.FipId, pf.Id); !output.Success {
+			return fmt.Errorf("fip %s delete port forwarding %s error %v", fip.FipId, pf.Id, output.Response)
+		}
 	}
 
 	if len(fip.FipAssocPort) != 0 {
-		w.AdminManager.FloatingIpDisassociatePort(fip.FipId)
+		if _, err := w.AdminManager.FloatingIpDisassociatePort(fip.FipId); err != nil {
+			return fmt.Errorf("fip disassociated port %s error %w", fip.FipId, err)
+		}
 	}
 
-	w.AdminManager.UpdatePortWithNoQos(fip.FipPort)
+	if err := w.AdminManager.UpdatePortWithNoQos(fip.FipPort); err != nil {
+		return fmt.Errorf("fip %s remove port qos error %w", fip.FipId, err)
+	}
+	return nil
 }
 
-func (w *Worker) deleteRouterResources(router routerAssoc, msg *string) {
-	defer func() {
-		if err := recover(); err != nil {
-			errInfo := fmt.Sprintf("router %s clear gateway error %v", router.RouterId, err)
-			log.Println("************************catch error：", errInfo)
-			*msg += "\n" + errInfo
+// eachFip calls f on the fips concurrently and returns their errors, one per
+// line, empty when all succeeded.
+func eachFip(fips []fipAssoc, f func(fipAssoc) error) string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	msg := ""
+	for _, fip := range fips {
+		wg.Add(1)
+		go func(fip fipAssoc) {
+			defer wg.Done()
+			if err := f(fip); err != nil {
+				log.Println("************************catch error：", err)
+				mu.Lock()
+				msg += "\n" + err.Error()
+				mu.Unlock()
+			}
+		}(fip)
+	}
+	wg.Wait()
+	return msg
+}
+
+// routerFips returns the fips of lrr associated to the router.
+func routerFips(assoc routerAssoc, lrr L3RelatedResource) []fipAssoc {
+	fips := make([]fipAssoc, 0, len(assoc.Fips))
+	for _, fipId := range assoc.Fips {
+		if fip, ok := lrr.Fips[fipId]; ok {
+			fips = append(fips, fip)
 		}
-	}()
+	}
+	return fips
+}
+
+// noAssocFips returns the fips of lrr associated to no router.
+func noAssocFips(lrr L3RelatedResource) []fipAssoc {
+	fips := make([]fipAssoc, 0)
+	for _, fip := range lrr.Fips {
+		if len(fip.RouterId) == 0 {
+			fips = append(fips, fip)
+		}
+	}
+	return fips
+}
+
+func (w *Worker) deleteRouterResources(router routerAssoc) error {
 	if reflect.DeepEqual(router.GatewayInfo, entity.GatewayInfo{}) {
 		log.Println("router no gateway", router.RouterId)
-		return
+		return nil
 	}
 	output := w.AdminManager.ClearRouterGateway(router.RouterId, router.NetworkID)
 	if !output.Success {
-		panic(output.Response)
+		return fmt.Errorf("router %s clear gateway error %v", router.RouterId, output.Response)
 	}
+	return nil
 }
 
 func (w *Worker) deleteRouterFip(assoc routerAssoc, lrr L3RelatedResource, msg *string) {
-	//defer func() {
-	//	if err := recover(); err != nil {
-	//		log.Println("************************catch error：", err)
-	//	}
-	//}()
-	var wg sync.WaitGroup
-	for _, fipId := range assoc.Fips {
-		wg.Add(1)
-		if fip, ok := lrr.Fips[fipId]; ok {
-			go w.deleteFipResources(fip, &wg, msg)
-		}
-	}
-	wg.Wait()
+	*msg += eachFip(routerFips(assoc, lrr), w.deleteFipResources)
 	if len(*msg) != 0 {
 		log.Printf("##########有错误THERE OCCUR ERROR FOR %s\n %s", assoc.RouterId, *msg)
 		return
 	}
-	w.deleteRouterResources(assoc, msg)
-	if len(*msg) != 0 {
+	if err := w.deleteRouterResources(assoc); err != nil {
+		*msg += "\n" + err.Error()
 		log.Printf("##########有错误THERE OCCUR ERROR FOR %s\n %s", assoc.RouterId, *msg)
-		return
 	}
 }
 
 func (w *Worker) deleteFipNoAssoc(lrr L3RelatedResource) string {
-	msg := ""
-	var wg sync.WaitGroup
-	for _, fip := range lrr.Fips {
-		if len(fip.RouterId) == 0 {
-			wg.Add(1)
-			go w.deleteFipResources(fip, &wg, &msg)
-		}
-	}
-	wg.Wait()
-	return msg
+	return eachFip(noAssocFips(lrr), w.deleteFipResources)
 }
 
 func (w *Worker) recoverFipNoAssoc(lrr L3RelatedResource) string {
-	msg := ""
-	var wg sync.WaitGroup
-	for _, fip := range lrr.Fips {
-		if len(fip.RouterId) == 0 {
-			wg.Add(1)
-			go w.processFip(fip, &wg, &msg)
-		}
-	}
-	wg.Wait()
-	return msg
+	return eachFip(noAssocFips(lrr), w.processFip)
 }
 
 func (w *Worker) DeleteAndRecoverL3RelatedRes(lrr L3RelatedResource) {
@@ -294,62 +301,48 @@ func (w *Worker) getRecordFromJsonFile(jsonFile string) L3RelatedResource {
 	return lrr
 }
 
-func (w *Worker) setRouterGateway(router routerAssoc, msg *string) {
-	defer func() {
-		if err := recover(); err != nil {
-			errInfo := fmt.Sprintf("router %s set gateway error %v", router.RouterId, err)
-			log.Println("************************catch error: ", errInfo)
-			*msg += "\n" + errInfo
-		}
-	}()
+func (w *Worker) setRouterGateway(router routerAssoc) error {
 	opts := entity.UpdateRouterOpts{
 		GatewayInfo: &router.GatewayInfo,
 	}
 	if reflect.DeepEqual(router.GatewayInfo, entity.GatewayInfo{}) {
 		log.Println("router no gateway", router.RouterId)
-		return
+		return nil
 	}
-	w.AdminManager.UpdateRouter(router.RouterId, &opts)
+	if _, err := w.AdminManager.UpdateRouter(router.RouterId, &opts); err != nil {
+		return fmt.Errorf("router %s set gateway error %w", router.RouterId, err)
+	}
+	return nil
 }
 
 func (w *Worker) handleRouterError(routerId string) bool {
 	timeout := 5 * 60 * time.Second
-	done := make(chan bool, 1)
-	go func() {
-		router := w.AdminManager.GetRouter(routerId)
-		state := router.Router.Status
-		for state != "ACTIVE" {
-			time.Sleep(10 * time.Second)
-			router = w.AdminManager.GetRouter(routerId)
-			state = router.Router.Status
+	err := internal.Poll(w.AdminManager.Context(), timeout, 10 * time.Second, func() (bool, error) {
+		router, err := w.AdminManager.GetRouter(routerId)
+		if err != nil {
+			return false, err
 		}
-		done <- true
-	}()
-	select {
-	case <-done:
-		log.Printf("*******************Router %s is ACTIVE\n", routerId)
-		return true
-	case <-time.After(timeout):
-		log.Printf("*******************Router %s is not ACTIVE\n", routerId)
+		return router.Router.Status == "ACTIVE", nil
+	})
+	if err != nil {
+		log.Printf("*******************Router %s is not ACTIVE: %v\n", routerId, err)
 		return false
 	}
+	log.Printf("*******************Router %s is ACTIVE\n", routerId)
+	return true
 }
 
-func (w *Worker) processFip(fip fipAssoc, wg *sync.WaitGroup, msg *string) {
-	defer func() {
-		if err := recover(); err != nil {
-			errInfo := fmt.Sprintf("fip associated port %s error %v", fip.FipId, err)
-			log.Println("************************catch error：", errInfo)
-			*msg += "\n" + errInfo
-		}
-		wg.Done()
-	}()
+func (w *Worker) processFip(fip fipAssoc) error {
 	if len(fip.FipAssocPort) != 0 {
-		w.AdminManager.UpdateFloatingIpWithPortIpAddress(fip.FipId, fip.FipAssocPort, fip.FixedIpAddress)
+		if _, err := w.AdminManager.UpdateFloatingIpWithPortIpAddress(fip.FipId, fip.FipAssocPort, fip.FixedIpAddress); err != nil {
+			return fmt.Errorf("fip associated port %s error %w", fip.FipId, err)
+		}
 	}
 
 	if len(fip.QosPolicyId) != 0 {
-		w.AdminManager.UpdatePortWithQos(fip.FipPort, fip.QosPolicyId)
+		if err := w.AdminManager.UpdatePortWithQos(fip.FipPort, fip.QosPolicyId); err != nil {
+			return fmt.Errorf("fip %s set port qos error %w", fip.FipId, err)
+		}
 	}
 
 	for _, pf := range fip.PortForwardings.Pfs {
@@ -360,30 +353,23 @@ func (w *Worker) processFip(fip fipAssoc, wg *sync.WaitGroup, msg *string) {
 			InternalPortID: pf.InternalPortId,
 			ExternalPort: pf.ExternalPort,
 		}
-		w.AdminManager.CreatePortForwarding(fip.FipId, &pfOpts)
+		if _, err := w.AdminManager.CreatePortForwarding(fip.FipId, &pfOpts); err != nil {
+			return fmt.Errorf("fip %s create port forwarding error %w", fip.FipId, err)
+		}
 	}
+	return nil
 }
 
 func (w *Worker) recoverRouterFip(assoc routerAssoc, lrr L3RelatedResource, msg *string) {
 	if w.handleRouterError(assoc.RouterId) {
-		w.setRouterGateway(assoc, msg)
-		if len(*msg) != 0 {
+		if err := w.setRouterGateway(assoc); err != nil {
+			*msg += "\n" + err.Error()
 			log.Printf("##########有错误THERE OCCUR ERROR FOR %s\n %s", assoc.RouterId, *msg)
 			return
 		}
-		fips := make([]fipAssoc, 0)
-		for _, fipId := range assoc.Fips {
-			if fip, ok := lrr.Fips[fipId]; ok {
-				fips = append(fips, fip)
-			}
-		}
+		fips := routerFips(assoc, lrr)
 		if w.handleRouterError(assoc.RouterId) {
-			var wg sync.WaitGroup
-			for _, fip := range fips {
-				wg.Add(1)
-				go w.processFip(fip, &wg, msg)
-			}
-			wg.Wait()
+			*msg += eachFip(fips, w.processFip)
 			if len(*msg) != 0 {
 				log.Printf("##########有错误THERE OCCUR ERROR FOR %s\n %s", assoc.RouterId, *msg)
 			} else {
@@ -407,12 +393,18 @@ func (w *Worker) RunRecoverL3Res(jsonFile string, routerId string) {
 }
 
 func (w *Worker) RunGenerateRecord() {
-	lrr := w.generateL3RelatedResObjs()
+	lrr, err := w.generateL3RelatedResObjs()
+	if err != nil {
+		log.Fatalln("Failed to generate l3 related resources", err)
+	}
 	w.exportToJsonFile(lrr)
 }
 
 func (w *Worker) RunDeleteAndRecoverRes() {
-	lrr := w.generateL3RelatedResObjs()
+	lrr, err := w.generateL3RelatedResObjs()
+	if err != nil {
+		log.Fatalln("Failed to generate l3 related resources", err)
+	}
 	w.exportToJsonFile(lrr)
 	w.DeleteAndRecoverL3RelatedRes(lrr)
 }
@@ -441,6 +433,7 @@ func L3RelatedCLI() {
 		fmt.Fprintf(os.Stderr, "%s\n", strings.Repeat(" ", len(usage)) + "[--vpc]")
 		fmt.Fprintf(os.Stderr, "%s\n", strings.Repeat(" ", len(usage)) + "[--username <username>]")
 		fmt.Fprintf(os.Stderr, "%s\n", strings.Repeat(" ", len(usage)) + "[--backupFile <backupFile>]")
+		fmt.Fprintf(os.Stderr, "%s\n", strings.Repeat(" ", len(usage)) + "[--os-cloud <cloud>] [--os-* <value>]")
 		fmt.Fprintf(os.Stderr, "optional arguments:\n  -h, --help            show this help message and exit\n")
 		flag.PrintDefaults()
 	}
@@ -450,13 +443,24 @@ func L3RelatedCLI() {
 	vpc := flag.String("vpc", "", "vpc id")
 	toRecover := flag.Bool("recover", false, "recover vpc")
 	backupFile := flag.String("backupFile", "", "Backup json file")
+	osFlags := configs.BindFlags(flag.CommandLine)
 	flag.Parse()
 	configs.ParseKeystonerc(*keystonercFile)
+	if err := osFlags.Load(); err != nil {
+		log.Fatalln("==============Failed to load openstack configuration", err)
+	}
 
 	if len(*username) == 0 {
 		log.Fatalf("==============The parameter usernmae must be specified!!!\n\n")
 	}
-	worker := NewWorker(*username)
+	worker, err := NewWorker(*username)
+	if err != nil {
+		log.Fatalln("==============Failed to init worker", err)
+	}
+	// Ctrl-C stops the in-flight requests and router polling
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	worker.AdminManager.SetContext(ctx)
 	log.Printf("CONF=%+v", configs.CONF)
 	if *toDeleteRecover {
 
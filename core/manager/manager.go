@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"github.com/valyala/fasthttp"
 	"log"
@@ -13,9 +14,6 @@ import (
 )
 
 var (
-	neutronUri = fmt.Sprintf("%d/v2.0/", consts.NeutronPort)
-	novaUri = fmt.Sprintf("%d/v2.1/", consts.NovaPort)
-	cinderUri = fmt.Sprintf("%d/v3/", consts.CinderPort)
 	defaultClient *fasthttp.Client
 	DefaultName = "default"
 )
@@ -35,84 +33,211 @@ type Manager struct {
 	*internal.SDN
 }
 
-func NewAdminManager() *Manager {
+func NewAdminManager() (*Manager, error) {
 	keystone := internal.NewKeystone(defaultClient)
-	token := keystone.GetToken(consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
-	keystone.SetHeader(consts.AuthToken, token)
-	projectId := keystone.GetProjectId(configs.CONF.ProjectName)
+	auth, token, err := newTokenProvider(keystone, consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
+	if err != nil {
+		return nil, err
+	}
+	projectId, err := keystone.GetProjectId(configs.CONF.ProjectName)
+	if err != nil {
+		return nil, err
+	}
 	if len(projectId) == 0 {
-		log.Fatalln("==============The user name not exist!!!\n")
+		return nil, fmt.Errorf("project %s not exist", configs.CONF.ProjectName)
 	}
-	adminProjectId := keystone.GetProjectId(consts.ADMIN)
+	adminProjectId, err := keystone.GetProjectId(consts.ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := resolveEndpoints(keystone)
+	if err != nil {
+		return nil, err
+	}
+	glance := internal.NewGlance(token, projectId, defaultClient)
+	glance.UrlPrefix, glance.Auth = endpoints[consts.GLANCE], auth
+	octavia := internal.NewLB(token, defaultClient)
+	octavia.UrlPrefix, octavia.Auth = endpoints[consts.OCTAVIA], auth
 	return &Manager{
 		Keystone: keystone,
 		Nova: internal.NewNova(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(novaUri, defaultClient),
+			internal.WithEndpoint(endpoints[consts.NOVA], defaultClient),
+			internal.WithAuth(auth),
 			internal.WithSnowFlake()),
 		Neutron: internal.NewNeutron(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(neutronUri, defaultClient),
+			internal.WithEndpoint(endpoints[consts.NEUTRON], defaultClient),
+			internal.WithAuth(auth),
 			internal.WithSnowFlake(),
 			internal.WithIsAdmin(true)),
 		Cinder: internal.NewCinder(
 			internal.WithAdminProjectId(adminProjectId),
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(cinderUri, defaultClient)),
-		Glance:   internal.NewGlance(token, projectId, defaultClient),
-		Octavia:  internal.NewLB(token, defaultClient),
-	}
+			internal.WithEndpoint(endpoints[consts.CINDER], defaultClient),
+			internal.WithAuth(auth)),
+		Glance:   glance,
+		Octavia:  octavia,
+	}, nil
 }
 
-func NewManager() *Manager {
+func NewManager() (*Manager, error) {
 	keystone := internal.NewKeystone(defaultClient)
-	adminToken := keystone.GetToken(consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
-	keystone.SetHeader(consts.AuthToken, adminToken)
-	projectId := keystone.MakeSureProjectExist()
-	token := keystone.GetToken(configs.CONF.ProjectName, configs.CONF.UserName, configs.CONF.UserPassword)
-	keystone.SetHeader(consts.AuthToken, token)
+	if _, _, err := newTokenProvider(keystone, consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword); err != nil {
+		return nil, err
+	}
+	projectId, err := keystone.MakeSureProjectExist()
+	if err != nil {
+		return nil, err
+	}
+	auth, token, err := newTokenProvider(keystone, configs.CONF.ProjectName, configs.CONF.UserName, configs.CONF.UserPassword)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := resolveEndpoints(keystone)
+	if err != nil {
+		return nil, err
+	}
+	glance := internal.NewGlance(token, projectId, defaultClient)
+	glance.UrlPrefix, glance.Auth = endpoints[consts.GLANCE], auth
+	octavia := internal.NewLB(token, defaultClient)
+	octavia.UrlPrefix, octavia.Auth = endpoints[consts.OCTAVIA], auth
 	manager := &Manager{
 		Keystone: keystone,
 		Nova: internal.NewNova(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(novaUri, defaultClient),
+			internal.WithEndpoint(endpoints[consts.NOVA], defaultClient),
+			internal.WithAuth(auth),
 			internal.WithSnowFlake()),
 		Neutron: internal.NewNeutron(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(neutronUri, defaultClient),
+			internal.WithEndpoint(endpoints[consts.NEUTRON], defaultClient),
+			internal.WithAuth(auth),
 			internal.WithSnowFlake(),
 			internal.WithIsAdmin(false)),
 		Cinder: internal.NewCinder(
 			internal.WithToken(token),
 			internal.WithProjectId(projectId),
-			internal.WithRequest(cinderUri, defaultClient)),
-		Glance:   internal.NewGlance(token, projectId, defaultClient),
-		Octavia:  internal.NewLB(token, defaultClient),
+			internal.WithEndpoint(endpoints[consts.CINDER], defaultClient),
+			internal.WithAuth(auth)),
+		Glance:   glance,
+		Octavia:  octavia,
 		//SDN:      internal.NewSDN(),
 	}
-	return manager
+	return manager, nil
 }
 
+// newTokenProvider authenticates the user and hands keystone over to the
+// provider, keystone keeps the catalog of the first token.
+func newTokenProvider(keystone *internal.Keystone, projectName, userName, userPassword string) (*internal.TokenProvider, string, error) {
+	auth, err := internal.NewTokenProvider(keystone, projectName, userName, userPassword)
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := auth.Token()
+	if err != nil {
+		return nil, "", err
+	}
+	keystone.SetCatalog(auth.Catalog())
+	keystone.SetHeader(consts.AuthToken, token)
+	keystone.Auth = auth
+	return auth, token, nil
+}
+
+// resolveEndpoints looks up the API root of every service client in the
+// catalog of the keystone token.
+func resolveEndpoints(keystone *internal.Keystone) (map[string]string, error) {
+	endpoints := make(map[string]string)
+	for _, service := range []string{consts.NOVA, consts.NEUTRON, consts.CINDER, consts.GLANCE, consts.OCTAVIA} {
+		url, err := keystone.EndpointFor(service)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[service] = url
+	}
+	return endpoints, nil
+}
 
-func (m *Manager) CreateNetworkHelper() string {
+// SetContext binds ctx to every service client of the manager, cancelling it
+// aborts the in-flight requests and status polling.
+func (m *Manager) SetContext(ctx context.Context) {
+	for _, r := range m.requests() {
+		r.SetContext(ctx)
+	}
+}
+
+// SetDryRun puts the clients in dry-run mode, only their GET requests are
+// sent, see internal.Request.DryRun.
+func (m *Manager) SetDryRun(dryRun bool) {
+	for _, r := range m.requests() {
+		r.DryRun = dryRun
+	}
+}
+
+// SetFilter makes the Delete methods of the clients delete only the
+// resources filter selects, see internal.Request.Filter.
+func (m *Manager) SetFilter(filter *internal.ResourceFilter) {
+	for _, r := range m.requests() {
+		r.Filter = filter
+	}
+}
+
+// Context returns the context bound by SetContext.
+func (m *Manager) Context() context.Context {
+	return m.Keystone.Context()
+}
+
+func (m *Manager) requests() []*internal.Request {
+	requests := make([]*internal.Request, 0)
+	if m.Keystone != nil {
+		requests = append(requests, &m.Keystone.Request)
+	}
+	if m.Nova != nil {
+		requests = append(requests, &m.Nova.Request)
+	}
+	if m.Neutron != nil {
+		requests = append(requests, &m.Neutron.Request)
+	}
+	if m.Cinder != nil {
+		requests = append(requests, &m.Cinder.Request)
+	}
+	if m.Glance != nil {
+		requests = append(requests, &m.Glance.Request)
+	}
+	if m.Octavia != nil {
+		requests = append(requests, &m.Octavia.Request)
+	}
+	if m.SDN != nil {
+		requests = append(requests, &m.SDN.Request)
+	}
+	return requests
+}
+
+
+func (m *Manager) CreateNetworkHelper() (string, error) {
 	netOpts := &entity.CreateNetworkOpts{Name: DefaultName, Description: DefaultName}
-	netId := m.CreateNetwork(netOpts)
-	return netId
+	return m.CreateNetwork(netOpts)
 }
 
-func (m *Manager) CreateExternalNetwork() (string, string) {
+func (m *Manager) CreateExternalNetwork() (string, string, error) {
 	netOpts := &entity.CreateNetworkOpts{Name: "ext_net", RouterExternal: true}
-	netId := m.CreateNetwork(netOpts)
-	subnetId := m.CreateSubnetHelper(netId)
-	return netId, subnetId
+	netId, err := m.CreateNetwork(netOpts)
+	if err != nil {
+		return "", "", err
+	}
+	subnetId, err := m.CreateSubnetHelper(netId)
+	if err != nil {
+		return "", "", err
+	}
+	return netId, subnetId, nil
 }
 
-func (m *Manager) CreateSubnetHelper(netId string) string {
+func (m *Manager) CreateSubnetHelper(netId string) (string, error) {
 	rand.Seed(time.Now().UnixNano())
 	randomNum := rand.Intn(200)
 	cidr := fmt.Sprintf("192.%d.%d.0/24", randomNum, randomNum)
@@ -124,36 +249,37 @@ func (m *Manager) CreateSubnetHelper(netId string) string {
 		GatewayIP: &gatewayIp1,
 		DNSNameservers: []string{"114.114.114.114"},
 	}
-	subnetId := m.CreateSubnet(subnetOpts)
-	return subnetId
+	return m.CreateSubnet(subnetOpts)
 }
 
-func (m *Manager) CreatePortHelper(netId, subnetId string) string {
+func (m *Manager) CreatePortHelper(netId, subnetId string) (string, error) {
 	fixedIp1 := entity.FixedIP{SubnetId: subnetId}
 	fixedIp2 := entity.FixedIP{SubnetId: subnetId}
     opts := &entity.CreatePortOpts{
-    	FixedIp: []entity.FixedIP{fixedIp1, fixedIp2},
+        FixedIp: []entity.FixedIP{fixedIp1, fixedIp2},
         NetworkId: netId,
 	}
     return m.CreatePort(opts)
 }
 
-func (m *Manager) CreateRouterHelper() string {
+func (m *Manager) CreateRouterHelper() (string, error) {
 	routerOpts := &entity.CreateRouterOpts{
 		Name: DefaultName, Description: DefaultName,
 	}
-	routerId := m.CreateRouter(routerOpts)
-	return routerId
+	return m.CreateRouter(routerOpts)
 }
 
-func (m *Manager) SetRouterGatewayHelper(routerId, externalNetId string)  {
+func (m *Manager) SetRouterGatewayHelper(routerId, externalNetId string) error {
 	updateRouterOpts := &entity.UpdateRouterOpts{
 		GatewayInfo: &entity.GatewayInfo{
 			NetworkID: externalNetId, QosPolicyId: "fe413250-e243-4694-b0b3-182731cd6f34"}}
-	m.UpdateRouter(routerId, updateRouterOpts)
+	if _, err := m.UpdateRouter(routerId, updateRouterOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) SetRouterGatewaySpecifyIPHelper(routerId, externalNetId, subnetId, fixedIP string)  {
+func (m *Manager) SetRouterGatewaySpecifyIPHelper(routerId, externalNetId, subnetId, fixedIP string) error {
 	fixedIp := entity.ExternalFixedIP{
 		IPAddress: fixedIP,
 		SubnetID: subnetId,
@@ -163,25 +289,36 @@ func (m *Manager) SetRouterGatewaySpecifyIPHelper(routerId, externalNetId, subne
 			NetworkID: externalNetId,
 			ExternalFixedIPs: []entity.ExternalFixedIP{fixedIp},
 		}}
-	m.UpdateRouter(routerId, updateRouterOpts)
+	if _, err := m.UpdateRouter(routerId, updateRouterOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) SetDefaultRouterGatewayHelper(routerId string) {
+func (m *Manager) SetDefaultRouterGatewayHelper(routerId string) error {
 	updateRouterOpts := &entity.UpdateRouterOpts{
 		GatewayInfo: &entity.GatewayInfo{
 			NetworkID: configs.CONF.ExternalNetwork}}
-	m.UpdateRouter(routerId, updateRouterOpts)
+	if _, err := m.UpdateRouter(routerId, updateRouterOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) AddRouterInterfaceHelper(routerId, subnetId string) {
+func (m *Manager) AddRouterInterfaceHelper(routerId, subnetId string) error {
 	addInterfaceOpts := &entity.AddRouterInterfaceOpts{
 		SubnetID: subnetId, RouterId: routerId,
 	}
-	m.AddRouterInterface(addInterfaceOpts)
+	if _, err := m.AddRouterInterface(addInterfaceOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) CreateInstanceHelper(netId string) string {
-	m.EnsureSgExist(DefaultName)
+func (m *Manager) CreateInstanceHelper(netId string) (string, error) {
+	if err := m.EnsureSgExist(DefaultName); err != nil {
+		return "", err
+	}
 	instanceOpts := entity.CreateInstanceOpts{
 		FlavorRef:      configs.CONF.FlavorId,
 		ImageRef:       configs.CONF.ImageId,
@@ -194,12 +331,13 @@ func (m *Manager) CreateInstanceHelper(netId string) string {
 			DestinationType: "volume", VolumeSize: 20, DeleteOnTermination: true,
 		}},
 	}
-	instanceId := m.CreateInstance(&instanceOpts)
-	return instanceId
+	return m.CreateInstance(&instanceOpts)
 }
 
-func (m *Manager) CreateInstanceByVolumeHelper(netId, volumeId string) string {
-	m.EnsureSgExist(DefaultName)
+func (m *Manager) CreateInstanceByVolumeHelper(netId, volumeId string) (string, error) {
+	if err := m.EnsureSgExist(DefaultName); err != nil {
+		return "", err
+	}
 	instanceOpts := entity.CreateInstanceOpts{
 		FlavorRef:      configs.CONF.FlavorId,
 		Networks:       []entity.ServerNet{{UUID: netId}},
@@ -211,12 +349,13 @@ func (m *Manager) CreateInstanceByVolumeHelper(netId, volumeId string) string {
 			DestinationType: "volume", VolumeSize: 10, DeleteOnTermination: true,
 		}},
 	}
-	instanceId := m.CreateInstance(&instanceOpts)
-	return instanceId
+	return m.CreateInstance(&instanceOpts)
 }
 
-func (m *Manager) CreateInstanceWithPortHelper(portId string) string {
-	m.EnsureSgExist(DefaultName)
+func (m *Manager) CreateInstanceWithPortHelper(portId string) (string, error) {
+	if err := m.EnsureSgExist(DefaultName); err != nil {
+		return "", err
+	}
 	instanceOpts := entity.CreateInstanceOpts{
 		FlavorRef:      configs.CONF.FlavorId,
 		Networks:       []entity.ServerNet{{Port: portId}},
@@ -228,18 +367,24 @@ func (m *Manager) CreateInstanceWithPortHelper(portId string) string {
 			DestinationType: "volume", VolumeSize: 10, DeleteOnTermination: true,
 		}},
 	}
-	instanceId := m.CreateInstance(&instanceOpts)
-	return instanceId
+	return m.CreateInstance(&instanceOpts)
 }
 
-func (m *Manager) CreateQosPolicyHelper() string {
-	qosId := m.CreateQos()
-	m.CreateBandwidthLimitRuleIngress(qosId)
-	m.CreateBandwidthLimitRuleEgress(qosId)
-	return qosId
+func (m *Manager) CreateQosPolicyHelper() (string, error) {
+	qosId, err := m.CreateQos()
+	if err != nil {
+		return "", err
+	}
+	if err := m.CreateBandwidthLimitRuleIngress(qosId); err != nil {
+		return "", err
+	}
+	if err := m.CreateBandwidthLimitRuleEgress(qosId); err != nil {
+		return "", err
+	}
+	return qosId, nil
 }
 
-func (m *Manager) CreateVpcConnectionHelper(localRouter, peerRouter string, localSubnets, peerSubnets []string) string {
+func (m *Manager) CreateVpcConnectionHelper(localRouter, peerRouter string, localSubnets, peerSubnets []string) (string, error) {
 	vpcConnectionOpts := &entity.CreateVpcConnectionOpts{
 		Name: DefaultName,
 		LocalRouter: localRouter,
@@ -250,7 +395,7 @@ func (m *Manager) CreateVpcConnectionHelper(localRouter, peerRouter string, loca
 	return m.CreateVpcConnection(vpcConnectionOpts)
 }
 
-func (m *Manager) CreateVpcConnectionWithCidrHelper(localRouter, peerRouter string, localCidrs, peerCidrs []string) string {
+func (m *Manager) CreateVpcConnectionWithCidrHelper(localRouter, peerRouter string, localCidrs, peerCidrs []string) (string, error) {
 	vpcConnectionOpts := &entity.CreateVpcConnectionOpts{
 		Name: DefaultName,
 		LocalRouter: localRouter,
@@ -261,7 +406,7 @@ func (m *Manager) CreateVpcConnectionWithCidrHelper(localRouter, peerRouter stri
 	return m.CreateVpcConnection(vpcConnectionOpts)
 }
 
-func (m *Manager) UpdateVpcConnectionWithCidrHelper(vpcConnId string, localCidrs, peerCidrs []string) string {
+func (m *Manager) UpdateVpcConnectionWithCidrHelper(vpcConnId string, localCidrs, peerCidrs []string) (string, error) {
 	vpcConnectionOpts := &entity.UpdateVpcConnectionOpts{
 		LocalCidrs: localCidrs,
 		PeerCidrs: peerCidrs,
@@ -269,28 +414,37 @@ func (m *Manager) UpdateVpcConnectionWithCidrHelper(vpcConnId string, localCidrs
 	return m.UpdateVpcConnection(vpcConnId, vpcConnectionOpts)
 }
 
-func (m *Manager) CreateFloatingipHelper() string {
+func (m *Manager) CreateFloatingipHelper() (string, error) {
 	opts := &entity.CreateFipOpts{FloatingNetworkID: configs.CONF.ExternalNetwork}
 	return m.CreateFloatingIP(opts)
 }
 
-func (m *Manager) CreateFloatingipWithPortHelper(portId string) string {
+func (m *Manager) CreateFloatingipWithPortHelper(portId string) (string, error) {
 	opts := &entity.CreateFipOpts{
 		FloatingNetworkID: configs.CONF.ExternalNetwork, PortID: portId}
 	return m.CreateFloatingIP(opts)
 }
 
-func (m *Manager) CreateSnatHelper(routerId, subnetId, natIp string) {
-	subnet := m.GetSubnet(subnetId)
+func (m *Manager) CreateSnatHelper(routerId, subnetId, natIp string) error {
+	subnet, err := m.GetSubnet(subnetId)
+	if err != nil {
+		return err
+	}
 	opts := &entity.Snat{SnatNetworkId: configs.CONF.ExternalNetwork,
 		OriginalCidrs: []string{subnet.Cidr}, TenantId: "48ad435f0e8c44598d3236acdbb9ca47",
 		RouterId: routerId, SnatIpAddress: natIp}
-	m.CreateSnat(opts)
+	if _, err := m.CreateSnat(opts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) CreateDnatHelper(floatingipId, floatingipAddr, portId string) {
+func (m *Manager) CreateDnatHelper(floatingipId, floatingipAddr, portId string) error {
 	rand.Seed(123)
-	portIP := m.GetPortIP(portId)
+	portIP, err := m.GetPortIP(portId)
+	if err != nil {
+		return err
+	}
 	opts := &entity.Dnat{
 		FloatingipId: floatingipId,
 		PortId: portId,
@@ -301,11 +455,17 @@ func (m *Manager) CreateDnatHelper(floatingipId, floatingipAddr, portId string)
 		FloatingIpPort: rand.Intn(65535),
 		FixedIpPort: 22,
 	}
-	m.CreateDnat(opts)
+	if _, err := m.CreateDnat(opts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) CreatePortForwardingHelper(fipId string, instanceId string) string {
-	internalPort, internalIpAddr := m.GetInstancePort(instanceId)
+func (m *Manager) CreatePortForwardingHelper(fipId string, instanceId string) (string, error) {
+	internalPort, internalIpAddr, err := m.GetInstancePort(instanceId)
+	if err != nil {
+		return "", err
+	}
 	opts := entity.CreatePortForwardingOpts{
 		InternalPortID: internalPort,
 		InternalIPAddress: internalIpAddr,
@@ -317,79 +477,120 @@ func (m *Manager) CreatePortForwardingHelper(fipId string, instanceId string) st
 }
 
 // CleanProjectAndUser delete project and user
-func (m *Manager) CleanProjectAndUser() {
-	delete(m.Keystone.Headers, consts.AuthToken)
-	token := m.GetToken(consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword)
-	m.Keystone.SetHeader(consts.AuthToken, token)
-	m.DeleteUserByName(configs.CONF.UserName)
-	m.DeleteProjectByName(configs.CONF.ProjectName)
+func (m *Manager) CleanProjectAndUser() error {
+	if _, _, err := newTokenProvider(m.Keystone, consts.ADMIN, consts.ADMIN, configs.CONF.AdminPassword); err != nil {
+		return err
+	}
+	if err := m.DeleteUserByName(configs.CONF.UserName); err != nil {
+		return err
+	}
+	return m.DeleteProjectByName(configs.CONF.ProjectName)
 }
 
-func (m *Manager) CleanGlanceResources() {
-	m.DeleteImage("91f28959-3f5d-4993-a793-44eb31ca3d8c")
+func (m *Manager) CleanGlanceResources() error {
+	return m.DeleteImage("91f28959-3f5d-4993-a793-44eb31ca3d8c")
 }
 
-func (m *Manager) CreateVpc() (string, string, string) {
+func (m *Manager) CreateVpc() (string, string, string, error) {
 	// 1. create network
-	netId := m.CreateNetworkHelper()
+	netId, err := m.CreateNetworkHelper()
+	if err != nil {
+		return "", "", "", err
+	}
 
 	// 2. create subnet
-	subnetId := m.CreateSubnetHelper(netId)
+	subnetId, err := m.CreateSubnetHelper(netId)
+	if err != nil {
+		return "", "", "", err
+	}
 
 	// 3. create router
-	routerId := m.CreateRouterHelper()
+	routerId, err := m.CreateRouterHelper()
+	if err != nil {
+		return "", "", "", err
+	}
 
 	// 4. router add subnet
-    m.AddRouterInterfaceHelper(routerId, subnetId)
-	return netId, subnetId, routerId
+	if err := m.AddRouterInterfaceHelper(routerId, subnetId); err != nil {
+		return "", "", "", err
+	}
+	return netId, subnetId, routerId, nil
 }
 
-func (m *Manager) CreateFirewallRuleHelper(protocol string, action string) string {
+func (m *Manager) CreateFirewallRuleHelper(protocol string, action string) (string, error) {
 	allowAnyRuleOpts := &entity.CreateFirewallRuleOpts{Name: DefaultName, Protocol: protocol, Action: action}
 	return m.CreateFirewallRuleV1(allowAnyRuleOpts)
 }
 
-func (m *Manager) CreateFirewallPolicy() string {
+func (m *Manager) CreateFirewallPolicy() (string, error) {
 	policyOpts := &entity.CreateFirewallPolicyOpts{Name: DefaultName}
 	return m.CreateFirewallPolicyV1(policyOpts)
 }
 
-func (m *Manager) CreateFirewallHelper(policyId string) string {
+func (m *Manager) CreateFirewallHelper(policyId string) (string, error) {
 	firewallOpts := &entity.CreateFirewallOpts{Name: DefaultName, PolicyID: policyId, RouterIDs: []string{}}
 	return m.CreateFirewallV1(firewallOpts)
 }
 
-func (m *Manager) FirewallAssociateRoutersHelper(firewallId string, routerIds []string)  {
+func (m *Manager) FirewallAssociateRoutersHelper(firewallId string, routerIds []string) error {
 	updateOpts := &entity.UpdateFirewallOpts{RouterIDs: routerIds}
-	m.UpdateFirewallV1(firewallId, updateOpts)
-}
-
-func (m *Manager) CreateFirewallAllAllowAndAssociateRouter(routerId string) string {
-    ruleId := m.CreateFirewallRuleHelper(consts.ProtocolAny, consts.ActionAllow)
-    firewallPolicyId := m.CreateFirewallPolicy()
-    m.UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId)
-    firewallId := m.CreateFirewallHelper(firewallPolicyId)
-    m.FirewallAssociateRoutersHelper(firewallId, []string{routerId})
-    return firewallId
-}
-
-func (m *Manager) CreateFirewallAllDenyAndAssociateRouter(routerId string) string {
-	ruleId := m.CreateFirewallRuleHelper(consts.ProtocolAny, consts.ActionDeny)
-	firewallPolicyId := m.CreateFirewallPolicy()
-	m.UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId)
-	firewallId := m.CreateFirewallHelper(firewallPolicyId)
-	m.FirewallAssociateRoutersHelper(firewallId, []string{routerId})
-	return firewallId
+	if _, err := m.UpdateFirewallV1(firewallId, updateOpts); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) CreateFirewallAllAllowAndAssociateRouter(routerId string) (string, error) {
+    ruleId, err := m.CreateFirewallRuleHelper(consts.ProtocolAny, consts.ActionAllow)
+    if err != nil {
+        return "", err
+    }
+    firewallPolicyId, err := m.CreateFirewallPolicy()
+    if err != nil {
+        return "", err
+    }
+    if _, err := m.UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId); err != nil {
+        return "", err
+    }
+    firewallId, err := m.CreateFirewallHelper(firewallPolicyId)
+    if err != nil {
+        return "", err
+    }
+    if err := m.FirewallAssociateRoutersHelper(firewallId, []string{routerId}); err != nil {
+        return "", err
+    }
+    return firewallId, nil
+}
+
+func (m *Manager) CreateFirewallAllDenyAndAssociateRouter(routerId string) (string, error) {
+	ruleId, err := m.CreateFirewallRuleHelper(consts.ProtocolAny, consts.ActionDeny)
+	if err != nil {
+		return "", err
+	}
+	firewallPolicyId, err := m.CreateFirewallPolicy()
+	if err != nil {
+		return "", err
+	}
+	if _, err := m.UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId); err != nil {
+		return "", err
+	}
+	firewallId, err := m.CreateFirewallHelper(firewallPolicyId)
+	if err != nil {
+		return "", err
+	}
+	if err := m.FirewallAssociateRoutersHelper(firewallId, []string{routerId}); err != nil {
+		return "", err
+	}
+	return firewallId, nil
 }
 
-func (m *Manager) CreateFirewallRuleAllowSSH() string {
+func (m *Manager) CreateFirewallRuleAllowSSH() (string, error) {
 	allowAnyRuleOpts := &entity.CreateFirewallRuleOpts{Name: DefaultName, Protocol: consts.ProtocolTCP,
 		Action: consts.ActionAllow, SourcePort: "22", DestinationPort: "22"}
-	ruleId := m.CreateFirewallRuleV1(allowAnyRuleOpts)
-	return ruleId
+	return m.CreateFirewallRuleV1(allowAnyRuleOpts)
 }
 
-func (m *Manager) CreateFirewallRuleDenySnat(sourceIpAddress, destIpAddress string) string {
+func (m *Manager) CreateFirewallRuleDenySnat(sourceIpAddress, destIpAddress string) (string, error) {
 	opts := &entity.CreateFirewallRuleOpts{
 		Name: DefaultName,
 		Action: consts.ActionDeny,
@@ -398,11 +599,10 @@ func (m *Manager) CreateFirewallRuleDenySnat(sourceIpAddress, destIpAddress stri
 		DestinationIPAddress: destIpAddress,
 		IPVersion: 4,
 	}
-	ruleId := m.CreateFirewallRuleV1(opts)
-	return ruleId
+	return m.CreateFirewallRuleV1(opts)
 }
 
-func (m *Manager) CreateFirewallRuleAllowSnat(snatIp string) string {
+func (m *Manager) CreateFirewallRuleAllowSnat(snatIp string) (string, error) {
 	opts := &entity.CreateFirewallRuleOpts{
 		Name: DefaultName,
 		Action: consts.ActionAllow,
@@ -410,11 +610,10 @@ func (m *Manager) CreateFirewallRuleAllowSnat(snatIp string) string {
 		SourceIPAddress: snatIp,
 		IPVersion: 4,
 	}
-	ruleId := m.CreateFirewallRuleV1(opts)
-	return ruleId
+	return m.CreateFirewallRuleV1(opts)
 }
 
-func (m *Manager) CreateFirewallRuleAllowDnat(vpcCidr string) string {
+func (m *Manager) CreateFirewallRuleAllowDnat(vpcCidr string) (string, error) {
 	opts := &entity.CreateFirewallRuleOpts{
 		Name: DefaultName,
 		Action: consts.ActionAllow,
@@ -422,57 +621,111 @@ func (m *Manager) CreateFirewallRuleAllowDnat(vpcCidr string) string {
 		DestinationIPAddress: vpcCidr,
 		IPVersion: 4,
 	}
-	ruleId := m.CreateFirewallRuleV1(opts)
-	return ruleId
+	return m.CreateFirewallRuleV1(opts)
 }
 
-func (m *Manager) CreateLoadbalancerHelper(vipSubnetId string) string {
+func (m *Manager) CreateLoadbalancerHelper(vipSubnetId string) (string, error) {
 	createLBOpts := entity.CreateLoadbalancerOpts{VipSubnetID: vipSubnetId}
 	return m.CreateLoadbalancer(createLBOpts)
 }
 
 // CreateVpnIpsecConnection the vpcs of two cluster connect with vpn
-func (m *Manager) CreateVpnIpsecConnection(routerId, subnetId, peerCidr, peerAddress string) {
-	localVpnServiceId := m.CreateVpnService(routerId)
+func (m *Manager) CreateVpnIpsecConnection(routerId, subnetId, peerCidr, peerAddress string) error {
+	localVpnServiceId, err := m.CreateVpnService(&entity.CreateVpnServiceOpts{RouterID: routerId})
+	if err != nil {
+		return err
+	}
 
-	localEGId := m.CreateLocalEndpointGroup(subnetId)
+	localEGId, err := m.CreateLocalEndpointGroup(subnetId)
+	if err != nil {
+		return err
+	}
 
-	peerEGId := m.CreatePeerEndpointGroup(peerCidr)
+	peerEGId, err := m.CreatePeerEndpointGroup(peerCidr)
+	if err != nil {
+		return err
+	}
 
-	ikePolicy := m.CreateIkePolicy()
-	ipsecPolicy := m.CreateIpsecPolicy()
+	ikePolicy, err := m.CreateIkePolicy(entity.DefaultIkePolicyOpts())
+	if err != nil {
+		return err
+	}
+	ipsecPolicy, err := m.CreateIpsecPolicy(entity.DefaultIpsecPolicyOpts())
+	if err != nil {
+		return err
+	}
 
-	m.CreateIpsecConnection(localVpnServiceId, ikePolicy, ipsecPolicy, peerEGId, localEGId, peerAddress)
+	opts := entity.DefaultIpsecConnectionOpts()
+	opts.VpnserviceID, opts.IkepolicyID, opts.IpsecpolicyID = localVpnServiceId, ikePolicy, ipsecPolicy
+	opts.LocalEpGroupID, opts.PeerEpGroupID, opts.PeerAddress = localEGId, peerEGId, peerAddress
+	if _, err := m.CreateIpsecConnection(opts); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) VpnIpsecConnectionDelete() {
-	m.DeleteIpsecConnections()
-	m.DeleteIkePolicies()
-	m.DeleteIpsecPolicies()
-	m.DeleteEndpointGroups()
-	m.DeleteVpnServices()
+func (m *Manager) VpnIpsecConnectionDelete() error {
+	if err := m.DeleteIpsecConnections(); err != nil {
+		return err
+	}
+	if err := m.DeleteIkePolicies(); err != nil {
+		return err
+	}
+	if err := m.DeleteIpsecPolicies(); err != nil {
+		return err
+	}
+	if err := m.DeleteEndpointGroups(); err != nil {
+		return err
+	}
+	if err := m.DeleteVpnServices(); err != nil {
+		return err
+	}
 	//m.DeleteRouters()
 	//m.DeleteNetworks()
+	return nil
 }
 
-func (m *Manager) SnapshotToImage() {
-	volume := m.CreateVolume()
-	snapshot := m.CreateSnapshot(volume)
-	TempVolume := m.CreateVolumeBySnapshot(snapshot)
-	image := m.UploadToImage(TempVolume)
-    imageDetail := m.GetImage(image)
+func (m *Manager) SnapshotToImage() error {
+	volume, err := m.CreateVolume()
+	if err != nil {
+		return err
+	}
+	snapshot, err := m.CreateSnapshot(volume)
+	if err != nil {
+		return err
+	}
+	TempVolume, err := m.CreateVolumeBySnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	image, err := m.UploadToImage(TempVolume)
+	if err != nil {
+		return err
+	}
+    imageDetail, err := m.GetImage(image)
+    if err != nil {
+        return err
+    }
     log.Println(imageDetail)
+	return nil
 }
 
-func (m *Manager) ImageShared() {
-	m.SetImageVisibilityProperty("91f28959-3f5d-4993-a793-44eb31ca3d8c", "shared")
-	m.CreateImageMember("91f28959-3f5d-4993-a793-44eb31ca3d8c", "375d082324a34f5f957d3be428da4506")
-	m.SetImageMemberStatus("91f28959-3f5d-4993-a793-44eb31ca3d8c", "375d082324a34f5f957d3be428da4506", "accepted")
+func (m *Manager) ImageShared() error {
+	if _, err := m.SetImageVisibilityProperty("91f28959-3f5d-4993-a793-44eb31ca3d8c", "shared"); err != nil {
+		return err
+	}
+	if err := m.CreateImageMember("91f28959-3f5d-4993-a793-44eb31ca3d8c", "375d082324a34f5f957d3be428da4506"); err != nil {
+		return err
+	}
+	return m.SetImageMemberStatus("91f28959-3f5d-4993-a793-44eb31ca3d8c", "375d082324a34f5f957d3be428da4506", "accepted")
 }
 
-func (m *Manager) Compensate() {
+func (m *Manager) Compensate() error {
 	policyOpts := &entity.CreateFirewallPolicyOpts{Name: "dx_test2", Rules: []string{}}
-	m.CreateFirewallPolicyV1(policyOpts)
+	if _, err := m.CreateFirewallPolicyV1(policyOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (m *Manager) Cre()  {
@@ -7,36 +7,59 @@ import (
 )
 
 // PrivateNetAccessToInternet 内网与Internet互访
-func (m *Manager) PrivateNetAccessToInternet() {
+func (m *Manager) PrivateNetAccessToInternet() error {
 	// 1. create vpc
-	networkId, _, routerId := m.CreateVpc()
+	networkId, _, routerId, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 2. create sg
-	m.EnsureSgExist(configs.CONF.ProjectName)
+	if err := m.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+		return err
+	}
 
 	// 3. create instance
-	instanceId := m.CreateInstanceHelper(networkId)
+	instanceId, err := m.CreateInstanceHelper(networkId)
+	if err != nil {
+		return err
+	}
 
 	// 4. set SNAT
-    m.SetDefaultRouterGatewayHelper(routerId)
+    if err := m.SetDefaultRouterGatewayHelper(routerId); err != nil {
+        return err
+    }
 
     // 5. create all allow acl, then associate router
-    m.CreateFirewallAllAllowAndAssociateRouter(routerId)
+    if _, err := m.CreateFirewallAllAllowAndAssociateRouter(routerId); err != nil {
+        return err
+    }
 
     // 6. bind floating ip
-    instancePortId, _ := m.GetInstancePort(instanceId)
+    instancePortId, _, err := m.GetInstancePort(instanceId)
+    if err != nil {
+        return err
+    }
     fipOpts := &entity.CreateFipOpts{FloatingNetworkID: configs.CONF.ExternalNetwork, PortID: instancePortId}
-    m.CreateFloatingIP(fipOpts)
+    if _, err := m.CreateFloatingIP(fipOpts); err != nil {
+        return err
+    }
+	return nil
 }
 
 // InterconnectInSameVpc vms in the same vpc interconnected
-func (m *Manager) InterconnectInSameVpc() {
+func (m *Manager) InterconnectInSameVpc() error {
 	name := "interconnect_In_same_vpc"
 	// 1. create vpc
-	networkId, _, _ := m.CreateVpc()
+	networkId, _, _, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 2. create sg
-	m.EnsureSgExist(configs.CONF.ProjectName)
+	if err := m.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+		return err
+	}
 
 	// 3. create instance
 	instanceOpts := entity.CreateInstanceOpts{
@@ -49,38 +72,58 @@ func (m *Manager) InterconnectInSameVpc() {
 		Min: 2,
 		Max: 2,
 	}
-	m.CreateMultipleInstances(instanceOpts)
+	return m.CreateMultipleInstances(instanceOpts)
 }
 
 // InterconnectInDifferentVpcFwEnabled vms in the different vpc interconnected
-func (m *Manager) InterconnectInDifferentVpcFwEnabled() {
+func (m *Manager) InterconnectInDifferentVpcFwEnabled() error {
 	name := "interconnect_In_different_vpc"
 	// 1. create sg
-	m.EnsureSgExist(configs.CONF.ProjectName)
+	if err := m.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+		return err
+	}
 
 	// 2. create local vpc
-	networkId1, subnetId1, routerId1 := m.CreateVpc()
+	networkId1, subnetId1, routerId1, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 3. set local SNAT
-	m.SetDefaultRouterGatewayHelper(routerId1)
+	if err := m.SetDefaultRouterGatewayHelper(routerId1); err != nil {
+		return err
+	}
 
 	// 4. create all allow acl, then associate router
-	m.CreateFirewallAllAllowAndAssociateRouter(routerId1)
+	if _, err := m.CreateFirewallAllAllowAndAssociateRouter(routerId1); err != nil {
+		return err
+	}
 
 	// 5. create peer vpc
-	networkId2, subnetId2, routerId2 := m.CreateVpc()
+	networkId2, subnetId2, routerId2, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 6. set peer SNAT
-	m.SetDefaultRouterGatewayHelper(routerId2)
+	if err := m.SetDefaultRouterGatewayHelper(routerId2); err != nil {
+		return err
+	}
 
 	// 7. create all allow acl, then associate router
-	m.CreateFirewallAllAllowAndAssociateRouter(routerId2)
+	if _, err := m.CreateFirewallAllAllowAndAssociateRouter(routerId2); err != nil {
+		return err
+	}
 
 	// 8. create local instance
-	m.CreateInstanceHelper(networkId1)
+	if _, err := m.CreateInstanceHelper(networkId1); err != nil {
+		return err
+	}
 
 	// 9. create peer instance
-	m.CreateInstanceHelper(networkId2)
+	if _, err := m.CreateInstanceHelper(networkId2); err != nil {
+		return err
+	}
 
 	// 10. create vpc connection
 	vpcConnectionOpts := &entity.CreateVpcConnectionOpts{
@@ -92,124 +135,214 @@ func (m *Manager) InterconnectInDifferentVpcFwEnabled() {
 		Mode: 1,
 		FwEnabled: true,
 	}
-    m.CreateVpcConnection(vpcConnectionOpts)
+    if _, err := m.CreateVpcConnection(vpcConnectionOpts); err != nil {
+        return err
+    }
+	return nil
 }
 
 
 // InterconnectInDifferentVpcNoFw vms in the different vpc interconnected
-func (m *Manager) InterconnectInDifferentVpcNoFw() {
+func (m *Manager) InterconnectInDifferentVpcNoFw() error {
 	// 1. create sg
-	m.EnsureSgExist(configs.CONF.ProjectName)
+	if err := m.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+		return err
+	}
 
 	// 2. create local vpc
-	networkId1, subnetId1, routerId1 := m.CreateVpc()
+	networkId1, subnetId1, routerId1, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 3. set local SNAT
-	m.SetDefaultRouterGatewayHelper(routerId1)
+	if err := m.SetDefaultRouterGatewayHelper(routerId1); err != nil {
+		return err
+	}
 
 	// 4. create peer vpc
-	networkId2, subnetId2, routerId2 := m.CreateVpc()
+	networkId2, subnetId2, routerId2, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
 
 	// 5. set peer SNAT
-	m.SetDefaultRouterGatewayHelper(routerId2)
+	if err := m.SetDefaultRouterGatewayHelper(routerId2); err != nil {
+		return err
+	}
 
 	// 6. create local instance
-	m.CreateInstanceHelper(networkId1)
+	if _, err := m.CreateInstanceHelper(networkId1); err != nil {
+		return err
+	}
 
 	// 7. create peer instance
-	m.CreateInstanceHelper(networkId2)
+	if _, err := m.CreateInstanceHelper(networkId2); err != nil {
+		return err
+	}
 
 	// 8. create vpc connection
-	m.CreateVpcConnectionHelper(routerId1, routerId2, []string{subnetId1}, []string{subnetId2})
+	if _, err := m.CreateVpcConnectionHelper(routerId1, routerId2, []string{subnetId1}, []string{subnetId2}); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m *Manager) LoadbalancerPoolProtocolRR() {
+func (m *Manager) LoadbalancerPoolProtocolRR() error {
 	// 1. create lb vip network
 	name := "lb_pool__rr"
-	vipNetworkId := m.CreateNetworkHelper()
-	vipSubnetId := m.CreateSubnetHelper(vipNetworkId)
+	vipNetworkId, err := m.CreateNetworkHelper()
+	if err != nil {
+		return err
+	}
+	vipSubnetId, err := m.CreateSubnetHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
 
 	//2. create member instance
-	instance1 := m.CreateInstanceHelper(vipNetworkId)
-	instance2 := m.CreateInstanceHelper(vipNetworkId)
+	instance1, err := m.CreateInstanceHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
+	instance2, err := m.CreateInstanceHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
 
 	// 3. create lb
 	createLBOpts := entity.CreateLoadbalancerOpts{Name: name, VipSubnetID: vipSubnetId, }
-	lbId := m.CreateLoadbalancer(createLBOpts)
+	lbId, err := m.CreateLoadbalancer(createLBOpts)
+	if err != nil {
+		return err
+	}
 
 	// 4. create listener
 	createListenerOpts := entity.CreateListenerOpts{
 		Name: name, LoadbalancerID: lbId, Protocol: entity.ProtocolTCP, ProtocolPort: 22}
-	listener1 := m.CreateListener(createListenerOpts)
+	listener1, err := m.CreateListener(createListenerOpts)
+	if err != nil {
+		return err
+	}
 
 	// 5. create pool
 	createPoolOpts := entity.CreatePoolOpts{
 		Name: name, ListenerID: listener1,
 		LBMethod: entity.LBMethodRoundRobin,
 		Protocol: entity.ProtocolTCP}
-	pool1 := m.CreatePool(createPoolOpts)
+	pool1, err := m.CreatePool(createPoolOpts)
+	if err != nil {
+		return err
+	}
 
 	// 6. create pool member
-	_, ip1 := m.GetInstancePort(instance1)
-	_, ip2 := m.GetInstancePort(instance2)
+	_, ip1, err := m.GetInstancePort(instance1)
+	if err != nil {
+		return err
+	}
+	_, ip2, err := m.GetInstancePort(instance2)
+	if err != nil {
+		return err
+	}
 	createMemberOpts1 := entity.CreateMemberOpts{
 		Name: name, Address: ip1, SubnetID: vipSubnetId, ProtocolPort: 22, Weight: 2}
-	m.CreatePoolMember(pool1, createMemberOpts1)
+	if _, err := m.CreatePoolMember(pool1, createMemberOpts1); err != nil {
+		return err
+	}
 	createMemberOpts2 := entity.CreateMemberOpts{
 		Name: name, Address: ip2, SubnetID: vipSubnetId, ProtocolPort: 22, Weight: 1}
-	m.CreatePoolMember(pool1, createMemberOpts2)
+	if _, err := m.CreatePoolMember(pool1, createMemberOpts2); err != nil {
+		return err
+	}
 
 	// 7. create health monitor
 	createHMOpts := entity.CreateHealthMonitorOpts{
 		Name: name, PoolID: pool1, Type: entity.PING,
 		MaxRetries: 5, Timeout: 30, Delay: 5}
-	m.CreateHealthMonitor(createHMOpts)
+	if _, err := m.CreateHealthMonitor(createHMOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
 
-func (m *Manager) LoadbalancerPoolProtocolLeastConnections() {
+func (m *Manager) LoadbalancerPoolProtocolLeastConnections() error {
 	// 1. create lb vip network
 	name := "lb_pool__lc"
-	vipNetworkId := m.CreateNetworkHelper()
-	vipSubnetId := m.CreateSubnetHelper(vipNetworkId)
+	vipNetworkId, err := m.CreateNetworkHelper()
+	if err != nil {
+		return err
+	}
+	vipSubnetId, err := m.CreateSubnetHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
 
 	//2. create member instance
-	instance1 := m.CreateInstanceHelper(vipNetworkId)
-	instance2 := m.CreateInstanceHelper(vipNetworkId)
+	instance1, err := m.CreateInstanceHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
+	instance2, err := m.CreateInstanceHelper(vipNetworkId)
+	if err != nil {
+		return err
+	}
 
 	// 3. create lb
 	createLBOpts := entity.CreateLoadbalancerOpts{Name: name, VipSubnetID: vipSubnetId}
-	lbId := m.CreateLoadbalancer(createLBOpts)
+	lbId, err := m.CreateLoadbalancer(createLBOpts)
+	if err != nil {
+		return err
+	}
 
 	// 4. create listener
 	connLimit := 5
 	createListenerOpts := entity.CreateListenerOpts{
 		Name: name, LoadbalancerID: lbId, Protocol: entity.ProtocolTCP,
 		ProtocolPort: 822, ConnLimit: &connLimit}
-	listener1 := m.CreateListener(createListenerOpts)
+	listener1, err := m.CreateListener(createListenerOpts)
+	if err != nil {
+		return err
+	}
 
 	// 5. create pool
 	createPoolOpts := entity.CreatePoolOpts{
 		Name: name, ListenerID: listener1,
 		LBMethod: entity.LBMethodLeastConnections,
 		Protocol: entity.ProtocolTCP}
-	pool1 := m.CreatePool(createPoolOpts)
+	pool1, err := m.CreatePool(createPoolOpts)
+	if err != nil {
+		return err
+	}
 
 	// 6. create pool member
-	_, ip1 := m.GetInstancePort(instance1)
-	_, ip2 := m.GetInstancePort(instance2)
+	_, ip1, err := m.GetInstancePort(instance1)
+	if err != nil {
+		return err
+	}
+	_, ip2, err := m.GetInstancePort(instance2)
+	if err != nil {
+		return err
+	}
 	createMemberOpts1 := entity.CreateMemberOpts{
 		Name: name, Address: ip1, SubnetID: vipSubnetId, ProtocolPort: 22, Weight: 2}
-	m.CreatePoolMember(pool1, createMemberOpts1)
+	if _, err := m.CreatePoolMember(pool1, createMemberOpts1); err != nil {
+		return err
+	}
 	createMemberOpts2 := entity.CreateMemberOpts{
 		Name: name, Address: ip2, SubnetID: vipSubnetId, ProtocolPort: 22, Weight: 1}
-	m.CreatePoolMember(pool1, createMemberOpts2)
+	if _, err := m.CreatePoolMember(pool1, createMemberOpts2); err != nil {
+		return err
+	}
 
 	// 7. create health monitor
 	createHMOpts := entity.CreateHealthMonitorOpts{
 		Name: name, PoolID: pool1, Type: entity.PING,
 		MaxRetries: 5, Timeout: 30, Delay: 5}
-	m.CreateHealthMonitor(createHMOpts)
+	if _, err := m.CreateHealthMonitor(createHMOpts); err != nil {
+		return err
+	}
+	return nil
 }
 
 // RDSAdmin_pt security group
@@ -220,11 +353,14 @@ func (m *Manager) LoadbalancerPoolProtocolLeastConnections() {
 //入口：IP协议：TCP 端口范围：8000、3300 : 3399、9104、9100、22 ，网段：10.50.0.0/0 、phpadmin server
 
 // CreateRDSSecurityGroup 需要remote_ip_prefix
-func (m *Manager) CreateRDSSecurityGroup() {
+func (m *Manager) CreateRDSSecurityGroup() error {
 	name := "rds-security-group-odin"
 	remoteIpPrefix := "10.240.20.0/22"
 	opts := entity.CreateSecurityGroupOpts{Name: name}
-	sgId := m.CreateSecurityGroupAndRules(&opts)
+	sgId, err := m.CreateSecurityGroupAndRules(&opts)
+	if err != nil {
+		return err
+	}
 
 	ruleOpts8000 := &entity.CreateSecurityRuleOpts{
 		Direction: consts.DirectionIngress, EtherType: consts.EtherTypeV4,
@@ -246,31 +382,78 @@ func (m *Manager) CreateRDSSecurityGroup() {
 		Direction: consts.DirectionIngress, EtherType: consts.EtherTypeV4,
 		PortRangeMax: 22, PortRangeMin: 22, RemoteIPPrefix: remoteIpPrefix,
 		Protocol: consts.ProtocolTCP, SecGroupID: sgId}
-    m.CreateSecurityGroupRule(ruleOpts22.ToRequestBody())
-    m.CreateSecurityGroupRule(ruleOpts8000.ToRequestBody())
-    m.CreateSecurityGroupRule(ruleOpts9100.ToRequestBody())
-    m.CreateSecurityGroupRule(ruleOpts9104.ToRequestBody())
-    m.CreateSecurityGroupRule(ruleOpts3300.ToRequestBody())
+    if err := m.CreateSecurityGroupRule(ruleOpts22.ToRequestBody()); err != nil {
+        return err
+    }
+    if err := m.CreateSecurityGroupRule(ruleOpts8000.ToRequestBody()); err != nil {
+        return err
+    }
+    if err := m.CreateSecurityGroupRule(ruleOpts9100.ToRequestBody()); err != nil {
+        return err
+    }
+    if err := m.CreateSecurityGroupRule(ruleOpts9104.ToRequestBody()); err != nil {
+        return err
+    }
+    if err := m.CreateSecurityGroupRule(ruleOpts3300.ToRequestBody()); err != nil {
+        return err
+    }
+	return nil
 }
 
-func (m *Manager) FipQosLimit() {
-	netId, _, routerId := m.CreateVpc()
-	m.SetDefaultRouterGatewayHelper(routerId)
-	instanceId := m.CreateInstanceHelper(netId)
-	instancePortId, _ := m.GetInstancePort(instanceId)
-	fipId := m.CreateFloatingipHelper()
-	m.UpdateFloatingIpWithPort(fipId, instancePortId)
-	fipPort := m.GetFloatingipPort(fipId)
-	qosId := m.CreateQosPolicyHelper()
-	m.UpdatePortWithQos(fipPort.Id, qosId)
+func (m *Manager) FipQosLimit() error {
+	netId, _, routerId, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
+	if err := m.SetDefaultRouterGatewayHelper(routerId); err != nil {
+		return err
+	}
+	instanceId, err := m.CreateInstanceHelper(netId)
+	if err != nil {
+		return err
+	}
+	instancePortId, _, err := m.GetInstancePort(instanceId)
+	if err != nil {
+		return err
+	}
+	fipId, err := m.CreateFloatingipHelper()
+	if err != nil {
+		return err
+	}
+	if _, err := m.UpdateFloatingIpWithPort(fipId, instancePortId); err != nil {
+		return err
+	}
+	fipPort, err := m.GetFloatingipPort(fipId)
+	if err != nil {
+		return err
+	}
+	qosId, err := m.CreateQosPolicyHelper()
+	if err != nil {
+		return err
+	}
+	return m.UpdatePortWithQos(fipPort.Id, qosId)
 }
 
 // FipPortForwarding fip port forwarding
-func (m *Manager) FipPortForwarding() {
-	netId, _, routerId := m.CreateVpc()
-	m.SetDefaultRouterGatewayHelper(routerId)
-	instanceId := m.CreateInstanceHelper(netId)
-	fipId := m.CreateFloatingipHelper()
-	m.CreatePortForwardingHelper(fipId, instanceId)
+func (m *Manager) FipPortForwarding() error {
+	netId, _, routerId, err := m.CreateVpc()
+	if err != nil {
+		return err
+	}
+	if err := m.SetDefaultRouterGatewayHelper(routerId); err != nil {
+		return err
+	}
+	instanceId, err := m.CreateInstanceHelper(netId)
+	if err != nil {
+		return err
+	}
+	fipId, err := m.CreateFloatingipHelper()
+	if err != nil {
+		return err
+	}
+	if _, err := m.CreatePortForwardingHelper(fipId, instanceId); err != nil {
+		return err
+	}
+	return nil
 }
 
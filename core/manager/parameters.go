@@ -0,0 +1,316 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"math"
+	"net"
+	"regexp"
+	"request_openstack/internal/entity"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Types of template parameters.
+const (
+	ParamString    = "string"
+	ParamNumber    = "number"
+	ParamBoolean   = "boolean"
+	ParamJson      = "json"
+	ParamCommaList = "comma_delimited_list"
+)
+
+// Parameter is a parameter of the parameters section of a template.
+type Parameter struct {
+	Type        string       `yaml:"type"`
+	Default     interface{}  `yaml:"default"`
+	Description string       `yaml:"description"`
+	Constraints []Constraint `yaml:"constraints"`
+}
+
+// Constraint restricts the value of a parameter, each constraint sets one of
+// its checks, e.g. {range: {min: 1, max: 1024}} or {custom_constraint: cidr}.
+type Constraint struct {
+	AllowedValues    []interface{} `yaml:"allowed_values"`
+	Range            *Bounds       `yaml:"range"`
+	Length           *Bounds       `yaml:"length"`
+	AllowedPattern   string        `yaml:"allowed_pattern"`
+	CustomConstraint string        `yaml:"custom_constraint"`
+	Description      string        `yaml:"description"`
+}
+
+type Bounds struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+// customConstraints are the checks of custom_constraint, named as in heat
+// where it has them.
+var customConstraints = map[string]func(string) bool{
+	"uuid":     entity.IsUUID,
+	"cidr":     isCIDR,
+	"net_cidr": isCIDR,
+	"ip":       isIP,
+	"ip_addr":  isIP,
+}
+
+func isCIDR(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+func isIP(value string) bool {
+	return net.ParseIP(value) != nil
+}
+
+// ParseParams parses --param key=value pairs into parameter values.
+func ParseParams(pairs []string) (map[string]interface{}, error) {
+	params := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", pair)
+		}
+		params[kv[0]] = kv[1]
+	}
+	return params, nil
+}
+
+// LoadEnvironment reads the parameters and parameter_defaults sections of
+// environment files, a later file overrides the values of an earlier one.
+func LoadEnvironment(files ...string) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read environment file %s: %w", file, err)
+		}
+		var env struct {
+			Parameters        map[string]interface{} `yaml:"parameters"`
+			ParameterDefaults map[string]interface{} `yaml:"parameter_defaults"`
+		}
+		if err = yaml.Unmarshal(data, &env); err != nil {
+			return nil, fmt.Errorf("parse environment file %s: %w", file, err)
+		}
+		for name, value := range env.ParameterDefaults {
+			params[name] = value
+		}
+		for name, value := range env.Parameters {
+			params[name] = value
+		}
+	}
+	return params, nil
+}
+
+// templateParameters decodes the parameters section of a template.
+func templateParameters(template map[string]interface{}) (map[string]Parameter, error) {
+	parameters := make(map[string]Parameter)
+	section, ok := template["parameters"]
+	if !ok || section == nil {
+		return parameters, nil
+	}
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, &parameters); err != nil {
+		return nil, fmt.Errorf("parameters: %w", err)
+	}
+	return parameters, nil
+}
+
+// resolveParameters returns the value of every parameter of the template,
+// params override the defaults. All values are converted to the type of
+// their parameter and checked against its constraints, the errors of all
+// parameters are reported together.
+func resolveParameters(template map[string]interface{}, params map[string]interface{}) (map[string]interface{}, error) {
+	parameters, err := templateParameters(template)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	for name := range params {
+		if _, ok := parameters[name]; !ok {
+			errs = append(errs, fmt.Sprintf("unknown parameter %s", name))
+		}
+	}
+	values := make(map[string]interface{}, len(parameters))
+	for name, parameter := range parameters {
+		value, ok := params[name]
+		if !ok {
+			value = parameter.Default
+		}
+		if value == nil {
+			errs = append(errs, fmt.Sprintf("parameter %s has no value", name))
+			continue
+		}
+		if value, err = parameter.Convert(value); err == nil {
+			err = parameter.Validate(value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("parameter %s: %s", name, err))
+			continue
+		}
+		values[name] = value
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return values, nil
+}
+
+// Convert converts value, e.g. the string of a --param, to the type of the
+// parameter.
+func (p Parameter) Convert(value interface{}) (interface{}, error) {
+	s, isString := value.(string)
+	switch p.Type {
+	case "", ParamString:
+		switch value.(type) {
+		case string, int, float64, bool:
+			return fmt.Sprint(value), nil
+		}
+	case ParamNumber:
+		if isString {
+			if i, err := strconv.Atoi(s); err == nil {
+				return i, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, nil
+			}
+		}
+		switch value.(type) {
+		case int, float64:
+			return value, nil
+		}
+	case ParamBoolean:
+		if isString {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, nil
+			}
+		}
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case ParamCommaList:
+		if isString {
+			items := make([]interface{}, 0)
+			for _, item := range strings.Split(s, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return items, nil
+		}
+		if items, ok := value.([]interface{}); ok {
+			return items, nil
+		}
+	case ParamJson:
+		if isString {
+			var v interface{}
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, fmt.Errorf("invalid json: %w", err)
+			}
+			return v, nil
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return value, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %s", p.Type)
+	}
+	return nil, fmt.Errorf("%v is not a %s", value, p.Type)
+}
+
+// Validate checks a converted value against the constraints of the parameter.
+func (p Parameter) Validate(value interface{}) error {
+	for _, c := range p.Constraints {
+		if err := c.check(value); err != nil {
+			if c.Description != "" {
+				return fmt.Errorf("%s", c.Description)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Constraint) check(value interface{}) error {
+	if c.AllowedValues != nil {
+		allowed := false
+		for _, v := range c.AllowedValues {
+			allowed = allowed || fmt.Sprint(v) == fmt.Sprint(value)
+		}
+		if !allowed {
+			return fmt.Errorf("%v is not one of %v", value, c.AllowedValues)
+		}
+	}
+	if c.Range != nil {
+		n, ok := toFloat(value)
+		if !ok || !c.Range.contains(n) {
+			return fmt.Errorf("%v is not in the range %s", value, c.Range)
+		}
+	}
+	if c.Length != nil {
+		length := -1
+		switch v := value.(type) {
+		case string:
+			length = len(v)
+		case []interface{}:
+			length = len(v)
+		case map[string]interface{}:
+			length = len(v)
+		}
+		if length < 0 || !c.Length.contains(float64(length)) {
+			return fmt.Errorf("the length of %v is not in the range %s", value, c.Length)
+		}
+	}
+	if c.AllowedPattern != "" {
+		pattern, err := regexp.Compile("^(?:" + c.AllowedPattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid allowed_pattern: %w", err)
+		}
+		if s, ok := value.(string); !ok || !pattern.MatchString(s) {
+			return fmt.Errorf("%v does not match %s", value, c.AllowedPattern)
+		}
+	}
+	if c.CustomConstraint != "" {
+		valid, ok := customConstraints[c.CustomConstraint]
+		if !ok {
+			return fmt.Errorf("unknown custom_constraint %s", c.CustomConstraint)
+		}
+		if s, isString := value.(string); !isString || !valid(s) {
+			return fmt.Errorf("%v is not a valid %s", value, c.CustomConstraint)
+		}
+	}
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func (b *Bounds) contains(n float64) bool {
+	return (b.Min == nil || n >= *b.Min) && (b.Max == nil || n <= *b.Max)
+}
+
+func (b *Bounds) String() string {
+	min, max := math.Inf(-1), math.Inf(1)
+	if b.Min != nil {
+		min = *b.Min
+	}
+	if b.Max != nil {
+		max = *b.Max
+	}
+	return fmt.Sprintf("[%v, %v]", min, max)
+}
@@ -0,0 +1,269 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"request_openstack/internal/entity"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestParameterConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"string", ParamString, "a", "a", false},
+		{"string of number", "", 3, "3", false},
+		{"number int", ParamNumber, "42", 42, false},
+		{"number float", ParamNumber, "1.5", 1.5, false},
+		{"number kept", ParamNumber, 7, 7, false},
+		{"number invalid", ParamNumber, "many", nil, true},
+		{"boolean", ParamBoolean, "true", true, false},
+		{"boolean invalid", ParamBoolean, "yes please", nil, true},
+		{"comma list", ParamCommaList, "a, b,,c", []interface{}{"a", "b", "c"}, false},
+		{"comma list kept", ParamCommaList, []interface{}{"a"}, []interface{}{"a"}, false},
+		{"json", ParamJson, `{"a": [1]}`, map[string]interface{}{"a": []interface{}{float64(1)}}, false},
+		{"json invalid", ParamJson, `{`, nil, true},
+		{"unknown type", "date", "today", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parameter{Type: tt.typ}.Convert(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert(%v) error %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Convert(%v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+		value      interface{}
+		wantErr    string
+	}{
+		{"allowed", Constraint{AllowedValues: []interface{}{"a", "b"}}, "b", ""},
+		{"not allowed", Constraint{AllowedValues: []interface{}{"a", "b"}}, "c", "c is not one of [a b]"},
+		{"in range", Constraint{Range: &Bounds{Min: float(1), Max: float(10)}}, 10, ""},
+		{"out of range", Constraint{Range: &Bounds{Min: float(1)}}, 0, "0 is not in the range [1, +Inf]"},
+		{"length", Constraint{Length: &Bounds{Min: float(8)}}, "Wang.123", ""},
+		{"too short", Constraint{Length: &Bounds{Min: float(8)}}, "short", "the length of short is not in the range [8, +Inf]"},
+		{"list length", Constraint{Length: &Bounds{Max: float(1)}}, []interface{}{"a", "b"}, "the length of [a b] is not in the range [-Inf, 1]"},
+		{"pattern", Constraint{AllowedPattern: "[a-z]+"}, "abc", ""},
+		{"pattern anchored", Constraint{AllowedPattern: "[a-z]+"}, "abc1", "abc1 does not match [a-z]+"},
+		{"cidr", Constraint{CustomConstraint: "cidr"}, "10.0.0.0/24", ""},
+		{"not a cidr", Constraint{CustomConstraint: "cidr"}, "10.0.0.0", "10.0.0.0 is not a valid cidr"},
+		{"unknown custom", Constraint{CustomConstraint: "dns_name"}, "a", "unknown custom_constraint dns_name"},
+		{"description", Constraint{AllowedPattern: "x", Description: "must be x"}, "y", "must be x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Parameter{Constraints: []Constraint{tt.constraint}}.Validate(tt.value)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Validate(%v) = %v, want %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+const parametersTemplate = `
+parameters:
+  cidr:
+    type: string
+    default: 10.0.0.0/24
+    constraints:
+      - custom_constraint: cidr
+  size:
+    type: number
+    constraints:
+      - range: {min: 1, max: 10}
+  names:
+    type: comma_delimited_list
+    default: a,b
+resources:
+  network:
+    type: network
+    properties:
+      description: {list_join: ["-", {get_param: names}]}
+  subnet:
+    type: subnet
+    properties:
+      network_id: network
+      cidr: {get_param: cidr}
+      ip_version: 4
+`
+
+func TestResolveParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			params: map[string]interface{}{"size": "3"},
+			want:   map[string]interface{}{"cidr": "10.0.0.0/24", "size": 3, "names": []interface{}{"a", "b"}},
+		},
+		{
+			name:   "override",
+			params: map[string]interface{}{"size": 10, "cidr": "192.168.0.0/16", "names": "x"},
+			want:   map[string]interface{}{"cidr": "192.168.0.0/16", "size": 10, "names": []interface{}{"x"}},
+		},
+		{
+			name:    "all errors together",
+			params:  map[string]interface{}{"cidr": "nope", "color": "red"},
+			wantErr: "parameter cidr: nope is not a valid cidr; parameter size has no value; unknown parameter color",
+		},
+		{
+			name:    "out of range",
+			params:  map[string]interface{}{"size": "11"},
+			wantErr: "parameter size: 11 is not in the range [1, 10]",
+		},
+	}
+	template := parseTemplate(t, parametersTemplate)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveParameters(template, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTemplateSubstitutesParameters(t *testing.T) {
+	template, err := ResolveTemplate(parseTemplate(t, parametersTemplate), map[string]interface{}{"size": 1, "names": "x,y,z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := template.Resources["subnet"].PropsObj.(*entity.CreateSubnetOpts).CIDR; got != "10.0.0.0/24" {
+		t.Fatalf("subnet cidr %v", got)
+	}
+	if got := template.Resources["network"].PropsObj.(*entity.CreateNetworkOpts).Description; got != "x-y-z" {
+		t.Fatalf("network description %v", got)
+	}
+}
+
+func TestSubstituteParams(t *testing.T) {
+	params := map[string]interface{}{
+		"name": "web",
+		"tags": []interface{}{"a", "b"},
+		"conf": map[string]interface{}{"port": 80},
+	}
+	tests := []struct {
+		name    string
+		value   string
+		want    interface{}
+		wantErr string
+	}{
+		{"get_param", `{get_param: name}`, "web", ""},
+		{"path", `{get_param: [conf, port]}`, 80, ""},
+		{"list_join", `{list_join: [",", {get_param: tags}, [c]]}`, "a,b,c", ""},
+		{"str_replace", `{str_replace: {template: "$name-1", params: {$name: {get_param: name}}}}`, "web-1", ""},
+		{"references kept", `{get_resource: net}`, map[string]interface{}{GetResource: "net"}, ""},
+		{"missing", `{get_param: size}`, nil, "parameter size has no value"},
+		{"list_join of a resource", `{list_join: [",", [{get_resource: net}]]}`, nil, "list_join cannot use get_resource or get_attr of net"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			got, err := substituteParams(value, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	params, err := ParseParams([]string{"a=1", "b=x=y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"a": "1", "b": "x=y"}; !reflect.DeepEqual(params, want) {
+		t.Fatalf("got %v, want %v", params, want)
+	}
+	for _, pair := range []string{"a", "=1"} {
+		if _, err = ParseParams([]string{pair}); err == nil {
+			t.Fatalf("expected an error for %q", pair)
+		}
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("base.yaml", "parameters:\n  a: 1\n  b: base\nparameter_defaults:\n  c: default\n")
+	lab := write("lab.yaml", "parameters:\n  b: lab\nparameter_defaults:\n  a: 2\n")
+	params, err := LoadEnvironment(base, lab)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a later file overrides an earlier one, its parameters its parameter_defaults
+	if want := map[string]interface{}{"a": 2, "b": "lab", "c": "default"}; !reflect.DeepEqual(params, want) {
+		t.Fatalf("got %v, want %v", params, want)
+	}
+	if _, err = LoadEnvironment(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if _, err = LoadEnvironment(write("bad.yaml", "parameters: [")); err == nil {
+		t.Fatal("expected an error for an invalid file")
+	}
+}
+
+func TestLoadTemplateWithEnvironment(t *testing.T) {
+	params, err := LoadEnvironment("../../configs/environments/lab.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := ResolveTemplate(yamlToMap("../../configs/templates/instance.yaml"), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := template.Resources["subnet"].PropsObj.(*entity.CreateSubnetOpts).CIDR; got != "192.168.100.0/24" {
+		t.Fatalf("subnet cidr %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PlanStep is a resource of an execution plan.
+type PlanStep struct {
+	Resource string
+	Type     string
+	// Request is the API call creating the resource
+	Request string
+	// After are the resources created before it
+	After []string
+}
+
+// String formats the step as a line of a plan.
+func (p PlanStep) String() string {
+	line := fmt.Sprintf("%-20s %-24s %s", p.Resource, p.Type, p.Request)
+	if len(p.After) > 0 {
+		line += " after " + strings.Join(p.After, ", ")
+	}
+	return line
+}
+
+// ExecutionPlan returns the steps the scheduler creates the resources of a
+// resolved template in, without any request to OpenStack. The resources of
+// a wave only depend on those of earlier waves and are created in parallel.
+func ExecutionPlan(template *Template) [][]PlanStep {
+	waves := make(map[string]int, len(template.Resources))
+	var wave func(name string) int
+	wave = func(name string) int {
+		if w, ok := waves[name]; ok {
+			return w
+		}
+		w := 0
+		for dep := range template.Resources[name].Dependencies {
+			if d := wave(dep) + 1; d > w {
+				w = d
+			}
+		}
+		waves[name] = w
+		return w
+	}
+	var plan [][]PlanStep
+	for _, name := range sortedResourceNames(template.Resources) {
+		w := wave(name)
+		for len(plan) <= w {
+			plan = append(plan, nil)
+		}
+		resource := template.Resources[name]
+		after := make([]string, 0, len(resource.Dependencies))
+		for dep := range resource.Dependencies {
+			after = append(after, dep)
+		}
+		sort.Strings(after)
+		plan[w] = append(plan[w], PlanStep{
+			Resource: name,
+			Type:     resource.Type,
+			Request:  resourceTypes[resource.Type].request,
+			After:    after,
+		})
+	}
+	return plan
+}
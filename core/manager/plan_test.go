@@ -0,0 +1,37 @@
+package manager
+
+import "testing"
+
+// planWaves maps the resources of a plan to their wave.
+func planWaves(plan [][]PlanStep) map[string]int {
+	waves := make(map[string]int)
+	for i, wave := range plan {
+		for _, step := range wave {
+			waves[step.Resource] = i
+		}
+	}
+	return waves
+}
+
+func TestExecutionPlanInstance(t *testing.T) {
+	template, err := LoadTemplate("../../configs/templates/instance.yaml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waves := planWaves(ExecutionPlan(template))
+	tests := []struct {
+		before, after string
+	}{
+		{"network", "subnet"},
+		{"subnet", "instance1"},
+		{"subnet", "routerInterface"},
+		{"myrouter", "routerInterface"},
+		{"instance1", "fip1"},
+		{"routerInterface", "fip1"},
+	}
+	for _, tt := range tests {
+		if waves[tt.before] >= waves[tt.after] {
+			t.Errorf("%s in wave %d, not before %s in wave %d", tt.before, waves[tt.before], tt.after, waves[tt.after])
+		}
+	}
+}
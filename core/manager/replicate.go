@@ -0,0 +1,333 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys of a template resource replicating it, e.g. {type: network, count: 50}.
+// count is a number, for_each a list, a map or the name of another
+// replicated resource, both together replicate for each item count times.
+const (
+	Count   = "count"
+	ForEach = "for_each"
+)
+
+// Placeholders in the properties of a replicated resource. %index% is the
+// position of the replica in its count, or in its for_each without count,
+// %key% the key of its for_each item, the item itself for a list of strings,
+// and %value% the value of the item, the name of the replica for a for_each
+// naming a replicated resource. A property that is only %value% takes the
+// value as is, e.g. a map of a json parameter.
+const (
+	IndexVar = "%index%"
+	KeyVar   = "%key%"
+	ValueVar = "%value%"
+)
+
+// replica is a member of a replicated resource, named after the resource
+// and its key, e.g. net[0] or port[net1][2].
+type replica struct {
+	name  string
+	index int
+	key   string
+	value interface{}
+}
+
+// expandResources replaces the resources of a template with count or
+// for_each by their replicas and returns them with the members of each
+// replicated resource. References to a replicated resource as a whole are
+// expanded to lists of its members, see expandSets.
+func expandResources(resources map[string]interface{}, params map[string]interface{}) (map[string]interface{}, map[string][]string, error) {
+	e := &expander{
+		resources: resources,
+		params:    params,
+		replicas:  make(map[string][]replica),
+	}
+	var errs []string
+	for _, name := range sortedKeys(resources) {
+		if _, _, err := e.replicasOf(name); err != nil {
+			errs = append(errs, fmt.Sprintf("resource %s: %s", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	if len(e.replicas) == 0 {
+		return resources, nil, nil
+	}
+
+	sets := make(map[string][]string, len(e.replicas))
+	for name, replicas := range e.replicas {
+		sets[name] = make([]string, 0, len(replicas))
+		for _, r := range replicas {
+			sets[name] = append(sets[name], r.name)
+		}
+	}
+	expanded := make(map[string]interface{}, len(resources))
+	for name, resource := range resources {
+		// a depends_on naming a replicated resource depends on its members
+		if definition, ok := resource.(map[string]interface{}); ok {
+			if dep, ok := definition[DependsOn].(string); ok {
+				resource = copyWith(definition, DependsOn, []interface{}{dep})
+			}
+		}
+		replicas, ok := e.replicas[name]
+		if !ok {
+			expanded[name] = expandSets(resource, sets)
+			continue
+		}
+		definition := resource.(map[string]interface{})
+		for _, r := range replicas {
+			if _, ok := resources[r.name]; ok {
+				return nil, nil, fmt.Errorf("resource %s: replica %s is a resource of the template", name, r.name)
+			}
+			member := make(map[string]interface{}, len(definition))
+			for key, value := range definition {
+				if key != Count && key != ForEach {
+					member[key] = value
+				}
+			}
+			for _, key := range []string{"properties", DependsOn} {
+				if value, ok := definition[key]; ok {
+					member[key] = expandSets(interpolate(value, r), sets)
+				}
+			}
+			expanded[r.name] = member
+		}
+	}
+	return expanded, sets, nil
+}
+
+type expander struct {
+	resources map[string]interface{}
+	params    map[string]interface{}
+	replicas  map[string][]replica
+	// expanding are the resources whose replicas are being computed, to
+	// report a cycle of for_each
+	expanding []string
+}
+
+// forEachCycle is the error of resources replicated for each other.
+type forEachCycle []string
+
+func (c forEachCycle) Error() string {
+	return fmt.Sprintf("%s cycle %s", ForEach, strings.Join(c, " -> "))
+}
+
+// replicasOf returns the replicas of the resource name, false when it is not
+// replicated.
+func (e *expander) replicasOf(name string) ([]replica, bool, error) {
+	if replicas, ok := e.replicas[name]; ok {
+		return replicas, true, nil
+	}
+	definition, _ := e.resources[name].(map[string]interface{})
+	count, hasCount := definition[Count]
+	forEach, hasForEach := definition[ForEach]
+	if !hasCount && !hasForEach {
+		return nil, false, nil
+	}
+	for i, expanding := range e.expanding {
+		if expanding == name {
+			return nil, true, append(forEachCycle(nil), append(e.expanding[i:], name)...)
+		}
+	}
+	e.expanding = append(e.expanding, name)
+	defer func() {
+		e.expanding = e.expanding[:len(e.expanding)-1]
+	}()
+
+	items := []replica{{}}
+	if hasForEach {
+		var err error
+		if items, err = e.forEachItems(forEach); err != nil {
+			if cycle, ok := err.(forEachCycle); ok {
+				return nil, true, cycle
+			}
+			return nil, true, fmt.Errorf("%s: %w", ForEach, err)
+		}
+	}
+	n := 1
+	if hasCount {
+		var err error
+		if n, err = e.count(count); err != nil {
+			return nil, true, fmt.Errorf("%s: %w", Count, err)
+		}
+	}
+	replicas := make([]replica, 0, len(items)*n)
+	for position, item := range items {
+		for i := 0; i < n; i++ {
+			r := replica{index: position, key: item.key, value: item.value}
+			r.name = fmt.Sprintf("%s[%s]", name, r.key)
+			switch {
+			case hasForEach && hasCount:
+				r.index = i
+				r.name = fmt.Sprintf("%s[%s][%d]", name, item.key, i)
+			case hasCount:
+				r.index, r.key, r.value = i, strconv.Itoa(i), i
+				r.name = fmt.Sprintf("%s[%d]", name, i)
+			}
+			replicas = append(replicas, r)
+		}
+	}
+	e.replicas[name] = replicas
+	return replicas, true, nil
+}
+
+func (e *expander) count(value interface{}) (int, error) {
+	value, err := substituteParams(value, e.params)
+	if err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case int:
+		if v >= 0 {
+			return v, nil
+		}
+	case float64:
+		if v >= 0 && v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not a number of replicas", value)
+}
+
+// forEachItems returns the items of a for_each with their keys and values.
+func (e *expander) forEachItems(value interface{}) ([]replica, error) {
+	value, err := substituteParams(value, e.params)
+	if err != nil {
+		return nil, err
+	}
+	var items []replica
+	switch v := value.(type) {
+	case string:
+		if _, ok := e.resources[v]; !ok {
+			return nil, fmt.Errorf("unknown resource %s", v)
+		}
+		replicas, ok, err := e.replicasOf(v)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s is not replicated", v)
+		}
+		for _, r := range replicas {
+			// the key of port[net1][2] is net1][2, so that its replicas
+			// are named like it
+			items = append(items, replica{key: r.name[len(v)+1 : len(r.name)-1], value: r.name})
+		}
+	case []interface{}:
+		for i, item := range v {
+			key := strconv.Itoa(i)
+			if s, ok := item.(string); ok {
+				key = s
+			}
+			items = append(items, replica{key: key, value: item})
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			items = append(items, replica{key: key, value: v[key]})
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a list, a map or a resource", value)
+	}
+	return items, nil
+}
+
+// interpolate returns a copy of value with the placeholders replaced by
+// those of r.
+func interpolate(value interface{}, r replica) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == ValueVar {
+			return r.value
+		}
+		return strings.NewReplacer(
+			IndexVar, strconv.Itoa(r.index),
+			KeyVar, r.key,
+			ValueVar, fmt.Sprint(r.value),
+		).Replace(v)
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = interpolate(item, r)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = interpolate(item, r)
+		}
+		return res
+	}
+	return value
+}
+
+// expandSets returns a copy of value referring to the members of replicated
+// resources rather than to the resources: get_resource and get_attr of a
+// replicated resource become lists of those of its members, and its name as
+// an item of a list, e.g. in the router_ids of a firewall, its members.
+func expandSets(value interface{}, sets map[string][]string) interface{} {
+	if function, arg, ok := intrinsic(value); ok && (function == GetResource || function == GetAttr) {
+		name, path, err := splitArgument(function, arg)
+		members, replicated := sets[name]
+		if err != nil || !replicated {
+			return value
+		}
+		res := make([]interface{}, 0, len(members))
+		for _, member := range members {
+			if function == GetResource {
+				res = append(res, map[string]interface{}{GetResource: member})
+			} else {
+				res = append(res, map[string]interface{}{GetAttr: append([]interface{}{member}, path...)})
+			}
+		}
+		return res
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			res[key] = expandSets(item, sets)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if members, ok := sets[fmt.Sprint(item)]; ok {
+				for _, member := range members {
+					res = append(res, member)
+				}
+				continue
+			}
+			res = append(res, expandSets(item, sets))
+		}
+		return res
+	}
+	return value
+}
+
+// copyWith returns a copy of m with key set to value.
+func copyWith(m map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		res[k] = v
+	}
+	res[key] = value
+	return res
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+package manager
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestExpandResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		params  map[string]interface{}
+		want    map[string]map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "count",
+			src: `
+net:
+  type: network
+  count: 2
+  properties: {description: "net %index%"}
+`,
+			want: map[string]map[string]interface{}{
+				"net[0]": {"description": "net 0"},
+				"net[1]": {"description": "net 1"},
+			},
+		},
+		{
+			name:   "count parameter",
+			src:    "net: {type: network, count: {get_param: n}}",
+			params: map[string]interface{}{"n": 3},
+			want:   map[string]map[string]interface{}{"net[0]": nil, "net[1]": nil, "net[2]": nil},
+		},
+		{
+			name: "zero",
+			src:  "net: {type: network, count: 0}",
+			want: map[string]map[string]interface{}{},
+		},
+		{
+			name: "for_each list",
+			src: `
+net:
+  type: network
+  for_each: [blue, red]
+  properties: {description: "%index% %key% %value%"}
+`,
+			want: map[string]map[string]interface{}{
+				"net[blue]": {"description": "0 blue blue"},
+				"net[red]":  {"description": "1 red red"},
+			},
+		},
+		{
+			name: "for_each map keeps the value",
+			src: `
+net:
+  type: network
+  for_each: {a: {mtu: 1400}, b: {mtu: 9000}}
+  properties: {description: "%key%", value_spec: "%value%"}
+`,
+			want: map[string]map[string]interface{}{
+				"net[a]": {"description": "a", "value_spec": map[string]interface{}{"mtu": 1400}},
+				"net[b]": {"description": "b", "value_spec": map[string]interface{}{"mtu": 9000}},
+			},
+		},
+		{
+			name: "for_each resource with count",
+			src: `
+net: {type: network, count: 2}
+port:
+  type: port
+  for_each: net
+  count: 2
+  properties: {network_id: "%value%", description: "%key% %index%"}
+`,
+			want: map[string]map[string]interface{}{
+				"net[0]":     nil,
+				"net[1]":     nil,
+				"port[0][0]": {"network_id": "net[0]", "description": "0 0"},
+				"port[0][1]": {"network_id": "net[0]", "description": "0 1"},
+				"port[1][0]": {"network_id": "net[1]", "description": "1 0"},
+				"port[1][1]": {"network_id": "net[1]", "description": "1 1"},
+			},
+		},
+		{
+			name: "references to the set",
+			src: `
+net: {type: network, count: 2}
+router:
+  type: router
+  depends_on: net
+  properties: {routes: [net], ids: {get_resource: net}}
+`,
+			want: map[string]map[string]interface{}{
+				"net[0]": nil,
+				"net[1]": nil,
+				"router": {
+					"routes": []interface{}{"net[0]", "net[1]"},
+					"ids":    []interface{}{map[string]interface{}{GetResource: "net[0]"}, map[string]interface{}{GetResource: "net[1]"}},
+				},
+			},
+		},
+		{name: "negative count", src: "net: {type: network, count: -1}", wantErr: "resource net: count: -1 is not a number of replicas"},
+		{name: "unknown for_each", src: "net: {type: network, for_each: nets}", wantErr: "resource net: for_each: unknown resource nets"},
+		{name: "for_each not replicated", src: "a: {type: network}\nb: {type: network, for_each: a}", wantErr: "resource b: for_each: a is not replicated"},
+		{name: "for_each cycle", src: "a: {type: network, for_each: b}\nb: {type: network, for_each: a}", wantErr: "resource a: for_each cycle a -> b -> a"},
+		{name: "replica name taken", src: "net: {type: network, count: 1}\n\"net[0]\": {type: network}", wantErr: "replica net[0] is a resource of the template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := parseTemplate(t, tt.src)
+			expanded, _, err := expandResources(resources, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0, len(expanded))
+			for name := range expanded {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			wantNames := make([]string, 0, len(tt.want))
+			for name := range tt.want {
+				wantNames = append(wantNames, name)
+			}
+			sort.Strings(wantNames)
+			if !reflect.DeepEqual(names, wantNames) {
+				t.Fatalf("resources %v, want %v", names, wantNames)
+			}
+			for name, want := range tt.want {
+				if want == nil {
+					continue
+				}
+				resource := expanded[name].(map[string]interface{})
+				props := resource["properties"].(map[string]interface{})
+				for key, value := range want {
+					if !reflect.DeepEqual(props[key], value) {
+						t.Errorf("%s %s = %#v, want %#v", name, key, props[key], value)
+					}
+				}
+				if _, ok := resource[Count]; ok {
+					t.Errorf("%s kept %s", name, Count)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveScaleTemplate(t *testing.T) {
+	template, err := LoadTemplate("../../configs/templates/scale.yaml", map[string]interface{}{"network_count": "2", "ports_per_network": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 networks, a subnet each and 3 ports on each
+	if n := len(template.Resources); n != 2+2+6 {
+		t.Fatalf("expected 10 resources, got %d", n)
+	}
+	port := template.Resources["port[1][2]"]
+	if _, ok := port.Dependencies["net[1]"]; !ok {
+		t.Fatalf("port[1][2] depends on %v", port.Dependencies)
+	}
+	if _, ok := port.Dependencies["subnet[1]"]; !ok {
+		t.Fatalf("port[1][2] depends on %v", port.Dependencies)
+	}
+	ids, _ := template.Outputs["network_ids"].(map[string]interface{})
+	if value, _ := ids["value"].([]interface{}); len(value) != 2 {
+		t.Fatalf("network_ids output %v", template.Outputs["network_ids"])
+	}
+}
@@ -1,11 +1,13 @@
 package manager
 
 import (
+    "fmt"
     "gopkg.in/yaml.v3"
     "io/ioutil"
     "log"
-    "request_openstack/consts"
     "request_openstack/internal/entity"
+    "sort"
+    "strings"
 )
 
 
@@ -24,39 +26,223 @@ func yamlToMap(yamlFile string) map[string]interface{} {
     return resources
 }
 
+// LoadTemplate reads and resolves a template file, see ResolveTemplate. A
+// kind-based or Heat HOT template is imported first, what ImportHOT left out
+// is logged.
+func LoadTemplate(yamlFile string, params map[string]interface{}) (*Template, error) {
+    data, err := ioutil.ReadFile(yamlFile)
+    if err != nil {
+        return nil, fmt.Errorf("read template %s: %w", yamlFile, err)
+    }
+    var template map[string]interface{}
+    if err = yaml.Unmarshal(data, &template); err != nil {
+        return nil, fmt.Errorf("parse template %s: %w", yamlFile, err)
+    }
+    if IsKind(template) {
+        if template, err = ImportKind(template); err != nil {
+            return nil, fmt.Errorf("import template %s: %w", yamlFile, err)
+        }
+    } else if IsHOT(template) {
+        var report *ImportReport
+        if template, report, err = ImportHOT(template); err != nil {
+            return nil, fmt.Errorf("import template %s: %w", yamlFile, err)
+        }
+        for _, line := range report.Lines() {
+            log.Println("##############Import", yamlFile, line)
+        }
+    }
+    return ResolveTemplate(template, params)
+}
+
 func Resolve(resources map[string]interface{}) map[string]Resource {
-    var resourceMap = make(map[string]Resource)
-    for key, resource := range resources["resources"].(map[string]interface{}) {
-        resourceType := resource.(map[string]interface{})["type"].(string)
-        var resourceProps map[string]interface{}
-        if val, ok := resource.(map[string]interface{})["properties"]; ok {
-            resourceProps = val.(map[string]interface{})
-        }
-        if resourceType == consts.NETWORK {
-            optsObj := &entity.CreateNetworkOpts{Name: key}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        } else if resourceType == consts.SUBNET {
-            optsObj := &entity.CreateSubnetOpts{Name: key}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        } else if resourceType == consts.ROUTER {
-            optsObj := &entity.CreateRouterOpts{Name: key}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        } else if resourceType == consts.ROUTERINTERFACE {
-            optsObj := &entity.AddRouterInterfaceOpts{}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        } else if resourceType == consts.FLOATINGIP {
-            optsObj := &entity.CreateFipOpts{}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        } else if resourceType == consts.SERVER {
-            optsObj := &entity.CreateInstanceOpts{}
-            optsObj, dependencies := optsObj.AssignProps(resourceProps)
-            resourceMap[key] = NewResource(key, resourceType, optsObj, dependencies)
-        }
-    }
-    return resourceMap
+    template, err := ResolveTemplate(resources, nil)
+    if err != nil {
+        log.Println("##############Resolve template failed", err)
+        return nil
+    }
+    return template.Resources
+}
+
+// DependsOn lists the resources a resource is created after, besides those
+// its properties refer to.
+const DependsOn = "depends_on"
+
+// Template is a resolved template, its outputs are resolved once the
+// resources are created. Parameters are the values of its parameters and
+// Properties the properties of each resource with them substituted.
+type Template struct {
+    Resources          map[string]Resource
+    Outputs            map[string]interface{}
+    Parameters         map[string]interface{}
+    Properties         map[string]map[string]interface{}
+}
+
+// ResolveTemplate resolves the resources and outputs of a template, params
+// override the defaults of its parameters section and are validated before
+// anything else is resolved. The properties of a
+// resource using get_resource or get_attr are kept to be resolved when it
+// is created, the resources they name become its dependencies.
+//
+// An unsupported type, a property its type has not or of the wrong type, a
+// reference to an unknown resource or a dependency cycle fails the template,
+// the errors of all resources are reported together. Resources with count or
+// for_each are replaced by their replicas first, see expandResources.
+func ResolveTemplate(template map[string]interface{}, params map[string]interface{}) (*Template, error) {
+    values, err := resolveParameters(template, params)
+    if err != nil {
+        return nil, err
+    }
+
+    resources, _ := template["resources"].(map[string]interface{})
+    resources, sets, err := expandResources(resources, values)
+    if err != nil {
+        return nil, err
+    }
+    resourceMap := make(map[string]Resource)
+    properties := make(map[string]map[string]interface{})
+    var errs []string
+    for key, resource := range resources {
+        res, props, err := resolveResource(key, resource, resources, values)
+        if err != nil {
+            errs = append(errs, fmt.Sprintf("resource %s: %s", key, err))
+            continue
+        }
+        resourceMap[key], properties[key] = res, props
+    }
+    if len(errs) > 0 {
+        sort.Strings(errs)
+        return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+    }
+    addImplicitDependencies(resourceMap)
+    if err := checkDependencies(resourceMap); err != nil {
+        return nil, err
+    }
+
+    outputs, err := substituteParams(expandSets(template["outputs"], sets), values)
+    if err != nil {
+        return nil, fmt.Errorf("outputs: %w", err)
+    }
+    outputMap, _ := outputs.(map[string]interface{})
+    if _, err = checkReferences(outputMap, resources); err != nil {
+        return nil, fmt.Errorf("outputs: %w", err)
+    }
+    return &Template{Resources: resourceMap, Outputs: outputMap, Parameters: values, Properties: properties}, nil
+}
+
+// resolveResource resolves a resource of the template and returns it with
+// its properties, the parameters substituted. Its properties are checked
+// against the opts of its type first.
+func resolveResource(key string, resource interface{}, resources, values map[string]interface{}) (Resource, map[string]interface{}, error) {
+    definition, ok := resource.(map[string]interface{})
+    if !ok {
+        return Resource{}, nil, fmt.Errorf("not a map of type and properties")
+    }
+    resourceType, _ := definition["type"].(string)
+    kind, ok := resourceTypes[resourceType]
+    if !ok {
+        return Resource{}, nil, fmt.Errorf("unsupported type %q", resourceType)
+    }
+    props, err := substituteParams(definition["properties"], values)
+    if err != nil {
+        return Resource{}, nil, err
+    }
+    resourceProps, ok := props.(map[string]interface{})
+    if !ok && props != nil {
+        return Resource{}, nil, fmt.Errorf("properties are not a map")
+    }
+    if err = checkProperties(kind, key, resourceProps); err != nil {
+        return Resource{}, nil, err
+    }
+    refs, err := checkReferences(resourceProps, resources)
+    if err != nil {
+        return Resource{}, nil, err
+    }
+    dependsOn, err := dependsOn(definition[DependsOn])
+    if err != nil {
+        return Resource{}, nil, err
+    }
+    resolved := resourceProps
+    if len(refs) > 0 {
+        placeholders, _ := resolveIntrinsics(resourceProps, placeholderResolver{})
+        resolved = placeholders.(map[string]interface{})
+    }
+    optsObj, dependencies, err := resolveOpts(kind, key, resolved)
+    if err != nil {
+        return Resource{}, nil, err
+    }
+    if dependencies == nil {
+        dependencies = make(map[string]string)
+    }
+    for _, ref := range append(refs, dependsOn...) {
+        if _, ok := dependencies[ref]; !ok {
+            dependencies[ref] = ""
+        }
+    }
+    res := NewResource(key, resourceType, optsObj, dependencies)
+    if len(refs) > 0 {
+        res.Props = resourceProps
+    }
+    return res, resourceProps, nil
+}
+
+// dependsOn returns the resources of depends_on, a name or a list of names.
+func dependsOn(value interface{}) ([]string, error) {
+    switch v := value.(type) {
+    case nil:
+        return nil, nil
+    case string:
+        return []string{v}, nil
+    case []interface{}:
+        names := make([]string, 0, len(v))
+        for _, item := range v {
+            name, ok := item.(string)
+            if !ok {
+                return nil, fmt.Errorf("invalid %s %v", DependsOn, item)
+            }
+            names = append(names, name)
+        }
+        return names, nil
+    }
+    return nil, fmt.Errorf("invalid %s %v", DependsOn, value)
+}
+
+// checkProperties checks the properties of a resource against the opts of
+// its type, the values of intrinsic functions are not known yet.
+func checkProperties(kind resourceType, name string, props map[string]interface{}) error {
+    opts, _, err := resolveOpts(kind, name, nil)
+    if err != nil {
+        return err
+    }
+    values, _ := walkIntrinsics(props, func(function string, arg interface{}) (interface{}, error) {
+        return nil, nil
+    })
+    checked, _ := values.(map[string]interface{})
+    return entity.CheckProps(opts, checked)
+}
+
+// resolveOpts builds the opts of a resource, a property its AssignProps
+// cannot take fails it rather than panicking.
+func resolveOpts(kind resourceType, name string, props map[string]interface{}) (opts interface{}, dependencies map[string]string, err error) {
+    defer func() {
+        if p := recover(); p != nil {
+            err = fmt.Errorf("invalid properties: %v", p)
+        }
+    }()
+    opts, dependencies = kind.resolve(name, props)
+    return opts, dependencies, nil
+}
+
+// checkReferences returns the resources value refers to, all of which must
+// be resources of the template.
+func checkReferences(value interface{}, resources map[string]interface{}) ([]string, error) {
+    refs, err := references(value)
+    if err != nil {
+        return nil, err
+    }
+    for _, ref := range refs {
+        if _, ok := resources[ref]; !ok {
+            return nil, fmt.Errorf("reference to unknown resource %s", ref)
+        }
+    }
+    return refs, nil
 }
@@ -0,0 +1,701 @@
+package manager
+
+import (
+	"fmt"
+	"reflect"
+	"request_openstack/consts"
+	"request_openstack/internal"
+	"request_openstack/internal/entity"
+	"strconv"
+	"strings"
+)
+
+// resourceType is how resources of a template type are resolved and created.
+type resourceType struct {
+	// resolve builds the opts of the resource named name from its properties
+	// and returns them with the dependencies of the resource
+	resolve func(name string, props map[string]interface{}) (interface{}, map[string]string)
+	// receive sets the ids of the created dependencies on the opts, it is
+	// applyTransmitter unless a dependency needs a lookup first
+	receive func(manager *Manager, opts interface{}, trans *Transmitter) error
+	create  func(manager *Manager, opts interface{}) (string, error)
+	// request is the API call of create, for plans
+	request string
+	// show returns the attributes of the created resource for get_attr
+	show func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error)
+	// updatable are the properties update changes in place, a change of any
+	// other property replaces the resource
+	updatable []string
+	update    func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error
+	// delete deletes the created resource, opts are rebuilt from the stack
+	// state for the ids of its parents, e.g. the pool of a member
+	delete func(manager *Manager, opts interface{}, id string) error
+	// after are the types of the resources on the same network or router
+	// created before it though it does not refer to them, e.g. the router
+	// interfaces making the port of a floating ip reachable
+	after []string
+}
+
+var resourceTypes = map[string]resourceType{
+	consts.NETWORK: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateNetworkOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateNetwork(opts.(*entity.CreateNetworkOpts))
+		},
+		request:   "POST network /v2.0/networks",
+		show:      neutronAttributes("networks", consts.NETWORK),
+		updatable: []string{"name", "description", "admin_state_up", "mtu", "port_security_enabled", "shared"},
+		update:    neutronUpdate("networks", consts.NETWORK),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteNetwork(id))
+		},
+	},
+	consts.SUBNET: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateSubnetOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateSubnet(opts.(*entity.CreateSubnetOpts))
+		},
+		request:   "POST network /v2.0/subnets",
+		show:      neutronAttributes("subnets", consts.SUBNET),
+		updatable: []string{"name", "description", "gateway_ip", "allocation_pools", "dns_nameservers", "host_routes", "enable_dhcp"},
+		update:    neutronUpdate("subnets", consts.SUBNET),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteSubnet(id))
+		},
+	},
+	consts.PORT: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreatePortOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreatePort(opts.(*entity.CreatePortOpts))
+		},
+		request:   "POST network /v2.0/ports",
+		show:      neutronAttributes("ports", consts.PORT),
+		updatable: []string{"name", "description", "admin_state_up", "allowed_address_pairs", "port_security_enabled"},
+		update:    neutronUpdate("ports", consts.PORT),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeletePort(id))
+		},
+	},
+	consts.ROUTER: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateRouterOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateRouter(opts.(*entity.CreateRouterOpts))
+		},
+		request:   "POST network /v2.0/routers",
+		show:      neutronAttributes("routers", consts.ROUTER),
+		updatable: []string{"name", "description", "admin_state_up"},
+		update:    neutronUpdate("routers", consts.ROUTER),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteRouter(id))
+		},
+	},
+	consts.ROUTERINTERFACE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.AddRouterInterfaceOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.AddRouterInterface(opts.(*entity.AddRouterInterfaceOpts))
+		},
+		request: "PUT network /v2.0/routers/{router_id}/add_router_interface",
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			ri := opts.(*entity.AddRouterInterfaceOpts)
+			if ri.SubnetID == "" {
+				// an interface added on a port is removed by its port
+				_, err := manager.Neutron.RemoveRouterInterfaceByPort(ri.RouterId, ri.PortID)
+				return ignoreNotFound(err)
+			}
+			return outputErr(manager.Neutron.RemoveRouterInterface(ri.RouterId, ri.SubnetID))
+		},
+	},
+	consts.FLOATINGIP: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateFipOpts{}).AssignProps(props)
+		},
+		// a floating ip of a server is bound to the port of the server
+		receive: func(manager *Manager, opts interface{}, trans *Transmitter) error {
+			return receiveServerPorts(manager, opts, trans, func(portId, ipAddr string) string {
+				return portId
+			})
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateFloatingIP(opts.(*entity.CreateFipOpts))
+		},
+		request:   "POST network /v2.0/floatingips",
+		show:      neutronAttributes(consts.FLOATINGIPS, consts.FLOATINGIP),
+		updatable: []string{"description"},
+		update:    neutronUpdate(consts.FLOATINGIPS, consts.FLOATINGIP),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteFIP(id))
+		},
+		after: []string{consts.ROUTERINTERFACE},
+	},
+	consts.PORTFORWARDING: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreatePortForwardingOpts{}).AssignProps(props)
+		},
+		// a port forwarding to a server forwards to its port and address
+		receive: func(manager *Manager, opts interface{}, trans *Transmitter) error {
+			pf := opts.(*entity.CreatePortForwardingOpts)
+			return receiveServerPorts(manager, opts, trans, func(portId, ipAddr string) string {
+				if pf.InternalIPAddress == "" {
+					pf.InternalIPAddress = ipAddr
+				}
+				return portId
+			})
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			pf := opts.(*entity.CreatePortForwardingOpts)
+			return manager.CreatePortForwarding(pf.FloatingipID, pf)
+		},
+		request: "POST network /v2.0/floatingips/{floatingip_id}/port_forwardings",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			urlSuffix := fmt.Sprintf("%s/%s/%s/%s", consts.FLOATINGIPS, opts.(*entity.CreatePortForwardingOpts).FloatingipID, consts.PORTFORWARDINGS, id)
+			return manager.Neutron.GetAttributes(urlSuffix, consts.PORTFORWARDING)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeletePortForwarding(opts.(*entity.CreatePortForwardingOpts).FloatingipID, id))
+		},
+		after: []string{consts.ROUTERINTERFACE},
+	},
+	consts.SERVER: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateInstanceOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateInstance(opts.(*entity.CreateInstanceOpts))
+		},
+		request: "POST compute /v2.1/servers",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return manager.Nova.GetAttributes("servers/"+id, consts.SERVER)
+		},
+		updatable: []string{"name"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			return manager.Nova.SetAttributes("servers/"+id, consts.SERVER, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Nova.DeleteInstance(id))
+		},
+		// the subnets give the ports of the server their fixed ips
+		after: []string{consts.SECURITYGROUP, consts.SUBNET, consts.PORT},
+	},
+	consts.VOLUME: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateVolumeOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateVolumeByOpts(opts.(*entity.CreateVolumeOpts))
+		},
+		request: "POST volumev3 /v3/{project_id}/volumes",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return manager.Cinder.GetAttributes("volumes/"+id, consts.VOLUME)
+		},
+		updatable: []string{"name", "description"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			return manager.Cinder.SetAttributes("volumes/"+id, consts.VOLUME, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return ignoreNotFound(manager.Cinder.DeleteVolumeAndWait(id))
+		},
+	},
+	consts.SNAPSHOT: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateSnapshotOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateSnapshotByOpts(opts.(*entity.CreateSnapshotOpts))
+		},
+		request: "POST volumev3 /v3/{project_id}/snapshots",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return manager.Cinder.GetAttributes("snapshots/"+id, consts.SNAPSHOT)
+		},
+		updatable: []string{"name", "description"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			return manager.Cinder.SetAttributes("snapshots/"+id, consts.SNAPSHOT, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return ignoreNotFound(manager.Cinder.DeleteSnapshotAndWait(id))
+		},
+	},
+	consts.SECURITYGROUP: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateSecurityGroupOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateSecurityGroup(opts.(*entity.CreateSecurityGroupOpts))
+		},
+		request:   "POST network /v2.0/security-groups",
+		show:      neutronAttributes("security-groups", consts.SECURITYGROUP),
+		updatable: []string{"name", "description"},
+		update:    neutronUpdate("security-groups", consts.SECURITYGROUP),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteSecurityGroup(id))
+		},
+	},
+	consts.SECURITYGROUPRULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateSecurityRuleOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateSecurityRule(opts.(*entity.CreateSecurityRuleOpts))
+		},
+		request: "POST network /v2.0/security-group-rules",
+		show:    neutronAttributes("security-group-rules", consts.SECURITYGROUPRULE),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteSecurityGroupRule(id))
+		},
+	},
+	consts.QOS_POLICY: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateQosPolicyOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateQosPolicy(opts.(*entity.CreateQosPolicyOpts))
+		},
+		request:   "POST network /v2.0/qos/policies",
+		show:      neutronAttributes("qos/policies", consts.POLICY),
+		updatable: []string{"name", "description", "shared"},
+		update:    neutronUpdate("qos/policies", consts.POLICY),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteQos(id))
+		},
+	},
+	consts.BANDWIDTH_LIMIT_RULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateBandwidthLimitRuleOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			rule := opts.(*entity.CreateBandwidthLimitRuleOpts)
+			return manager.CreateQosRule(rule.QosPolicyId, consts.BANDWIDTH_LIMIT_RULE, rule.ToRequestBody())
+		},
+		request: "POST network /v2.0/qos/policies/{qos_policy_id}/bandwidth_limit_rules",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return qosRuleAttributes(manager, opts.(*entity.CreateBandwidthLimitRuleOpts).QosPolicyId, consts.BANDWIDTH_LIMIT_RULE, id)
+		},
+		updatable: []string{"max_kbps", "max_burst_kbps"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			urlSuffix := fmt.Sprintf("qos/policies/%s/%ss/%s", opts.(*entity.CreateBandwidthLimitRuleOpts).QosPolicyId, consts.BANDWIDTH_LIMIT_RULE, id)
+			return manager.Neutron.SetAttributes(urlSuffix, consts.BANDWIDTH_LIMIT_RULE, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			ruleType := strings.TrimSuffix(consts.BANDWIDTH_LIMIT_RULE, "_rule")
+			return outputErr(manager.Neutron.DeleteQosRule(ruleType, opts.(*entity.CreateBandwidthLimitRuleOpts).QosPolicyId, id))
+		},
+	},
+	consts.DSCP_MARKING_RULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateDscpMarkingRuleOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			rule := opts.(*entity.CreateDscpMarkingRuleOpts)
+			return manager.CreateQosRule(rule.QosPolicyId, consts.DSCP_MARKING_RULE, rule.ToRequestBody())
+		},
+		request: "POST network /v2.0/qos/policies/{qos_policy_id}/dscp_marking_rules",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return qosRuleAttributes(manager, opts.(*entity.CreateDscpMarkingRuleOpts).QosPolicyId, consts.DSCP_MARKING_RULE, id)
+		},
+		updatable: []string{"dscp_mark"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			urlSuffix := fmt.Sprintf("qos/policies/%s/%ss/%s", opts.(*entity.CreateDscpMarkingRuleOpts).QosPolicyId, consts.DSCP_MARKING_RULE, id)
+			return manager.Neutron.SetAttributes(urlSuffix, consts.DSCP_MARKING_RULE, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			ruleType := strings.TrimSuffix(consts.DSCP_MARKING_RULE, "_rule")
+			return outputErr(manager.Neutron.DeleteQosRule(ruleType, opts.(*entity.CreateDscpMarkingRuleOpts).QosPolicyId, id))
+		},
+	},
+	consts.MINIMUM_BANDWIDTH_RULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateMinimumBandwidthRuleOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			rule := opts.(*entity.CreateMinimumBandwidthRuleOpts)
+			return manager.CreateQosRule(rule.QosPolicyId, consts.MINIMUM_BANDWIDTH_RULE, rule.ToRequestBody())
+		},
+		request: "POST network /v2.0/qos/policies/{qos_policy_id}/minimum_bandwidth_rules",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			return qosRuleAttributes(manager, opts.(*entity.CreateMinimumBandwidthRuleOpts).QosPolicyId, consts.MINIMUM_BANDWIDTH_RULE, id)
+		},
+		updatable: []string{"min_kbps"},
+		update: func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			urlSuffix := fmt.Sprintf("qos/policies/%s/%ss/%s", opts.(*entity.CreateMinimumBandwidthRuleOpts).QosPolicyId, consts.MINIMUM_BANDWIDTH_RULE, id)
+			return manager.Neutron.SetAttributes(urlSuffix, consts.MINIMUM_BANDWIDTH_RULE, changed)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			ruleType := strings.TrimSuffix(consts.MINIMUM_BANDWIDTH_RULE, "_rule")
+			return outputErr(manager.Neutron.DeleteQosRule(ruleType, opts.(*entity.CreateMinimumBandwidthRuleOpts).QosPolicyId, id))
+		},
+	},
+	consts.FIREWALLRULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateFirewallRuleOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateFirewallRuleV1(opts.(*entity.CreateFirewallRuleOpts))
+		},
+		request:   "POST network /v2.0/fw/firewall_rules",
+		show:      neutronAttributes("fw/firewall_rules", consts.FIREWALLRULE),
+		updatable: []string{"name", "description", "protocol", "action", "source_ip_address", "destination_ip_address", "source_port", "destination_port", "enabled"},
+		update:    neutronUpdate("fw/firewall_rules", consts.FIREWALLRULE),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteFirewallRuleV1(id))
+		},
+	},
+	consts.FIREWALLPOLICY: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateFirewallPolicyOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateFirewallPolicyV1(opts.(*entity.CreateFirewallPolicyOpts))
+		},
+		request:   "POST network /v2.0/fw/firewall_policies",
+		show:      neutronAttributes("fw/firewall_policies", consts.FIREWALLPOLICY),
+		updatable: []string{"name", "description"},
+		update:    neutronUpdate("fw/firewall_policies", consts.FIREWALLPOLICY),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteFirewallPolicyV1(id))
+		},
+	},
+	consts.FIREWALL: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateFirewallOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateFirewallV1(opts.(*entity.CreateFirewallOpts))
+		},
+		request:   "POST network /v2.0/fw/firewalls",
+		show:      neutronAttributes("fw/firewalls", consts.FIREWALL),
+		updatable: []string{"name", "description", "admin_state_up"},
+		update:    neutronUpdate("fw/firewalls", consts.FIREWALL),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteFirewallV1(id))
+		},
+	},
+	consts.VpcConnection: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateVpcConnectionOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateVpcConnection(opts.(*entity.CreateVpcConnectionOpts))
+		},
+		request:   "POST network /v2.0/vpc-connections",
+		show:      neutronAttributes("vpc-connections", consts.VpcConnection),
+		updatable: []string{"name"},
+		update:    neutronUpdate("vpc-connections", consts.VpcConnection),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteVpcConnection(id))
+		},
+		after: []string{consts.ROUTERINTERFACE, consts.FIREWALL},
+	},
+	consts.VpnService: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateVpnServiceOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateVpnService(opts.(*entity.CreateVpnServiceOpts))
+		},
+		request:   "POST network /v2.0/vpn/vpnservices",
+		show:      neutronAttributes("vpn/vpnservices", consts.VPNSERVICE),
+		updatable: []string{"name", "description", "admin_state_up"},
+		update:    neutronUpdate("vpn/vpnservices", consts.VPNSERVICE),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteVpnService(id))
+		},
+		after: []string{consts.ROUTERINTERFACE},
+	},
+	consts.EndpointGroup: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateEndpointGroupOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateEndpointGroup(opts.(*entity.CreateEndpointGroupOpts))
+		},
+		request:   "POST network /v2.0/vpn/endpoint-groups",
+		show:      neutronAttributes("vpn/endpoint-groups", consts.ENDPOINTGROUP),
+		updatable: []string{"name", "description"},
+		update:    neutronUpdate("vpn/endpoint-groups", consts.ENDPOINTGROUP),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteEndpointGroup(id))
+		},
+	},
+	consts.IkePolicy: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			opts := entity.DefaultIkePolicyOpts()
+			opts.Name = name
+			return opts.AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateIkePolicy(opts.(*entity.CreateIkePolicyOpts))
+		},
+		request:   "POST network /v2.0/vpn/ikepolicies",
+		show:      neutronAttributes("vpn/ikepolicies", consts.IKEPOLICY),
+		updatable: []string{"name", "description"},
+		update:    neutronUpdate("vpn/ikepolicies", consts.IKEPOLICY),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteIkePolicy(id))
+		},
+	},
+	consts.IpsecPolicy: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			opts := entity.DefaultIpsecPolicyOpts()
+			opts.Name = name
+			return opts.AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateIpsecPolicy(opts.(*entity.CreateIpsecPolicyOpts))
+		},
+		request:   "POST network /v2.0/vpn/ipsecpolicies",
+		show:      neutronAttributes("vpn/ipsecpolicies", consts.IPSECPOLICY),
+		updatable: []string{"name", "description"},
+		update:    neutronUpdate("vpn/ipsecpolicies", consts.IPSECPOLICY),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteIpsecPolicy(id))
+		},
+	},
+	consts.IpsecConnection: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			opts := entity.DefaultIpsecConnectionOpts()
+			opts.Name = name
+			return opts.AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateIpsecConnection(opts.(*entity.CreateIpsecConnectionOpts))
+		},
+		request:   "POST network /v2.0/vpn/ipsec-site-connections",
+		show:      neutronAttributes("vpn/ipsec-site-connections", consts.IPSECCONNECTION),
+		updatable: []string{"name", "description", "psk", "mtu", "initiator", "admin_state_up"},
+		update:    neutronUpdate("vpn/ipsec-site-connections", consts.IPSECCONNECTION),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Neutron.DeleteIpsecConnection(id))
+		},
+		after: []string{consts.ROUTERINTERFACE},
+	},
+	consts.LOADBALANCER: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateLoadbalancerOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateLoadbalancer(*opts.(*entity.CreateLoadbalancerOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/loadbalancers",
+		show:    octaviaAttributes("lbaas/loadbalancers", consts.LOADBALANCER),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			return outputErr(manager.Octavia.DeleteLoadbalancer(id))
+		},
+	},
+	consts.LISTENER: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateListenerOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateListener(*opts.(*entity.CreateListenerOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/listeners",
+		show:    octaviaAttributes("lbaas/listeners", consts.LISTENER),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			urlSuffix := "lbaas/listeners/" + id
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(urlSuffix, urlSuffix, consts.LISTENER))
+		},
+	},
+	consts.POOL: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreatePoolOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreatePool(*opts.(*entity.CreatePoolOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/pools",
+		show:    octaviaAttributes("lbaas/pools", consts.POOL),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			urlSuffix := "lbaas/pools/" + id
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(urlSuffix, urlSuffix, consts.POOL))
+		},
+	},
+	consts.MEMBER: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateMemberOpts{Name: name}).AssignProps(props)
+		},
+		// a member naming a server balances to the address of the server
+		receive: func(manager *Manager, opts interface{}, trans *Transmitter) error {
+			return receiveServerPorts(manager, opts, trans, func(portId, ipAddr string) string {
+				return ipAddr
+			})
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			member := opts.(*entity.CreateMemberOpts)
+			return manager.CreatePoolMember(member.PoolID, *member)
+		},
+		request: "POST load-balancer /v2.0/lbaas/pools/{pool_id}/members",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			urlSuffix := fmt.Sprintf("lbaas/pools/%s/members/%s", opts.(*entity.CreateMemberOpts).PoolID, id)
+			return manager.Octavia.GetAttributes(urlSuffix, consts.MEMBER)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			poolSuffix := "lbaas/pools/" + opts.(*entity.CreateMemberOpts).PoolID
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(poolSuffix+"/members/"+id, poolSuffix, consts.POOL))
+		},
+	},
+	consts.HEALTHMONITOR: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateHealthMonitorOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateHealthMonitor(*opts.(*entity.CreateHealthMonitorOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/healthmonitors",
+		show:    octaviaAttributes("lbaas/healthmonitors", consts.HEALTHMONITOR),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			urlSuffix := "lbaas/healthmonitors/" + id
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(urlSuffix, urlSuffix, consts.HEALTHMONITOR))
+		},
+	},
+	consts.L7POLICY: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateL7PoliciesOpts{Name: name}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateL7Policy(*opts.(*entity.CreateL7PoliciesOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/l7policies",
+		show:    octaviaAttributes("lbaas/l7policies", consts.L7POLICY),
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			urlSuffix := "lbaas/l7policies/" + id
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(urlSuffix, urlSuffix, consts.L7POLICY))
+		},
+	},
+	consts.L7RULE: {
+		resolve: func(name string, props map[string]interface{}) (interface{}, map[string]string) {
+			return (&entity.CreateRuleOpts{}).AssignProps(props)
+		},
+		create: func(manager *Manager, opts interface{}) (string, error) {
+			return manager.CreateL7Rule(*opts.(*entity.CreateRuleOpts))
+		},
+		request: "POST load-balancer /v2.0/lbaas/l7policies/{l7policy_id}/rules",
+		show: func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+			urlSuffix := fmt.Sprintf("lbaas/l7policies/%s/rules/%s", opts.(*entity.CreateRuleOpts).PolicyID, id)
+			return manager.Octavia.GetAttributes(urlSuffix, consts.RULE)
+		},
+		delete: func(manager *Manager, opts interface{}, id string) error {
+			policySuffix := "lbaas/l7policies/" + opts.(*entity.CreateRuleOpts).PolicyID
+			return ignoreNotFound(manager.Octavia.DeleteLbChild(policySuffix+"/rules/"+id, policySuffix, consts.L7POLICY))
+		},
+	},
+}
+
+func neutronAttributes(collection, key string) func(*Manager, interface{}, string) (map[string]interface{}, error) {
+	return func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+		return manager.Neutron.GetAttributes(collection+"/"+id, key)
+	}
+}
+
+func octaviaAttributes(collection, key string) func(*Manager, interface{}, string) (map[string]interface{}, error) {
+	return func(manager *Manager, opts interface{}, id string) (map[string]interface{}, error) {
+		return manager.Octavia.GetAttributes(collection+"/"+id, key)
+	}
+}
+
+func neutronUpdate(collection, key string) func(*Manager, interface{}, string, map[string]interface{}) error {
+	return func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+		return manager.Neutron.SetAttributes(collection+"/"+id, key, changed)
+	}
+}
+
+// outputErr returns the error a delete call recorded in its output, a
+// resource that is already gone counts as deleted.
+func outputErr(out internal.Output) error {
+	if out.Success {
+		return nil
+	}
+	if err, ok := out.Response.(error); ok {
+		return ignoreNotFound(err)
+	}
+	return fmt.Errorf("%v", out.Response)
+}
+
+func ignoreNotFound(err error) error {
+	if internal.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func qosRuleAttributes(manager *Manager, qosId, ruleType, id string) (map[string]interface{}, error) {
+	return manager.Neutron.GetAttributes(fmt.Sprintf("qos/policies/%s/%ss/%s", qosId, ruleType, id), ruleType)
+}
+
+// applyTransmitter sets the id of every created dependency on the field
+// path AssignProps recorded for it.
+func applyTransmitter(manager *Manager, opts interface{}, trans *Transmitter) error {
+	for path, value := range trans.Data {
+		if err := setFieldPath(opts, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiveServerPorts applies trans like applyTransmitter, but looks up the
+// port of the dependencies that are servers and sets what portValue picks
+// of it, the port id or its ip address.
+func receiveServerPorts(manager *Manager, opts interface{}, trans *Transmitter, portValue func(portId, ipAddr string) string) error {
+	for path, value := range trans.Data {
+		if trans.Types[path] == consts.SERVER {
+			portId, ipAddr, err := manager.GetInstancePort(value)
+			if err != nil {
+				return err
+			}
+			value = portValue(portId, ipAddr)
+		}
+		if err := setFieldPath(opts, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFieldPath sets the string field at path, e.g. "SubnetID",
+// "RouterIDs[1]" or "FixedIp[0]/SubnetId", of the struct opts points to.
+func setFieldPath(opts interface{}, path, value string) error {
+	field := reflect.ValueOf(opts).Elem()
+	for _, segment := range strings.Split(path, "/") {
+		name, index := segment, -1
+		if i := strings.Index(segment, "["); i > 0 && strings.HasSuffix(segment, "]") {
+			n, err := strconv.Atoi(segment[i+1 : len(segment)-1])
+			if err != nil {
+				return fmt.Errorf("invalid field path %s", path)
+			}
+			name, index = segment[:i], n
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("invalid field path %s", path)
+		}
+		field = field.FieldByName(name)
+		if !field.IsValid() {
+			return fmt.Errorf("no field %s in %s", name, path)
+		}
+		if index >= 0 {
+			if field.Kind() != reflect.Slice || index >= field.Len() {
+				return fmt.Errorf("invalid field path %s", path)
+			}
+			field = field.Index(index)
+		}
+	}
+	if field.Kind() != reflect.String {
+		return fmt.Errorf("field %s is not a string", path)
+	}
+	field.SetString(value)
+	return nil
+}
@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"request_openstack/consts"
+	"request_openstack/internal"
+	"request_openstack/internal/entity"
+	"testing"
+)
+
+func TestRouterInterfaceDelete(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	manager := &Manager{Neutron: internal.NewNeutron(internal.WithEndpoint(server.URL+"/", nil))}
+
+	kind := resourceTypes[consts.ROUTERINTERFACE]
+	tests := []struct {
+		name  string
+		props map[string]interface{}
+		deps  map[string]string
+		body  string
+	}{
+		{"by subnet", map[string]interface{}{"router_id": "router", "subnet_id": "subnet"}, map[string]string{"router": "RouterId", "subnet": "SubnetID"}, `{"subnet_id": "s"}`},
+		{"by port", map[string]interface{}{"router_id": "router", "port_id": "port"}, map[string]string{"router": "RouterId", "port": "PortID"}, `{"port_id": "p"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, deps := kind.resolve("ri", tt.props)
+			if !reflect.DeepEqual(deps, tt.deps) {
+				t.Fatalf("dependencies %v, want %v", deps, tt.deps)
+			}
+			// the ids of the router and the subnet or port are transmitted
+			ids := reflect.ValueOf(opts.(*entity.AddRouterInterfaceOpts)).Elem()
+			for name, field := range deps {
+				ids.FieldByName(field).SetString(name[:1])
+			}
+			if err := kind.delete(manager, opts, "r"); err != nil {
+				t.Fatal(err)
+			}
+			if path != "/routers/r/remove_router_interface" || body != tt.body {
+				t.Fatalf("removed with %s %s, want %s", path, body, tt.body)
+			}
+		})
+	}
+}
@@ -4,31 +4,36 @@ import (
     "fmt"
     "log"
     "reflect"
-    "request_openstack/consts"
-    "request_openstack/internal/entity"
-    "strings"
     "sync"
 )
 
 var (
-	ResourcesMap      = make(map[string]Resource)
 	RW                = sync.RWMutex{}
 
     netDoneChannel    chan struct{}
 )
 
+// Resource is a resource of a template. Dependencies maps the resources it
+// depends on to the field path of PropsObj receiving their id, "" when the
+// dependency comes from an intrinsic function in Props.
 type Resource struct {
     Name               string
     Type               string
     PropsObj           interface{}
+    Props              map[string]interface{}
     Dependencies       map[string]string
     Done               bool
 }
 
+// Output is how the creation of a resource ended, Resp is its id or why it
+// failed. Id is also kept when the resource was created but its create
+// failed after, e.g. a server that did not become ACTIVE, so that it can be
+// deleted.
 type Output struct {
     Type                  string
     IsSuccess             bool
     Resp                  string
+    Id                    string
 }
 
 func NewResource(name, typ string, propsObj interface{}, deps map[string]string) Resource {
@@ -41,73 +46,73 @@ func NewResource(name, typ string, propsObj interface{}, deps map[string]string)
     }
 }
 
-func (r Resource) Create(manager *Manager, completedOuts *sync.Map, trans *Transmitter, wg *sync.WaitGroup) {
+// Create creates the resource and stores its Output in the completed outputs
+// of resolver, trans carries the ids of its dependencies.
+func (r Resource) Create(resolver stackResolver, trans *Transmitter) {
+    manager, completedOuts := resolver.manager, resolver.completedOuts
     log.Println("##############Creating", r.Name, r.Type)
     var out Output
+    var err error
     defer func() {
-        if err := recover(); err != nil {
+        if p := recover(); p != nil {
             out.Type = r.Name
             out.IsSuccess = false
-            out.Resp = fmt.Sprintf("panic err %s", err)
+            out.Resp = fmt.Sprintf("panic err %s", p)
+        } else if err != nil {
+            out.IsSuccess = false
+            out.Resp = fmt.Sprintf("error %s", err)
         }
         completedOuts.Store(r.Name, out)
-        wg.Done()
         log.Println(fmt.Sprintf("##############Create resource " +
             "%s resp ---> %s", out.Type, out.Resp))
     }()
     out.Type = r.Name
     out.IsSuccess = true
-    switch r.Type {
-    case consts.NETWORK:
-        opts := r.PropsObj.(*entity.CreateNetworkOpts)
-        out.Resp = manager.CreateNetwork(opts)
-    case consts.SUBNET:
-        opts := r.PropsObj.(*entity.CreateSubnetOpts)
-        if trans != nil {
-            for key, value := range trans.Data {
-                reflect.ValueOf(opts).Elem().FieldByName(key).SetString(value)
-            }
+    kind, ok := resourceTypes[r.Type]
+    if !ok {
+        err = fmt.Errorf("unsupported resource type %s", r.Type)
+        return
+    }
+    if r.Props != nil {
+        resolver := transmittedResolver{stackResolver: resolver, dependencies: r.Dependencies}
+        if err = r.resolveProps(kind, resolver, trans); err != nil {
+            return
         }
-        out.Resp = manager.CreateSubnet(opts)
-    case consts.ROUTER:
-        opts := r.PropsObj.(*entity.CreateRouterOpts)
-        out.Resp = manager.CreateRouter(opts)
-    case consts.ROUTERINTERFACE:
-        opts := r.PropsObj.(*entity.AddRouterInterfaceOpts)
-        if trans != nil {
-            for key, value := range trans.Data {
-                reflect.ValueOf(opts).Elem().FieldByName(key).SetString(value)
-            }
+    }
+    if trans != nil {
+        receive := kind.receive
+        if receive == nil {
+            receive = applyTransmitter
         }
-        out.Resp = manager.AddRouterInterface(opts)
-    case consts.FLOATINGIP:
-        opts := r.PropsObj.(*entity.CreateFipOpts)
-        if trans != nil {
-            for key, value := range trans.Data {
-                if trans.Type == consts.SERVER {
-                    value, _ = manager.GetInstancePort(value)
-                }
-                reflect.ValueOf(opts).Elem().FieldByName(key).SetString(value)
-            }
+        if err = receive(manager, r.PropsObj, trans); err != nil {
+            return
         }
-        out.Resp = manager.CreateFloatingIP(opts)
-    case consts.SERVER:
-        opts := r.PropsObj.(*entity.CreateInstanceOpts)
-        if trans != nil {
-            for key, value := range trans.Data {
-                if strings.Contains(key, "/") {
-                    field := strings.Split(key, "/")
-                    firstField := field[0]
-                    if firstField == "Networks" {
-                        objSlice := make([]entity.ServerNet, 0)
-                        obj := entity.ServerNet{}
-                        reflect.ValueOf(&obj).Elem().FieldByName(field[1]).SetString(value)
-                        objSlice = append(objSlice, obj)
-                        reflect.ValueOf(opts).Elem().FieldByName(firstField).Set(reflect.ValueOf(objSlice))
-                    }
-                }
-            }
+    }
+    out.Id, err = kind.create(manager, r.PropsObj)
+    out.Resp = out.Id
+}
+
+// resolveProps rebuilds PropsObj from Props with the intrinsic functions
+// resolved, only the dependencies still named by the rebuilt opts are left
+// in trans for receive.
+func (r Resource) resolveProps(kind resourceType, resolver intrinsicResolver, trans *Transmitter) error {
+    props, err := resolveIntrinsics(r.Props, resolver)
+    if err != nil {
+        return err
+    }
+    optsObj, dependencies := kind.resolve(r.Name, props.(map[string]interface{}))
+    reflect.ValueOf(r.PropsObj).Elem().Set(reflect.ValueOf(optsObj).Elem())
+    if trans == nil {
+        return nil
+    }
+    paths := make(map[string]bool)
+    for _, path := range dependencies {
+        paths[path] = true
+    }
+    for path := range trans.Data {
+        if !paths[path] {
+            delete(trans.Data, path)
         }
-        out.Resp = manager.CreateInstance(opts)
     }
+    return nil
 }
@@ -1,90 +1,225 @@
 package manager
 
 import (
+    "context"
+    "encoding/json"
+    "errors"
     "fmt"
+    "io/ioutil"
     "log"
     "request_openstack/configs"
+    "strings"
     "sync"
+    "time"
 )
 
+// Transmitter carries the ids of the created dependencies of a resource,
+// Data and Types are keyed by the field path AssignProps recorded.
 type Transmitter struct {
     Type               string
     Data               map[string]string
+    Types              map[string]string
+}
+
+// Statuses of the resources of a scheduler run.
+const (
+    StatusPending = "pending"
+    StatusCreated = "created"
+    StatusFailed  = "failed"
+)
+
+// ResourceResult is how the creation of a resource ended, Duration is the
+// time its create took, 0 when it was not tried for a failed dependency.
+type ResourceResult struct {
+    Status             string
+    Duration           time.Duration
+    Error              string
 }
 
 type Scheduler struct {
     Manager                 *Manager
+    // resources are the resources of the template the scheduler creates
+    resources               map[string]Resource
+    // OutputsFile receives the resolved outputs of the template as json
+    OutputsFile             string
+    // Workers bounds the resources created at a time, 0 for no bound
+    Workers                 int
     completedOuts           sync.Map
-    nodes                   map[string]*Node
-    completedChannel        chan struct{}
+    outputs                 map[string]interface{}
+    // existing are the ids of resources created before, e.g. by an earlier
+    // run of a stack, they are not created again
+    existing                map[string]string
+    // onCompleted is called with the output of every resource created
+    onCompleted             func(name string, out Output)
+    started                 sync.Map
+    results                 map[string]ResourceResult
+}
+
+func NewScheduler(yamlFile string, params map[string]interface{}) (*Scheduler, error) {
+    template, err := LoadTemplate(yamlFile, params)
+    if err != nil {
+        return nil, err
+    }
+    adminManager, err := NewManager()
+    if err != nil {
+        return nil, err
+    }
+    if err := adminManager.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+        return nil, err
+    }
+    return newScheduler(adminManager, template), nil
 }
 
-func NewScheduler(yamlFile string) *Scheduler {
-    resources := yamlToMap(yamlFile)
-    ResourcesMap = Resolve(resources)
-    adminManager := NewManager()
-    nodes := nodeAssociateResources(InitNodes())
-    scheduler := &Scheduler{
-        Manager: adminManager,
-        nodes: nodes,
-        completedChannel: make(chan struct{}, len(nodes)),
-    }
-    adminManager.EnsureSgExist(configs.CONF.ProjectName)
-    return scheduler
+// newScheduler returns a scheduler creating the resources of template, a
+// scheduler runs once.
+func newScheduler(manager *Manager, template *Template) *Scheduler {
+    return &Scheduler{
+        Manager: manager,
+        resources: template.Resources,
+        OutputsFile: time.Now().Format("2006-01-02_15-04-05") + "_outputs.json",
+        outputs: template.Outputs,
+    }
+}
+
+// resolver resolves get_resource and get_attr against the resources the
+// scheduler created.
+func (s *Scheduler) resolver() stackResolver {
+    return stackResolver{manager: s.Manager, resources: s.resources, completedOuts: &s.completedOuts}
 }
 
-func (s *Scheduler) waitDepsThenCall(resource string, wg *sync.WaitGroup) {
-    var trans Transmitter
-    trans.Data = make(map[string]string)
-    for dep, fieldName := range ResourcesMap[resource].Dependencies {
-        out, ok := s.completedOuts.Load(dep)
-        for !ok {
-            out, ok = s.completedOuts.Load(dep)
+// graph returns the resources to create as a DAG. A resource is created
+// after the resources it depends on, a failed dependency fails it without
+// creating it.
+func (s *Scheduler) graph() *DAG {
+    dag := NewDAG(s.Workers)
+    for name, resource := range s.resources {
+        if _, ok := s.existing[name]; ok {
+            continue
         }
-        if out.(Output).IsSuccess {
-            trans.Type = ResourcesMap[dep].Type
-            trans.Data[fieldName] = out.(Output).Resp
-        } else {
-            errorInfo := fmt.Sprintf("The dependency %s of resource %s call failed", dep, resource)
-            s.completedOuts.Store(dep, Output{Type: resource, IsSuccess: false, Resp: errorInfo})
-            return
+        deps := make([]string, 0, len(resource.Dependencies))
+        for dep := range resource.Dependencies {
+            if _, ok := s.existing[dep]; !ok {
+                deps = append(deps, dep)
+            }
         }
+        dag.Add(name, deps, s.creator(name))
     }
-    ResourcesMap[resource].Create(s.Manager, &s.completedOuts, &trans, wg)
+    dag.OnDone = func(name string, err error) {
+        if err != nil {
+            // a resource skipped for a failed dependency or a done ctx
+            s.completedOuts.LoadOrStore(name, Output{Type: name, IsSuccess: false, Resp: err.Error()})
+        }
+        out, _ := s.completedOuts.Load(name)
+        result := ResourceResult{Status: StatusCreated}
+        if err != nil {
+            result = ResourceResult{Status: StatusFailed, Error: err.Error()}
+        }
+        if start, ok := s.started.Load(name); ok {
+            result.Duration = time.Since(start.(time.Time))
+        }
+        s.results[name] = result
+        if s.onCompleted != nil {
+            s.onCompleted(name, out.(Output))
+        }
+    }
+    return dag
 }
 
-func (s *Scheduler) notify(node *Node) {
-    if len(node.monitorCreateChannel) != cap(node.monitorCreateChannel) {
-        for len(node.monitorCreateChannel) != cap(node.monitorCreateChannel) {}
-    }
-    var wg sync.WaitGroup
-    for _, resourceName := range node.resources {
-        wg.Add(1)
-        if len(ResourcesMap[resourceName].Dependencies) == 0 {
-            go ResourcesMap[resourceName].Create(s.Manager, &s.completedOuts, nil, &wg)
-        } else {
-            go s.waitDepsThenCall(resourceName, &wg)
+// creator returns the node creating the resource name, its dependencies
+// are created by the time it runs.
+func (s *Scheduler) creator(name string) func(ctx context.Context) error {
+    return func(ctx context.Context) error {
+        s.started.Store(name, time.Now())
+        resource := s.resources[name]
+        var trans *Transmitter
+        if len(resource.Dependencies) > 0 {
+            trans = &Transmitter{Data: make(map[string]string), Types: make(map[string]string)}
+            for dep, fieldName := range resource.Dependencies {
+                out, _ := s.completedOuts.Load(dep)
+                if fieldName != "" {
+                    trans.Type = s.resources[dep].Type
+                    trans.Data[fieldName] = out.(Output).Resp
+                    trans.Types[fieldName] = s.resources[dep].Type
+                }
+            }
         }
+        resource.Create(s.resolver(), trans)
+        out, _ := s.completedOuts.Load(name)
+        if !out.(Output).IsSuccess {
+            return errors.New(out.(Output).Resp)
+        }
+        return nil
     }
-    wg.Wait()
-    for _, dep := range node.requiredBy {
-        s.nodes[dep.resourceType].monitorCreateChannel <- struct{}{}
-        log.Printf("%s completed, notify %s", node.resourceType, dep.resourceType)
-    }
-    s.completedChannel <- struct{}{}
-    log.Printf("%s call completed", node.resourceType)
 }
 
-func (s *Scheduler) call() {
-    for _, node := range s.nodes {
-        go s.notify(node)
+func (s *Scheduler) Run() {
+    _ = s.RunContext(context.Background())
+}
+
+// RunContext runs the scheduler with ctx bound to its manager, once ctx is
+// done the remaining resources fail fast instead of waiting on OpenStack.
+func (s *Scheduler) RunContext(ctx context.Context) error {
+    s.Manager.SetContext(ctx)
+    s.results = make(map[string]ResourceResult, len(s.resources))
+    for name, id := range s.existing {
+        s.completedOuts.Store(name, Output{Type: name, IsSuccess: true, Resp: id, Id: id})
     }
-    if len(s.completedChannel) != cap(s.completedChannel) {
-        for len(s.completedChannel) != cap(s.completedChannel) {}
+    if _, err := s.graph().Run(ctx); err != nil {
+        return err
     }
     log.Println("##############Completed##############")
+    if err := s.exportOutputs(); err != nil {
+        log.Println("##############Export outputs failed", err)
+    }
+    return ctx.Err()
 }
 
-func (s *Scheduler) Run() {
-    s.call()
+// Results returns how the creation of each resource of the last run ended,
+// the resources created before it are left out.
+func (s *Scheduler) Results() map[string]ResourceResult {
+    return s.results
+}
+
+// Outputs resolves the outputs of the template against the created
+// resources, an output that cannot be resolved is left out and reported.
+func (s *Scheduler) Outputs() (map[string]interface{}, error) {
+    resolver := s.resolver()
+    res := make(map[string]interface{}, len(s.outputs))
+    var errs []string
+    for name, output := range s.outputs {
+        var value interface{} = output
+        if o, ok := output.(map[string]interface{}); ok {
+            value = o["value"]
+        }
+        resolved, err := resolveIntrinsics(value, resolver)
+        if err != nil {
+            errs = append(errs, fmt.Sprintf("output %s: %s", name, err))
+            continue
+        }
+        res[name] = resolved
+    }
+    if len(errs) > 0 {
+        return res, fmt.Errorf("%s", strings.Join(errs, "; "))
+    }
+    return res, nil
+}
+
+// exportOutputs prints the outputs and writes them to OutputsFile.
+func (s *Scheduler) exportOutputs() error {
+    if len(s.outputs) == 0 {
+        return nil
+    }
+    outputs, resolveErr := s.Outputs()
+    for name, value := range outputs {
+        log.Printf("==============Output %s: %v", name, value)
+    }
+    data, err := json.MarshalIndent(outputs, "", "  ")
+    if err != nil {
+        return err
+    }
+    if err = ioutil.WriteFile(s.OutputsFile, data, 0644); err != nil {
+        return err
+    }
+    log.Println("==============Export outputs to json file success", s.OutputsFile)
+    return resolveErr
 }
\ No newline at end of file
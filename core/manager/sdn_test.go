@@ -11,18 +11,36 @@ import (
 
 var (
     manager *Manager
+    // managerErr is why there is no manager, the tests against OpenStack are
+    // skipped then so that the other tests of the package still run
+    managerErr error
 )
 
 
 func init() {
     configs.Viper()
-    manager = NewManager()
+    manager, managerErr = NewManager()
+}
+
+// requireManager skips a test against OpenStack when it is not reachable.
+func requireManager(t *testing.T) {
+    t.Helper()
+    if managerErr != nil {
+        t.Skip("no OpenStack to test against:", managerErr)
+    }
 }
 
 // TestCreateQosPolicy 1. test qos
 func TestCreateQosPolicy(t *testing.T) {
-    qosId := manager.CreateQosAndRule()
-    qos := manager.GetQos(qosId)
+    requireManager(t)
+    qosId, err := manager.CreateQosAndRule()
+    if err != nil {
+        t.Fatal(err)
+    }
+    qos, err := manager.GetQos(qosId)
+    if err != nil {
+        t.Fatal(err)
+    }
 
     if len(qos.Rules) != 3 {
         t.Fatal("Create qos policy and rule failed")
@@ -34,8 +52,15 @@ func TestCreateQosPolicy(t *testing.T) {
 
 // TestCreateNetwork 2. test network
 func TestCreateNetwork(t *testing.T) {
-    netId := manager.CreateNetworkHelper()
-    network := manager.GetNetwork(netId)
+    requireManager(t)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    network, err := manager.GetNetwork(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if network.Name != DefaultName + "_" + consts.NETWORK || network.Description != DefaultName {
         t.Fatal("Create network failed")
     }
@@ -46,12 +71,21 @@ func TestCreateNetwork(t *testing.T) {
 
 // TestUpdateNetwork 3. test update network
 func TestUpdateNetwork(t *testing.T) {
-    netId := manager.CreateNetworkHelper()
+    requireManager(t)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
     updateName := "test_update_network"
     updateBody := fmt.Sprintf("{\"network\": {\"name\": \"%+v\"}}", updateName)
-    manager.UpdateNetwork(netId, updateBody)
+    if err := manager.UpdateNetwork(netId, updateBody); err != nil {
+        t.Fatal(err)
+    }
 
-    network := manager.GetNetwork(netId)
+    network, err := manager.GetNetwork(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if network.Name != updateName {
         t.Fatal("Update network failed")
     }
@@ -63,7 +97,11 @@ func TestUpdateNetwork(t *testing.T) {
 
 // TestCreateSubnet 4. test subnet
 func TestCreateSubnet(t *testing.T) {
-    netId := manager.CreateNetworkHelper()
+    requireManager(t)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
 
     gatewayIp1 := "11.11.11.1"
     subnetOpts := &entity.CreateSubnetOpts{
@@ -73,8 +111,14 @@ func TestCreateSubnet(t *testing.T) {
         GatewayIP: &gatewayIp1,
         DNSNameservers: []string{"114.114.114.114"},
     }
-    subnetId := manager.CreateSubnet(subnetOpts)
-    subnet := manager.GetSubnet(subnetId)
+    subnetId, err := manager.CreateSubnet(subnetOpts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    subnet, err := manager.GetSubnet(subnetId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if subnet.Subnet.Cidr != "11.11.11.0/24" || subnet.Subnet.GatewayIp != "11.11.11.1" ||
         subnet.Subnet.AllocationPools[0].Start != "11.11.11.2" ||
         subnet.Subnet.AllocationPools[0].End != "11.11.11.254" ||
@@ -90,8 +134,14 @@ func TestCreateSubnet(t *testing.T) {
         GatewayIP: &gatewayIp2,
         DNSNameservers: []string{"114.114.114.114"},
     }
-    subnetId2 := manager.CreateSubnet(subnetOpts2)
-    subnet2 := manager.GetSubnet(subnetId2)
+    subnetId2, err := manager.CreateSubnet(subnetOpts2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    subnet2, err := manager.GetSubnet(subnetId2)
+    if err != nil {
+        t.Fatal(err)
+    }
     if subnet2.Subnet.Cidr != "22.22.22.0/24" || subnet2.Subnet.GatewayIp != "22.22.22.1" ||
         subnet2.Subnet.AllocationPools[0].Start != "22.22.22.2" ||
         subnet2.Subnet.AllocationPools[0].End != "22.22.22.254" ||
@@ -105,11 +155,20 @@ func TestCreateSubnet(t *testing.T) {
 
 // TestCreateRouter 5. test router
 func TestCreateRouter(t *testing.T) {
-    routerId := manager.CreateRouterHelper()
-    manager.SetDefaultRouterGatewayHelper(routerId)
-    router := manager.GetRouter(routerId)
+    requireManager(t)
+    routerId, err := manager.CreateRouterHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.SetDefaultRouterGatewayHelper(routerId); err != nil {
+        t.Fatal(err)
+    }
+    router, err := manager.GetRouter(routerId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if router.Name != DefaultName + "_" + consts.ROUTER || router.Description != DefaultName ||
-        router.ExternalGatewayInfo.NetworkId != configs.CONF.ExternalNetwork {
+        router.GatewayInfo.NetworkID != configs.CONF.ExternalNetwork {
         t.Fatal("Create router failed")
     }
     t.Cleanup(func() {
@@ -119,9 +178,18 @@ func TestCreateRouter(t *testing.T) {
 
 // TestAddRemoveRouterInterface 6. test router interface and snat
 func TestAddRemoveRouterInterface(t *testing.T) {
-    routerId := manager.CreateRouterHelper()
-    manager.SetDefaultRouterGatewayHelper(routerId)
-    netId := manager.CreateNetworkHelper()
+    requireManager(t)
+    routerId, err := manager.CreateRouterHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.SetDefaultRouterGatewayHelper(routerId); err != nil {
+        t.Fatal(err)
+    }
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
 
     gatewayIp1 := "100.100.100.1"
     subnetOpts := &entity.CreateSubnetOpts{
@@ -131,13 +199,21 @@ func TestAddRemoveRouterInterface(t *testing.T) {
         GatewayIP: &gatewayIp1,
         DNSNameservers: []string{"114.114.114.114"},
     }
-    subnetId := manager.CreateSubnet(subnetOpts)
+    subnetId, err := manager.CreateSubnet(subnetOpts)
+    if err != nil {
+        t.Fatal(err)
+    }
 
     addInterfaceOpts := &entity.AddRouterInterfaceOpts{
         SubnetID: subnetId, RouterId: routerId,
     }
-    manager.AddRouterInterface(addInterfaceOpts)
-    port := manager.GetPortByDevice(routerId, consts.NETWORKROUTERINTERFACE)
+    if _, err := manager.AddRouterInterface(addInterfaceOpts); err != nil {
+        t.Fatal(err)
+    }
+    port, err := manager.GetPortByDevice(routerId, consts.NETWORKROUTERINTERFACE)
+    if err != nil {
+        t.Fatal(err)
+    }
     if port == nil || port.DeviceId != routerId ||
         port.DeviceOwner != consts.NETWORKROUTERINTERFACE ||
         port.FixedIps[0].IpAddress != gatewayIp1 {
@@ -145,7 +221,10 @@ func TestAddRemoveRouterInterface(t *testing.T) {
     }
 
     manager.RemoveRouterInterface(routerId, subnetId)
-    port = manager.GetPortByDevice(routerId, consts.NETWORKROUTERINTERFACE)
+    port, err = manager.GetPortByDevice(routerId, consts.NETWORKROUTERINTERFACE)
+    if err != nil {
+        t.Fatal(err)
+    }
     if port != nil {
         t.Fatal("Remove router interface failed")
     }
@@ -157,32 +236,71 @@ func TestAddRemoveRouterInterface(t *testing.T) {
 
 // TestCreateFip 7. test floating ip and fip qos limit
 func TestCreateFip(t *testing.T) {
-    routerId := manager.CreateRouterHelper()
-    manager.SetDefaultRouterGatewayHelper(routerId)
+    requireManager(t)
+    routerId, err := manager.CreateRouterHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.SetDefaultRouterGatewayHelper(routerId); err != nil {
+        t.Fatal(err)
+    }
     fipOpts := &entity.CreateFipOpts{
         FloatingNetworkID: configs.CONF.ExternalNetwork,
     }
-    fipId := manager.CreateFloatingIP(fipOpts)
-    port := manager.GetPortByDevice(fipId, consts.NETWORKFLOATINGIP)
+    fipId, err := manager.CreateFloatingIP(fipOpts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    port, err := manager.GetPortByDevice(fipId, consts.NETWORKFLOATINGIP)
+    if err != nil {
+        t.Fatal(err)
+    }
     if port == nil || port.DeviceId != fipId || port.DeviceOwner != consts.NETWORKFLOATINGIP {
         t.Fatal("Create floating ip failed")
     }
 
-    netId := manager.CreateNetworkHelper()
-    subnetId := manager.CreateSubnetHelper(netId)
-    manager.AddRouterInterfaceHelper(routerId, subnetId)
-    instanceId := manager.CreateInstanceHelper(netId)
-    instancePortId, _ := manager.GetInstancePort(instanceId)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    subnetId, err := manager.CreateSubnetHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.AddRouterInterfaceHelper(routerId, subnetId); err != nil {
+        t.Fatal(err)
+    }
+    instanceId, err := manager.CreateInstanceHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    instancePortId, _, err := manager.GetInstancePort(instanceId)
+    if err != nil {
+        t.Fatal(err)
+    }
 
-    manager.UpdateFloatingIpWithPort(fipId, instancePortId)
-    fip := manager.GetFIP(fipId)
+    if _, err := manager.UpdateFloatingIpWithPort(fipId, instancePortId); err != nil {
+        t.Fatal(err)
+    }
+    fip, err := manager.GetFIP(fipId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if fip.PortId == "" {
         t.Fatal("Floating ip associate instance failed")
     }
 
-    qosId := manager.CreateQosPolicyHelper()
-    manager.UpdatePortWithQos(port.Id, qosId)
-    fipPort := manager.GetPort(port.Id)
+    qosId, err := manager.CreateQosPolicyHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.UpdatePortWithQos(port.Id, qosId); err != nil {
+        t.Fatal(err)
+    }
+    fipPort, err := manager.GetPort(port.Id)
+    if err != nil {
+        t.Fatal(err)
+    }
     if fipPort.QosPolicyId != qosId {
         t.Fatal("Floating ip associate qos policy failed")
     }
@@ -199,18 +317,45 @@ func TestCreateFip(t *testing.T) {
 
 // TestDNAT 8. test dnat
 func TestDNAT(t *testing.T) {
-    netId := manager.CreateNetworkHelper()
-    manager.CreateSubnetHelper(netId)
-    instance1 := manager.CreateInstanceHelper(netId)
-    instance2 := manager.CreateInstanceHelper(netId)
+    requireManager(t)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := manager.CreateSubnetHelper(netId); err != nil {
+        t.Fatal(err)
+    }
+    instance1, err := manager.CreateInstanceHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    instance2, err := manager.CreateInstanceHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
     fipOpts := &entity.CreateFipOpts{
         FloatingNetworkID: configs.CONF.ExternalNetwork,
     }
-    fipId := manager.CreateFloatingIP(fipOpts)
-    pfId1 := manager.CreatePortForwardingHelper(fipId, instance1)
-    pfId2 := manager.CreatePortForwardingHelper(fipId, instance2)
-    pf1 := manager.GetPortForwarding(fipId, pfId1)
-    pf2 := manager.GetPortForwarding(fipId, pfId2)
+    fipId, err := manager.CreateFloatingIP(fipOpts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    pfId1, err := manager.CreatePortForwardingHelper(fipId, instance1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    pfId2, err := manager.CreatePortForwardingHelper(fipId, instance2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    pf1, err := manager.GetPortForwarding(fipId, pfId1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    pf2, err := manager.GetPortForwarding(fipId, pfId2)
+    if err != nil {
+        t.Fatal(err)
+    }
     if pf1.PortForwarding.Id == "" || pf2.PortForwarding.Id == ""{
         t.Fatal("Create floating port forwarding failed")
     }
@@ -218,14 +363,37 @@ func TestDNAT(t *testing.T) {
 
 // TestCreateInstance 9. test qos limit of two instances
 func TestCreateInstance(t *testing.T) {
-    netId := manager.CreateNetworkHelper()
-    manager.CreateSubnetHelper(netId)
-    instanceId := manager.CreateInstanceHelper(netId)
-    instanceId2 := manager.CreateInstanceHelper(netId)
-    instancePortId, _ := manager.GetInstancePort(instanceId)
-    qosId := manager.CreateQosPolicyHelper()
-    manager.UpdatePortWithQos(instancePortId, qosId)
-    fipPort := manager.GetPort(instancePortId)
+    requireManager(t)
+    netId, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := manager.CreateSubnetHelper(netId); err != nil {
+        t.Fatal(err)
+    }
+    instanceId, err := manager.CreateInstanceHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    instanceId2, err := manager.CreateInstanceHelper(netId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    instancePortId, _, err := manager.GetInstancePort(instanceId)
+    if err != nil {
+        t.Fatal(err)
+    }
+    qosId, err := manager.CreateQosPolicyHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.UpdatePortWithQos(instancePortId, qosId); err != nil {
+        t.Fatal(err)
+    }
+    fipPort, err := manager.GetPort(instancePortId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if fipPort.QosPolicyId != qosId {
         t.Fatal("Floating ip associate qos policy failed")
     }
@@ -239,20 +407,53 @@ func TestCreateInstance(t *testing.T) {
 
 // TestCreateVpcConnection 10. test vpc connection of one tenant
 func TestCreateVpcConnection(t *testing.T) {
-    netId1 := manager.CreateNetworkHelper()
-    subnetId1 := manager.CreateSubnetHelper(netId1)
-    routerId1 := manager.CreateRouterHelper()
-    manager.SetDefaultRouterGatewayHelper(routerId1)
-    manager.AddRouterInterfaceHelper(routerId1, subnetId1)
+    requireManager(t)
+    netId1, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    subnetId1, err := manager.CreateSubnetHelper(netId1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    routerId1, err := manager.CreateRouterHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.SetDefaultRouterGatewayHelper(routerId1); err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.AddRouterInterfaceHelper(routerId1, subnetId1); err != nil {
+        t.Fatal(err)
+    }
 
-    netId2 := manager.CreateNetworkHelper()
-    subnetId2 := manager.CreateSubnetHelper(netId2)
-    routerId2 := manager.CreateRouterHelper()
-    manager.SetDefaultRouterGatewayHelper(routerId2)
-    manager.AddRouterInterfaceHelper(routerId2, subnetId2)
+    netId2, err := manager.CreateNetworkHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    subnetId2, err := manager.CreateSubnetHelper(netId2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    routerId2, err := manager.CreateRouterHelper()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.SetDefaultRouterGatewayHelper(routerId2); err != nil {
+        t.Fatal(err)
+    }
+    if err := manager.AddRouterInterfaceHelper(routerId2, subnetId2); err != nil {
+        t.Fatal(err)
+    }
 
-    vpcConnectionId := manager.CreateVpcConnectionHelper(routerId1, routerId2, []string{subnetId1}, []string{subnetId2})
-    vpcConnection := manager.GetVpcConnection(vpcConnectionId)
+    vpcConnectionId, err := manager.CreateVpcConnectionHelper(routerId1, routerId2, []string{subnetId1}, []string{subnetId2})
+    if err != nil {
+        t.Fatal(err)
+    }
+    vpcConnection, err := manager.GetVpcConnection(vpcConnectionId)
+    if err != nil {
+        t.Fatal(err)
+    }
     if vpcConnection.VpcConnection.FwEnabled != false ||
         !reflect.DeepEqual(vpcConnection.VpcConnection.LocalSubnets, []string{subnetId1}) ||
         !reflect.DeepEqual(vpcConnection.VpcConnection.PeerSubnets, []string{subnetId2}) {
@@ -271,5 +472,6 @@ func TestCreateVpcConnection(t *testing.T) {
 }
 
 func TestCreateFirewall(t *testing.T) {
+    requireManager(t)
     
 }
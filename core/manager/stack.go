@@ -0,0 +1,586 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"request_openstack/configs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StackResource is the state of a resource created by a stack, Properties
+// are its template properties and Dependencies map the resources it depends
+// on to the field path receiving their id, as in Resource. Failed marks a
+// resource partially created, e.g. a server that did not become ACTIVE, it
+// is replaced by the next update.
+type StackResource struct {
+	Type         string                 `json:"type"`
+	Id           string                 `json:"id"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+	Dependencies map[string]string      `json:"dependencies,omitempty"`
+	Failed       bool                   `json:"failed,omitempty"`
+}
+
+// StackState is what a stack keeps between runs.
+type StackState struct {
+	Name       string                    `json:"name"`
+	Template   string                    `json:"template"`
+	Parameters map[string]interface{}    `json:"parameters,omitempty"`
+	Resources  map[string]*StackResource `json:"resources"`
+	UpdatedAt  string                    `json:"updated_at"`
+}
+
+// StateBackend stores the state of stacks, Load returns nil and no error for
+// a stack without state.
+type StateBackend interface {
+	Load(name string) (*StackState, error)
+	Save(state *StackState) error
+	Delete(name string) error
+}
+
+// FileBackend stores the state of each stack in Dir/<name>.json.
+type FileBackend struct {
+	Dir string
+}
+
+func (f FileBackend) path(name string) string {
+	return filepath.Join(f.Dir, name+".json")
+}
+
+func (f FileBackend) Load(name string) (*StackState, error) {
+	data, err := ioutil.ReadFile(f.path(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state StackState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse state of stack %s: %w", name, err)
+	}
+	return &state, nil
+}
+
+// Save replaces the state file by a rename, an interrupted save leaves the
+// previous state.
+func (f FileBackend) Save(state *StackState) error {
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path(state.Name) + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(state.Name))
+}
+
+func (f FileBackend) Delete(name string) error {
+	if err := os.Remove(f.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// MemoryBackend keeps the state of stacks in memory, e.g. for a single run
+// that only needs it to roll back.
+type MemoryBackend struct {
+	mu     sync.Mutex
+	states map[string]*StackState
+}
+
+func (m *MemoryBackend) Load(name string) (*StackState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[name], nil
+}
+
+func (m *MemoryBackend) Save(state *StackState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.states == nil {
+		m.states = make(map[string]*StackState)
+	}
+	m.states[state.Name] = state
+	return nil
+}
+
+func (m *MemoryBackend) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, name)
+	return nil
+}
+
+// Actions of a stack update on a resource.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionReplace = "replace"
+	ActionDelete  = "delete"
+)
+
+// Change is what a stack update does to a resource, Properties are the
+// changed properties of an update or replace.
+type Change struct {
+	Resource   string
+	Type       string
+	Action     string
+	Properties []string
+}
+
+func (c Change) String() string {
+	if len(c.Properties) == 0 {
+		return fmt.Sprintf("%s %s %s", c.Action, c.Type, c.Resource)
+	}
+	return fmt.Sprintf("%s %s %s (%s)", c.Action, c.Type, c.Resource, strings.Join(c.Properties, ", "))
+}
+
+// Diff returns the changes turning the resources recorded in state into
+// those of template, sorted by resource. A resource is updated in place when
+// all its changed properties are updatable, otherwise it is replaced, and so
+// is every resource depending on a replaced one since its id changes. A
+// partially created resource is replaced.
+func Diff(state *StackState, template *Template) []Change {
+	changes := make(map[string]Change)
+	for name, resource := range template.Resources {
+		old, ok := state.Resources[name]
+		switch {
+		case !ok:
+			changes[name] = Change{Resource: name, Type: resource.Type, Action: ActionCreate}
+		case old.Type != resource.Type, old.Failed:
+			changes[name] = Change{Resource: name, Type: resource.Type, Action: ActionReplace}
+		default:
+			changed := changedProperties(old.Properties, template.Properties[name])
+			if len(changed) == 0 {
+				continue
+			}
+			action := ActionReplace
+			if updatable(resourceTypes[resource.Type], template.Properties[name], changed) {
+				action = ActionUpdate
+			}
+			changes[name] = Change{Resource: name, Type: resource.Type, Action: action, Properties: changed}
+		}
+	}
+	for replaced := true; replaced; {
+		replaced = false
+		for name, resource := range template.Resources {
+			if change, ok := changes[name]; ok && change.Action != ActionUpdate {
+				continue
+			}
+			for dep := range resource.Dependencies {
+				if changes[dep].Action == ActionReplace {
+					change := changes[name]
+					change.Resource, change.Type, change.Action = name, resource.Type, ActionReplace
+					changes[name] = change
+					replaced = true
+					break
+				}
+			}
+		}
+	}
+	for name, old := range state.Resources {
+		if _, ok := template.Resources[name]; !ok {
+			changes[name] = Change{Resource: name, Type: old.Type, Action: ActionDelete}
+		}
+	}
+	res := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		res = append(res, change)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Resource < res[j].Resource
+	})
+	return res
+}
+
+// changedProperties returns the properties that differ, compared as json
+// since the recorded properties went through it.
+func changedProperties(old, new map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for key := range old {
+		keys[key] = true
+	}
+	for key := range new {
+		keys[key] = true
+	}
+	changed := make([]string, 0)
+	for key := range keys {
+		if !reflect.DeepEqual(normalize(old[key]), normalize(new[key])) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func normalize(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var res interface{}
+	_ = json.Unmarshal(data, &res)
+	return res
+}
+
+// updatable tells whether kind updates the changed properties in place, a
+// removed property or one naming another resource needs a replace.
+func updatable(kind resourceType, props map[string]interface{}, changed []string) bool {
+	if kind.update == nil {
+		return false
+	}
+	for _, key := range changed {
+		value, ok := props[key]
+		if !ok || !contains(kind.updatable, key) {
+			return false
+		}
+		if refs, err := references(value); err != nil || len(refs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Stack creates the resources of a template and records them in Backend, so
+// that a later run can update them to a changed template or delete them.
+type Stack struct {
+	Name    string
+	Backend StateBackend
+	// OnFailure is what Apply does when resources fail to be created, the
+	// zero policy keeps what was created
+	OnFailure FailurePolicy
+	// Workers bounds the resources created at a time, 0 for no bound
+	Workers int
+	state   *StackState
+	mu      sync.Mutex
+	// template and results are those of the last Apply
+	template *Template
+	results  map[string]ResourceResult
+}
+
+// NewStack loads the state of the stack name, a stack without state has no
+// resources yet.
+func NewStack(name string, backend StateBackend) (*Stack, error) {
+	state, err := backend.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &StackState{Name: name, Resources: make(map[string]*StackResource)}
+	}
+	return &Stack{Name: name, Backend: backend, state: state}, nil
+}
+
+// Exists tells whether the stack has recorded resources.
+func (s *Stack) Exists() bool {
+	return len(s.state.Resources) > 0
+}
+
+// State returns the recorded state of the stack.
+func (s *Stack) State() *StackState {
+	return s.state
+}
+
+// Graph returns the resources of the template of the last Apply with how
+// their creation ended, those recorded before it are created. It is nil
+// before Apply resolved a template.
+func (s *Stack) Graph() *Graph {
+	if s.template == nil {
+		return nil
+	}
+	graph := TemplateGraph(s.template)
+	graph.Annotate(s.results)
+	for i, node := range graph.Nodes {
+		if resource, ok := s.state.Resources[node.Name]; ok && !resource.Failed && node.Status == StatusPending {
+			graph.Nodes[i].Status = StatusCreated
+		}
+	}
+	return graph
+}
+
+// Plan resolves the template file with params and returns the changes Apply
+// would make, without any request to OpenStack.
+func (s *Stack) Plan(templateFile string, params map[string]interface{}) (*Template, []Change, error) {
+	template, err := LoadTemplate(templateFile, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return template, Diff(s.state, template), nil
+}
+
+// Apply brings the stack to the template file: the deleted and replaced
+// resources are deleted, the updated ones updated in place and the rest
+// created, the state is saved after every step.
+func (s *Stack) Apply(ctx context.Context, templateFile string, params map[string]interface{}) ([]Change, error) {
+	template, changes, err := s.Plan(templateFile, params)
+	if err != nil {
+		return nil, err
+	}
+	s.state.Template, s.state.Parameters = templateFile, template.Parameters
+	s.template, s.results = template, make(map[string]ResourceResult)
+	for _, change := range changes {
+		log.Println("==============Stack", s.Name, change)
+	}
+
+	manager, err := NewManager()
+	if err != nil {
+		return changes, err
+	}
+	manager.SetContext(ctx)
+	if err = manager.EnsureSgExist(configs.CONF.ProjectName); err != nil {
+		return changes, err
+	}
+	return changes, s.apply(ctx, manager, template, changes)
+}
+
+// apply makes the changes of Diff with manager, deletions first.
+func (s *Stack) apply(ctx context.Context, manager *Manager, template *Template, changes []Change) error {
+	toDelete := make(map[string]bool)
+	for _, change := range changes {
+		if change.Action == ActionDelete || change.Action == ActionReplace {
+			toDelete[change.Resource] = true
+		}
+	}
+	if err := s.deleteResources(manager, toDelete); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.Action != ActionUpdate {
+			continue
+		}
+		if err := s.updateResource(manager, template, change); err != nil {
+			return fmt.Errorf("update %s: %w", change.Resource, err)
+		}
+	}
+	return s.create(ctx, manager, template)
+}
+
+// create runs the scheduler for the resources of template that are not in
+// the state yet and handles their failures by the OnFailure policy. The
+// resources partially created are recorded as failed, a rollback or a retry
+// deletes them first.
+func (s *Stack) create(ctx context.Context, manager *Manager, template *Template) error {
+	created := make(map[string]bool)
+	for attempt := 0; ; attempt++ {
+		scheduler := newScheduler(manager, template)
+		scheduler.Workers = s.Workers
+		scheduler.existing = make(map[string]string)
+		for name, resource := range s.state.Resources {
+			scheduler.existing[name] = resource.Id
+		}
+		var failed []string
+		partial := make(map[string]bool)
+		scheduler.onCompleted = func(name string, out Output) {
+			s.mu.Lock()
+			if !out.IsSuccess {
+				if out.Id == "" {
+					failed = append(failed, fmt.Sprintf("%s: %s", name, out.Resp))
+					s.mu.Unlock()
+					return
+				}
+				failed = append(failed, fmt.Sprintf("%s: %s, partially created %s", name, out.Resp, out.Id))
+				partial[name] = true
+			}
+			created[name] = true
+			s.mu.Unlock()
+			err := s.record(name, &StackResource{
+				Type:         template.Resources[name].Type,
+				Id:           out.Id,
+				Properties:   template.Properties[name],
+				Dependencies: template.Resources[name].Dependencies,
+				Failed:       !out.IsSuccess,
+			})
+			if err != nil {
+				log.Println("##############Save state of stack failed", s.Name, err)
+			}
+		}
+		err := scheduler.RunContext(ctx)
+		for name, result := range scheduler.Results() {
+			s.results[name] = result
+		}
+		if err != nil {
+			return err
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		sort.Strings(failed)
+		createErr := fmt.Errorf("failed to create %s", strings.Join(failed, "; "))
+		switch s.OnFailure.Action {
+		case OnFailureRetry:
+			if attempt < s.OnFailure.Retries {
+				log.Printf("##############Retry the failed resources of stack %s, %d of %d: %s", s.Name, attempt+1, s.OnFailure.Retries, createErr)
+				names := sortedNames(partial)
+				if err := s.deleteResources(manager, partial); err != nil {
+					return fmt.Errorf("%w, delete partially created: %s", createErr, err)
+				}
+				for _, name := range names {
+					delete(created, name)
+				}
+				continue
+			}
+		case OnFailureRollback:
+			log.Println("##############Roll back stack", s.Name, createErr)
+			if err := s.deleteResources(manager, created); err != nil {
+				return fmt.Errorf("%w, rollback: %s", createErr, err)
+			}
+		}
+		return createErr
+	}
+}
+
+// Delete deletes every resource of the stack, dependents first, and then its
+// state. The resources that could not be deleted stay in the state.
+func (s *Stack) Delete(ctx context.Context) error {
+	manager, err := NewManager()
+	if err != nil {
+		return err
+	}
+	manager.SetContext(ctx)
+	all := make(map[string]bool, len(s.state.Resources))
+	for name := range s.state.Resources {
+		all[name] = true
+	}
+	if err = s.deleteResources(manager, all); err != nil {
+		return err
+	}
+	return s.Backend.Delete(s.Name)
+}
+
+// deleteResources deletes the named resources of the state, a resource only
+// once no other recorded resource depends on it. A failed deletion does not
+// stop the others, the error lists the resources left behind and why.
+func (s *Stack) deleteResources(manager *Manager, names map[string]bool) error {
+	var errs []string
+	for len(names) > 0 {
+		next := ""
+		for _, name := range sortedNames(names) {
+			if !s.hasDependents(name) {
+				next = name
+				break
+			}
+		}
+		if next == "" {
+			for _, name := range sortedNames(names) {
+				errs = append(errs, fmt.Sprintf("%s: other resources of the stack depend on it", name))
+			}
+			break
+		}
+		if err := s.deleteResource(manager, next); err != nil {
+			// next stays in the state, so do its dependencies
+			errs = append(errs, fmt.Sprintf("%s: %s", next, err))
+		}
+		delete(names, next)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *Stack) hasDependents(name string) bool {
+	for _, resource := range s.state.Resources {
+		if _, ok := resource.Dependencies[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Stack) deleteResource(manager *Manager, name string) error {
+	resource, ok := s.state.Resources[name]
+	if !ok {
+		return nil
+	}
+	kind, ok := resourceTypes[resource.Type]
+	if !ok || kind.delete == nil {
+		return fmt.Errorf("%s resources cannot be deleted", resource.Type)
+	}
+	log.Println("##############Deleting", name, resource.Type, resource.Id)
+	if err := kind.delete(manager, s.opts(name), resource.Id); err != nil {
+		return err
+	}
+	log.Println("##############Delete resource", name, "success")
+	return s.record(name, nil)
+}
+
+func (s *Stack) updateResource(manager *Manager, template *Template, change Change) error {
+	resource := s.state.Resources[change.Resource]
+	props := template.Properties[change.Resource]
+	changed := make(map[string]interface{}, len(change.Properties))
+	for _, key := range change.Properties {
+		changed[key] = props[key]
+	}
+	log.Println("##############Updating", change.Resource, resource.Type, resource.Id)
+	if err := resourceTypes[resource.Type].update(manager, s.opts(change.Resource), resource.Id, changed); err != nil {
+		return err
+	}
+	return s.record(change.Resource, &StackResource{
+		Type:         resource.Type,
+		Id:           resource.Id,
+		Properties:   props,
+		Dependencies: template.Resources[change.Resource].Dependencies,
+	})
+}
+
+// opts rebuilds the opts of a recorded resource with the recorded ids of its
+// dependencies, e.g. for the pool a member is deleted from.
+func (s *Stack) opts(name string) interface{} {
+	resource := s.state.Resources[name]
+	props, _ := resolveIntrinsics(resource.Properties, placeholderResolver{})
+	resourceProps, _ := props.(map[string]interface{})
+	opts, dependencies := resourceTypes[resource.Type].resolve(name, resourceProps)
+	for dep, path := range dependencies {
+		if recorded, ok := s.state.Resources[dep]; ok && path != "" {
+			if err := setFieldPath(opts, path, recorded.Id); err != nil {
+				log.Println("##############Ignore dependency", dep, "of", name, err)
+			}
+		}
+	}
+	return opts
+}
+
+// record sets or, for nil, removes the state of a resource and saves the
+// state, resources are recorded concurrently by the scheduler.
+func (s *Stack) record(name string, resource *StackResource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resource == nil {
+		delete(s.state.Resources, name)
+	} else {
+		s.state.Resources[name] = resource
+	}
+	s.state.UpdatedAt = time.Now().Format(time.RFC3339)
+	return s.Backend.Save(s.state)
+}
+
+func sortedNames(names map[string]bool) []string {
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,434 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"request_openstack/consts"
+	"request_openstack/internal/entity"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCloud stands in for OpenStack: it replaces the create, update and
+// delete of resource types for the duration of a test and records them.
+type fakeCloud struct {
+	mu    sync.Mutex
+	ids   int
+	live  map[string]string // id to resource name
+	calls []string
+	// failing are the resources whose creates fail, forever for -1, those
+	// also partial are left behind with their id returned along the error
+	failing map[string]int
+	partial map[string]bool
+}
+
+func newFakeCloud(t *testing.T, types ...string) *fakeCloud {
+	cloud := &fakeCloud{live: make(map[string]string), failing: make(map[string]int), partial: make(map[string]bool)}
+	for _, typ := range types {
+		typ, saved := typ, resourceTypes[typ]
+		kind := saved
+		kind.receive = func(manager *Manager, opts interface{}, trans *Transmitter) error {
+			return applyTransmitter(manager, opts, trans)
+		}
+		kind.create = func(manager *Manager, opts interface{}) (string, error) {
+			return cloud.create(optsName(opts))
+		}
+		kind.update = func(manager *Manager, opts interface{}, id string, changed map[string]interface{}) error {
+			keys := make([]string, 0, len(changed))
+			for key := range changed {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			cloud.record("update %s %s", cloud.name(id), strings.Join(keys, ","))
+			return nil
+		}
+		kind.delete = func(manager *Manager, opts interface{}, id string) error {
+			return cloud.delete(id)
+		}
+		resourceTypes[typ] = kind
+		t.Cleanup(func() { resourceTypes[typ] = saved })
+	}
+	return cloud
+}
+
+// optsName is the resource name resolve set on the opts.
+func optsName(opts interface{}) string {
+	return reflect.ValueOf(opts).Elem().FieldByName("Name").String()
+}
+
+func (c *fakeCloud) record(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, fmt.Sprintf(format, args...))
+}
+
+func (c *fakeCloud) create(name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "create "+name)
+	n := c.failing[name]
+	if n != 0 && !c.partial[name] {
+		c.failing[name] = n - 1
+		return "", fmt.Errorf("%s failed", name)
+	}
+	c.ids++
+	id := fmt.Sprintf("00000000-0000-0000-0000-%012d", c.ids)
+	c.live[id] = name
+	if n != 0 {
+		c.failing[name] = n - 1
+		return id, fmt.Errorf("%s failed", name)
+	}
+	return id, nil
+}
+
+func (c *fakeCloud) delete(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, ok := c.live[id]
+	if !ok {
+		return fmt.Errorf("no resource %s", id)
+	}
+	c.calls = append(c.calls, "delete "+name)
+	delete(c.live, id)
+	return nil
+}
+
+func (c *fakeCloud) name(id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.live[id]
+}
+
+// names are the names of the live resources, sorted.
+func (c *fakeCloud) names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.live))
+	for _, name := range c.live {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *fakeCloud) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = nil
+}
+
+func resolveTestTemplate(t *testing.T, src string) *Template {
+	t.Helper()
+	template, err := ResolveTemplate(parseTemplate(t, src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+const stackV1 = `
+resources:
+  net:
+    type: network
+    properties:
+      description: one
+  subnet:
+    type: subnet
+    properties:
+      network_id: net
+      cidr: 10.0.0.0/24
+      ip_version: 4
+  port:
+    type: port
+    properties:
+      network_id: net
+`
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"unchanged", stackV1, []string{}},
+		{
+			name: "update in place",
+			src:  strings.Replace(stackV1, "description: one", "description: two\n      mtu: 1400", 1),
+			want: []string{"update network net (description, mtu)"},
+		},
+		{
+			name: "replace",
+			src:  strings.Replace(stackV1, "10.0.0.0/24", "10.1.0.0/24", 1),
+			want: []string{"replace subnet subnet (cidr)"},
+		},
+		{
+			name: "replace the dependents",
+			src:  strings.Replace(stackV1, "description: one", "shared: true", 1),
+			want: []string{"replace network net (description, shared)", "replace port port", "replace subnet subnet"},
+		},
+		{
+			name: "create and delete",
+			src:  strings.Replace(stackV1, "  port:\n", "  port2:\n", 1),
+			want: []string{"delete port port", "create port port2"},
+		},
+		{
+			name: "type change",
+			src:  strings.Replace(stackV1, "type: port\n    properties:\n      network_id: net", "type: network", 1),
+			want: []string{"replace network port"},
+		},
+	}
+	state := stateOf(resolveTestTemplate(t, stackV1))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, change := range Diff(state, resolveTestTemplate(t, tt.src)) {
+				got = append(got, change.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// stateOf records every resource of template as created.
+func stateOf(template *Template) *StackState {
+	state := &StackState{Name: "test", Resources: make(map[string]*StackResource)}
+	for name, resource := range template.Resources {
+		state.Resources[name] = &StackResource{
+			Type:         resource.Type,
+			Id:           "00000000-0000-0000-0000-" + fmt.Sprintf("%012d", len(state.Resources)+100),
+			Properties:   normalize(template.Properties[name]).(map[string]interface{}),
+			Dependencies: resource.Dependencies,
+		}
+	}
+	return state
+}
+
+func TestFileBackend(t *testing.T) {
+	backend := FileBackend{Dir: t.TempDir()}
+	if state, err := backend.Load("missing"); state != nil || err != nil {
+		t.Fatalf("expected no state, got %v %v", state, err)
+	}
+	state := stateOf(resolveTestTemplate(t, stackV1))
+	if err := backend.Save(state); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := backend.Load(state.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, resource := range state.Resources {
+		if got := loaded.Resources[name]; got == nil || got.Id != resource.Id || got.Type != resource.Type {
+			t.Fatalf("loaded %s %+v, saved %+v", name, got, resource)
+		}
+	}
+	if changes := Diff(loaded, resolveTestTemplate(t, stackV1)); len(changes) != 0 {
+		t.Fatalf("expected no changes after a reload, got %v", changes)
+	}
+	if err = backend.Delete(state.Name); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ = backend.Load(state.Name); state != nil {
+		t.Fatal("expected the state to be deleted")
+	}
+}
+
+// applyTemplate applies src to the stack as Apply does.
+func applyTemplate(t *testing.T, stack *Stack, src string) error {
+	t.Helper()
+	template := resolveTestTemplate(t, src)
+	stack.template, stack.results = template, make(map[string]ResourceResult)
+	return stack.apply(context.Background(), &Manager{}, template, Diff(stack.state, template))
+}
+
+func TestStackApply(t *testing.T) {
+	cloud := newFakeCloud(t, consts.NETWORK, consts.SUBNET, consts.PORT)
+	backend := &MemoryBackend{}
+	stack, err := NewStack("test", backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = applyTemplate(t, stack, stackV1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"net", "port", "subnet"}; !reflect.DeepEqual(cloud.names(), want) {
+		t.Fatalf("created %v, want %v", cloud.names(), want)
+	}
+	if calls := cloud.calls; calls[0] != "create net" {
+		t.Fatalf("expected net to be created first, got %v", calls)
+	}
+
+	cloud.reset()
+	v2 := strings.Replace(stackV1, "description: one", "description: two", 1)
+	v2 = strings.Replace(v2, "10.0.0.0/24", "10.1.0.0/24", 1)
+	v2 = strings.Replace(v2, "  port:\n", "  port2:\n", 1)
+	if err = applyTemplate(t, stack, v2); err != nil {
+		t.Fatal(err)
+	}
+	// deletions first, port before subnet by name, then updates and creates
+	want := []string{"delete port", "delete subnet", "update net description"}
+	if got := cloud.calls[:3]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if want := []string{"net", "port2", "subnet"}; !reflect.DeepEqual(cloud.names(), want) {
+		t.Fatalf("live %v, want %v", cloud.names(), want)
+	}
+	saved, _ := backend.Load("test")
+	if got := saved.Resources["net"].Properties["description"]; got != "two" {
+		t.Fatalf("saved description %v", got)
+	}
+
+	// as Delete does, which needs OpenStack for its manager
+	cloud.reset()
+	all := map[string]bool{"net": true, "subnet": true, "port2": true}
+	if err = stack.deleteResources(&Manager{}, all); err != nil {
+		t.Fatal(err)
+	}
+	if len(cloud.names()) != 0 || len(stack.state.Resources) != 0 {
+		t.Fatalf("left %v and state %v", cloud.names(), stack.state.Resources)
+	}
+	if last := cloud.calls[len(cloud.calls)-1]; last != "delete net" {
+		t.Fatalf("expected net to be deleted last, got %v", cloud.calls)
+	}
+}
+
+func TestStackDeleteKeepsFailed(t *testing.T) {
+	cloud := newFakeCloud(t, consts.NETWORK, consts.SUBNET, consts.PORT)
+	stack, _ := NewStack("test", &MemoryBackend{})
+	if err := applyTemplate(t, stack, stackV1); err != nil {
+		t.Fatal(err)
+	}
+	// the subnet is gone behind the back of the stack, so its delete fails
+	_ = cloud.delete(stack.state.Resources["subnet"].Id)
+	err := stack.deleteResources(&Manager{}, map[string]bool{"net": true, "subnet": true, "port": true})
+	if err == nil || !strings.Contains(err.Error(), "subnet: no resource") || !strings.Contains(err.Error(), "net: other resources of the stack depend on it") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := stack.state.Resources["port"]; ok {
+		t.Fatal("expected port to be deleted")
+	}
+	if _, ok := stack.state.Resources["subnet"]; !ok {
+		t.Fatal("expected subnet to stay in the state")
+	}
+}
+
+// TestUpdatableProperties checks that a template can set every property
+// a type updates in place.
+func TestUpdatableProperties(t *testing.T) {
+	for typ, kind := range resourceTypes {
+		if kind.resolve == nil {
+			continue
+		}
+		opts, _, err := resolveOpts(kind, typ, nil)
+		if err != nil {
+			t.Fatal(typ, err)
+		}
+		for _, key := range kind.updatable {
+			if err = entity.CheckProps(opts, map[string]interface{}{key: nil}); err != nil {
+				t.Errorf("%s: %s", typ, err)
+			}
+		}
+	}
+}
+
+func TestSchedulersKeepTheirResources(t *testing.T) {
+	cloud := newFakeCloud(t, consts.NETWORK)
+	a := newScheduler(&Manager{}, resolveTestTemplate(t, "resources:\n  a:\n    type: network\n"))
+	b := newScheduler(&Manager{}, resolveTestTemplate(t, "resources:\n  b:\n    type: network\n"))
+	if err := a.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := cloud.names(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("scheduler of a created %v", got)
+	}
+	if err := b.RunContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := cloud.names(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("schedulers created %v", got)
+	}
+}
+
+func TestCreateKeepsPartialId(t *testing.T) {
+	cloud := newFakeCloud(t, consts.NETWORK)
+	cloud.failing["net"], cloud.partial["net"] = 1, true
+	template := resolveTestTemplate(t, "resources:\n  net:\n    type: network\n")
+	var outs sync.Map
+	template.Resources["net"].Create(stackResolver{manager: &Manager{}, resources: template.Resources, completedOuts: &outs}, nil)
+	out, _ := outs.Load("net")
+	if got := out.(Output); got.IsSuccess || got.Id == "" || got.Resp != "error net failed" {
+		t.Fatalf("unexpected output %+v", got)
+	}
+}
+
+func TestStackFailurePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  FailurePolicy
+		failing int
+		partial bool
+		wantErr string
+		live    []string
+		state   []string
+	}{
+		{"keep", FailurePolicy{Action: OnFailureKeep}, -1, false, "failed to create subnet: error subnet failed", []string{"net", "port"}, []string{"net", "port"}},
+		{"keep partial", FailurePolicy{Action: OnFailureKeep}, -1, true, "partially created", []string{"net", "port", "subnet"}, []string{"net", "port", "subnet"}},
+		{"rollback", FailurePolicy{Action: OnFailureRollback}, -1, false, "failed to create subnet", []string{}, []string{}},
+		{"rollback partial", FailurePolicy{Action: OnFailureRollback}, -1, true, "partially created", []string{}, []string{}},
+		{"retry", FailurePolicy{Action: OnFailureRetry, Retries: 2}, 2, false, "", []string{"net", "port", "subnet"}, []string{"net", "port", "subnet"}},
+		{"retry partial", FailurePolicy{Action: OnFailureRetry, Retries: 1}, 1, true, "", []string{"net", "port", "subnet"}, []string{"net", "port", "subnet"}},
+		{"retries exhausted", FailurePolicy{Action: OnFailureRetry, Retries: 1}, 2, false, "failed to create subnet", []string{"net", "port"}, []string{"net", "port"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cloud := newFakeCloud(t, consts.NETWORK, consts.SUBNET, consts.PORT)
+			cloud.failing["subnet"], cloud.partial["subnet"] = tt.failing, tt.partial
+			stack, _ := NewStack("test", &MemoryBackend{})
+			stack.OnFailure = tt.policy
+			err := applyTemplate(t, stack, stackV1)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("got %v, want %q", err, tt.wantErr)
+			}
+			if got := cloud.names(); !reflect.DeepEqual(got, tt.live) {
+				t.Fatalf("live %v, want %v", got, tt.live)
+			}
+			state := make([]string, 0)
+			for name := range stack.state.Resources {
+				state = append(state, name)
+			}
+			sort.Strings(state)
+			if !reflect.DeepEqual(state, tt.state) {
+				t.Fatalf("state %v, want %v", state, tt.state)
+			}
+		})
+	}
+}
+
+func TestStackReplacesPartial(t *testing.T) {
+	cloud := newFakeCloud(t, consts.NETWORK, consts.SUBNET, consts.PORT)
+	cloud.failing["subnet"], cloud.partial["subnet"] = 1, true
+	stack, _ := NewStack("test", &MemoryBackend{})
+	if err := applyTemplate(t, stack, stackV1); err == nil {
+		t.Fatal("expected subnet to fail")
+	}
+	if !stack.state.Resources["subnet"].Failed {
+		t.Fatal("expected subnet to be recorded as failed")
+	}
+	changes := Diff(stack.state, resolveTestTemplate(t, stackV1))
+	if len(changes) != 1 || changes[0].String() != "replace subnet subnet" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	if err := applyTemplate(t, stack, stackV1); err != nil {
+		t.Fatal(err)
+	}
+	if got := cloud.names(); !reflect.DeepEqual(got, []string{"net", "port", "subnet"}) {
+		t.Fatalf("live %v", got)
+	}
+	if stack.state.Resources["subnet"].Failed {
+		t.Fatal("expected subnet to be replaced")
+	}
+}
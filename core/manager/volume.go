@@ -4,20 +4,30 @@ import (
 	"strings"
 )
 
-func (m *Manager) CreateCinderQosType() {
-	m.RequestQosLimitCommons()
-	m.RequestQosLimitEfficients()
-	m.RequestQosLimitSSDs()
+func (m *Manager) CreateCinderQosType() error {
+	if err := m.RequestQosLimitCommons(); err != nil {
+		return err
+	}
+	if err := m.RequestQosLimitEfficients(); err != nil {
+		return err
+	}
+	return m.RequestQosLimitSSDs()
 }
 
-func (m *Manager) SetVolumeTypeBackendProperty() {
-	volumeTypes := m.ListVolumeTypes()
+func (m *Manager) SetVolumeTypeBackendProperty() error {
+	volumeTypes, err := m.ListVolumeTypes()
+	if err != nil {
+		return err
+	}
 
 	for _, volumeType := range volumeTypes.VTs {
 		if strings.Contains(volumeType.Name, "SEBS-ssd") {
-            m.AddExtraSpecsForVolumeType(volumeType.Id, "volume_backend_name", "rbd-2")
+            if err := m.AddExtraSpecsForVolumeType(volumeType.Id, "volume_backend_name", "rbd-2"); err != nil {
+            	return err
+            }
 		}
 	}
+	return nil
 }
 
 // 云硬盘类型
@@ -32,8 +42,11 @@ func (m *Manager) SetVolumeTypeBackendProperty() {
 //ssd硬盘	替换成	SEBS-ssd
 //普通硬盘	替换成	SEBS-common
 
-func (m *Manager) SetVolumeTypeNameDesc() {
-	volumeTypes := m.ListVolumeTypes()
+func (m *Manager) SetVolumeTypeNameDesc() error {
+	volumeTypes, err := m.ListVolumeTypes()
+	if err != nil {
+		return err
+	}
 
 	for _, volumeType := range volumeTypes.VTs {
 		if strings.Contains(volumeType.Name, "SEBS-ssd") {
@@ -41,14 +54,18 @@ func (m *Manager) SetVolumeTypeNameDesc() {
 			if len(names) > 0 {
 				name := "SSD" + names[1]
 				desc := "SEBS-ssd" + names[1] + "G"
-				m.UpdateVolumeType(volumeType.Id, name, desc)
+				if _, err := m.UpdateVolumeType(volumeType.Id, name, desc); err != nil {
+					return err
+				}
 			}
 		}
 
 		if strings.Contains(volumeType.Name, "SEBS-common-0") {
 			name := "普通硬盘"
 			desc := "SEBS-common"
-			m.UpdateVolumeType(volumeType.Id, name, desc)
+			if _, err := m.UpdateVolumeType(volumeType.Id, name, desc); err != nil {
+				return err
+			}
 		}
 
 		if strings.Contains(volumeType.Name, "SEBS-efficient") {
@@ -56,10 +73,13 @@ func (m *Manager) SetVolumeTypeNameDesc() {
 			if len(names) > 0 {
 				name := "高效硬盘" + names[1]
 				desc := "SEBS-efficient" + names[1] + "G"
-				m.UpdateVolumeType(volumeType.Id, name, desc)
+				if _, err := m.UpdateVolumeType(volumeType.Id, name, desc); err != nil {
+					return err
+				}
 			}
 		}
 	}
+	return nil
 }
 
 //
@@ -1,12 +1,50 @@
 package manager
 
+import (
+	"gopkg.in/yaml.v3"
+)
 
-
+// ConfigMap is a kind-based template, e.g. kind: ipsec_connection, see
+// ImportKind. Metadata are the properties of the resource of the kind and
+// Dependences the resources it is created after.
 type ConfigMap struct {
-	Kind             string
-	Metadata
+	Kind        string                 `yaml:"kind"`
+	Metadata    Metadata               `yaml:"metadata"`
+	Parameters  map[string]interface{} `yaml:"parameters"`
+	Outputs     map[string]interface{} `yaml:"outputs"`
+	Dependences Dependences            `yaml:"dependences"`
+}
+
+// Metadata is an alias, yaml decodes the maps nested in a named map type
+// to that type, which intrinsic functions are not.
+type Metadata = map[string]interface{}
+
+// Dependence is a resource of a kind-based template, Resource is its type,
+// e.g. Router, Vpn or ike_policy, and Name tells apart the resources of a
+// type, a dependence of the same type and name refers to the same resource.
+type Dependence struct {
+	Resource    string                 `yaml:"resource"`
+	Name        string                 `yaml:"name"`
+	Properties  map[string]interface{} `yaml:"properties"`
+	Dependences Dependences            `yaml:"dependences"`
 }
 
-type Metadata struct {
+// Dependences are a list of dependences, a single one may be given as is.
+type Dependences []Dependence
 
+func (d *Dependences) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var dependence Dependence
+		if err := value.Decode(&dependence); err != nil {
+			return err
+		}
+		*d = Dependences{dependence}
+		return nil
+	}
+	var dependences []Dependence
+	if err := value.Decode(&dependences); err != nil {
+		return err
+	}
+	*d = dependences
+	return nil
 }
@@ -3,15 +3,19 @@ module request_openstack
 go 1.18
 
 require (
+	github.com/Unknwon/goconfig v1.0.0
+	github.com/panjf2000/ants v1.3.0
 	github.com/redis/go-redis/v9 v9.0.4
 	github.com/spf13/viper v1.13.0
 	github.com/valyala/fasthttp v1.45.0
 	go.etcd.io/etcd/client/v3 v3.5.8
+	gopkg.in/yaml.v3 v3.0.1
+	gorm.io/driver/mysql v1.5.1
+	gorm.io/gorm v1.25.2
 )
 
 require (
 	github.com/BurntSushi/toml v1.3.2 // indirect
-	github.com/Unknwon/goconfig v1.0.0 // indirect
 	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/coreos/go-semver v0.3.1 // indirect
@@ -31,7 +35,6 @@ require (
 	github.com/klauspost/compress v1.16.5 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
-	github.com/panjf2000/ants v1.3.0 // indirect
 	github.com/pelletier/go-toml v1.9.5 // indirect
 	github.com/pelletier/go-toml/v2 v2.0.7 // indirect
 	github.com/smartystreets/goconvey v1.8.1 // indirect
@@ -61,7 +64,4 @@ require (
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
-	gorm.io/driver/mysql v1.5.1 // indirect
-	gorm.io/gorm v1.25.2 // indirect
 )
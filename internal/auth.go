@@ -0,0 +1,309 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"request_openstack/configs"
+	"request_openstack/consts"
+	"request_openstack/internal/entity"
+	"strings"
+)
+
+// AuthMethod is one of the identity methods of a keystone
+// POST /v3/auth/tokens request.
+type AuthMethod interface {
+	// Identity returns the identity object of the auth request
+	Identity() map[string]interface{}
+}
+
+// Password authenticates a user by name or id. The user domain defaults to
+// the domain with id "default".
+type Password struct {
+	UserId         string
+	UserName       string
+	Password       string
+	UserDomainId   string
+	UserDomainName string
+}
+
+func (p Password) Identity() map[string]interface{} {
+	user := map[string]interface{}{"password": p.Password}
+	if p.UserId != "" {
+		user["id"] = p.UserId
+	} else {
+		user["name"] = p.UserName
+		user["domain"] = domainRef(p.UserDomainId, p.UserDomainName)
+	}
+	return map[string]interface{}{
+		"methods":  []string{"password"},
+		"password": map[string]interface{}{"user": user},
+	}
+}
+
+// ApplicationCredential authenticates with an application credential, by id
+// or by name together with its user. The token is scoped to the project the
+// credential was created in, a Scope must not be requested.
+type ApplicationCredential struct {
+	Id             string
+	Name           string
+	Secret         string
+	UserId         string
+	UserName       string
+	UserDomainId   string
+	UserDomainName string
+}
+
+func (a ApplicationCredential) Identity() map[string]interface{} {
+	credential := map[string]interface{}{"secret": a.Secret}
+	if a.Id != "" {
+		credential["id"] = a.Id
+	} else {
+		credential["name"] = a.Name
+		user := map[string]interface{}{}
+		if a.UserId != "" {
+			user["id"] = a.UserId
+		} else {
+			user["name"] = a.UserName
+			user["domain"] = domainRef(a.UserDomainId, a.UserDomainName)
+		}
+		credential["user"] = user
+	}
+	return map[string]interface{}{
+		"methods":                []string{"application_credential"},
+		"application_credential": credential,
+	}
+}
+
+// Token re-scopes an existing token, e.g. an unscoped federated one.
+type Token struct {
+	Id string
+}
+
+func (t Token) Identity() map[string]interface{} {
+	return map[string]interface{}{
+		"methods": []string{"token"},
+		"token":   map[string]interface{}{"id": t.Id},
+	}
+}
+
+// Scope selects what the token is authorized on, exactly one of a project,
+// a domain or the system. A nil Scope requests the default scope of the user.
+type Scope struct {
+	ProjectId         string
+	ProjectName       string
+	ProjectDomainId   string
+	ProjectDomainName string
+	DomainId          string
+	DomainName        string
+	// System requests a system scoped token, keystone only knows "all"
+	System bool
+}
+
+func ProjectScope(projectName string) *Scope {
+	return &Scope{
+		ProjectName:       projectName,
+		ProjectDomainId:   configs.CONF.ProjectDomainId,
+		ProjectDomainName: configs.CONF.ProjectDomainName,
+	}
+}
+
+func (s *Scope) body() map[string]interface{} {
+	switch {
+	case s.System:
+		return map[string]interface{}{"system": map[string]interface{}{"all": true}}
+	case s.ProjectId != "":
+		return map[string]interface{}{"project": map[string]interface{}{"id": s.ProjectId}}
+	case s.ProjectName != "":
+		return map[string]interface{}{"project": map[string]interface{}{
+			"name":   s.ProjectName,
+			"domain": domainRef(s.ProjectDomainId, s.ProjectDomainName),
+		}}
+	case s.DomainId != "":
+		return map[string]interface{}{"domain": map[string]interface{}{"id": s.DomainId}}
+	default:
+		return map[string]interface{}{"domain": map[string]interface{}{"name": s.DomainName}}
+	}
+}
+
+// domainRef defaults to the domain with id "default" like keystone itself.
+func domainRef(id, name string) map[string]interface{} {
+	if id != "" {
+		return map[string]interface{}{"id": id}
+	}
+	if name != "" {
+		return map[string]interface{}{"name": name}
+	}
+	return map[string]interface{}{"id": "default"}
+}
+
+func authRequestBody(method AuthMethod, scope *Scope) string {
+	auth := map[string]interface{}{"identity": method.Identity()}
+	if scope != nil {
+		auth["scope"] = scope.body()
+	}
+	body, _ := json.Marshal(map[string]interface{}{"auth": auth})
+	return string(body)
+}
+
+// ConfiguredAuth returns the auth method configs.CONF asks for: an
+// application credential or a token when configured, the password of the
+// user otherwise. Application credentials carry their own user and project,
+// so every token is issued with them once configured. The other tokens are
+// scoped by configuredScope.
+func ConfiguredAuth(projectName, userName, userPassword string) (AuthMethod, *Scope) {
+	conf := configs.CONF
+	authType := strings.TrimPrefix(conf.AuthType, "v3")
+	switch {
+	case authType == "applicationcredential" || (authType == "" && conf.ApplicationCredentialSecret != ""):
+		return ApplicationCredential{
+			Id:             conf.ApplicationCredentialId,
+			Name:           conf.ApplicationCredentialName,
+			Secret:         conf.ApplicationCredentialSecret,
+			UserName:       conf.UserName,
+			UserDomainId:   conf.UserDomainId,
+			UserDomainName: conf.UserDomainName,
+		}, nil
+	case authType == "token" && conf.Token != "":
+		return Token{Id: conf.Token}, configuredScope(projectName)
+	}
+	return Password{
+		UserName:       userName,
+		Password:       userPassword,
+		UserDomainId:   conf.UserDomainId,
+		UserDomainName: conf.UserDomainName,
+	}, configuredScope(projectName)
+}
+
+// configuredScope is the system scope when configs.CONF has a system scope,
+// the domain scope when it has a domain, the scope of the project otherwise.
+func configuredScope(projectName string) *Scope {
+	conf := configs.CONF
+	switch {
+	case conf.SystemScope != "":
+		return &Scope{System: true}
+	case conf.DomainId != "" || conf.DomainName != "":
+		return &Scope{DomainId: conf.DomainId, DomainName: conf.DomainName}
+	}
+	return ProjectScope(projectName)
+}
+
+// ConfiguredFederation returns the federation configs.CONF asks for with
+// the auth types v3oidcaccesstoken and v3samlpassword, the SAML2 assertion
+// is requested with the name and password of the user.
+func ConfiguredFederation(userName, userPassword string) (Federation, bool) {
+	conf := configs.CONF
+	federation := Federation{IdentityProvider: conf.IdentityProvider, Protocol: conf.Protocol}
+	switch strings.TrimPrefix(conf.AuthType, "v3") {
+	case "oidcaccesstoken":
+		federation.AccessToken = conf.AccessToken
+		if federation.Protocol == "" {
+			federation.Protocol = "openid"
+		}
+	case "samlpassword":
+		federation.IdentityProviderUrl = conf.IdentityProviderUrl
+		federation.Username, federation.Password = userName, userPassword
+		if federation.Protocol == "" {
+			federation.Protocol = "saml2"
+		}
+	default:
+		return Federation{}, false
+	}
+	return federation, true
+}
+
+// Federation obtains an unscoped token from a keystone identity provider,
+// either with an OIDC access token or with a SAML2 ECP assertion. Without
+// an assertion, it is requested from the ECP endpoint IdentityProviderUrl
+// of the SAML2 identity provider with Username and Password.
+type Federation struct {
+	IdentityProvider string
+	// Protocol is the federation protocol name, e.g. openid, saml2 or mapped
+	Protocol    string
+	AccessToken string
+	Assertion   string
+
+	IdentityProviderUrl string
+	Username            string
+	Password            string
+}
+
+// Authenticate issues a token for the method and scope.
+func (k *Keystone) Authenticate(method AuthMethod, scope *Scope) (string, entity.TokenMap, error) {
+	return k.issueToken(make(map[string]string), "/auth/tokens", authRequestBody(method, scope))
+}
+
+// Federate runs the federated flow, an unscoped token from the identity
+// provider is re-scoped to scope.
+func (k *Keystone) Federate(federation Federation, scope *Scope) (string, entity.TokenMap, error) {
+	urlSuffix := fmt.Sprintf("/OS-FEDERATION/identity_providers/%s/protocols/%s/auth",
+		federation.IdentityProvider, federation.Protocol)
+	if federation.AccessToken == "" && federation.Assertion == "" && federation.IdentityProviderUrl != "" {
+		assertion, err := k.samlAssertion(urlSuffix, federation)
+		if err != nil {
+			return "", entity.TokenMap{}, err
+		}
+		federation.Assertion = assertion
+	}
+	headers := make(map[string]string)
+	body := ""
+	switch {
+	case federation.AccessToken != "":
+		headers["Authorization"] = "Bearer " + federation.AccessToken
+	case federation.Assertion != "":
+		headers["Content-Type"] = consts.ContentTypePaos
+		body = federation.Assertion
+	default:
+		return "", entity.TokenMap{}, fmt.Errorf("federation with %s needs an access token or an assertion", federation.IdentityProvider)
+	}
+	unscoped, _, err := k.issueToken(headers, urlSuffix, body)
+	if err != nil {
+		return "", entity.TokenMap{}, err
+	}
+	return k.Authenticate(Token{Id: unscoped}, scope)
+}
+
+// soapHeader is the header of the SOAP envelope of the service provider,
+// the identity provider is sent the authn request without it.
+var soapHeader = regexp.MustCompile(`(?s)<([\w-]+:)?Header[\s>].*?</([\w-]+:)?Header>`)
+
+// samlAssertion runs the SAML2 ECP flow: the authn request of keystone, the
+// service provider at urlSuffix, is sent to the identity provider with the
+// credentials of the user, the SOAP envelope it answers is the assertion.
+func (k *Keystone) samlAssertion(urlSuffix string, federation Federation) (string, error) {
+	// neither request carries the token of the client, the assertion is
+	// requested while it is renewed
+	sp := Request{UrlPrefix: k.UrlPrefix, Service: k.Service}
+	sp.SetContext(k.Context())
+	authnRequest, err := sp.Get(map[string]string{
+		"Accept": "text/html, " + consts.ContentTypePaos,
+		"PAOS":   consts.PaosHeader,
+	}, urlSuffix)
+	if err != nil {
+		return "", fmt.Errorf("authn request of %s: %w", federation.IdentityProvider, err)
+	}
+	idp := Request{UrlPrefix: federation.IdentityProviderUrl, Service: "identity provider"}
+	idp.SetContext(k.Context())
+	credentials := base64.StdEncoding.EncodeToString([]byte(federation.Username + ":" + federation.Password))
+	assertion, err := idp.Post(map[string]string{
+		"Content-Type":  "text/xml",
+		"Authorization": "Basic " + credentials,
+	}, "", soapHeader.ReplaceAllString(string(authnRequest), ""))
+	if err != nil {
+		return "", fmt.Errorf("assertion of %s: %w", federation.IdentityProvider, err)
+	}
+	return string(assertion), nil
+}
+
+func (k *Keystone) issueToken(headers map[string]string, urlSuffix, body string) (string, entity.TokenMap, error) {
+	var tokenMap entity.TokenMap
+	token, resp, err := k.GetHeaderToken(headers, urlSuffix, body)
+	if err != nil {
+		return "", tokenMap, err
+	}
+	if err = json.Unmarshal(resp, &tokenMap); err != nil {
+		return "", tokenMap, fmt.Errorf("unmarshal token: %w", err)
+	}
+	return token, tokenMap, nil
+}
@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"request_openstack/configs"
+	"strings"
+	"testing"
+)
+
+func TestAuthRequestBody(t *testing.T) {
+	body := authRequestBody(Password{UserName: "demo", Password: "secret", UserDomainName: "ldap"}, &Scope{System: true})
+	var req struct {
+		Auth struct {
+			Identity struct {
+				Methods  []string
+				Password struct {
+					User struct {
+						Name   string
+						Domain map[string]string
+					}
+				}
+			}
+			Scope map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Auth.Identity.Password.User.Domain["name"] != "ldap" {
+		t.Fatalf("unexpected user domain in %s", body)
+	}
+	if _, ok := req.Auth.Scope["system"]; !ok {
+		t.Fatalf("expected system scope in %s", body)
+	}
+
+	body = authRequestBody(ApplicationCredential{Id: "cred", Secret: "secret"}, nil)
+	if want := `{"auth":{"identity":{"application_credential":{"id":"cred","secret":"secret"},"methods":["application_credential"]}}}`; body != want {
+		t.Fatalf("got %s, want %s", body, want)
+	}
+}
+
+func TestFederate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/OS-FEDERATION/identity_providers/sso/protocols/openid/auth":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Subject-Token", "unscoped")
+			_, _ = w.Write([]byte(`{"token": {}}`))
+		case "/auth/tokens":
+			body, _ := io.ReadAll(r.Body)
+			var req map[string]map[string]map[string]interface{}
+			_ = json.Unmarshal(body, &req)
+			if req["auth"]["identity"]["token"].(map[string]interface{})["id"] != "unscoped" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Subject-Token", "scoped")
+			_, _ = w.Write([]byte(`{"token": {"project": {"name": "demo"}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	keystone := &Keystone{Request: Request{UrlPrefix: server.URL, Client: NewClient()}, Headers: map[string]string{}}
+	token, tokenMap, err := keystone.Federate(Federation{IdentityProvider: "sso", Protocol: "openid", AccessToken: "access"}, ProjectScope("demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "scoped" || tokenMap.Token.Project.Name != "demo" {
+		t.Fatalf("unexpected token %s %+v", token, tokenMap)
+	}
+}
+
+// withConf replaces configs.CONF for the duration of a test.
+func withConf(t *testing.T, conf configs.Openstack) {
+	saved := configs.CONF
+	t.Cleanup(func() { configs.CONF = saved })
+	configs.CONF = configs.Server{Openstack: conf}
+}
+
+func TestConfiguredAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		conf       configs.Openstack
+		wantMethod AuthMethod
+		wantScope  *Scope
+	}{
+		{
+			"password",
+			configs.Openstack{UserDomainName: "ldap", ProjectDomainId: "pd"},
+			Password{UserName: "demo", Password: "secret", UserDomainName: "ldap"},
+			&Scope{ProjectName: "demo", ProjectDomainId: "pd"},
+		},
+		{
+			"domain id",
+			configs.Openstack{DomainId: "d1"},
+			Password{UserName: "demo", Password: "secret"},
+			&Scope{DomainId: "d1"},
+		},
+		{
+			"domain name",
+			configs.Openstack{DomainName: "Default"},
+			Password{UserName: "demo", Password: "secret"},
+			&Scope{DomainName: "Default"},
+		},
+		{
+			"system scope",
+			configs.Openstack{SystemScope: "all", DomainId: "d1"},
+			Password{UserName: "demo", Password: "secret"},
+			&Scope{System: true},
+		},
+		{
+			"token",
+			configs.Openstack{AuthType: "v3token", Token: "t", SystemScope: "all"},
+			Token{Id: "t"},
+			&Scope{System: true},
+		},
+		{
+			"application credential",
+			configs.Openstack{AuthType: "v3applicationcredential", ApplicationCredentialId: "cred", ApplicationCredentialSecret: "s", DomainId: "d1"},
+			ApplicationCredential{Id: "cred", Secret: "s"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConf(t, tt.conf)
+			method, scope := ConfiguredAuth("demo", "demo", "secret")
+			if !reflect.DeepEqual(method, tt.wantMethod) {
+				t.Errorf("method %+v, want %+v", method, tt.wantMethod)
+			}
+			if !reflect.DeepEqual(scope, tt.wantScope) {
+				t.Errorf("scope %+v, want %+v", scope, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestConfiguredFederation(t *testing.T) {
+	tests := []struct {
+		name string
+		conf configs.Openstack
+		want *Federation
+	}{
+		{
+			"oidc access token",
+			configs.Openstack{AuthType: "v3oidcaccesstoken", IdentityProvider: "sso", AccessToken: "access"},
+			&Federation{IdentityProvider: "sso", Protocol: "openid", AccessToken: "access"},
+		},
+		{
+			"saml password",
+			configs.Openstack{AuthType: "v3samlpassword", IdentityProvider: "adfs", Protocol: "mapped", IdentityProviderUrl: "https://idp/ecp"},
+			&Federation{IdentityProvider: "adfs", Protocol: "mapped", IdentityProviderUrl: "https://idp/ecp", Username: "demo", Password: "secret"},
+		},
+		{"password", configs.Openstack{AuthType: "password", IdentityProvider: "sso"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConf(t, tt.conf)
+			federation, ok := ConfiguredFederation("demo", "secret")
+			if ok != (tt.want != nil) || ok && !reflect.DeepEqual(federation, *tt.want) {
+				t.Fatalf("got %+v, %v, want %+v", federation, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueTokenSAMLPassword(t *testing.T) {
+	const authnRequest = `<S:Envelope xmlns:S="http://schemas.xmlsoap.org/soap/envelope/"><S:Header><paos:Request/></S:Header><S:Body><samlp:AuthnRequest/></S:Body></S:Envelope>`
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		if user != "demo" || password != "secret" || strings.Contains(string(body), "Header") || !strings.Contains(string(body), "AuthnRequest") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("<S:Envelope><samlp:Response/></S:Envelope>"))
+	}))
+	t.Cleanup(idp.Close)
+	keystone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/OS-FEDERATION/identity_providers/adfs/protocols/saml2/auth" && r.Method == http.MethodGet:
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.paos+xml") || r.Header.Get("PAOS") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(authnRequest))
+		case r.URL.Path == "/OS-FEDERATION/identity_providers/adfs/protocols/saml2/auth":
+			if r.Header.Get("Content-Type") != "application/vnd.paos+xml" || !strings.Contains(string(body), "samlp:Response") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Subject-Token", "unscoped")
+			_, _ = w.Write([]byte(`{"token": {}}`))
+		case r.URL.Path == "/auth/tokens":
+			var req map[string]map[string]map[string]interface{}
+			_ = json.Unmarshal(body, &req)
+			if req["auth"]["identity"]["token"].(map[string]interface{})["id"] != "unscoped" || req["auth"]["scope"]["system"] == nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Subject-Token", "scoped")
+			_, _ = w.Write([]byte(`{"token": {"system": {"all": true}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(keystone.Close)
+	withConf(t, configs.Openstack{AuthType: "v3samlpassword", IdentityProvider: "adfs", IdentityProviderUrl: idp.URL, SystemScope: "all"})
+
+	client := &Keystone{Request: Request{UrlPrefix: keystone.URL, Client: NewClient()}, Headers: map[string]string{}}
+	token, _, err := client.IssueToken("demo", "demo", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "scoped" {
+		t.Fatalf("got token %s, want scoped", token)
+	}
+}
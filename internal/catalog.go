@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"log"
+	"request_openstack/configs"
+	"request_openstack/consts"
+	"request_openstack/internal/entity"
+	"strings"
+)
+
+// catalogTypes maps a service to the types it is registered with in the
+// keystone catalog, in order of preference.
+var catalogTypes = map[string][]string{
+	consts.KEYSTONE: {"identity"},
+	consts.NOVA:     {"compute"},
+	consts.NEUTRON:  {"network"},
+	consts.CINDER:   {"volumev3", "block-storage", "volume"},
+	consts.GLANCE:   {"image"},
+	consts.OCTAVIA:  {"load-balancer"},
+}
+
+// apiVersions is the API root each client builds its url suffixes on.
+var apiVersions = map[string]string{
+	consts.KEYSTONE: "v3",
+	consts.NOVA:     "v2.1/",
+	consts.NEUTRON:  "v2.0/",
+	consts.CINDER:   "v3/",
+	consts.GLANCE:   "v2",
+	consts.OCTAVIA:  "v2.0/",
+}
+
+var defaultPorts = map[string]int{
+	consts.KEYSTONE: consts.KeystonePort,
+	consts.NOVA:     consts.NovaPort,
+	consts.NEUTRON:  consts.NeutronPort,
+	consts.CINDER:   consts.CinderPort,
+	consts.GLANCE:   consts.GlancePort,
+	consts.OCTAVIA:  consts.OctaviaPort,
+}
+
+// DefaultEndpoint returns the configured override of the service or the
+// plain http url on its well known port of configs.CONF.Host.
+func DefaultEndpoint(service string) string {
+	if service == consts.KEYSTONE && configs.CONF.AuthUrl != "" {
+		return apiRoot(configs.CONF.AuthUrl, apiVersions[service])
+	}
+	if url, ok := configs.CONF.Endpoints[service]; ok && url != "" {
+		return apiRoot(url, apiVersions[service])
+	}
+	return fmt.Sprintf("http://%s:%d/%s", configs.CONF.Host, defaultPorts[service], apiVersions[service])
+}
+
+// EndpointFor resolves the API root of the service from the catalog, matching
+// the interface and region of configs.CONF. Configured overrides win, and a
+// service missing from the catalog falls back to DefaultEndpoint.
+func EndpointFor(catalog entity.Catalog, service string) (string, error) {
+	if url, ok := configs.CONF.Endpoints[service]; ok && url != "" {
+		return apiRoot(url, apiVersions[service]), nil
+	}
+	iface := configs.CONF.Interface
+	if iface == "" {
+		iface = "public"
+	}
+	iface = strings.TrimSuffix(iface, "URL")
+	region := configs.CONF.Region
+
+	found := false
+	for _, serviceType := range catalogTypes[service] {
+		for _, s := range catalog {
+			if s.Type != serviceType {
+				continue
+			}
+			found = true
+			for _, endpoint := range s.Endpoints {
+				if endpoint.Interface != iface {
+					continue
+				}
+				if region != "" && endpoint.Region != region && endpoint.RegionId != region {
+					continue
+				}
+				return apiRoot(endpoint.Url, apiVersions[service]), nil
+			}
+		}
+	}
+	if found {
+		return "", fmt.Errorf("no %s endpoint with interface %s in region %q of the catalog", service, iface, region)
+	}
+	url := DefaultEndpoint(service)
+	log.Printf("Service %s not in the catalog, fall back to %s", service, url)
+	return url, nil
+}
+
+// apiRoot turns a catalog url into the API root a client expects. Anything
+// after the version, like the project id of cinder, is dropped and a missing
+// version is appended, e.g. http://host:9696 becomes http://host:9696/v2.0/.
+func apiRoot(url, version string) string {
+	url = strings.TrimRight(url, "/")
+	trimmed := strings.TrimSuffix(version, "/")
+	if i := strings.Index(url+"/", "/"+trimmed+"/"); i >= 0 {
+		url = url[:i]
+	}
+	return url + "/" + version
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"request_openstack/configs"
+	"request_openstack/consts"
+	"request_openstack/internal/entity"
+	"testing"
+)
+
+func TestApiRoot(t *testing.T) {
+	cases := map[string][2]string{
+		"https://network.example.com":                     {"v2.0/", "https://network.example.com/v2.0/"},
+		"https://compute.example.com/v2.1/":               {"v2.1/", "https://compute.example.com/v2.1/"},
+		"https://volume.example.com:8776/v3/0a1b2c3d4e5f": {"v3/", "https://volume.example.com:8776/v3/"},
+		"https://image.example.com:9292":                  {"v2", "https://image.example.com:9292/v2"},
+	}
+	for url, c := range cases {
+		if got := apiRoot(url, c[0]); got != c[1] {
+			t.Errorf("apiRoot(%s) = %s, want %s", url, got, c[1])
+		}
+	}
+}
+
+func TestEndpointFor(t *testing.T) {
+	conf := configs.CONF
+	defer func() { configs.CONF = conf }()
+	configs.CONF.Interface = "internal"
+	configs.CONF.Region = "RegionTwo"
+
+	catalog := entity.Catalog{{
+		Type: "network",
+		Endpoints: []entity.CatalogEndpoint{
+			{Interface: "public", Region: "RegionTwo", Url: "https://public.example.com:9696"},
+			{Interface: "internal", Region: "RegionOne", Url: "https://one.example.com:9696"},
+			{Interface: "internal", Region: "RegionTwo", Url: "https://two.example.com:9696"},
+		},
+	}}
+	url, err := EndpointFor(catalog, consts.NEUTRON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://two.example.com:9696/v2.0/" {
+		t.Fatalf("unexpected endpoint %s", url)
+	}
+
+	configs.CONF.Region = "RegionThree"
+	if _, err = EndpointFor(catalog, consts.NEUTRON); err == nil {
+		t.Fatal("expected no endpoint in RegionThree")
+	}
+
+	configs.CONF.Endpoints = map[string]string{consts.NEUTRON: "https://override.example.com"}
+	if url, _ = EndpointFor(catalog, consts.NEUTRON); url != "https://override.example.com/v2.0/" {
+		t.Fatalf("unexpected override %s", url)
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -13,7 +14,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 )
 
 var maxEfficientQOS string
@@ -30,7 +30,6 @@ type Cinder struct {
 	headers              map[string]string
 	snowflake            *utils.Snowflake
 	wg                   sync.WaitGroup
-	ctx                  context.Context
 	tag                  string
 	DeleteChannels       map[string]chan Output
 	mu                   sync.Mutex
@@ -61,6 +60,7 @@ func NewCinder(options ...Option) *Cinder {
 	headers[consts.AuthToken] = opts.Token
 	cinder.headers = headers
 	cinder.Request = opts.Request
+	cinder.Service = consts.CINDER
 	return cinder
 }
 
@@ -79,14 +79,14 @@ func (c *Cinder) makeDeleteChannel(resourceType string, length int) chan Output
 }
 
 
+
 // Volume type
-func (c *Cinder) createVolumeType(projectId string, reqBody string) map[string]interface{} {
+func (c *Cinder) createVolumeType(projectId string, reqBody string) (map[string]interface{}, error) {
 	PostUrl := fmt.Sprintf("%s/types", projectId)
-	resp := c.DecorateResp(c.Post)(c.headers, PostUrl, reqBody)
-	return resp
+	return c.DecorateResp(c.Post)(c.headers, PostUrl, reqBody)
 }
 
-func (c *Cinder) UpdateVolumeType(volumeTypeId, name, desc string) entity.VolumeType {
+func (c *Cinder) UpdateVolumeType(volumeTypeId, name, desc string) (entity.VolumeType, error) {
 	reqSuffix := fmt.Sprintf("%s/types/%s", c.adminProjectId, volumeTypeId)
 	formatter := `{
                        "volume_type": {
@@ -95,10 +95,13 @@ func (c *Cinder) UpdateVolumeType(volumeTypeId, name, desc string) entity.Volume
                        }
                   }`
 	reqBody := fmt.Sprintf(formatter, name, desc)
-	resp := c.Put(c.headers, reqSuffix, reqBody)
 	var volumeType entity.VolumeType
+	resp, err := c.Put(c.headers, reqSuffix, reqBody)
+	if err != nil {
+		return volumeType, err
+	}
 	_ = json.Unmarshal(resp, &volumeType)
-	return volumeType
+	return volumeType, nil
 }
 
 func (c *Cinder) constructVolumeTypeSSD(size int) string {
@@ -122,30 +125,36 @@ func (c *Cinder) constructVolumeTypeCommon() string {
 	return reqBody
 }
 
-func (c *Cinder) VolumeTypeAssociateQos(projectId, qosId, volTypeId string) map[string]interface{} {
+func (c *Cinder) VolumeTypeAssociateQos(projectId, qosId, volTypeId string) (map[string]interface{}, error) {
 	URL := fmt.Sprintf("/%s/qos-specs/%s/associate?vol_type_id=%s", projectId, qosId, volTypeId)
 	return c.DecorateGetResp(c.Get)(c.headers, URL)
 }
 
-func (c *Cinder) GetVolumeType(volumeTypeId string) entity.VolumeType {
-	resp := c.Get(c.headers, fmt.Sprintf("%s/types/%s", c.adminProjectId, volumeTypeId))
+func (c *Cinder) GetVolumeType(volumeTypeId string) (entity.VolumeType, error) {
 	var volumeType entity.VolumeType
+	resp, err := c.Get(c.headers, fmt.Sprintf("%s/types/%s", c.adminProjectId, volumeTypeId))
+	if err != nil {
+		return volumeType, err
+	}
 
 	log.Println(string(resp))
 	_ = json.Unmarshal(resp, &volumeType)
 
-	return volumeType
+	return volumeType, nil
 }
 
-func (c *Cinder) ListVolumeTypes() entity.VolumeTypes {
-	resp := c.List(c.headers, fmt.Sprintf("%s/types", c.adminProjectId))
+func (c *Cinder) ListVolumeTypes() (entity.VolumeTypes, error) {
 	var volumeTypes entity.VolumeTypes
+	resp, err := c.List(c.headers, fmt.Sprintf("%s/types", c.adminProjectId))
+	if err != nil {
+		return volumeTypes, err
+	}
 	_ = json.Unmarshal(resp, &volumeTypes)
 
-	return volumeTypes
+	return volumeTypes, nil
 }
 
-func (c *Cinder) AddExtraSpecsForVolumeType(volumeTypeId string, key, val string) {
+func (c *Cinder) AddExtraSpecsForVolumeType(volumeTypeId string, key, val string) error {
      urlSuffix := fmt.Sprintf("%s/types/%s/extra_specs", c.adminProjectId, volumeTypeId)
 	 formatter := `{
                    "extra_specs": {
@@ -153,12 +162,26 @@ func (c *Cinder) AddExtraSpecsForVolumeType(volumeTypeId string, key, val string
                    }
                 }`
 	 reqBody := fmt.Sprintf(formatter, key, val)
-     c.Post(c.headers, urlSuffix, reqBody)
+     if _, err := c.Post(c.headers, urlSuffix, reqBody); err != nil {
+     	return err
+	 }
 
 	log.Println("==============Add extra specs for volume type success", volumeTypeId)
+	return nil
+}
+
+// createQosVolumeType creates a volume type and associates it with the qos spec.
+func (c *Cinder) createQosVolumeType(qosId, reqVolType string) (map[string]interface{}, error) {
+	volType, err := c.createVolumeType(c.adminProjectId, reqVolType)
+	if err != nil {
+		return nil, err
+	}
+	volTypeId := c.parseVolumeTypeId(volType)
+	log.Println(volType)
+	return c.VolumeTypeAssociateQos(c.adminProjectId, qosId, volTypeId)
 }
 
-func (c *Cinder) RequestQosLimitCommons() {
+func (c *Cinder) RequestQosLimitCommons() error {
 	readIopsSec := "600"
 	writeIopsSec := readIopsSec
 	readBytesSec := fmt.Sprintf("%d", 30 * 1024 * 1024)
@@ -166,92 +189,108 @@ func (c *Cinder) RequestQosLimitCommons() {
 
 	reqBody := c.constructQosBody(readIopsSec, writeIopsSec, readBytesSec, writeBytesSec)
 	log.Println("create volume qos")
-	var qos map[string]interface{}
-	qos = c.createVolumeQOS(c.adminProjectId, reqBody)
+	qos, err := c.createVolumeQOS(c.adminProjectId, reqBody)
+	if err != nil {
+		return err
+	}
 	qosId := c.parseVolumeQosId(qos)
 
 	reqVolType := c.constructVolumeTypeCommon()
-	volType := c.createVolumeType(c.adminProjectId, reqVolType)
-	volTypeId := c.parseVolumeTypeId(volType)
-	log.Println(volType)
-
-	resp := c.VolumeTypeAssociateQos(c.adminProjectId, qosId, volTypeId)
+	resp, err := c.createQosVolumeType(qosId, reqVolType)
+	if err != nil {
+		return err
+	}
 	log.Println("completed", resp)
+	return nil
 }
 
-func (c *Cinder) RequestQosLimitEfficients()  {
+func (c *Cinder) RequestQosLimitEfficients() error {
 	for i := 50; i <= 1000; i=i+50 {
 		readIopsSec := fmt.Sprintf("%g", math.Min(float64(1800 + 8 * i), 5000))
 		writeIopsSec := readIopsSec
 		readBytesSec := fmt.Sprintf("%.f", math.Min(100 + 0.15 * float64(i), 130) * 1024 * 1024)
 		writeBytesSec := readBytesSec
 		reqBody := c.constructQosBody(readIopsSec, writeIopsSec, readBytesSec, writeBytesSec)
-		var qos map[string]interface{}
 		log.Println("create volume qos", i)
 		if i <= 400 {
-			qos = c.createVolumeQOS(c.adminProjectId, reqBody)
+			qos, err := c.createVolumeQOS(c.adminProjectId, reqBody)
+			if err != nil {
+				return err
+			}
 			qosId := c.parseVolumeQosId(qos)
 			maxEfficientQOS = qosId
 		}
 		log.Println(maxEfficientQOS)
 		reqVolType := c.constructVolumeTypeEfficient(i)
-		volType := c.createVolumeType(c.adminProjectId, reqVolType)
-		volTypeId := c.parseVolumeTypeId(volType)
-		log.Println(volType)
-		resp := c.VolumeTypeAssociateQos(c.adminProjectId, maxEfficientQOS, volTypeId)
+		resp, err := c.createQosVolumeType(maxEfficientQOS, reqVolType)
+		if err != nil {
+			return err
+		}
 		log.Println("completed", i, resp)
 	}
+	return nil
 }
 
-func (c *Cinder) RequestQosLimitSSDs()  {
+func (c *Cinder) RequestQosLimitSSDs() error {
 	for i := 50; i <= 1000; i=i+50 {
 		readIopsSec := fmt.Sprintf("%g", math.Min(float64(1800 + 30 * i), 25000))
 		writeIopsSec := readIopsSec
 		readBytesSec := fmt.Sprintf("%.f", math.Min(120 + 0.5 * float64(i), 256) * 1024 * 1024)
 		writeBytesSec := readBytesSec
 		reqBody := c.constructQosBody(readIopsSec, writeIopsSec, readBytesSec, writeBytesSec)
-		var qos map[string]interface{}
 		log.Println("create volume qos", i)
 		if i <= 800 {
-			qos = c.createVolumeQOS(c.adminProjectId, reqBody)
+			qos, err := c.createVolumeQOS(c.adminProjectId, reqBody)
+			if err != nil {
+				return err
+			}
 			maxSSDEfficientQOS = c.parseVolumeQosId(qos)
 		}
 		log.Println(maxSSDEfficientQOS)
 		reqVolType := c.constructVolumeTypeSSD(i)
-		volType := c.createVolumeType(c.adminProjectId, reqVolType)
-		volTypeId := c.parseVolumeTypeId(volType)
-		log.Println(volType)
-		resp := c.VolumeTypeAssociateQos(c.adminProjectId, maxSSDEfficientQOS, volTypeId)
+		resp, err := c.createQosVolumeType(maxSSDEfficientQOS, reqVolType)
+		if err != nil {
+			return err
+		}
 		log.Println("completed", i, resp)
 	}
+	return nil
 }
 
-func (c *Cinder) ListVolumeQos() entity.QosSpecss {
+func (c *Cinder) ListVolumeQos() (entity.QosSpecss, error) {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs", c.projectId)
-	resp := c.Get(c.headers, urlSuffix)
-
 	var qss entity.QosSpecss
+	resp, err := c.List(c.headers, urlSuffix)
+	if err != nil {
+		return qss, err
+	}
+
 	_ = json.Unmarshal(resp, &qss)
 	log.Println("==============Get qos specs success")
-    return qss
+    return qss, nil
 }
 
-func (c *Cinder) UpdateQosSpec(qosId, updateBody string)  {
+func (c *Cinder) UpdateQosSpec(qosId, updateBody string) error {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs/%s", c.projectId, qosId)
-	c.Put(c.headers, urlSuffix, updateBody)
+	_, err := c.Put(c.headers, urlSuffix, updateBody)
+	return err
 }
 
-func (c *Cinder) QosSpecDeleteKey(qosId string, keyName string)  {
+func (c *Cinder) QosSpecDeleteKey(qosId string, keyName string) error {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs/%s/delete_keys", c.projectId, qosId)
 	formatter := `{
                       "keys": ["%+v"]
                   }`
 	updateBody := fmt.Sprintf(formatter, keyName)
-	c.Put(c.headers, urlSuffix, updateBody)
+	_, err := c.Put(c.headers, urlSuffix, updateBody)
+	return err
 }
 
-func (c *Cinder) CorrectQosSpecs() {
-    qss := c.ListVolumeQos()
+func (c *Cinder) CorrectQosSpecs() error {
+    qss, err := c.ListVolumeQos()
+    if err != nil {
+    	return err
+	}
     for _, qs := range qss.Qss {
     	qosId := qs.Id
     	byteVal := qs.Specs.ReadBytesSecMax
@@ -259,58 +298,83 @@ func (c *Cinder) CorrectQosSpecs() {
     	byteConvertVal, _ := strconv.ParseFloat(byteVal, 64)
     	byteVal = strconv.FormatFloat(byteConvertVal, 'f', -1, 64)
     	body := c.constructQosBody(iopsVal, iopsVal, byteVal, byteVal)
-		c.UpdateQosSpec(qosId, body)
+		if err = c.UpdateQosSpec(qosId, body); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (c *Cinder) DeleteQosSpecKeyName()  {
-	qss := c.ListVolumeQos()
+func (c *Cinder) DeleteQosSpecKeyName() error {
+	qss, err := c.ListVolumeQos()
+	if err != nil {
+		return err
+	}
 	for _, qs := range qss.Qss {
 		fmt.Printf("%+v", qs.Specs)
 		//if len(qs.Name) != 0 {
 		//	c.QosSpecDeleteKey(qosId, "name")
 		//}
 	}
+	return nil
 }
 
-func (c *Cinder) ListQosAndDisassociateAll()  {
-	resp := c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/qos-specs", c.projectId))
-	qosSpecs := resp["qos_specs"]
-	for _, v := range qosSpecs.([]interface{}) {
+func (c *Cinder) ListQosAndDisassociateAll() error {
+	resp, err := c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/qos-specs", c.projectId))
+	if err != nil {
+		return err
+	}
+	qosSpecs, _ := resp["qos_specs"].([]interface{})
+	for _, v := range qosSpecs {
 		qos := v.(map[string]interface{})
 		name := qos["name"].(string)
 		qosId := qos["id"].(string)
 		if strings.Contains(name, "read_iops_sec") {
-            c.QosDisAssociateAll(qosId)
+            if err = c.QosDisAssociateAll(qosId); err != nil {
+            	return err
+			}
+		}
+		if err = c.DeleteVolumeQos(qosId); err != nil {
+			return err
 		}
-		c.DeleteVolumeQos(qosId)
 	}
+	return nil
 }
 
-func (c *Cinder) QosDisAssociateAll(qosId string)  {
-	c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/qos-specs/%s/disassociate_all", c.projectId, qosId))
+func (c *Cinder) QosDisAssociateAll(qosId string) error {
+	_, err := c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/qos-specs/%s/disassociate_all", c.projectId, qosId))
+	return err
 }
 
-func (c *Cinder) DeleteVolumeQos(qosId string)  {
-	c.Delete(c.headers, fmt.Sprintf("/%s/qos-specs/%s", c.projectId, qosId))
+func (c *Cinder) DeleteVolumeQos(qosId string) error {
+	return c.Delete(c.headers, fmt.Sprintf("/%s/qos-specs/%s", c.projectId, qosId))
 }
 
-func (c *Cinder) ListVolumeTypeAndDelete() {
-	resp := c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/types", c.projectId))
-	volumeTypes := resp["volume_types"]
-	for _, v := range volumeTypes.([]interface{}) {
+func (c *Cinder) ListVolumeTypeAndDelete() error {
+	resp, err := c.DecorateGetResp(c.Get)(c.headers, fmt.Sprintf("/%s/types", c.projectId))
+	if err != nil {
+		return err
+	}
+	volumeTypes, _ := resp["volume_types"].([]interface{})
+	for _, v := range volumeTypes {
 		typ := v.(map[string]interface{})
 		name := typ["name"].(string)
 		id := typ["id"].(string)
 		if strings.Contains(name, "SEBS") {
-			c.deleteVolumeType(id)
+			if err = c.deleteVolumeType(id); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-func (c *Cinder) deleteVolumeType(typeId string)  {
-	c.Delete(c.headers, fmt.Sprintf("/%s/types/%s", c.projectId, typeId))
+func (c *Cinder) deleteVolumeType(typeId string) error {
+	if err := c.Delete(c.headers, fmt.Sprintf("/%s/types/%s", c.projectId, typeId)); err != nil {
+		return err
+	}
 	log.Println("success delete", typeId)
+	return nil
 }
 
 
@@ -332,22 +396,44 @@ func (c *Cinder) parseVolumeTypeId(resp map[string]interface{}) string {
 	return ""
 }
 
-func (c *Cinder) RequestQosLimitScenario()  {
-    c.RequestQosLimitCommons()
-    c.RequestQosLimitSSDs()
-    c.RequestQosLimitEfficients()
+func (c *Cinder) RequestQosLimitScenario() error {
+    if err := c.RequestQosLimitCommons(); err != nil {
+    	return err
+	}
+    if err := c.RequestQosLimitSSDs(); err != nil {
+    	return err
+	}
+    return c.RequestQosLimitEfficients()
 }
 
-func (c *Cinder) RequestClearQosLimit()  {
-	c.ListQosAndDisassociateAll()
-	c.ListVolumeTypeAndDelete()
+func (c *Cinder) RequestClearQosLimit() error {
+	if err := c.ListQosAndDisassociateAll(); err != nil {
+		return err
+	}
+	return c.ListVolumeTypeAndDelete()
 }
 
 // volume
 
-// CreateVolume create volume
-func (c *Cinder) CreateVolume() string {
+// createVolume posts the volume body and waits for the volume to become available.
+func (c *Cinder) createVolume(reqBody string) (string, error) {
 	urlSuffix := fmt.Sprintf("/%s/volumes", c.projectId)
+	resp, err := c.Post(c.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
+	var volume entity.VolumeMap
+	_ = json.Unmarshal(resp, &volume)
+
+	//cache.RedisClient.AddSliceAndJson(volumeId, c.tag + consts.VOLUMES, volume)
+	if err = c.MakeSureVolumeAvailable(volume.Id); err != nil {
+		return volume.Id, err
+	}
+	return volume.Id, nil
+}
+
+// CreateVolume create volume
+func (c *Cinder) CreateVolume() (string, error) {
 	name := c.snowflake.NextVal()
 	formatter := `{
                      "volume": {
@@ -356,18 +442,24 @@ func (c *Cinder) CreateVolume() string {
                      }
                   }`
 	reqBody := fmt.Sprintf(formatter, name)
-	resp := c.Post(c.headers, urlSuffix, reqBody)
-    var volume entity.VolumeMap
-    _ = json.Unmarshal(resp, &volume)
+	volumeId, err := c.createVolume(reqBody)
+	if err != nil {
+		return volumeId, err
+	}
+	log.Println("==============Create volume success", volumeId)
+	return volumeId, nil
+}
 
-	//cache.RedisClient.AddSliceAndJson(volumeId, c.tag + consts.VOLUMES, volume)
-	c.MakeSureVolumeAvailable(volume.Id)
-	log.Println("==============Create volume success", volume.Id)
-	return volume.Id
+func (c *Cinder) CreateVolumeByOpts(opts *entity.CreateVolumeOpts) (string, error) {
+	volumeId, err := c.createVolume(opts.ToRequestBody())
+	if err != nil {
+		return volumeId, err
+	}
+	log.Println("==============Create volume success", volumeId)
+	return volumeId, nil
 }
 
-func (c *Cinder) CreateVolumeBySnapshot(snapshotId string) string {
-	urlSuffix := fmt.Sprintf("/%s/volumes", c.projectId)
+func (c *Cinder) CreateVolumeBySnapshot(snapshotId string) (string, error) {
 	name := fmt.Sprintf("snapshot-%s_to_volume", snapshotId)
 	formatter := `{
                      "volume": {
@@ -376,18 +468,15 @@ func (c *Cinder) CreateVolumeBySnapshot(snapshotId string) string {
                      }
                   }`
 	reqBody := fmt.Sprintf(formatter, name, snapshotId)
-	resp := c.Post(c.headers, urlSuffix, reqBody)
-	var volume entity.VolumeMap
-	_ = json.Unmarshal(resp, &volume)
-
-	//cache.RedisClient.AddSliceAndJson(volumeId, c.tag + consts.VOLUMES, volume)
-	c.MakeSureVolumeAvailable(volume.Id)
-	log.Println("==============Create volume from snapshot success", volume.Id)
-	return volume.Id
+	volumeId, err := c.createVolume(reqBody)
+	if err != nil {
+		return volumeId, err
+	}
+	log.Println("==============Create volume from snapshot success", volumeId)
+	return volumeId, nil
 }
 
-func (c *Cinder) CreateVolumeByVolume(volumeId string) string {
-	urlSuffix := fmt.Sprintf("/%s/volumes", c.projectId)
+func (c *Cinder) CreateVolumeByVolume(volumeId string) (string, error) {
 	name := fmt.Sprintf("volume-%s_to_volume", volumeId)
 	formatter := `{
                      "volume": {
@@ -396,139 +485,204 @@ func (c *Cinder) CreateVolumeByVolume(volumeId string) string {
                      }
                   }`
 	reqBody := fmt.Sprintf(formatter, name, volumeId)
-	resp := c.Post(c.headers, urlSuffix, reqBody)
-	var volume entity.VolumeMap
-	_ = json.Unmarshal(resp, &volume)
-
-	//cache.RedisClient.AddSliceAndJson(volumeId, c.tag + consts.VOLUMES, volume)
-	c.MakeSureVolumeAvailable(volume.Id)
-	log.Println("==============Create volume from volume success", volume.Id)
-	return volume.Id
+	newVolumeId, err := c.createVolume(reqBody)
+	if err != nil {
+		return newVolumeId, err
+	}
+	log.Println("==============Create volume from volume success", newVolumeId)
+	return newVolumeId, nil
 }
 
 
-func (c *Cinder) SetVolumeBootable(volumeId string) string {
+func (c *Cinder) SetVolumeBootable(volumeId string) (string, error) {
 	urlSuffix := fmt.Sprintf("%s/volumes/%s/action", c.projectId, volumeId)
 	formatter := `{"os-set_bootable": {"bootable": true}}`
-	resp := c.Post(c.headers, urlSuffix, formatter)
+	resp, err := c.Post(c.headers, urlSuffix, formatter)
+	if err != nil {
+		return "", err
+	}
 	var volume entity.VolumeMap
 	_ = json.Unmarshal(resp, &volume)
 
 	//cache.RedisClient.AddSliceAndJson(volumeId, c.tag + consts.VOLUMES, volume)
-	c.MakeSureVolumeAvailable(volumeId)
+	if err = c.MakeSureVolumeAvailable(volumeId); err != nil {
+		return volume.Id, err
+	}
 	log.Println("==============Set volume bootable success", volume.Id)
-	return volume.Id
-}
-
-func (c *Cinder) MakeSureVolumeAvailable(volumeId string) {
-	volume := c.GetVolume(volumeId)
-	done := make(chan bool, 1)
-	go func() {
-		state := volume.Status
-		for state != consts.Available && state != consts.Error {
-			time.Sleep(consts.IntervalTime)
-			volume = c.GetVolume(volumeId)
-			state = volume.Status
+	return volume.Id, nil
+}
+
+func (c *Cinder) MakeSureVolumeAvailable(volumeId string) error {
+	err := Poll(c.Context(), consts.Timeout, consts.IntervalTime, func() (bool, error) {
+		volume, err := c.GetVolume(volumeId)
+		if err != nil {
+			return false, err
 		}
-		done <- true
-	}()
-	select {
-	case <-done:
-		log.Println("*******************Create Volume success")
-	case <-time.After(consts.Timeout):
-		log.Fatalln("*******************Create volume timeout")
+		switch volume.Status {
+		case consts.Available:
+			return true, nil
+		case consts.Error:
+			return false, fmt.Errorf("volume %s went into error status", volumeId)
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Println("*******************Create volume timeout")
+		return fmt.Errorf("volume %s not available after %s: %w", volumeId, consts.Timeout, err)
+	}
+	if err != nil {
+		return err
 	}
+	log.Println("*******************Create Volume success")
+	return nil
 }
 
-func (c *Cinder) DeleteProjectVolume(volumeId string) {
+func (c *Cinder) DeleteProjectVolume(volumeId string) error {
 	defer c.wg.Done()
 	urlSuffix := fmt.Sprintf("/%s/volumes/%s", c.projectId, volumeId)
-	if ok, _ := c.Delete(c.headers, urlSuffix); ok {
-		log.Println("==============Delete volume success", volumeId)
-	} else {
-		log.Fatalln("==============Delete volume failed", volumeId)
+	if err := c.Delete(c.headers, urlSuffix); err != nil {
+		log.Println("==============Delete volume failed", volumeId)
+		return err
 	}
+	log.Println("==============Delete volume success", volumeId)
+	return nil
 }
 
-func (c *Cinder) UpdateVolumeNoAttachments(volumeId string) {
+func (c *Cinder) UpdateVolumeNoAttachments(volumeId string) error {
 	urlSuffix := fmt.Sprintf("/%s/volumes/%s", c.adminProjectId, volumeId)
 	formatter := `{
                      "volume": {
                          "attachments": []
                      }
                   }`
-	resp := c.Put(c.headers, urlSuffix, formatter)
+	resp, err := c.Put(c.headers, urlSuffix, formatter)
+	if err != nil {
+		return err
+	}
 	var volume entity.VolumeMap
 	_ = json.Unmarshal(resp, &volume)
 
-	c.MakeSureVolumeAvailable(volume.Id)
+	if err = c.MakeSureVolumeAvailable(volume.Id); err != nil {
+		return err
+	}
 	log.Println("==============Update volume no attachments success", volume.Id)
+	return nil
 }
 
-func (c *Cinder) ListProjectVolumes() entity.Volumes {
-	res := c.List(c.headers, fmt.Sprintf("/%s/volumes", c.projectId))
+func (c *Cinder) ListProjectVolumes() (entity.Volumes, error) {
 	var volumes entity.Volumes
+	res, err := c.List(c.headers, fmt.Sprintf("/%s/volumes", c.projectId))
+	if err != nil {
+		return volumes, err
+	}
 	_ = json.Unmarshal(res, &volumes)
 	log.Println("==============List volume success")
-	return volumes
+	return volumes, nil
+}
+
+// projectOpts lists the resources of the project through the admin project.
+func (c *Cinder) projectOpts() entity.ListVolumesOpts {
+	return entity.ListVolumesOpts{AllTenants: true, ProjectId: c.projectId}
 }
 
-func (c *Cinder) ListVolumes() entity.Volumes {
-	res := c.List(c.headers, fmt.Sprintf("/%s/volumes/detail?all_tenants=True&project_id=%s", c.adminProjectId, c.projectId))
+func (c *Cinder) ListVolumes(opts entity.ListVolumesOpts) (entity.Volumes, error) {
 	var volumes entity.Volumes
+	res, err := c.ListBy(c.headers, fmt.Sprintf("/%s/volumes/detail", c.adminProjectId), opts)
+	if err != nil {
+		return volumes, err
+	}
 	_ = json.Unmarshal(res, &volumes)
 	log.Println("==============List volume success, there had", len(volumes.Vs))
-	return volumes
+	return volumes, nil
 }
 
-func (c *Cinder) GetVolume(volumeId string) entity.VolumeMap {
-	res := c.Get(c.headers, fmt.Sprintf("%s/volumes/%s", c.projectId, volumeId))
+func (c *Cinder) GetVolume(volumeId string) (entity.VolumeMap, error) {
 	var volume entity.VolumeMap
+	res, err := c.Get(c.headers, fmt.Sprintf("%s/volumes/%s", c.projectId, volumeId))
+	if err != nil {
+		return volume, err
+	}
 	_ = json.Unmarshal(res, &volume)
 	log.Println("==============Get volume success", volumeId)
-	return volume
+	return volume, nil
+}
+
+// ListAttachments lists the attachments of the attachments API, which needs
+// microversion 3.27.
+func (c *Cinder) ListAttachments(opts entity.ListAttachmentsOpts) (entity.VolumeAttachments, error) {
+	var attachments entity.VolumeAttachments
+	headers, err := c.MicroversionHeaders(c.headers, "3.27")
+	if err != nil {
+		return attachments, err
+	}
+	res, err := c.ListBy(headers, fmt.Sprintf("%s/attachments", c.projectId), opts)
+	if err != nil {
+		return attachments, err
+	}
+	_ = json.Unmarshal(res, &attachments)
+	log.Println("==============List attachment success, there had", len(attachments.As))
+	return attachments, nil
 }
 
-func (c *Cinder) CreateVolumes() {
+func (c *Cinder) CreateVolumes() error {
+	errs := make(chan error, 2)
 	for i := 0;i < 2;i++ {
 		c.wg.Add(1)
-		go c.CreateVolume()
+		go func() {
+			defer c.wg.Done()
+			_, err := c.CreateVolume()
+			errs <- err
+		}()
 		log.Println("create volume", i)
 	}
 	c.wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (c *Cinder) CinderDetachVolume(volumeId string) {
+func (c *Cinder) CinderDetachVolume(volumeId string) error {
 	urlSuffix := fmt.Sprintf("%s/volumes/%s/action", c.projectId, volumeId)
 	formatter := `{
 		"os-detach": {
 		}
 	}`
-	c.Post(c.headers, urlSuffix, formatter)
+	if _, err := c.Post(c.headers, urlSuffix, formatter); err != nil {
+		return err
+	}
 	log.Println("==============Detach volume success", volumeId)
+	return nil
 }
 
-func (c *Cinder) DetachVolume(volumeId string) {
+func (c *Cinder) DetachVolume(volumeId string) error {
 	urlSuffix := fmt.Sprintf("%s/volumes/%s/action", c.adminProjectId, volumeId)
 	formatter := `{
 		"os-detach": {
 		}
 	}`
-	c.Post(c.headers, urlSuffix, formatter)
+	if _, err := c.Post(c.headers, urlSuffix, formatter); err != nil {
+		return err
+	}
 	log.Println("==============Detach volume success", volumeId)
+	return nil
 }
 
 
-func (c *Cinder) DeleteAttachment(attachmentId string) {
+func (c *Cinder) DeleteAttachment(attachmentId string) error {
 	urlSuffix := fmt.Sprintf("%s/attachments/%s", c.adminProjectId, attachmentId)
-	if ok, _ := c.Delete(c.headers, urlSuffix); ok {
-		log.Println("==============Delete attachment success", attachmentId)
-		return
+	if err := c.Delete(c.headers, urlSuffix); err != nil {
+		log.Println("==============Delete attachment failed", attachmentId)
+		return err
 	}
-	log.Println("==============Delete attachment failed", attachmentId)
+	log.Println("==============Delete attachment success", attachmentId)
+	return nil
 }
 
-func (c *Cinder) UploadToImage(volumeId string) string {
+func (c *Cinder) UploadToImage(volumeId string) (string, error) {
 	imageName := fmt.Sprintf("%s_to_image", volumeId)
 	urlSuffix := fmt.Sprintf("/%s/volumes/%s/action", c.projectId, volumeId)
 	formatter := `{
@@ -542,79 +696,94 @@ func (c *Cinder) UploadToImage(volumeId string) string {
 		}
 	}`
 	reqBody := fmt.Sprintf(formatter, imageName)
-	resp := c.Post(c.headers, urlSuffix, reqBody)
+	resp, err := c.Post(c.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var volumeToImage entity.VolumeToImage
 	_ = json.Unmarshal(resp, &volumeToImage)
 	log.Println("==============Volume uploads to image request success", volumeId)
-	return volumeToImage.OsVolumeUploadImage.ImageId
+	return volumeToImage.OsVolumeUploadImage.ImageId, nil
 }
 
 func (c *Cinder) DeleteVolume(volumeId string, ch chan Output) {
 	outputObj := Output{ParametersMap: map[string]string{"volume_id": volumeId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-		ch <- outputObj
-	}()
-
 	urlSuffix := fmt.Sprintf("/%s/volumes/%s", c.adminProjectId, volumeId)
-	outputObj.Success, outputObj.Response = c.Delete(c.headers, urlSuffix)
+	outputObj.setErr(c.Delete(c.headers, urlSuffix))
+	ch <- outputObj
 }
 
-func (c *Cinder) DeleteVolumes() {
-	volumes := c.ListVolumes()
+func (c *Cinder) DeleteVolumes() error {
+	volumes, err := c.ListVolumes(c.projectOpts())
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := c.makeDeleteChannel(consts.VOLUME, len(volumes.Vs))
 	for _, volume := range volumes.Vs {
+		if !c.selects(volume) {
+			continue
+		}
 		if len(volume.Attachments) != 0 {
 			for _, attachment := range volume.Attachments {
-				c.DeleteAttachment(attachment.AttachmentId)
+				// a failed detach surfaces through the volume delete output
+				_ = c.DeleteAttachment(attachment.AttachmentId)
 			}
 		}
-		go c.DeleteVolume(volume.Id, ch)
-	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			c.DeleteVolume(id, ch)
+		}(volume.Id)
 	}
+	wg.Wait()
 	log.Println("Volumes were deleted completely")
+	return nil
 }
 
-func (c *Cinder) DeleteVolumesFromRedis() {
+func (c *Cinder) DeleteVolumesFromRedis() error {
 	volumeIds := cache.RedisClient.GetMembers(c.tag + consts.VOLUMES)
 	for _, volumeId := range volumeIds {
-		c.DeleteProjectVolume(volumeId)
+		c.wg.Add(1)
+		if err := c.DeleteProjectVolume(volumeId); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (c *Cinder) CheckSysOrDataDisk(volumeId string) bool {
-	volume := c.GetVolume(volumeId)
+func (c *Cinder) CheckSysOrDataDisk(volumeId string) (bool, error) {
+	volume, err := c.GetVolume(volumeId)
+	if err != nil {
+		return false, err
+	}
 	if volume.Bootable == "true" {
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
 }
 
 // Volume Qos
 
 //AddQosSpecs add qos specs
-func (c *Cinder) AddQosSpecs(qosId, reqBody string) map[string]interface{} {
+func (c *Cinder) AddQosSpecs(qosId, reqBody string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs/%s", c.projectId, qosId)
-	resp := c.DecorateResp(c.Put)(c.headers, urlSuffix, reqBody)
-	return resp
+	return c.DecorateResp(c.Put)(c.headers, urlSuffix, reqBody)
 }
 
-func (c *Cinder) ListQos() map[string]interface{} {
+func (c *Cinder) ListQos() (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs", c.projectId)
 	return c.DecorateGetResp(c.List)(c.headers, urlSuffix)
 }
 
-func (c *Cinder) createVolumeQOS(projectId string, reqBody string) map[string]interface{} {
+func (c *Cinder) createVolumeQOS(projectId string, reqBody string) (map[string]interface{}, error) {
 	PostUrl := fmt.Sprintf("/%s/qos-specs", projectId)
-	resp := c.DecorateResp(c.Post)(c.headers, PostUrl, reqBody)
+	resp, err := c.DecorateResp(c.Post)(c.headers, PostUrl, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	log.Println("create volume qos resp", resp)
-	return resp
+	return resp, nil
 }
 
 func (c *Cinder) constructQosBody(readIopsSec, writeIopsSec, readBytesSec, writeBytesSec string) string {
@@ -629,10 +798,13 @@ func (c *Cinder) constructQosBody(readIopsSec, writeIopsSec, readBytesSec, write
 	return body
 }
 
-func (c *Cinder) RequestAddVolumeQosSpecs() {
-	resp := c.ListQos()
-	qosSpecs := resp["qos_specs"]
-	for _, v := range qosSpecs.([]interface{}) {
+func (c *Cinder) RequestAddVolumeQosSpecs() error {
+	resp, err := c.ListQos()
+	if err != nil {
+		return err
+	}
+	qosSpecs, _ := resp["qos_specs"].([]interface{})
+	for _, v := range qosSpecs {
 		qos := v.(map[string]interface{})
 		log.Println("qos==", qos)
 		qosId := qos["id"]
@@ -656,22 +828,32 @@ func (c *Cinder) RequestAddVolumeQosSpecs() {
 			putBody.WriteString(strings.Join(tempStr, ", "))
 			putBody.WriteString("}}")
 			log.Println("putBody", putBody.String())
-			resp = c.AddQosSpecs(qosId.(string), putBody.String())
+			resp, err = c.AddQosSpecs(qosId.(string), putBody.String())
+			if err != nil {
+				return err
+			}
 			log.Println("增加_max参数，resp==", resp)
 		}
 	}
+	return nil
 }
 
-func (c *Cinder) updateQos(qosId, reqBody string) {
+func (c *Cinder) updateQos(qosId, reqBody string) error {
 	urlSuffix := fmt.Sprintf("/%s/qos-specs/%s", c.projectId, qosId)
-    c.Put(c.headers, urlSuffix, reqBody)
+    if _, err := c.Put(c.headers, urlSuffix, reqBody); err != nil {
+    	return err
+	}
 	log.Println("==============Update qos success")
+	return nil
 }
 
-func (c *Cinder) ModifyQosSpecs() {
-	resp := c.ListQos()
-	qosSpecs := resp["qos_specs"]
-	for _, v := range qosSpecs.([]interface{}) {
+func (c *Cinder) ModifyQosSpecs() error {
+	resp, err := c.ListQos()
+	if err != nil {
+		return err
+	}
+	qosSpecs, _ := resp["qos_specs"].([]interface{})
+	for _, v := range qosSpecs {
 		qos := v.(map[string]interface{})
 		log.Println("qos==", qos)
 		qosId := qos["id"].(string)
@@ -710,82 +892,108 @@ func (c *Cinder) ModifyQosSpecs() {
 			}
 			putBody := fmt.Sprintf(formatter, readBytesSec, readBytesSecMax, writeBytesSec, writeBytesSecMax)
 			log.Println("putBody", putBody)
-			c.updateQos(qosId, putBody)
+			if err = c.updateQos(qosId, putBody); err != nil {
+				return err
+			}
 			log.Println("修改吞吐参数 success")
 		}
 	}
+	return nil
 }
 
 // snapshot
 
 // CreateSnapshot create snapshot from volume
-func (c *Cinder) CreateSnapshot(volumeId string) string {
-    urlSuffix := fmt.Sprintf("/%s/snapshots", c.projectId)
+func (c *Cinder) CreateSnapshot(volumeId string) (string, error) {
     name := "dx_vol_" + strconv.FormatUint(c.snowflake.NextVal(), 10)
-    description := "dx volume"
-    reqBody := fmt.Sprintf("{\"snapshot\": {\"name\": \"%+v\", \"description\": \"%+v\", \"volume_id\": \"%+v\", \"force\": true}}", name, description, volumeId)
-    resp := c.Post(c.headers, urlSuffix, reqBody)
+    return c.CreateSnapshotByOpts(&entity.CreateSnapshotOpts{VolumeId: volumeId, Name: name, Description: "dx volume", Force: true})
+}
+
+func (c *Cinder) CreateSnapshotByOpts(opts *entity.CreateSnapshotOpts) (string, error) {
+    urlSuffix := fmt.Sprintf("/%s/snapshots", c.projectId)
+    reqBody := opts.ToRequestBody()
+    resp, err := c.Post(c.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return "", err
+	}
     var snapshot entity.SnapshotMap
     _ = json.Unmarshal(resp, &snapshot)
     //cache.RedisClient.AddSliceAndJson(snapshotId, c.tag + consts.SNAPSHOTS, snapshot)
-    c.makeSureSnapshotAvailable(snapshot.Id)
-    return snapshot.Id
+    if err = c.makeSureSnapshotAvailable(snapshot.Id); err != nil {
+    	return snapshot.Id, err
+	}
+    return snapshot.Id, nil
 }
 
-func (c *Cinder) getSnapshot(snapshotId string) entity.SnapshotMap {
+func (c *Cinder) getSnapshot(snapshotId string) (entity.SnapshotMap, error) {
 	urlSuffix := fmt.Sprintf("/%s/snapshots/%s", c.projectId, snapshotId)
-	resp := c.Get(c.headers, urlSuffix)
 	var snapshot entity.SnapshotMap
+	resp, err := c.Get(c.headers, urlSuffix)
+	if err != nil {
+		return snapshot, err
+	}
 	_ = json.Unmarshal(resp, &snapshot)
 	log.Println("==============Get snapshot success", snapshotId)
-	return snapshot
-}
-
-func (c *Cinder) makeSureSnapshotAvailable(snapshotId string) {
-	snapshot := c.getSnapshot(snapshotId)
-	done := make(chan bool, 1)
-	go func() {
-		state := snapshot.Status
-		for state != consts.Available && state != consts.Error {
-			time.Sleep(consts.IntervalTime)
-			snapshot = c.getSnapshot(snapshotId)
-			state = snapshot.Status
+	return snapshot, nil
+}
+
+func (c *Cinder) makeSureSnapshotAvailable(snapshotId string) error {
+	err := Poll(c.Context(), consts.Timeout, consts.IntervalTime, func() (bool, error) {
+		snapshot, err := c.getSnapshot(snapshotId)
+		if err != nil {
+			return false, err
+		}
+		switch snapshot.Status {
+		case consts.Available:
+			return true, nil
+		case consts.Error:
+			return false, fmt.Errorf("snapshot %s went into error status", snapshotId)
 		}
-		done <- true
-	}()
-	select {
-	case <-done:
-		log.Println("*******************Create snapshot success")
-	case <-time.After(consts.Timeout):
-		log.Fatalln("*******************Create snapshot timeout")
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Println("*******************Create snapshot timeout")
+		return fmt.Errorf("snapshot %s not available after %s: %w", snapshotId, consts.Timeout, err)
 	}
+	if err != nil {
+		return err
+	}
+	log.Println("*******************Create snapshot success")
+	return nil
 }
 
-func (c *Cinder) listProjectSnapshots() entity.Snapshots {
+func (c *Cinder) listProjectSnapshots() (entity.Snapshots, error) {
 	urlSuffix := fmt.Sprintf("/%s/snapshots", c.projectId)
-	resp := c.List(c.headers, urlSuffix)
 	var ss entity.Snapshots
+	resp, err := c.List(c.headers, urlSuffix)
+	if err != nil {
+		return ss, err
+	}
 	_ = json.Unmarshal(resp, &ss)
 	log.Println("==============List snapshot success")
-	return ss
+	return ss, nil
 }
 
-func (c *Cinder) listSnapshots() entity.Snapshots {
-	urlSuffix := fmt.Sprintf("/%s/snapshots/detail?all_tenants=True&project_id=%s", c.adminProjectId, c.projectId)
-	resp := c.List(c.headers, urlSuffix)
+func (c *Cinder) listSnapshots(opts entity.ListSnapshotsOpts) (entity.Snapshots, error) {
+	urlSuffix := fmt.Sprintf("/%s/snapshots/detail", c.adminProjectId)
 	var ss entity.Snapshots
+	resp, err := c.ListBy(c.headers, urlSuffix, opts)
+	if err != nil {
+		return ss, err
+	}
 	_ = json.Unmarshal(resp, &ss)
 	log.Println("==============List snapshot success, there had", len(ss.Ss))
-	return ss
+	return ss, nil
 }
 
-func (c *Cinder) DeleteProjectSnapshot(snapshotId string) {
+func (c *Cinder) DeleteProjectSnapshot(snapshotId string) error {
 	urlSuffix := fmt.Sprintf("/%s/snapshots/%s", c.projectId, snapshotId)
-	if ok, _ := c.Delete(c.headers, urlSuffix); ok {
-		log.Println("==============Delete snapshot success", snapshotId)
-	} else {
-		log.Fatalln("==============Delete snapshot failed", snapshotId)
+	if err := c.Delete(c.headers, urlSuffix); err != nil {
+		log.Println("==============Delete snapshot failed", snapshotId)
+		return err
 	}
+	log.Println("==============Delete snapshot success", snapshotId)
+	return nil
 }
 
 //func (c *Cinder) DeleteProjectSnapshots() {
@@ -797,54 +1005,103 @@ func (c *Cinder) DeleteProjectSnapshot(snapshotId string) {
 
 func (c *Cinder) DeleteSnapshot(snapshotId string, ch chan Output) {
 	outputObj := Output{ParametersMap: map[string]string{"snapshot_id": snapshotId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-		ch <- outputObj
-	}()
-
 	urlSuffix := fmt.Sprintf("/%s/snapshots/%s", c.adminProjectId, snapshotId)
-	outputObj.Success, outputObj.Response = c.Delete(c.headers, urlSuffix)
+	outputObj.setErr(c.Delete(c.headers, urlSuffix))
+	ch <- outputObj
 }
 
-func (c *Cinder) DeleteSnapshots() {
-	snapshots := c.listSnapshots()
+func (c *Cinder) DeleteSnapshots() error {
+	snapshots, err := c.listSnapshots(entity.ListSnapshotsOpts{ListVolumesOpts: c.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := c.makeDeleteChannel(consts.SNAPSHOT, len(snapshots.Ss))
 	for _, snapshot := range snapshots.Ss {
-		go c.DeleteSnapshot(snapshot.Id, ch)
-	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
+		if !c.selects(snapshot) {
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			c.DeleteSnapshot(id, ch)
+		}(snapshot.Id)
 	}
+	wg.Wait()
 	log.Println("Snapshots were deleted completely")
+	return nil
 }
 
-func (c *Cinder) revertToAnySnapshot(volumeId string, snapshotId string)  {
+func (c *Cinder) revertToAnySnapshot(volumeId string, snapshotId string) error {
 	reqBody := fmt.Sprintf("{\"revert_any\": {\"snapshot_id\": \"%+v\"}}", snapshotId)
 	reqUrl := fmt.Sprintf("/%s/volumes/%s/action", c.projectId, volumeId)
-	resp := c.DecorateResp(c.Post)(c.headers, reqUrl, reqBody)
-	volume := resp["volume"].(map[string]interface{})
+	resp, err := c.DecorateResp(c.Post)(c.headers, reqUrl, reqBody)
+	if err != nil {
+		return err
+	}
+	volume, _ := resp["volume"].(map[string]interface{})
 
 	c.SyncResource(volumeId, nil, volume)
 	log.Println("==============revert to any snapshot success", volumeId)
+	return nil
 }
 
 // backup
-func (c *Cinder) createBackup(volumeId string) string {
+func (c *Cinder) createBackup(volumeId string) (string, error) {
 	urlSuffix := fmt.Sprintf("/%s/snapshots", c.projectId)
 	name := "dx_vol_" + strconv.FormatUint(c.snowflake.NextVal(), 10)
 	description := "dx volume"
 	reqBody := fmt.Sprintf("{\"snapshot\": {\"name\": \"%+v\", \"description\": \"%+v\", \"volume_id\": \"%+v\"}}", name, description, volumeId)
-	resp := c.DecorateResp(c.Post)(c.headers, urlSuffix, reqBody)
+	resp, err := c.DecorateResp(c.Post)(c.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	snapshot := resp["snapshot"].(map[string]interface{})
 	snapshotId := snapshot["id"].(string)
 
 	cache.RedisClient.AddSliceAndJson(snapshotId, c.tag + consts.SNAPSHOTS, snapshot)
 	log.Println("==============create snapshot success", snapshotId)
-	return snapshotId
+	return snapshotId, nil
 }
 
+// GetAttributes returns the attributes of the resource at urlSuffix below
+// the project, the object under key of the response.
+func (c *Cinder) GetAttributes(urlSuffix, key string) (map[string]interface{}, error) {
+	return c.getAttributes(c.headers, fmt.Sprintf("/%s/%s", c.projectId, urlSuffix), key)
+}
+
+// DeleteVolumeAndWait deletes the volume of the project and waits until it
+// is gone, e.g. before the volume a snapshot was taken of is deleted.
+func (c *Cinder) DeleteVolumeAndWait(volumeId string) error {
+	return c.deleteAndWait(fmt.Sprintf("volumes/%s", volumeId), consts.VOLUME)
+}
 
+// DeleteSnapshotAndWait deletes the snapshot of the project and waits until
+// it is gone.
+func (c *Cinder) DeleteSnapshotAndWait(snapshotId string) error {
+	return c.deleteAndWait(fmt.Sprintf("snapshots/%s", snapshotId), consts.SNAPSHOT)
+}
+
+func (c *Cinder) deleteAndWait(urlSuffix, key string) error {
+	if err := c.Delete(c.headers, fmt.Sprintf("/%s/%s", c.projectId, urlSuffix)); err != nil {
+		return err
+	}
+	err := Poll(c.Context(), consts.Timeout, consts.IntervalTime, func() (bool, error) {
+		_, err := c.GetAttributes(urlSuffix, key)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return err
+	}
+	log.Println("==============Delete success", urlSuffix)
+	return nil
+}
+
+// SetAttributes updates the attributes of the resource at urlSuffix below
+// the project, attrs are sent under key.
+func (c *Cinder) SetAttributes(urlSuffix, key string, attrs map[string]interface{}) error {
+	return c.setAttributes(c.headers, fmt.Sprintf("/%s/%s", c.projectId, urlSuffix), key, attrs)
+}
@@ -126,7 +126,8 @@ type PortForwardings struct {
 }
 
 type CreatePortForwardingOpts struct {
-	InternalPortID         string    `json:"internal_port_id"  required:"true"`
+	FloatingipID           string    `json:"-" prop:"floatingip_id" ref:"true"`
+	InternalPortID         string    `json:"internal_port_id"  required:"true" ref:"true"`
 	InternalIPAddress      string    `json:"internal_ip_address"  required:"true"`
 	Protocol               string    `json:"protocol" required:"true"`
 	InternalPort           int       `json:"internal_port,omitempty"`
@@ -143,3 +144,17 @@ func (opts *CreatePortForwardingOpts) ToRequestBody() string {
 	}
 	return reqBody
 }
+
+func (opts *CreatePortForwardingOpts) AssignProps(props map[string]interface{}) (*CreatePortForwardingOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// ListFipsOpts filters GET /v2.0/floatingips.
+type ListFipsOpts struct {
+	ListOpts
+	RouterId          string `q:"router_id"`
+	PortId            string `q:"port_id"`
+	FloatingNetworkId string `q:"floating_network_id"`
+	FloatingIpAddress string `q:"floating_ip_address"`
+	FixedIpAddress    string `q:"fixed_ip_address"`
+}
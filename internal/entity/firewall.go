@@ -74,7 +74,7 @@ type FirewallV1s struct {
 
 
 type CreateFirewallOpts struct {
-	PolicyID string `json:"firewall_policy_id" required:"true"`
+	PolicyID string `json:"firewall_policy_id" required:"true" ref:"true"`
 	// TenantID specifies a tenant to own the firewall. The caller must have
 	// an admin role in order to set this. Otherwise, this field is left unset
 	// and the caller will be the owner.
@@ -84,7 +84,7 @@ type CreateFirewallOpts struct {
 	Description  string `json:"description,omitempty"`
 	AdminStateUp *bool  `json:"admin_state_up,omitempty"`
 	Shared       *bool  `json:"shared,omitempty"`
-	RouterIDs    []string `json:"router_ids"`
+	RouterIDs    []string `json:"router_ids" ref:"true"`
 }
 
 func (opts *CreateFirewallOpts) ToRequestBody() string {
@@ -95,6 +95,10 @@ func (opts *CreateFirewallOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateFirewallOpts) AssignProps(props map[string]interface{}) (*CreateFirewallOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 type UpdateFirewallOpts struct {
 	PolicyID     string  `json:"firewall_policy_id,omitempty"`
 	Name         string `json:"name,omitempty"`
@@ -122,7 +126,7 @@ type CreateFirewallPolicyOpts struct {
 	Description string   `json:"description,omitempty"`
 	Shared      *bool    `json:"shared,omitempty"`
 	Audited     *bool    `json:"audited,omitempty"`
-	Rules       []string `json:"firewall_rules,omitempty"`
+	Rules       []string `json:"firewall_rules,omitempty" ref:"true"`
 }
 
 func (opts *CreateFirewallPolicyOpts) ToRequestBody() string {
@@ -133,6 +137,10 @@ func (opts *CreateFirewallPolicyOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateFirewallPolicyOpts) AssignProps(props map[string]interface{}) (*CreateFirewallPolicyOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 type UpdateFirewallPolicyOpts struct {
 	Name        *string  `json:"name,omitempty"`
 	Description *string  `json:"description,omitempty"`
@@ -173,6 +181,10 @@ func (opts *CreateFirewallRuleOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateFirewallRuleOpts) AssignProps(props map[string]interface{}) (*CreateFirewallRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 type UpdateFirewallRuleOpts struct {
 	Protocol             *string                `json:"protocol,omitempty"`
 	Action               *string                `json:"action,omitempty"`
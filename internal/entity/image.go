@@ -65,3 +65,17 @@ type ImageMember struct {
 	Status    string    `json:"status"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ListImagesOpts filters GET /v2/images, Tags are matched all at once.
+type ListImagesOpts struct {
+	Name       string   `q:"name"`
+	Status     string   `q:"status"`
+	ProjectId  string   `q:"project_id"`
+	Owner      string   `q:"owner"`
+	Visibility string   `q:"visibility"`
+	Tags       []string `q:"tag"`
+	SortKey    string   `q:"sort_key"`
+	SortDir    string   `q:"sort_dir"`
+	Limit      int      `q:"limit"`
+	Marker     string   `q:"marker"`
+}
@@ -68,6 +68,7 @@ type Server struct {
 		Name string `json:"name"`
 	} `json:"security_groups"`
 	Status   string    `json:"status"`
+	Tags     []string  `json:"tags"`
 	TenantId string    `json:"tenant_id"`
 	Updated  time.Time `json:"updated"`
 	UserId   string    `json:"user_id"`
@@ -402,14 +403,13 @@ func (opts *CreateInstanceOpts) AssignProps(props map[string]interface{}) (*Crea
 			case reflect.Slice:
 				if compareFieldName == "networks" {
 					originSlice := make([]ServerNet, value.Len())
-					for _, item := range v.([]interface{}) {
+					for i, item := range v.([]interface{}) {
 						uuid := item.(map[string]interface{})["uuid"].(string)
 						if !IsUUID(uuid) {
-							deps[uuid] = field.Name + "/UUID"
-						} else {
-							obj := ServerNet{UUID: uuid}
-							originSlice = append(originSlice, obj)
+							deps[uuid] = fmt.Sprintf("%s[%d]/UUID", field.Name, i)
+							uuid = ""
 						}
+						originSlice = append(originSlice, ServerNet{UUID: uuid})
 					}
 					value.Set(reflect.ValueOf(originSlice))
 				}
@@ -443,4 +443,20 @@ func (opts *CreateInstanceOpts) AssignProps(props map[string]interface{}) (*Crea
 		}
 	}
 	return opts, deps
-}
\ No newline at end of file
+}
+
+// ListServersOpts filters GET /v2.1/servers/detail. Nova names the project
+// tenant_id and only honours it for admins together with AllTenants.
+type ListServersOpts struct {
+	Name       string   `q:"name"`
+	Status     string   `q:"status"`
+	Host       string   `q:"host"`
+	TenantId   string   `q:"tenant_id"`
+	AllTenants bool     `q:"all_tenants"`
+	Tags       []string `q:"tags,comma"`
+	TagsAny    []string `q:"tags-any,comma"`
+	SortKey    []string `q:"sort_key"`
+	SortDir    []string `q:"sort_dir"`
+	Limit      int      `q:"limit"`
+	Marker     string   `q:"marker"`
+}
@@ -53,6 +53,8 @@ type CreateNetworkOpts struct {
 	ProjectID             string   `json:"project_id,omitempty"`
 	AvailabilityZoneHints []string `json:"availability_zone_hints,omitempty"`
 	RouterExternal        bool     `json:"router:external,omitempty"`
+	Mtu                   int      `json:"mtu,omitempty"`
+	PortSecurityEnabled   *bool    `json:"port_security_enabled,omitempty"`
 }
 
 func (opts *CreateNetworkOpts) ToRequestBody() string {
@@ -80,6 +82,14 @@ func (opts *CreateNetworkOpts) AssignProps(props map[string]interface{}) (*Creat
 				value.SetString(v.(string))
 			case reflect.Bool:
 				value.SetBool(v.(bool))
+			case reflect.Int:
+				// a float64 when the props come from the json of a stack state
+				switch n := v.(type) {
+				case float64:
+					value.SetInt(int64(n))
+				default:
+					value.SetInt(int64(n.(int)))
+				}
 			case reflect.Ptr:
 				ptr := reflect.New(fieldType.Elem())
 				ptr.Elem().Set(reflect.ValueOf(v))
@@ -92,3 +102,12 @@ func (opts *CreateNetworkOpts) AssignProps(props map[string]interface{}) (*Creat
 	}
 	return opts, deps
 }
+
+// ListNetworksOpts filters GET /v2.0/networks.
+type ListNetworksOpts struct {
+	ListOpts
+	RouterExternal *bool  `q:"router:external"`
+	Shared         *bool  `q:"shared"`
+	AdminStateUp   *bool  `q:"admin_state_up"`
+	Id             string `q:"id"`
+}
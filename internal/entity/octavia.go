@@ -65,17 +65,17 @@ type CreateLoadbalancerOpts struct {
 	// port for the VIP. If the port has more than one subnet you must specify
 	// either the vip_subnet_id or vip_address to clarify which address should
 	// be used for the VIP.
-	VipPortID string `json:"vip_port_id,omitempty"`
+	VipPortID string `json:"vip_port_id,omitempty" ref:"true"`
 
 	// The subnet on which to allocate the Loadbalancer's address. A project can
 	// only create Loadbalancers on networks authorized by policy (e.g. networks
 	// that belong to them or networks that are shared).
-	VipSubnetID string `json:"vip_subnet_id,omitempty"`
+	VipSubnetID string `json:"vip_subnet_id,omitempty" ref:"true"`
 
 	// The network on which to allocate the Loadbalancer's address. A tenant can
 	// only create Loadbalancers on networks authorized by policy (e.g. networks
 	// that belong to them or networks that are shared).
-	VipNetworkID string `json:"vip_network_id,omitempty"`
+	VipNetworkID string `json:"vip_network_id,omitempty" ref:"true"`
 
 	// ProjectID is the UUID of the project who owns the Loadbalancer.
 	// Only administrative users can specify a project UUID other than their own.
@@ -85,7 +85,7 @@ type CreateLoadbalancerOpts struct {
 	VipAddress string `json:"vip_address,omitempty"`
 
 	// The ID of the QoS Policy which will apply to the Virtual IP
-	VipQosPolicyID string `json:"vip_qos_policy_id,omitempty"`
+	VipQosPolicyID string `json:"vip_qos_policy_id,omitempty" ref:"true"`
 
 	// The administrative state of the Loadbalancer. A valid value is true (UP)
 	// or false (DOWN).
@@ -148,8 +148,11 @@ type SessionPersistence struct {
 }
 
 type CreateMemberOpts struct {
+	// The pool of the member, it is part of the url and not of the body.
+	PoolID string `json:"-" prop:"pool_id" ref:"true"`
+
 	// The IP address of the member to receive traffic from the load balancer.
-	Address string `json:"address" required:"true"`
+	Address string `json:"address" required:"true" ref:"ip"`
 
 	// The port on which to listen for client traffic.
 	ProtocolPort int `json:"protocol_port" required:"true"`
@@ -169,7 +172,7 @@ type CreateMemberOpts struct {
 
 	// If you omit this parameter, LBaaS uses the vip_subnet_id parameter value
 	// for the subnet UUID.
-	SubnetID string `json:"subnet_id,omitempty"`
+	SubnetID string `json:"subnet_id,omitempty" ref:"true"`
 
 	// The administrative state of the Pool. A valid value is true (UP)
 	// or false (DOWN).
@@ -204,11 +207,11 @@ type CreatePoolOpts struct {
 
 	// The Loadbalancer on which the members of the pool will be associated with.
 	// Note: one of LoadbalancerID or ListenerID must be provided.
-	LoadbalancerID string `json:"loadbalancer_id,omitempty"`
+	LoadbalancerID string `json:"loadbalancer_id,omitempty" ref:"true"`
 
 	// The Listener on which the members of the pool will be associated with.
 	// Note: one of LoadbalancerID or ListenerID must be provided.
-	ListenerID string `json:"listener_id,omitempty"`
+	ListenerID string `json:"listener_id,omitempty" ref:"true"`
 
 	// ProjectID is the UUID of the project who owns the Pool.
 	// Only administrative users can specify a project UUID other than their own.
@@ -273,7 +276,7 @@ type UpdatePoolOpts struct {
 
 type CreateListenerOpts struct {
 	// The load balancer on which to provision this listener.
-	LoadbalancerID string `json:"loadbalancer_id,omitempty"`
+	LoadbalancerID string `json:"loadbalancer_id,omitempty" ref:"true"`
 
 	// The protocol - can either be TCP, SCTP, HTTP, HTTPS or TERMINATED_HTTPS.
 	Protocol Protocol `json:"protocol" required:"true"`
@@ -289,7 +292,7 @@ type CreateListenerOpts struct {
 	Name string `json:"name,omitempty"`
 
 	// The ID of the default pool with which the Listener is associated.
-	DefaultPoolID string `json:"default_pool_id,omitempty"`
+	DefaultPoolID string `json:"default_pool_id,omitempty" ref:"true"`
 
 	// DefaultPool an instance of pools.CreateOpts which allows a
 	// (default) pool to be created at the same time the listener is created.
@@ -400,7 +403,7 @@ type UpdateListenerOpts struct {
 // operation.
 type CreateHealthMonitorOpts struct {
 	// The Pool to Monitor.
-	PoolID string `json:"pool_id,omitempty"`
+	PoolID string `json:"pool_id,omitempty" ref:"true"`
 
 	// The type of probe, which is PING, TCP, HTTP, or HTTPS, that is
 	// sent by the load balancer to verify the member state.
@@ -496,7 +499,7 @@ type CreateL7PoliciesOpts struct {
 	Name string `json:"name,omitempty"`
 
 	// The ID of the listener.
-	ListenerID string `json:"listener_id,omitempty"`
+	ListenerID string `json:"listener_id,omitempty" ref:"true"`
 
 	// The L7 policy action. One of REDIRECT_PREFIX, REDIRECT_TO_POOL, REDIRECT_TO_URL, or REJECT.
 	Action Action `json:"action" required:"true"`
@@ -517,7 +520,7 @@ type CreateL7PoliciesOpts struct {
 
 	// Requests matching this policy will be redirected to the pool with this ID.
 	// Only valid if action is REDIRECT_TO_POOL.
-	RedirectPoolID string `json:"redirect_pool_id,omitempty"`
+	RedirectPoolID string `json:"redirect_pool_id,omitempty" ref:"true"`
 
 	// Requests matching this policy will be redirected to this URL.
 	// Only valid if action is REDIRECT_TO_URL.
@@ -540,6 +543,39 @@ type CreateL7PoliciesOpts struct {
 	Rules []CreateRuleOpts `json:"rules,omitempty"`
 }
 
+// ListLoadbalancersOpts filters GET /v2/lbaas/loadbalancers.
+type ListLoadbalancersOpts struct {
+	ListOpts
+	VipNetworkId       string `q:"vip_network_id"`
+	VipSubnetId        string `q:"vip_subnet_id"`
+	VipAddress         string `q:"vip_address"`
+	ProvisioningStatus string `q:"provisioning_status"`
+	OperatingStatus    string `q:"operating_status"`
+}
+
+// ListListenersOpts filters GET /v2/lbaas/listeners.
+type ListListenersOpts struct {
+	ListOpts
+	LoadbalancerId string `q:"loadbalancer_id"`
+	Protocol       string `q:"protocol"`
+	ProtocolPort   int    `q:"protocol_port"`
+}
+
+// ListPoolsOpts filters GET /v2/lbaas/pools.
+type ListPoolsOpts struct {
+	ListOpts
+	LoadbalancerId string `q:"loadbalancer_id"`
+	Protocol       string `q:"protocol"`
+	LbAlgorithm    string `q:"lb_algorithm"`
+}
+
+// ListHealthMonitorsOpts filters GET /v2/lbaas/healthmonitors.
+type ListHealthMonitorsOpts struct {
+	ListOpts
+	PoolId string `q:"pool_id"`
+	Type   string `q:"type"`
+}
+
 // ListL7PoliciesOpts allows the filtering and sorting of paginated collections through
 // the API.
 type ListL7PoliciesOpts struct {
@@ -597,6 +633,9 @@ type UpdateL7PoliciesOpts struct {
 }
 
 type CreateRuleOpts struct {
+	// The L7 policy of the rule, it is part of the url and not of the body.
+	PolicyID string `json:"-" prop:"l7policy_id" ref:"true"`
+
 	// The L7 rule type. One of COOKIE, FILE_TYPE, HEADER, HOST_NAME, or PATH.
 	RuleType RuleType `json:"type" required:"true"`
 
@@ -630,6 +669,10 @@ func (opts *CreateLoadbalancerOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateLoadbalancerOpts) AssignProps(props map[string]interface{}) (*CreateLoadbalancerOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 func (opts *CreateListenerOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.LISTENER)
 	if err != nil {
@@ -638,6 +681,10 @@ func (opts *CreateListenerOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateListenerOpts) AssignProps(props map[string]interface{}) (*CreateListenerOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 func (opts *CreatePoolOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.POOL)
 	if err != nil {
@@ -646,6 +693,10 @@ func (opts *CreatePoolOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreatePoolOpts) AssignProps(props map[string]interface{}) (*CreatePoolOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 func (opts *CreateMemberOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.MEMBER)
 	if err != nil {
@@ -654,6 +705,10 @@ func (opts *CreateMemberOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateMemberOpts) AssignProps(props map[string]interface{}) (*CreateMemberOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 func (opts *CreateHealthMonitorOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.HEALTHMONITOR)
 	if err != nil {
@@ -662,6 +717,10 @@ func (opts *CreateHealthMonitorOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateHealthMonitorOpts) AssignProps(props map[string]interface{}) (*CreateHealthMonitorOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 func (opts *CreateL7PoliciesOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.L7POLICY)
 	if err != nil {
@@ -670,6 +729,22 @@ func (opts *CreateL7PoliciesOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateL7PoliciesOpts) AssignProps(props map[string]interface{}) (*CreateL7PoliciesOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+func (opts *CreateRuleOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.RULE)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateRuleOpts) AssignProps(props map[string]interface{}) (*CreateRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 type Loadbalancer struct {
 	Description        string `json:"description"`
 	AdminStateUp       bool   `json:"admin_state_up"`
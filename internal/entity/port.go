@@ -51,17 +51,26 @@ type Ports struct {
 
 type FixedIP struct {
 	IpAddress             string    `json:"ip_address,omitempty"`
-	SubnetId              string    `json:"subnet_id"`
+	SubnetId              string    `json:"subnet_id" ref:"true"`
 }
 
 type CreatePortOpts struct {
-	AdminStateUp          *bool        `json:"admin_state_up,omitempty"`
-	Name                  string       `json:"name,omitempty"`
-	Description           string       `json:"description,omitempty"`
-	TenantID              string       `json:"tenant_id,omitempty"`
-	ProjectID             string       `json:"project_id,omitempty"`
-	FixedIp               []FixedIP    `json:"fixed_ips,omitempty"`
-	NetworkId             string       `json:"network_id,omitempty"`
+	AdminStateUp          *bool          `json:"admin_state_up,omitempty"`
+	Name                  string         `json:"name,omitempty"`
+	Description           string         `json:"description,omitempty"`
+	TenantID              string         `json:"tenant_id,omitempty"`
+	ProjectID             string         `json:"project_id,omitempty"`
+	FixedIp               []FixedIP      `json:"fixed_ips,omitempty"`
+	NetworkId             string         `json:"network_id,omitempty" ref:"true"`
+	PortSecurityEnabled   *bool          `json:"port_security_enabled,omitempty"`
+	AllowedAddressPairs   []AddressPair  `json:"allowed_address_pairs,omitempty"`
+}
+
+// AddressPair is an address besides its fixed ips a port may send from,
+// e.g. the virtual ip of a keepalived pair.
+type AddressPair struct {
+	IpAddress             string         `json:"ip_address"`
+	MacAddress            string         `json:"mac_address,omitempty"`
 }
 
 
@@ -71,4 +80,19 @@ func (opts *CreatePortOpts) ToRequestBody() string {
 		panic(fmt.Sprintf("Failed to build request body %s", err))
 	}
 	return reqBody
-}
\ No newline at end of file
+}
+
+func (opts *CreatePortOpts) AssignProps(props map[string]interface{}) (*CreatePortOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// ListPortsOpts filters GET /v2.0/ports, e.g. the interfaces of a router by
+// DeviceId and DeviceOwner "network:router_interface".
+type ListPortsOpts struct {
+	ListOpts
+	NetworkId   string   `q:"network_id"`
+	DeviceId    string   `q:"device_id"`
+	DeviceOwner string   `q:"device_owner"`
+	MacAddress  string   `q:"mac_address"`
+	FixedIps    []string `q:"fixed_ips"`
+}
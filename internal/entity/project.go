@@ -16,4 +16,12 @@ type ProjectMap struct {
 		DomainId string `json:"domain_id"`
 		Name     string `json:"name"`
 	} `json:"project"`
-}
\ No newline at end of file
+}
+
+// ListProjectsOpts filters GET /v3/projects.
+type ListProjectsOpts struct {
+	Name     string `q:"name"`
+	DomainId string `q:"domain_id"`
+	ParentId string `q:"parent_id"`
+	Enabled  *bool  `q:"enabled"`
+}
@@ -0,0 +1,201 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// assignProps fills opts from the properties of a template resource and
+// returns its dependencies, the name of the resource a property refers to
+// mapped to the path of the field that receives its id once it is created.
+//
+// Properties are matched by the json tag of a field, or by its prop tag when
+// the field is not part of the request body, e.g. the pool of a member.
+// Fields tagged ref:"true" hold ids, any value that is not a UUID is taken as
+// the name of another resource of the template. A ref:"ip" field also keeps
+// IP addresses, e.g. the address of a member that may name a server.
+//
+// Paths are the field name, "Field[i]" for an element of a slice and
+// "Field[i]/SubField" for a field of a struct in a slice, e.g.
+// "FixedIp[0]/SubnetId" of a port. A resource referenced by two properties
+// keeps the last of them.
+func assignProps(opts interface{}, props map[string]interface{}) map[string]string {
+	deps := make(map[string]string)
+	assignStruct(reflect.ValueOf(opts).Elem(), props, "", deps)
+	return deps
+}
+
+// CheckProps checks the properties of a template resource against opts, the
+// opts its AssignProps fills: every property must match a field, see
+// assignProps, and its value must fit the type of the field. Nil values are
+// not checked, e.g. those of intrinsic functions known once the resources
+// they refer to are created.
+func CheckProps(opts interface{}, props map[string]interface{}) error {
+	var errs []string
+	checkStruct(reflect.TypeOf(opts).Elem(), props, "", &errs)
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func checkStruct(typ reflect.Type, props map[string]interface{}, path string, errs *[]string) {
+	fields := make(map[string]reflect.Type, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		if name := propName(typ.Field(i)); name != "" {
+			fields[name] = typ.Field(i).Type
+		}
+	}
+	for name, v := range props {
+		fieldType, ok := fields[name]
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("unknown property %s%s", path, name))
+			continue
+		}
+		if v != nil {
+			checkValue(fieldType, v, path+name, errs)
+		}
+	}
+}
+
+// checkValue walks into the structs as assignNested does and otherwise
+// checks that v decodes into typ.
+func checkValue(typ reflect.Type, v interface{}, path string, errs *[]string) {
+	elem := typ
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	switch elem.Kind() {
+	case reflect.Struct:
+		if m, ok := v.(map[string]interface{}); ok {
+			checkStruct(elem, m, path+".", errs)
+			return
+		}
+	case reflect.Slice:
+		if items, ok := v.([]interface{}); ok && elem.Elem().Kind() == reflect.Struct {
+			for i, item := range items {
+				if item != nil {
+					checkValue(elem.Elem(), item, fmt.Sprintf("%s[%d]", path, i), errs)
+				}
+			}
+			return
+		}
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(data, reflect.New(typ).Interface())
+	}
+	if err != nil {
+		*errs = append(*errs, fmt.Sprintf("property %s: %v is not a %s", path, v, strings.TrimPrefix(typ.String(), "*")))
+	}
+}
+
+func assignStruct(val reflect.Value, props map[string]interface{}, path string, deps map[string]string) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := propName(field)
+		v, ok := props[name]
+		if name == "" || !ok || v == nil {
+			continue
+		}
+		value := val.Field(i)
+		fieldPath := path + field.Name
+		if ref := field.Tag.Get("ref"); ref != "" {
+			assignRef(value, v, ref, fieldPath, deps)
+			continue
+		}
+		if assignNested(value, v, fieldPath, deps) {
+			continue
+		}
+		data, _ := json.Marshal(v)
+		if err := json.Unmarshal(data, value.Addr().Interface()); err != nil {
+			log.Printf("##############Ignore property %s of %s: %s", name, typ.Name(), err)
+		}
+	}
+}
+
+// propName is the template property of a field, "" when it has none.
+func propName(field reflect.StructField) string {
+	if prop := field.Tag.Get("prop"); prop != "" {
+		return prop
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func assignRef(value reflect.Value, v interface{}, ref, path string, deps map[string]string) {
+	switch value.Kind() {
+	case reflect.String:
+		if s, ok := v.(string); ok {
+			if isReference(s, ref) {
+				deps[s] = path
+			} else {
+				value.SetString(s)
+			}
+		}
+	case reflect.Slice:
+		items, ok := v.([]interface{})
+		if !ok || value.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range InterfaceSliceToStringSlice(items) {
+			if isReference(item, ref) {
+				deps[item] = fmt.Sprintf("%s[%d]", path, i)
+			} else {
+				slice.Index(i).SetString(item)
+			}
+		}
+		value.Set(slice)
+	}
+}
+
+// assignNested walks into struct, *struct and []struct fields so that the
+// references inside them, e.g. the subnet of a fixed ip, become dependencies.
+func assignNested(value reflect.Value, v interface{}, path string, deps map[string]string) bool {
+	switch value.Kind() {
+	case reflect.Struct:
+		if m, ok := v.(map[string]interface{}); ok {
+			assignStruct(value, m, path+"/", deps)
+			return true
+		}
+	case reflect.Ptr:
+		if m, ok := v.(map[string]interface{}); ok && value.Type().Elem().Kind() == reflect.Struct {
+			ptr := reflect.New(value.Type().Elem())
+			assignStruct(ptr.Elem(), m, path+"/", deps)
+			value.Set(ptr)
+			return true
+		}
+	case reflect.Slice:
+		items, ok := v.([]interface{})
+		if !ok || value.Type().Elem().Kind() != reflect.Struct {
+			return false
+		}
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				assignStruct(slice.Index(i), m, fmt.Sprintf("%s[%d]/", path, i), deps)
+			}
+		}
+		value.Set(slice)
+		return true
+	}
+	return false
+}
+
+func isReference(value, ref string) bool {
+	if value == "" || IsUUID(value) {
+		return false
+	}
+	return ref != "ip" || net.ParseIP(value) == nil
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAssignProps(t *testing.T) {
+	var props map[string]interface{}
+	err := yaml.Unmarshal([]byte(`
+network_id: net1
+fixed_ips:
+  - subnet_id: subnet1
+    ip_address: 10.0.0.10
+  - subnet_id: 5c8b1b6e-3c1f-4d5b-9d33-2a1a1f0a4c11
+admin_state_up: false
+`), &props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, deps := (&CreatePortOpts{Name: "port1"}).AssignProps(props)
+	if deps["net1"] != "NetworkId" || deps["subnet1"] != "FixedIp[0]/SubnetId" || len(deps) != 2 {
+		t.Fatalf("unexpected dependencies %v", deps)
+	}
+	if len(port.FixedIp) != 2 || port.FixedIp[0].IpAddress != "10.0.0.10" ||
+		port.FixedIp[1].SubnetId != "5c8b1b6e-3c1f-4d5b-9d33-2a1a1f0a4c11" {
+		t.Fatalf("unexpected fixed ips %+v", port.FixedIp)
+	}
+	if port.AdminStateUp == nil || *port.AdminStateUp || port.Name != "port1" {
+		t.Fatalf("unexpected port %+v", port)
+	}
+
+	member, deps := (&CreateMemberOpts{}).AssignProps(map[string]interface{}{
+		"pool_id": "pool1", "address": "10.0.0.5", "protocol_port": 80, "weight": 2.0,
+	})
+	if deps["pool1"] != "PoolID" || len(deps) != 1 || member.Address != "10.0.0.5" ||
+		member.ProtocolPort != 80 || member.Weight != 2 {
+		t.Fatalf("unexpected member %+v with dependencies %v", member, deps)
+	}
+	if body := member.ToRequestBody(); body != `{"member":{"address":"10.0.0.5","protocol_port":80,"weight":2}}` {
+		t.Fatalf("unexpected request body %s", body)
+	}
+
+	firewall, deps := (&CreateFirewallOpts{}).AssignProps(map[string]interface{}{
+		"firewall_policy_id": "policy1", "router_ids": []interface{}{"router1", "router2"},
+	})
+	if deps["router2"] != "RouterIDs[1]" || deps["policy1"] != "PolicyID" || len(firewall.RouterIDs) != 2 {
+		t.Fatalf("unexpected firewall %+v with dependencies %v", firewall, deps)
+	}
+}
+
+func TestCheckProps(t *testing.T) {
+	props := map[string]interface{}{
+		"network_id":     "net1",
+		"fixed_ips":      []interface{}{map[string]interface{}{"subnet_id": "subnet1", "ip_adress": "10.0.0.10"}},
+		"admin_state_up": "yes",
+		"description":    nil,
+		"nmae":           "port1",
+	}
+	err := CheckProps(&CreatePortOpts{}, props)
+	want := "property admin_state_up: yes is not a bool; unknown property fixed_ips[0].ip_adress; unknown property nmae"
+	if err == nil || err.Error() != want {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err = CheckProps(&CreateMemberOpts{}, map[string]interface{}{
+		"pool_id": "pool1", "address": "10.0.0.5", "protocol_port": 80,
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
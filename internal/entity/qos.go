@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"request_openstack/consts"
+	"time"
+)
 
 type Rule struct {
 	MaxKbps      int    `json:"max_kbps"`
@@ -34,3 +38,78 @@ type QosPolicies struct {
 	Qps                []Policy `json:"policies"`
 	Count              int   `json:"count"`
 }
+
+type CreateQosPolicyOpts struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Shared      bool   `json:"shared"`
+	IsDefault   bool   `json:"is_default,omitempty"`
+}
+
+func (opts *CreateQosPolicyOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.POLICY)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateQosPolicyOpts) AssignProps(props map[string]interface{}) (*CreateQosPolicyOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// CreateBandwidthLimitRuleOpts and the other rule opts carry the policy of
+// the rule for templates, it is part of the url and not of the body.
+type CreateBandwidthLimitRuleOpts struct {
+	QosPolicyId  string `json:"-" prop:"qos_policy_id" ref:"true"`
+	MaxKbps      int    `json:"max_kbps" required:"true"`
+	MaxBurstKbps int    `json:"max_burst_kbps,omitempty"`
+	Direction    string `json:"direction,omitempty"`
+}
+
+func (opts *CreateBandwidthLimitRuleOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.BANDWIDTH_LIMIT_RULE)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateBandwidthLimitRuleOpts) AssignProps(props map[string]interface{}) (*CreateBandwidthLimitRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+type CreateDscpMarkingRuleOpts struct {
+	QosPolicyId string `json:"-" prop:"qos_policy_id" ref:"true"`
+	DscpMark    int    `json:"dscp_mark" required:"true"`
+}
+
+func (opts *CreateDscpMarkingRuleOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.DSCP_MARKING_RULE)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateDscpMarkingRuleOpts) AssignProps(props map[string]interface{}) (*CreateDscpMarkingRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+type CreateMinimumBandwidthRuleOpts struct {
+	QosPolicyId string `json:"-" prop:"qos_policy_id" ref:"true"`
+	MinKbps     int    `json:"min_kbps" required:"true"`
+	Direction   string `json:"direction,omitempty"`
+}
+
+func (opts *CreateMinimumBandwidthRuleOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.MINIMUM_BANDWIDTH_RULE)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateMinimumBandwidthRuleOpts) AssignProps(props map[string]interface{}) (*CreateMinimumBandwidthRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
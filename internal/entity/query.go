@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ListOpts holds the filters every neutron style collection understands.
+// Embed it into the ListXxxOpts of a resource and add the resource specific
+// filters next to it, BuildQueryString flattens embedded structs.
+type ListOpts struct {
+	Name      string `q:"name"`
+	ProjectId string `q:"project_id"`
+	Status    string `q:"status"`
+	// Tags matches resources carrying all of the tags, TagsAny one of them
+	Tags    []string `q:"tags,comma"`
+	TagsAny []string `q:"tags-any,comma"`
+	NotTags []string `q:"not-tags,comma"`
+	// Fields limits the attributes returned for each resource
+	Fields []string `q:"fields"`
+	// SortKey and SortDir are paired by position, e.g. name asc, id desc
+	SortKey []string `q:"sort_key"`
+	SortDir []string `q:"sort_dir"`
+	Limit   int      `q:"limit"`
+	Marker  string   `q:"marker"`
+}
+
+// BuildQueryString turns a ListXxxOpts struct into an escaped query string
+// starting with "?", or "" when no filter is set. Fields are named by their
+// q tag, zero values are left out, slices repeat the parameter unless the
+// tag asks for a comma separated list, e.g. `q:"tags,comma"`.
+func BuildQueryString(opts interface{}) (string, error) {
+	optsValue := reflect.ValueOf(opts)
+	if optsValue.Kind() == reflect.Ptr {
+		if optsValue.IsNil() {
+			return "", nil
+		}
+		optsValue = optsValue.Elem()
+	}
+	if optsValue.Kind() != reflect.Struct {
+		return "", fmt.Errorf("options type is not a struct")
+	}
+
+	params := url.Values{}
+	if err := addQueryParams(params, optsValue); err != nil {
+		return "", err
+	}
+	if len(params) == 0 {
+		return "", nil
+	}
+	return "?" + params.Encode(), nil
+}
+
+func addQueryParams(params url.Values, optsValue reflect.Value) error {
+	optsType := optsValue.Type()
+	for i := 0; i < optsValue.NumField(); i++ {
+		v := optsValue.Field(i)
+		f := optsType.Field(i)
+		if f.Anonymous && v.Kind() == reflect.Struct {
+			if err := addQueryParams(params, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		qTag := f.Tag.Get("q")
+		if qTag == "" || qTag == "-" {
+			continue
+		}
+		tagPieces := strings.Split(qTag, ",")
+		name := tagPieces[0]
+		comma := len(tagPieces) > 1 && tagPieces[1] == "comma"
+
+		if isZero(v) {
+			if f.Tag.Get("required") == "true" {
+				return fmt.Errorf("missing required argument %s", f.Name)
+			}
+			continue
+		}
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Slice:
+			values := make([]string, 0, v.Len())
+			for j := 0; j < v.Len(); j++ {
+				value, err := queryValue(v.Index(j))
+				if err != nil {
+					return fmt.Errorf("query parameter %s: %w", name, err)
+				}
+				values = append(values, value)
+			}
+			if comma {
+				params.Add(name, strings.Join(values, ","))
+				continue
+			}
+			for _, value := range values {
+				params.Add(name, value)
+			}
+		default:
+			value, err := queryValue(v)
+			if err != nil {
+				return fmt.Errorf("query parameter %s: %w", name, err)
+			}
+			params.Add(name, value)
+		}
+	}
+	return nil
+}
+
+func queryValue(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -165,6 +165,7 @@ func (opts *UpdateRouterOpts) ToRequestBody() string {
 
 type AddRouterInterfaceOpts struct {
 	SubnetID              string       `json:"subnet_id,omitempty"`
+	PortID                string       `json:"port_id,omitempty"`
 	RouterId              string       `json:"router_id,-"`
 }
 
@@ -200,3 +201,11 @@ func (opts *AddRouterInterfaceOpts) AssignProps(props map[string]interface{}) (*
 	return opts, deps
 }
 
+
+// ListRoutersOpts filters GET /v2.0/routers.
+type ListRoutersOpts struct {
+	ListOpts
+	AdminStateUp *bool  `q:"admin_state_up"`
+	Distributed  *bool  `q:"distributed"`
+	Id           string `q:"id"`
+}
@@ -105,7 +105,7 @@ type CreateSecurityRuleOpts struct {
 	EtherType string `json:"ethertype" required:"true"`
 
 	// The security group ID to associate with this security group rule.
-	SecGroupID string `json:"security_group_id" required:"true"`
+	SecGroupID string `json:"security_group_id" required:"true" ref:"true"`
 
 	// The maximum port number in the range that is matched by the security group
 	// rule. The PortRangeMin attribute constrains the PortRangeMax attribute. If
@@ -124,7 +124,7 @@ type CreateSecurityRuleOpts struct {
 
 	// The remote group ID to be associated with this security group rule. You can
 	// specify either RemoteGroupID or RemoteIPPrefix.
-	RemoteGroupID string `json:"remote_group_id,omitempty"`
+	RemoteGroupID string `json:"remote_group_id,omitempty" ref:"true"`
 
 	// The remote IP prefix to be associated with this security group rule. You can
 	// specify either RemoteGroupID or RemoteIPPrefix. This attribute matches the
@@ -145,6 +145,10 @@ func (opts *CreateSecurityGroupOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateSecurityGroupOpts) AssignProps(props map[string]interface{}) (*CreateSecurityGroupOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 
 func (opts *CreateSecurityRuleOpts) ToRequestBody() string {
 	reqBody, err := BuildRequestBody(opts, consts.SECURITYGROUPRULE)
@@ -153,3 +157,21 @@ func (opts *CreateSecurityRuleOpts) ToRequestBody() string {
 	}
 	return reqBody
 }
+
+func (opts *CreateSecurityRuleOpts) AssignProps(props map[string]interface{}) (*CreateSecurityRuleOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// ListSecurityGroupsOpts filters GET /v2.0/security-groups.
+type ListSecurityGroupsOpts struct {
+	ListOpts
+	Id string `q:"id"`
+}
+
+// ListSecurityRulesOpts filters GET /v2.0/security-group-rules.
+type ListSecurityRulesOpts struct {
+	ListOpts
+	SecurityGroupId string `q:"security_group_id"`
+	Direction       string `q:"direction"`
+	Protocol        string `q:"protocol"`
+}
@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"fmt"
+	"request_openstack/consts"
+)
+
 type Snapshot struct {
 	CreatedAt   string `json:"created_at"`
 	Description string `json:"description"`
@@ -24,3 +29,29 @@ type SnapshotMap struct {
 type Snapshots struct {
 	Ss                  []Snapshot `json:"snapshots"`
 }
+
+// ListSnapshotsOpts filters GET /v3/{project_id}/snapshots/detail.
+type ListSnapshotsOpts struct {
+	ListVolumesOpts
+	VolumeId string `q:"volume_id"`
+}
+
+type CreateSnapshotOpts struct {
+	VolumeId    string            `json:"volume_id" required:"true" ref:"true"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Force       bool              `json:"force,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+func (opts *CreateSnapshotOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.SNAPSHOT)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateSnapshotOpts) AssignProps(props map[string]interface{}) (*CreateSnapshotOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
@@ -185,3 +185,12 @@ func (opts *CreateSubnetOpts) AssignProps(props map[string]interface{}) (*Create
 	}
 	return opts, deps
 }
+
+// ListSubnetsOpts filters GET /v2.0/subnets.
+type ListSubnetsOpts struct {
+	ListOpts
+	NetworkId string `q:"network_id"`
+	IpVersion int    `q:"ip_version"`
+	Cidr      string `q:"cidr"`
+	GatewayIp string `q:"gateway_ip"`
+}
@@ -1 +1,31 @@
 package entity
+
+type TokenMap struct {
+	Token struct {
+		ExpiresAt string  `json:"expires_at"`
+		IssuedAt  string  `json:"issued_at"`
+		Catalog   Catalog `json:"catalog"`
+		Project   struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"project"`
+	} `json:"token"`
+}
+
+// Catalog is the service catalog returned along with a keystone token.
+type Catalog []CatalogService
+
+type CatalogService struct {
+	Id        string            `json:"id"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Endpoints []CatalogEndpoint `json:"endpoints"`
+}
+
+type CatalogEndpoint struct {
+	Id        string `json:"id"`
+	Interface string `json:"interface"`
+	Region    string `json:"region"`
+	RegionId  string `json:"region_id"`
+	Url       string `json:"url"`
+}
@@ -19,3 +19,16 @@ type User struct {
 type UserMap struct {
 	User `json:"user"`
 }
+
+// ListUsersOpts filters GET /v3/users.
+type ListUsersOpts struct {
+	Name     string `q:"name"`
+	DomainId string `q:"domain_id"`
+	Enabled  *bool  `q:"enabled"`
+}
+
+// ListRolesOpts filters GET /v3/roles.
+type ListRolesOpts struct {
+	Name     string `q:"name"`
+	DomainId string `q:"domain_id"`
+}
@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"fmt"
+	"request_openstack/configs"
+	"request_openstack/consts"
+)
 
 type Attachment struct {
 	ServerId     string      `json:"server_id"`
@@ -11,6 +16,20 @@ type Attachment struct {
 	Id           string      `json:"id"`
 }
 
+// VolumeAttachment is an attachment of the cinder attachments API.
+type VolumeAttachment struct {
+	Id         string `json:"id"`
+	Status     string `json:"status"`
+	Instance   string `json:"instance"`
+	VolumeId   string `json:"volume_id"`
+	AttachMode string `json:"attach_mode"`
+	AttachedAt string `json:"attached_at"`
+}
+
+type VolumeAttachments struct {
+	As []VolumeAttachment `json:"attachments"`
+}
+
 type Volume struct {
 	Attachments        []Attachment  `json:"attachments"`
 	AvailabilityZone   string        `json:"availability_zone"`
@@ -70,3 +89,54 @@ type VolumeToImage struct {
 		VolumeType         interface{} `json:"volume_type"`
 	} `json:"os-volume_upload_image"`
 }
+
+// ListVolumesOpts filters GET /v3/{project_id}/volumes/detail, an admin sees
+// the volumes of another project with AllTenants and ProjectId.
+type ListVolumesOpts struct {
+	Name       string `q:"name"`
+	Status     string `q:"status"`
+	ProjectId  string `q:"project_id"`
+	AllTenants bool   `q:"all_tenants"`
+	// Sort is a comma separated list of key[:direction], e.g. "created_at:desc"
+	Sort   string `q:"sort"`
+	Limit  int    `q:"limit"`
+	Marker string `q:"marker"`
+}
+
+// ListAttachmentsOpts filters GET /v3/{project_id}/attachments.
+type ListAttachmentsOpts struct {
+	VolumeId   string `q:"volume_id"`
+	InstanceId string `q:"instance_id"`
+	Status     string `q:"status"`
+}
+
+type CreateVolumeOpts struct {
+	Size             int               `json:"size,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	VolumeType       string            `json:"volume_type,omitempty"`
+	AvailabilityZone string            `json:"availability_zone,omitempty"`
+	ImageRef         string            `json:"imageRef,omitempty"`
+	SnapshotId       string            `json:"snapshot_id,omitempty" ref:"true"`
+	SourceVolid      string            `json:"source_volid,omitempty" ref:"true"`
+	Multiattach      bool              `json:"multiattach,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+func (opts *CreateVolumeOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.VOLUME)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+// AssignProps second output parameter is dependent resources slice, an
+// imageRef of "local" is the image of the configuration
+func (opts *CreateVolumeOpts) AssignProps(props map[string]interface{}) (*CreateVolumeOpts, map[string]string) {
+	deps := assignProps(opts, props)
+	if opts.ImageRef == "local" {
+		opts.ImageRef = configs.CONF.ImageId
+	}
+	return opts, deps
+}
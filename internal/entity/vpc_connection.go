@@ -31,16 +31,26 @@ type VpcConnections struct {
 	Vcs                    []VpcConnection `json:"vpc_connections"`
 }
 
+// ListVpcConnectionsOpts filters GET /v2.0/vpc-connections, the extension
+// filters the project by tenant_id.
+type ListVpcConnectionsOpts struct {
+	Name        string `q:"name"`
+	TenantId    string `q:"tenant_id"`
+	LocalRouter string `q:"local_router"`
+	PeerRouter  string `q:"peer_router"`
+	Status      string `q:"status"`
+}
+
 type CreateVpcConnectionOpts struct {
 	Name                string   `json:"name,omitempty"`
 	TenantId            string   `json:"tenant_id,omitempty"`
-	LocalRouter         string   `json:"local_router"`
-	LocalSubnets        []string `json:"local_subnets,omitempty"`
+	LocalRouter         string   `json:"local_router" ref:"true"`
+	LocalSubnets        []string `json:"local_subnets,omitempty" ref:"true"`
 	LocalCidrs          []string `json:"local_cidrs,omitempty"`
 	FwEnabled           bool     `json:"fw_enabled,omitempty"`
 	LocalFirewallEnable bool     `json:"local_firewall_enable,omitempty"`
-	PeerRouter          string   `json:"peer_router"`
-	PeerSubnets         []string `json:"peer_subnets,omitempty"`
+	PeerRouter          string   `json:"peer_router" ref:"true"`
+	PeerSubnets         []string `json:"peer_subnets,omitempty" ref:"true"`
 	PeerCidrs           []string `json:"peer_cidrs,omitempty"`
 	PeerFirewallEnable  bool     `json:"peer_firewall_enable,omitempty"`
 	Mode                int      `json:"mode,omitempty"`
@@ -55,6 +65,10 @@ func (opts *CreateVpcConnectionOpts) ToRequestBody() string {
 	return reqBody
 }
 
+func (opts *CreateVpcConnectionOpts) AssignProps(props map[string]interface{}) (*CreateVpcConnectionOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
 
 type UpdateVpcConnectionOpts struct {
 	Name                string   `json:"name,omitempty"`
@@ -1,5 +1,9 @@
 package entity
 
+import (
+	"fmt"
+	"request_openstack/consts"
+)
 
 type VpnService struct {
 	RouterId     string      `json:"router_id"`
@@ -21,7 +25,7 @@ type VpnServiceMap struct {
 }
 
 type VpnServices struct {
-	Vss              []VpnService `json:"vpcservices"`
+	Vss              []VpnService `json:"vpnservices"`
 	Count            int          `json:"count"`
 }
 
@@ -132,3 +136,166 @@ type IpsecConnections struct {
 	ICs          []IpsecSiteConnection `json:"ipsec_site_connections"`
 	Count              int             `json:"count"`
 }
+
+// Lifetime is the security association lifetime of ike and ipsec policies.
+type Lifetime struct {
+	Units string `json:"units,omitempty"`
+	Value int    `json:"value,omitempty"`
+}
+
+type CreateVpnServiceOpts struct {
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	RouterID     string `json:"router_id" ref:"true"`
+	SubnetID     string `json:"subnet_id,omitempty" ref:"true"`
+	AdminStateUp *bool  `json:"admin_state_up,omitempty"`
+}
+
+func (opts *CreateVpnServiceOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.VPNSERVICE)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateVpnServiceOpts) AssignProps(props map[string]interface{}) (*CreateVpnServiceOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// CreateEndpointGroupOpts specifies an endpoint group, of subnets for the
+// type subnet, the local group of a connection, or of cidrs for the type
+// cidr, its peer group. Subnets are the subnet endpoints of a template.
+type CreateEndpointGroupOpts struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Endpoints   []string `json:"endpoints"`
+	Subnets     []string `json:"-" prop:"subnets" ref:"true"`
+}
+
+func (opts *CreateEndpointGroupOpts) ToRequestBody() string {
+	if len(opts.Subnets) > 0 {
+		opts.Endpoints = append(opts.Subnets, opts.Endpoints...)
+		opts.Subnets = nil
+	}
+	reqBody, err := BuildRequestBody(opts, consts.ENDPOINTGROUP)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateEndpointGroupOpts) AssignProps(props map[string]interface{}) (*CreateEndpointGroupOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+type CreateIkePolicyOpts struct {
+	Name                  string    `json:"name,omitempty"`
+	Description           string    `json:"description,omitempty"`
+	AuthAlgorithm         string    `json:"auth_algorithm,omitempty"`
+	EncryptionAlgorithm   string    `json:"encryption_algorithm,omitempty"`
+	Pfs                   string    `json:"pfs,omitempty"`
+	Phase1NegotiationMode string    `json:"phase1_negotiation_mode,omitempty"`
+	IkeVersion            string    `json:"ike_version,omitempty"`
+	Lifetime              *Lifetime `json:"lifetime,omitempty"`
+}
+
+// DefaultIkePolicyOpts are the opts of the ike policies of the vpn helpers.
+func DefaultIkePolicyOpts() *CreateIkePolicyOpts {
+	return &CreateIkePolicyOpts{
+		AuthAlgorithm:         "sha1",
+		EncryptionAlgorithm:   "aes-128",
+		Pfs:                   "group14",
+		Phase1NegotiationMode: "main",
+		IkeVersion:            "v1",
+		Lifetime:              &Lifetime{Units: "seconds", Value: 7200},
+	}
+}
+
+func (opts *CreateIkePolicyOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.IKEPOLICY)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateIkePolicyOpts) AssignProps(props map[string]interface{}) (*CreateIkePolicyOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+type CreateIpsecPolicyOpts struct {
+	Name                string    `json:"name,omitempty"`
+	Description         string    `json:"description,omitempty"`
+	TransformProtocol   string    `json:"transform_protocol,omitempty"`
+	AuthAlgorithm       string    `json:"auth_algorithm,omitempty"`
+	EncapsulationMode   string    `json:"encapsulation_mode,omitempty"`
+	EncryptionAlgorithm string    `json:"encryption_algorithm,omitempty"`
+	Pfs                 string    `json:"pfs,omitempty"`
+	Lifetime            *Lifetime `json:"lifetime,omitempty"`
+}
+
+// DefaultIpsecPolicyOpts are the opts of the ipsec policies of the vpn
+// helpers.
+func DefaultIpsecPolicyOpts() *CreateIpsecPolicyOpts {
+	return &CreateIpsecPolicyOpts{
+		TransformProtocol:   "esp",
+		AuthAlgorithm:       "sha1",
+		EncapsulationMode:   "tunnel",
+		EncryptionAlgorithm: "aes-128",
+		Pfs:                 "group5",
+		Lifetime:            &Lifetime{Units: "seconds", Value: 7200},
+	}
+}
+
+func (opts *CreateIpsecPolicyOpts) ToRequestBody() string {
+	reqBody, err := BuildRequestBody(opts, consts.IPSECPOLICY)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateIpsecPolicyOpts) AssignProps(props map[string]interface{}) (*CreateIpsecPolicyOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
+
+// CreateIpsecConnectionOpts specifies an ipsec site connection, PeerID
+// defaults to PeerAddress.
+type CreateIpsecConnectionOpts struct {
+	Name           string `json:"name,omitempty"`
+	Description    string `json:"description,omitempty"`
+	VpnserviceID   string `json:"vpnservice_id" ref:"true"`
+	IkepolicyID    string `json:"ikepolicy_id" ref:"true"`
+	IpsecpolicyID  string `json:"ipsecpolicy_id" ref:"true"`
+	LocalEpGroupID string `json:"local_ep_group_id" ref:"true"`
+	PeerEpGroupID  string `json:"peer_ep_group_id" ref:"true"`
+	PeerAddress    string `json:"peer_address"`
+	PeerID         string `json:"peer_id"`
+	Psk            string `json:"psk"`
+	Initiator      string `json:"initiator,omitempty"`
+	Mtu            int    `json:"mtu,omitempty"`
+	AdminStateUp   *bool  `json:"admin_state_up,omitempty"`
+}
+
+// DefaultIpsecConnectionOpts are the opts of the connections of the vpn
+// helpers.
+func DefaultIpsecConnectionOpts() *CreateIpsecConnectionOpts {
+	return &CreateIpsecConnectionOpts{Psk: "secret", Initiator: "bi-directional", Mtu: 1500}
+}
+
+func (opts *CreateIpsecConnectionOpts) ToRequestBody() string {
+	if opts.PeerID == "" {
+		opts.PeerID = opts.PeerAddress
+	}
+	reqBody, err := BuildRequestBody(opts, consts.IPSECCONNECTION)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to build request body %s", err))
+	}
+	return reqBody
+}
+
+func (opts *CreateIpsecConnectionOpts) AssignProps(props map[string]interface{}) (*CreateIpsecConnectionOpts, map[string]string) {
+	return opts, assignProps(opts, props)
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned by the Request verbs whenever an OpenStack service
+// answers with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Service    string
+	Method     string
+	URL        string
+	// Fault is the message extracted from the service's fault body, e.g. the
+	// NeutronError message or the Nova/Cinder badRequest message.
+	Fault      string
+	Body       string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	service := e.Service
+	if service == "" {
+		service = "openstack"
+	}
+	msg := e.Fault
+	if msg == "" {
+		msg = e.Body
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s: %s %s failed with status %d (request id %s): %s",
+			service, e.Method, e.URL, e.StatusCode, e.RequestID, msg)
+	}
+	return fmt.Sprintf("%s: %s %s failed with status %d: %s", service, e.Method, e.URL, e.StatusCode, msg)
+}
+
+func newAPIError(service, method, url string, statusCode int, header func(string) string, body []byte) *APIError {
+	requestId := header("X-Openstack-Request-Id")
+	if requestId == "" {
+		requestId = header("X-Compute-Request-Id")
+	}
+	return &APIError{
+		StatusCode: statusCode,
+		Service:    service,
+		Method:     method,
+		URL:        url,
+		Fault:      parseFault(body),
+		Body:       strings.TrimSpace(string(body)),
+		RequestID:  requestId,
+	}
+}
+
+// parseFault extracts the human-readable message from the different fault
+// formats used by the OpenStack services:
+//   neutron:  {"NeutronError": {"type": ..., "message": ..., "detail": ...}}
+//   nova/cinder: {"itemNotFound": {"message": ..., "code": 404}}
+//   keystone: {"error": {"code": ..., "title": ..., "message": ...}}
+//   octavia:  {"faultcode": ..., "faultstring": ..., "debuginfo": ...}
+func parseFault(body []byte) string {
+	var fault map[string]interface{}
+	if err := json.Unmarshal(body, &fault); err != nil {
+		return ""
+	}
+	if v, ok := fault["faultstring"].(string); ok {
+		return v
+	}
+	if v, ok := fault["message"].(string); ok {
+		return v
+	}
+	for _, v := range fault {
+		if detail, ok := v.(map[string]interface{}); ok {
+			if message, ok := detail["message"].(string); ok {
+				return message
+			}
+		}
+	}
+	return ""
+}
+
+// IsStatus reports whether err is an APIError with the given status code.
+func IsStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == statusCode
+	}
+	return false
+}
+
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+func IsConflict(err error) bool {
+	return IsStatus(err, http.StatusConflict)
+}
+
+// ErrDryRun is returned instead of sending a request that changes a resource
+// while the client is in dry-run mode, see Request.DryRun.
+var ErrDryRun = errors.New("dry run, request not sent")
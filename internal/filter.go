@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ResourceFilter selects the resources the Delete methods of the cleaner
+// delete, a resource is selected when it matches all the rules set. A rule
+// the resource has no field for, e.g. the tags of a volume, does not match,
+// so that a filter never widens what is deleted. See Request.Filter.
+type ResourceFilter struct {
+	// Name matches the names of the resources, e.g. _network$ for the
+	// snowflake-suffixed names of CreateNetwork
+	Name *regexp.Regexp
+	// Tags are the neutron or nova tags a resource must all have
+	Tags []string
+	// OlderThan selects the resources created at least that long ago
+	OlderThan time.Duration
+	// Protected are the ids of the resources never deleted
+	Protected map[string]bool
+	// Now is the time OlderThan counts from, time.Now when nil
+	Now func() time.Time
+}
+
+// filterFields are the fields of a resource the rules of ResourceFilter
+// read, created is the creation time of nova servers.
+type filterFields struct {
+	Id        string        `json:"id"`
+	Name      string        `json:"name"`
+	Tags      []interface{} `json:"tags"`
+	CreatedAt string        `json:"created_at"`
+	Created   string        `json:"created"`
+}
+
+// createdLayouts are the formats of created_at, cinder leaves out the zone.
+var createdLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"}
+
+// Selects tells whether resource, an entity of a list response, is deleted.
+func (f *ResourceFilter) Selects(resource interface{}) bool {
+	if f == nil {
+		return true
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return false
+	}
+	var fields filterFields
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	if f.Protected[fields.Id] {
+		return false
+	}
+	if f.Name != nil && !f.Name.MatchString(fields.Name) {
+		return false
+	}
+	if !hasTags(fields.Tags, f.Tags) {
+		return false
+	}
+	if f.OlderThan > 0 {
+		created, ok := parseCreated(fields.CreatedAt)
+		if !ok {
+			created, ok = parseCreated(fields.Created)
+		}
+		if !ok || f.now().Sub(created) < f.OlderThan {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *ResourceFilter) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+	return f.Now()
+}
+
+func hasTags(tags []interface{}, wanted []string) bool {
+	have := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		have[fmt.Sprint(tag)] = true
+	}
+	for _, tag := range wanted {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCreated parses a creation time, the zero time of an entity without
+// one is not a creation time.
+func parseCreated(value string) (time.Time, bool) {
+	for _, layout := range createdLayouts {
+		if created, err := time.Parse(layout, value); err == nil && !created.IsZero() {
+			return created, true
+		}
+	}
+	return time.Time{}, false
+}
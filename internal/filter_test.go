@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"regexp"
+	"request_openstack/internal/entity"
+	"testing"
+	"time"
+)
+
+func TestResourceFilterSelects(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	filter := &ResourceFilter{
+		Name:      regexp.MustCompile(`^sdn_test\d+_network$`),
+		Tags:      []string{"ci"},
+		OlderThan: 24 * time.Hour,
+		Protected: map[string]bool{"kept": true},
+		Now:       func() time.Time { return now },
+	}
+	leftover := entity.Network{
+		Id:        "net",
+		Name:      "sdn_test1790000000001_network",
+		Tags:      []interface{}{"ci", "nightly"},
+		CreatedAt: now.Add(-48 * time.Hour),
+	}
+	if !filter.Selects(leftover) {
+		t.Fatal("expected the leftover network to be selected")
+	}
+
+	recent := leftover
+	recent.CreatedAt = now.Add(-time.Hour)
+	kept := leftover
+	kept.Id = "kept"
+	untagged := leftover
+	untagged.Tags = nil
+	renamed := leftover
+	renamed.Name = "production"
+	undated := leftover
+	undated.CreatedAt = time.Time{}
+	for name, network := range map[string]entity.Network{
+		"recent": recent, "protected": kept, "untagged": untagged, "renamed": renamed, "undated": undated,
+	} {
+		if filter.Selects(network) {
+			t.Errorf("expected the %s network not to be selected", name)
+		}
+	}
+}
+
+func TestResourceFilterServerCreated(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	filter := &ResourceFilter{OlderThan: time.Hour, Now: func() time.Time { return now }}
+	server := entity.Server{Id: "vm", Created: now.Add(-2 * time.Hour)}
+	if !filter.Selects(server) {
+		t.Fatal("expected the server created two hours ago to be selected")
+	}
+	var none *ResourceFilter
+	if !none.Selects(server) {
+		t.Fatal("expected a nil filter to select everything")
+	}
+}
@@ -20,8 +20,9 @@ type Glance struct {
 func NewGlance(token, projectId string, client *fasthttp.Client) *Glance {
 	return &Glance{
 		Request: Request{
-			UrlPrefix: fmt.Sprintf("http://%s:%d/v2", configs.CONF.Host, consts.GlancePort),
+			UrlPrefix: DefaultEndpoint(consts.GLANCE),
 			Client: client,
+			Service: consts.GLANCE,
 		},
 		projectId: projectId,
 		headers: map[string]string{"X-Auth-Token": token},
@@ -29,91 +30,114 @@ func NewGlance(token, projectId string, client *fasthttp.Client) *Glance {
 	}
 }
 
-func (g *Glance) CreateImage(reqBody string) string {
+func (g *Glance) CreateImage(reqBody string) (string, error) {
 	createSuffix := "/images"
-	resp := g.Post(g.headers, createSuffix, reqBody)
+	resp, err := g.Post(g.headers, createSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 
 	var image entity.ImageMap
 	_ = json.Unmarshal(resp, &image)
 	//cache.RedisClient.SetMap(g.tag + consts.Images, image.Id, image)
 	log.Println("==============Create image success", image.Id)
-	return image.Id
+	return image.Id, nil
 }
 
-func (g *Glance) SetImageProtected(imageId string, protected bool) string {
+func (g *Glance) SetImageProtected(imageId string, protected bool) (string, error) {
 	// property-->[private, public, shared, community]
 	createSuffix := fmt.Sprintf("/images/%s", imageId)
 	formatter := `[{"path": "/protected", "value": %+v, "op": "replace"}]`
 	reqBody := fmt.Sprintf(formatter, protected)
-	resp := g.Patch(g.headers, createSuffix, reqBody)
+	resp, err := g.Patch(g.headers, createSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var image entity.ImageMap
 	_ = json.Unmarshal(resp, &image)
 	//cache.RedisClient.SetMap(g.tag + consts.Images, image.Id, image)
 	log.Printf("==============Set image %s protected %v success", image.Id, protected)
-	return image.Id
+	return image.Id, nil
 }
 
-func (g *Glance) SetImageVisibilityProperty(imageId string, property string) string {
+func (g *Glance) SetImageVisibilityProperty(imageId string, property string) (string, error) {
 	// property-->[private, public, shared, community]
 	createSuffix := fmt.Sprintf("/images/%s", imageId)
 	formatter := `[{"path": "/visibility", "value": "%+v", "op": "replace"}]`
 	reqBody := fmt.Sprintf(formatter, property)
-	resp := g.Patch(g.headers, createSuffix, reqBody)
+	resp, err := g.Patch(g.headers, createSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var image entity.ImageMap
 	_ = json.Unmarshal(resp, &image)
 	//cache.RedisClient.SetMap(g.tag + consts.Images, image.Id, image)
 	log.Printf("==============Set image %s visibility %s success", image.Id, property)
-	return image.Id
+	return image.Id, nil
 }
 
-func (g *Glance) CreateImageMember(imageId, memberId string) {
+func (g *Glance) CreateImageMember(imageId, memberId string) error {
 	createSuffix := fmt.Sprintf("/images/%s/members", imageId)
 	formatter := `{"member": "%+v"}`
 	reqBody := fmt.Sprintf(formatter, memberId)
-	resp := g.Post(g.headers, createSuffix, reqBody)
+	resp, err := g.Post(g.headers, createSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	var imageMember entity.ImageMember
 	_ = json.Unmarshal(resp, &imageMember)
 	//cache.RedisClient.SetMap(g.tag + consts.Images, image.Id, image)
 	log.Println("==============Create image member success", imageMember.ImageId)
+	return nil
 }
 
-func (g *Glance) SetImageMemberStatus(imageId, memberId, status string) {
+func (g *Glance) SetImageMemberStatus(imageId, memberId, status string) error {
 	// status must in [pending, accepted, rejected]
 	createSuffix := fmt.Sprintf("/images/%s/members/%s", imageId, memberId)
 	formatter := `{"status": "%+v"}`
 	reqBody := fmt.Sprintf(formatter, status)
-	resp := g.Put(g.headers, createSuffix, reqBody)
+	resp, err := g.Put(g.headers, createSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	var imageMember entity.ImageMember
 	_ = json.Unmarshal(resp, &imageMember)
 	//cache.RedisClient.SetMap(g.tag + consts.Images, image.Id, image)
 	log.Println("==============Set image status success", imageMember.ImageId)
+	return nil
 }
 
-func (g *Glance) DeleteImageMember(imageId, memberId string) {
+func (g *Glance) DeleteImageMember(imageId, memberId string) error {
 	createSuffix := fmt.Sprintf("/images/%s/members/%s", imageId, memberId)
-	if ok, _ := g.Delete(g.headers, createSuffix); ok {
-		log.Println("==============Delete image member success", memberId)
-	} else {
+	if err := g.Delete(g.headers, createSuffix); err != nil {
 		log.Println("==============Delete image member failed", memberId)
+		return err
 	}
+	log.Println("==============Delete image member success", memberId)
+	return nil
 }
 
-func (g *Glance) GetImage(imageId string) entity.ImageMap {
+func (g *Glance) GetImage(imageId string) (entity.ImageMap, error) {
 	suffix := fmt.Sprintf("/images/%s", imageId)
-	resp := g.Get(g.headers, suffix)
 	var image entity.ImageMap
+	resp, err := g.Get(g.headers, suffix)
+	if err != nil {
+		return image, err
+	}
 	_ = json.Unmarshal(resp, &image)
 	log.Println("==============Get image success")
-	return image
+	return image, nil
 }
 
-func (g *Glance) GetImages() entity.Images {
-	suffix := fmt.Sprintf("/images?project_id=%s", g.projectId)
-	resp := g.Get(g.headers, suffix)
+func (g *Glance) GetImages(opts entity.ListImagesOpts) (entity.Images, error) {
 	var images entity.Images
+	resp, err := g.ListBy(g.headers, "/images", opts)
+	if err != nil {
+		return images, err
+	}
 	_ = json.Unmarshal(resp, &images)
 	log.Println("==============List image success", images.Is)
-	return images
+	return images, nil
 }
 
 func (g *Glance) ConstructRawImage() string {
@@ -154,54 +178,73 @@ func (g *Glance) ConstructS3Image() string {
 	return body
 }
 
-func (g *Glance) CreateRawImage() string {
+func (g *Glance) CreateRawImage() (string, error) {
     reqBody := g.ConstructRawImage()
     return g.CreateImage(reqBody)
 }
 
-func (g *Glance) CreateImageToS3() string {
+func (g *Glance) CreateImageToS3() (string, error) {
 	g.headers["OpenStack-image-store-ids"] = "cheap"
 	reqBody := g.ConstructS3Image()
 	return g.CreateImage(reqBody)
 }
 
-func (g *Glance) DeleteImage(imageId string) {
+func (g *Glance) DeleteImage(imageId string) error {
 	urlSuffix := "/images/" + imageId
-	if ok, _ := g.Delete(g.headers, urlSuffix); ok {
-		log.Println("==============Delete image success", imageId)
-	} else {
+	if err := g.Delete(g.headers, urlSuffix); err != nil {
 		log.Println("==============Delete image failed", imageId)
+		return err
 	}
+	log.Println("==============Delete image success", imageId)
+	return nil
 }
 
-func (g *Glance) DeleteImages() {
-	images := g.GetImages()
+func (g *Glance) DeleteImages() error {
+	images, err := g.GetImages(entity.ListImagesOpts{ProjectId: g.projectId})
+	if err != nil {
+		return err
+	}
 	for _, image := range images.Is {
-		g.DeleteImage(image.Id)
+		if err = g.DeleteImage(image.Id); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (g *Glance) GetImageSchemas() {
+func (g *Glance) GetImageSchemas() error {
 	suffix := "/schemas/images"
-	resp := g.Get(g.headers, suffix)
+	resp, err := g.Get(g.headers, suffix)
+	if err != nil {
+		return err
+	}
 	fmt.Println(resp)
 	//images := resp["images"]
 	//for _, image := range images.([]interface{}) {
 	//	fmt.Println("image==", image.(map[string]interface{}))
 	//}
+	return nil
 }
 
 // GetStores not implement
-func (g *Glance) GetStores()  {
+func (g *Glance) GetStores() error {
 	suffix := "/info/stores"
-	resp := g.Get(g.headers, suffix)
+	resp, err := g.Get(g.headers, suffix)
+	if err != nil {
+		return err
+	}
 	fmt.Println(resp)
+	return nil
 }
 
-func (g *Glance) GetImportMethods()  {
+func (g *Glance) GetImportMethods() error {
 	suffix := "/info/import"
-	resp := g.DecorateGetResp(g.Get)(g.headers, suffix)
+	resp, err := g.DecorateGetResp(g.Get)(g.headers, suffix)
+	if err != nil {
+		return err
+	}
 	importMethods := resp["import-methods"]
 	fmt.Println(importMethods)
+	return nil
 }
 
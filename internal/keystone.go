@@ -17,50 +17,89 @@ type Keystone struct {
 	Headers               map[string]string
 	Request
 	tag                   string
+	catalog               entity.Catalog
 }
 
 func NewKeystone(client  *fasthttp.Client) *Keystone {
 	return &Keystone{
 		Request: Request{
-		     UrlPrefix: fmt.Sprintf("http://%s:%d/v3", configs.CONF.Host, consts.KeystonePort),
+		     UrlPrefix: DefaultEndpoint(consts.KEYSTONE),
 		     Client: client,
+		     Service: consts.KEYSTONE,
 	    },
 		Headers: make(map[string]string),
 		tag: configs.CONF.Host + "_",
 	}
 }
 
-func (k *Keystone) GetToken(projectName, userName, userPassword string) string {
-	urlSuffix := "/auth/tokens"
-	reqBody := k.constructAuthReqBody(projectName, userName, userPassword)
-	token := k.GetHeaderToken(k.Headers, urlSuffix, reqBody)
+func (k *Keystone) GetToken(projectName, userName, userPassword string) (string, error) {
+	token, tokenMap, err := k.IssueToken(projectName, userName, userPassword)
+	if err != nil {
+		return "", err
+	}
+	k.catalog = tokenMap.Token.Catalog
 	log.Println("==============Get token success")
-	return token
+	return token, nil
 }
 
-func (k *Keystone) GetFederationToken(projectName, userName, userPassword string) string {
-	urlSuffix := "/auth/tokens"
-	reqBody := k.constructAuthReqBody(projectName, userName, userPassword)
-	token := k.GetHeaderToken(k.Headers, urlSuffix, reqBody)
-	log.Println("==============Get token success")
-	return token
+// IssueToken authenticates through the identity provider of
+// ConfiguredFederation, or with the method of ConfiguredAuth, without
+// sending the possibly expired token of the client.
+func (k *Keystone) IssueToken(projectName, userName, userPassword string) (string, entity.TokenMap, error) {
+	method, scope := ConfiguredAuth(projectName, userName, userPassword)
+	if federation, ok := ConfiguredFederation(userName, userPassword); ok {
+		return k.Federate(federation, scope)
+	}
+	return k.Authenticate(method, scope)
+}
+
+// GetFederationToken issues a token through the identity provider, scoped to
+// the project.
+func (k *Keystone) GetFederationToken(federation Federation, projectName string) (string, error) {
+	token, tokenMap, err := k.Federate(federation, ProjectScope(projectName))
+	if err != nil {
+		return "", err
+	}
+	k.catalog = tokenMap.Token.Catalog
+	log.Println("==============Get federation token success")
+	return token, nil
 }
 
+// Catalog returns the service catalog of the last token issued by GetToken.
+func (k *Keystone) Catalog() entity.Catalog {
+	return k.catalog
+}
+
+// SetCatalog shares a catalog with a Keystone that was handed a token
+// instead of requesting one.
+func (k *Keystone) SetCatalog(catalog entity.Catalog) {
+	k.catalog = catalog
+}
 
+// EndpointFor resolves the API root of the service from the catalog.
+func (k *Keystone) EndpointFor(service string) (string, error) {
+	return EndpointFor(k.catalog, service)
+}
 
-func (k *Keystone) setToken(projectName, userName, userPassword string) string {
+func (k *Keystone) setToken(projectName, userName, userPassword string) (string, error) {
 	log.Println("Etcd server no token, get from openstack then put to etcd server")
-	token := k.GetToken(projectName, userName, userPassword)
+	token, err := k.GetToken(projectName, userName, userPassword)
+	if err != nil {
+		return "", err
+	}
 	g := etcd.GrantLease(2 * 60 * 60)
 	etcd.PutKV(configs.CONF.Host + "_" + projectName + "_token", token, clientv3.WithLease(g.ID))
-	return token
+	return token, nil
 }
 
-func (k *Keystone) AllocateToken(projectName, userName, userPassword string) (token string) {
+func (k *Keystone) AllocateToken(projectName, userName, userPassword string) (token string, err error) {
 	tokenKey := configs.CONF.Host + "_" + projectName + "_token"
 	v := etcd.GetKV(tokenKey)
 	if v == "" {
-		token = k.setToken(projectName, userName, userPassword)
+		token, err = k.setToken(projectName, userName, userPassword)
+		if err != nil {
+			return "", err
+		}
 	} else {
 		token = v
 	}
@@ -68,196 +107,236 @@ func (k *Keystone) AllocateToken(projectName, userName, userPassword string) (to
 	return
 }
 
-func (k *Keystone) constructAuthReqBody(projectName, userName, userPassword string) string {
-	bodyStr := fmt.Sprintf("{\"auth\": {\"identity\": {\"methods\": [\"password\"],\"password\": {\"user\": {\"name\": \"%+v\",\"domain\": {\"id\": \"default\"},\"password\": \"%+v\"}}},\"scope\": {\"project\": {\"domain\": {\"name\": \"default\"},\"name\": \"%+v\"}}}}", userName, userPassword, projectName)
-	return bodyStr
-}
-
-func (k *Keystone) GetAdminProjectId(token string) string {
+func (k *Keystone) GetAdminProjectId(token string) (string, error) {
 	key := fmt.Sprintf("%s_admin", configs.CONF.Host)
 	v := etcd.GetKV(key)
 	if v == "" {
-		urlSuffix := "/projects?name=admin"
 		k.Headers["X-Auth-Token"] = token
-		res := k.DecorateGetResp(k.List)(k.Headers, urlSuffix)
-		projectId := parseProjectId(res)
+		resp, err := k.ListBy(k.Headers, "/projects", entity.ListProjectsOpts{Name: consts.ADMIN})
+		if err != nil {
+			return "", err
+		}
+		projectId := parseProjectId(k.HandleRespBody(resp))
 		etcd.PutKV(key, projectId)
-		return projectId
+		return projectId, nil
 	} else {
-		return v
+		return v, nil
 	}
 }
 
 
 
-func (k *Keystone) GetProjectId(projectName string) string {
-	urlSuffix := fmt.Sprintf("/projects?name=%s", projectName)
-	res := k.DecorateGetResp(k.List)(k.Headers, urlSuffix)
-	projectId := parseProjectId(res)
+func (k *Keystone) GetProjectId(projectName string) (string, error) {
+	resp, err := k.ListBy(k.Headers, "/projects", entity.ListProjectsOpts{Name: projectName})
+	if err != nil {
+		return "", err
+	}
+	projectId := parseProjectId(k.HandleRespBody(resp))
 	log.Println("==============Get project success")
-	return projectId
+	return projectId, nil
 }
 
-func (k *Keystone) createProject(projectName string) string {
+func (k *Keystone) createProject(projectName string) (string, error) {
 	urlSuffix := "/projects"
 	reqBody := fmt.Sprintf("{\"project\": {\"description\": \"My new project\", \"domain_id\": \"default\", \"enabled\": true, \"is_domain\": false, \"name\": \"%+v\", \"options\": {}}}", projectName)
-    resp := k.Post(k.Headers, urlSuffix, reqBody)
+    resp, err := k.Post(k.Headers, urlSuffix, reqBody)
+    if err != nil {
+    	return "", err
+	}
 
     var project entity.ProjectMap
-    err := json.Unmarshal(resp, &project)
-    if err != nil {
-    	log.Println("json unmarshal failed", err)
-    	panic("")
+    if err = json.Unmarshal(resp, &project); err != nil {
+    	return "", fmt.Errorf("unmarshal project: %w", err)
 	}
     //cache.RedisClient.SetMap(k.tag + consts.PROJECTS, project.Project.Id, project)
 	log.Println("==============Create project success", project.Project.Id)
-    return project.Project.Id
+    return project.Project.Id, nil
 }
 
-func (k *Keystone) DeleteProject(projectId string) {
+func (k *Keystone) DeleteProject(projectId string) error {
 	urlSuffix := fmt.Sprintf("/projects/%s", projectId)
-	resp, _ := k.Delete(k.Headers, urlSuffix)
-	if resp {
-		//cache.RedisClient.DeleteMap(k.tag + consts.PROJECTS, projectId)
-		log.Println("==============Delete project success", projectId)
-	} else {
+	if err := k.Delete(k.Headers, urlSuffix); err != nil {
 		log.Println("==============Delete project failed", projectId)
+		return err
 	}
+	//cache.RedisClient.DeleteMap(k.tag + consts.PROJECTS, projectId)
+	log.Println("==============Delete project success", projectId)
+	return nil
 }
 
-func (k *Keystone) createUser(projectId, userName, userPassword string) string {
+func (k *Keystone) createUser(projectId, userName, userPassword string) (string, error) {
 	urlSuffix := "/users"
 	reqBody := fmt.Sprintf("{\"user\": {\"default_project_id\": \"%+v\", \"domain_id\": \"default\", \"enabled\": true, \"name\": \"%+v\", \"password\": \"%+v\", \"description\": \"sdn test user\", \"options\": {\"ignore_password_expiry\": true}}}", projectId, userName, userPassword)
-	resp := k.Post(k.Headers, urlSuffix, reqBody)
+	resp, err := k.Post(k.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 
 	var user entity.UserMap
-	err := json.Unmarshal(resp, &user)
-	if err != nil {
-		log.Println("json unmarshal failed", err)
-		panic("")
+	if err = json.Unmarshal(resp, &user); err != nil {
+		return "", fmt.Errorf("unmarshal user: %w", err)
 	}
 
 	//cache.RedisClient.SetMap(k.tag + consts.USERS, user.User.Id, user)
 	log.Println("==============Create user success", userName)
-	return user.User.Id
+	return user.User.Id, nil
 }
 
-func (k *Keystone) SetUserPasswordNotExpire(userId string) string {
+func (k *Keystone) SetUserPasswordNotExpire(userId string) (string, error) {
 	k.Headers["Content-Type"] = consts.ContentTypeJson
 	urlSuffix := fmt.Sprintf("/users/%s", userId)
 	reqBody := fmt.Sprintf("{\"user\": {\"options\": {\"ignore_password_expiry\": true}}}")
-	resp := k.Patch(k.Headers, urlSuffix, reqBody)
+	resp, err := k.Patch(k.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 
 	var user entity.UserMap
-	err := json.Unmarshal(resp, &user)
-	if err != nil {
-		log.Println("json unmarshal failed", err)
-		panic("")
+	if err = json.Unmarshal(resp, &user); err != nil {
+		return "", fmt.Errorf("unmarshal user: %w", err)
 	}
 
 	log.Println("==============Update user success", userId)
-	return user.User.Id
+	return user.User.Id, nil
 }
 
-func (k *Keystone) getUser(userId string) entity.UserMap {
+func (k *Keystone) getUser(userId string) (entity.UserMap, error) {
 	urlSuffix := fmt.Sprintf("/users/%s", userId)
-	resp := k.Get(k.Headers, urlSuffix)
-
 	var user entity.UserMap
-	if err := json.Unmarshal(resp, &user); err != nil {
-		log.Println("json unmarshal failed", err)
-		panic("")
+	resp, err := k.Get(k.Headers, urlSuffix)
+	if err != nil {
+		return user, err
+	}
+
+	if err = json.Unmarshal(resp, &user); err != nil {
+		return user, fmt.Errorf("unmarshal user: %w", err)
 	}
-	return user
+	return user, nil
 }
 
-func (k *Keystone) GetUserByName(userName string) string {
-	urlSuffix := fmt.Sprintf("/users?name=%s", userName)
-	res := k.DecorateGetResp(k.List)(k.Headers, urlSuffix)
-	userId := parseUserId(res)
+func (k *Keystone) GetUserByName(userName string) (string, error) {
+	resp, err := k.ListBy(k.Headers, "/users", entity.ListUsersOpts{Name: userName})
+	if err != nil {
+		return "", err
+	}
+	userId := parseUserId(k.HandleRespBody(resp))
 	log.Println("==============List user success")
-	return userId
+	return userId, nil
 }
 
-func (k *Keystone) DeleteUserByName(userName string) {
-	userId := k.GetUserByName(userName)
-	k.DeleteUser(userId)
+func (k *Keystone) DeleteUserByName(userName string) error {
+	userId, err := k.GetUserByName(userName)
+	if err != nil {
+		return err
+	}
+	return k.DeleteUser(userId)
 }
 
-func (k *Keystone) DeleteProjectByName(projectName string) {
-	projectId := k.GetProjectId(projectName)
-	k.DeleteProject(projectId)
+func (k *Keystone) DeleteProjectByName(projectName string) error {
+	projectId, err := k.GetProjectId(projectName)
+	if err != nil {
+		return err
+	}
+	return k.DeleteProject(projectId)
 }
 
-func (k *Keystone) DeleteUser(userId string) {
+func (k *Keystone) DeleteUser(userId string) error {
 	urlSuffix := fmt.Sprintf("/users/%s", userId)
-	resp, _ := k.Delete(k.Headers, urlSuffix)
-    if resp {
-    	//cache.RedisClient.DeleteMap(k.tag + consts.USERS, userId)
-		log.Println("==============Delete user success", userId)
-	} else {
+	if err := k.Delete(k.Headers, urlSuffix); err != nil {
 		log.Println("==============Delete user failed", userId)
+		return err
 	}
+	//cache.RedisClient.DeleteMap(k.tag + consts.USERS, userId)
+	log.Println("==============Delete user success", userId)
+	return nil
 }
 
-func (k *Keystone) getAdminRole() string {
-	urlSuffix := "/roles?name=admin"
-	resp := k.DecorateGetResp(k.List)(k.Headers, urlSuffix)
-	roles := resp["roles"].([]interface{})
+func (k *Keystone) getAdminRole() (string, error) {
+	resp, err := k.ListBy(k.Headers, "/roles", entity.ListRolesOpts{Name: consts.ADMIN})
+	if err != nil {
+		return "", err
+	}
+	roles, _ := k.HandleRespBody(resp)["roles"].([]interface{})
+	if len(roles) == 0 {
+		return "", fmt.Errorf("admin role not found")
+	}
 	adminRole := roles[0].(map[string]interface{})
-	return adminRole["id"].(string)
+	return adminRole["id"].(string), nil
 }
 
-func (k *Keystone) AssignRoleToUser(projectId, userId string) {
-    adminRoleId := k.getAdminRole()
+func (k *Keystone) AssignRoleToUser(projectId, userId string) error {
+    adminRoleId, err := k.getAdminRole()
+    if err != nil {
+    	return err
+	}
     urlSuffix := fmt.Sprintf("/projects/%s/users/%s/roles/%s", projectId, userId, adminRoleId)
-    k.Put(k.Headers, urlSuffix, "")
+    _, err = k.Put(k.Headers, urlSuffix, "")
+    return err
 
 	//k.SyncMap(k.tag + consts.USERS, userId, func(resourceId string) interface{} {
 	//	return k.getUser(userId)
 	//}, nil)
 }
 
-func (k *Keystone) PrepareProjectUserToken(projectName, userName, userPassword string) (string, string) {
-	projectId := k.GetProjectId(projectName)
-	if projectId == "" {
-		projectId = k.createProject(projectName)
+func (k *Keystone) PrepareProjectUserToken(projectName, userName, userPassword string) (string, string, error) {
+	projectId, err := k.ensureProjectUser(projectName, userName, userPassword)
+	if err != nil {
+		return "", "", err
 	}
-	userId := k.GetUserByName(userName)
-	if userId == "" {
-		userId = k.createUser(projectId, userName, userPassword)
-		k.AssignRoleToUser(projectId, userId)
+    token, err := k.AllocateToken(projectName, userName, userPassword)
+    if err != nil {
+    	return "", "", err
 	}
-    token := k.AllocateToken(projectName, userName, userPassword)
-    return projectId, token
+    return projectId, token, nil
 }
 
-func (k *Keystone) DeleteProjectUser() {
+func (k *Keystone) DeleteProjectUser() error {
 	userIds := cache.RedisClient.GetMaps(k.tag + consts.USERS)
 	for _, userId := range userIds {
-		k.DeleteUser(userId)
+		if err := k.DeleteUser(userId); err != nil {
+			return err
+		}
 	}
 
 	projectIds := cache.RedisClient.GetMaps(k.tag + consts.PROJECTS)
 	for _, projectId := range projectIds {
-		k.DeleteProject(projectId)
+		if err := k.DeleteProject(projectId); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (k *Keystone) MakeSureProjectExist() string {
+func (k *Keystone) MakeSureProjectExist() (string, error) {
 	projectName := configs.CONF.ProjectName
 	userName := configs.CONF.UserName
 	userPassword := configs.CONF.UserPassword
-	projectId := k.GetProjectId(projectName)
+	return k.ensureProjectUser(projectName, userName, userPassword)
+}
+
+func (k *Keystone) ensureProjectUser(projectName, userName, userPassword string) (string, error) {
+	projectId, err := k.GetProjectId(projectName)
+	if err != nil {
+		return "", err
+	}
 	if projectId == "" {
-		projectId = k.createProject(projectName)
+		if projectId, err = k.createProject(projectName); err != nil {
+			return "", err
+		}
+	}
+	userId, err := k.GetUserByName(userName)
+	if err != nil {
+		return "", err
 	}
-	userId := k.GetUserByName(userName)
 	if userId == "" {
-		userId = k.createUser(projectId, userName, userPassword)
-		k.AssignRoleToUser(projectId, userId)
+		if userId, err = k.createUser(projectId, userName, userPassword); err != nil {
+			return "", err
+		}
+		if err = k.AssignRoleToUser(projectId, userId); err != nil {
+			return "", err
+		}
 	}
-	return projectId
+	return projectId, nil
 }
 
 func (k *Keystone) SetHeader(key, val string)  {
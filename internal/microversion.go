@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"request_openstack/configs"
+	"request_openstack/consts"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LatestMicroversion negotiates the newest microversion the service supports.
+const LatestMicroversion = "latest"
+
+// Microversion is an API microversion, e.g. 2.79 of nova or 3.27 of cinder.
+type Microversion struct {
+	Major int
+	Minor int
+}
+
+// ParseMicroversion parses "2.79". A bare major version like "3", as the
+// python clients accept for OS_VOLUME_API_VERSION, is the zero Microversion.
+func ParseMicroversion(version string) (Microversion, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" || !strings.Contains(version, ".") {
+		if _, err := strconv.Atoi(version); version != "" && err != nil {
+			return Microversion{}, fmt.Errorf("invalid microversion %q", version)
+		}
+		return Microversion{}, nil
+	}
+	parts := strings.SplitN(version, ".", 2)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Microversion{}, fmt.Errorf("invalid microversion %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Microversion{}, fmt.Errorf("invalid microversion %q", version)
+	}
+	return Microversion{Major: major, Minor: minor}, nil
+}
+
+func (v Microversion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (v Microversion) IsZero() bool {
+	return v == Microversion{}
+}
+
+func (v Microversion) Less(other Microversion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+// VersionRange is the range of microversions a service announces in its
+// version document, zero when it does not support microversions.
+type VersionRange struct {
+	Min Microversion
+	Max Microversion
+}
+
+func (r VersionRange) Contains(v Microversion) bool {
+	return !v.Less(r.Min) && !r.Max.Less(v)
+}
+
+// MicroversionError is returned when an operation needs a microversion the
+// service does not support.
+type MicroversionError struct {
+	Service   string
+	Required  Microversion
+	Supported VersionRange
+}
+
+func (e *MicroversionError) Error() string {
+	if e.Supported.Max.IsZero() {
+		return fmt.Sprintf("%s does not support microversions, %s is required", e.Service, e.Required)
+	}
+	return fmt.Sprintf("%s supports microversions %s to %s, %s is required",
+		e.Service, e.Supported.Min, e.Supported.Max, e.Required)
+}
+
+// microversionServices maps a service to the name it is known by in the
+// OpenStack-API-Version header.
+var microversionServices = map[string]string{
+	consts.NOVA:   "compute",
+	consts.CINDER: "volume",
+}
+
+// discoveredVersions caches the version document per API root, it is shared
+// by every client of the endpoint.
+var discoveredVersions = struct {
+	sync.Mutex
+	m map[string]VersionRange
+}{m: make(map[string]VersionRange)}
+
+// SupportedMicroversions reads the microversion range from the version
+// document of the API root, e.g. GET /v2.1/ of nova or GET /v3/ of cinder.
+func (r *Request) SupportedMicroversions() (VersionRange, error) {
+	discoveredVersions.Lock()
+	defer discoveredVersions.Unlock()
+	if versions, ok := discoveredVersions.m[r.UrlPrefix]; ok {
+		return versions, nil
+	}
+	token, err := r.authToken()
+	if err != nil {
+		return VersionRange{}, err
+	}
+	// no version headers, the document describes every microversion
+	resp, _, err := r.doOnce(consts.GET, nil, token, r.UrlPrefix, nil)
+	if err != nil {
+		return VersionRange{}, fmt.Errorf("discover %s versions: %w", r.Service, err)
+	}
+	versions, err := parseVersionDocument(resp, apiVersions[r.Service])
+	if err != nil {
+		return VersionRange{}, fmt.Errorf("discover %s versions: %w", r.Service, err)
+	}
+	log.Printf("==============Discovered %s microversions %s to %s", r.Service, versions.Min, versions.Max)
+	discoveredVersions.m[r.UrlPrefix] = versions
+	return versions, nil
+}
+
+// parseVersionDocument understands the single "version" document of nova
+// and the "versions" list of cinder, preferring the entry of apiVersion.
+func parseVersionDocument(body []byte, apiVersion string) (VersionRange, error) {
+	type version struct {
+		Id         string `json:"id"`
+		Version    string `json:"version"`
+		MinVersion string `json:"min_version"`
+	}
+	var doc struct {
+		Version  *version  `json:"version"`
+		Versions []version `json:"versions"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return VersionRange{}, err
+	}
+	var found *version
+	if doc.Version != nil {
+		found = doc.Version
+	}
+	major := strings.TrimSuffix(apiVersion, "/")
+	for i := range doc.Versions {
+		if found == nil || strings.HasPrefix(doc.Versions[i].Id, major) {
+			found = &doc.Versions[i]
+		}
+	}
+	if found == nil {
+		return VersionRange{}, fmt.Errorf("no version in the version document")
+	}
+	min, err := ParseMicroversion(found.MinVersion)
+	if err != nil {
+		return VersionRange{}, err
+	}
+	max, err := ParseMicroversion(found.Version)
+	if err != nil {
+		return VersionRange{}, err
+	}
+	return VersionRange{Min: min, Max: max}, nil
+}
+
+// microversion returns the version pinned on the client, falling back to
+// configs.CONF.ComputeApiVersion or VolumeApiVersion.
+func (r *Request) microversion() string {
+	if r.Microversion != "" {
+		return r.Microversion
+	}
+	switch r.Service {
+	case consts.NOVA:
+		return configs.CONF.ComputeApiVersion
+	case consts.CINDER:
+		return configs.CONF.VolumeApiVersion
+	}
+	return ""
+}
+
+// ResolveMicroversion turns "latest" into the newest supported microversion
+// and checks any other version against the range of the service.
+func (r *Request) ResolveMicroversion(version string) (Microversion, error) {
+	if _, ok := microversionServices[r.Service]; !ok || version == "" {
+		return Microversion{}, nil
+	}
+	var required Microversion
+	if version != LatestMicroversion {
+		var err error
+		if required, err = ParseMicroversion(version); err != nil || required.IsZero() {
+			return required, err
+		}
+	}
+	supported, err := r.SupportedMicroversions()
+	if err != nil {
+		return Microversion{}, err
+	}
+	if version == LatestMicroversion {
+		return supported.Max, nil
+	}
+	if !supported.Contains(required) {
+		return Microversion{}, &MicroversionError{Service: r.Service, Required: required, Supported: supported}
+	}
+	return required, nil
+}
+
+// MicroversionHeaders returns a copy of headers requesting version for a
+// single call, overriding the microversion of the client. An operation that
+// needs e.g. nova 2.26 for server tags asks for it here and gets a
+// MicroversionError from an older service instead of a 404.
+func (r *Request) MicroversionHeaders(headers map[string]string, version string) (map[string]string, error) {
+	v, err := r.ResolveMicroversion(version)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]string, len(headers)+2)
+	for k, value := range headers {
+		res[k] = value
+	}
+	setMicroversionHeaders(res, r.Service, v)
+	return res, nil
+}
+
+// withMicroversion adds the version headers of the client unless the call
+// already carries its own.
+func (r *Request) withMicroversion(headers map[string]string) (map[string]string, error) {
+	if _, ok := headers[consts.ApiVersionHeader]; ok {
+		return headers, nil
+	}
+	version := r.microversion()
+	if version == "" {
+		return headers, nil
+	}
+	return r.MicroversionHeaders(headers, version)
+}
+
+func setMicroversionHeaders(headers map[string]string, service string, v Microversion) {
+	if v.IsZero() {
+		return
+	}
+	headers[consts.ApiVersionHeader] = microversionServices[service] + " " + v.String()
+	if service == consts.NOVA {
+		headers[consts.NovaApiVersionHeader] = v.String()
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"request_openstack/consts"
+	"testing"
+)
+
+func TestMicroversionNegotiation(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2.1/" {
+			fmt.Fprint(w, `{"version": {"id": "v2.1", "status": "CURRENT", "version": "2.79", "min_version": "2.1"}}`)
+			return
+		}
+		sent = append(sent, r.Header.Get(consts.ApiVersionHeader)+"|"+r.Header.Get(consts.NovaApiVersionHeader))
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(server.Close)
+
+	r := &Request{UrlPrefix: server.URL + "/v2.1/", Service: consts.NOVA, Microversion: LatestMicroversion}
+	if _, err := r.Get(nil, "servers"); err != nil {
+		t.Fatal(err)
+	}
+	headers, err := r.MicroversionHeaders(nil, "2.26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Get(headers, "servers"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0] != "compute 2.79|2.79" || sent[1] != "compute 2.26|2.26" {
+		t.Fatalf("unexpected version headers %q", sent)
+	}
+
+	_, err = r.MicroversionHeaders(nil, "2.90")
+	var versionErr *MicroversionError
+	if !errors.As(err, &versionErr) || versionErr.Supported.Max.String() != "2.79" {
+		t.Fatalf("expected a MicroversionError, got %v", err)
+	}
+}
+
+func TestParseVersionDocument(t *testing.T) {
+	cinder := `{"versions": [{"id": "v2.0", "version": "", "min_version": ""}, {"id": "v3.0", "version": "3.70", "min_version": "3.0"}]}`
+	versions, err := parseVersionDocument([]byte(cinder), "v3/")
+	if err != nil || versions.Min.String() != "3.0" || versions.Max.String() != "3.70" {
+		t.Fatalf("unexpected versions %+v: %v", versions, err)
+	}
+	if v, err := ParseMicroversion("3"); err != nil || !v.IsZero() {
+		t.Fatalf("expected no microversion for a bare major version, got %s: %v", v, err)
+	}
+}
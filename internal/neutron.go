@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -53,6 +54,7 @@ func NewNeutron(options ...Option) *Neutron {
 		DeleteChannels: initNeutronOutputChannels(),
 		Request: opts.Request,
 	}
+	neutron.Service = consts.NEUTRON
 	neutron.projectId = opts.ProjectId
 	Headers := make(map[string]string)
 	Headers[consts.AuthToken] = opts.Token
@@ -85,445 +87,515 @@ func (n *Neutron) MakeDeleteChannel(resourceType string, length int) chan Output
 
 // network
 
-func (n *Neutron) CreateNetwork(opts *entity.CreateNetworkOpts) string {
+func (n *Neutron) CreateNetwork(opts *entity.CreateNetworkOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.NETWORK)
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, consts.NETWORKS, reqBody)
+	resp, err := n.Post(n.Headers, consts.NETWORKS, reqBody)
+	if err != nil {
+		return "", err
+	}
 
 	var network entity.NetworkMap
 	_ = json.Unmarshal(resp, &network)
 
 	//cache.RedisClient.SetMap(n.tag + consts.NETWORKS, network.Network.Id, network)
 	log.Println("==============Create internal network success", network.Network.Id)
-	return network.Network.Id
+	return network.Network.Id, nil
 }
 
-func (n *Neutron) GetNetwork(networkId string) entity.NetworkMap {
+func (n *Neutron) GetNetwork(networkId string) (entity.NetworkMap, error) {
 	urlSuffix := fmt.Sprintf("networks/%s", networkId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.NetworkMap{}, err
+	}
 	var obj entity.NetworkMap
 	_ = json.Unmarshal(resp, &obj)
-	return obj
+	return obj, nil
 }
 
-func (n *Neutron) UpdateNetwork(netId string, updateBody string) {
+func (n *Neutron) UpdateNetwork(netId string, updateBody string) error {
 	urlSuffix := fmt.Sprintf("networks/%s", netId)
-	resp := n.Put(n.Headers, urlSuffix, updateBody)
+	resp, err := n.Put(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return err
+	}
 
 	var net entity.NetworkMap
     _ = json.Unmarshal(resp, &net)
 	//n.SyncMap(n.tag + consts.NETWORKS, netId, nil, net)
 	log.Printf("==============Update network %s success\n", netId)
+	return nil
 }
 
-func (n *Neutron) UpdateNetWithQos(netId, qosId string) string {
+func (n *Neutron) UpdateNetWithQos(netId, qosId string) (string, error) {
 	updateBody := fmt.Sprintf("{\"network\": {\"qos_policy_id\": \"%+v\"}}", qosId)
-    n.UpdateNetwork(netId, updateBody)
-	return netId
+	if err := n.UpdateNetwork(netId, updateBody); err != nil {
+		return "", err
+	}
+	return netId, nil
 }
 
-func (n *Neutron) ListNetworks() entity.Networks {
-	var urlSuffix string
+// projectOpts scopes a list to the project of the client, an admin client
+// sees the resources of every project.
+func (n *Neutron) projectOpts() entity.ListOpts {
 	if n.isAdmin {
-		urlSuffix = consts.NETWORKS
-	} else {
-		urlSuffix = fmt.Sprintf("networks?project_id=%s", n.projectId)
+		return entity.ListOpts{}
+	}
+	return entity.ListOpts{ProjectId: n.projectId}
+}
+
+func (n *Neutron) ListNetworks(opts entity.ListNetworksOpts) (entity.Networks, error) {
+	resp, err := n.ListBy(n.Headers, consts.NETWORKS, opts)
+	if err != nil {
+		return entity.Networks{}, err
 	}
-	//urlSuffix := "networks"
-	resp := n.List(n.Headers, urlSuffix)
 	var networks entity.Networks
 	_ = json.Unmarshal(resp, &networks)
 	log.Println("==============List network success, there had", networks.Count)
-	return networks
+	return networks, nil
 }
 
-func (n *Neutron) getNetworkPorts(networkId string) []entity.Port {
-	urlSuffix := fmt.Sprintf("ports?network_id=%s", networkId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) getNetworkPorts(networkId string) ([]entity.Port, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, entity.ListPortsOpts{NetworkId: networkId})
+	if err != nil {
+		return nil, err
+	}
 	var ports entity.Ports
 	_ = json.Unmarshal(resp, &ports)
 
 	log.Println("==============List ports success", networkId)
-	return ports.Ps
+	return ports.Ps, nil
 }
 
 func (n *Neutron) DeleteNetwork(ipId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"network_id": ipId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("networks/%s", ipId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteNetworks() {
-	networks := n.ListNetworks()
+func (n *Neutron) DeleteNetworks() error {
+	networks, err := n.ListNetworks(entity.ListNetworksOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.NETWORK, len(networks.Nets))
 
 	for _, network := range networks.Nets {
+		if !n.selects(network) {
+			continue
+		}
 		tempNetwork := network
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteNetwork(tempNetwork.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Networks were deleted completely")
+	return nil
 }
 
 // GetExtNet get the first ext net
-func (n *Neutron) GetExtNet() string {
-	resp := n.DecorateGetResp(n.Get)(n.Headers, "networks?router%3Aexternal=True")
-	v, _ := resp["networks"]
-	networks := v.([]interface{})
-	if len(networks) == 0 {
+func (n *Neutron) GetExtNet() (string, error) {
+	external := true
+	networks, err := n.ListNetworks(entity.ListNetworksOpts{RouterExternal: &external})
+	if err != nil {
+		return "", err
+	}
+	if len(networks.Nets) == 0 {
 		log.Println("not ext net to use")
-		return ""
+		return "", nil
 	}
-	firstNet := networks[0].(map[string]interface{})
-	return firstNet["id"].(string)
+	return networks.Nets[0].Id, nil
 }
 
 // subnet
 
-func (n *Neutron) CreateSubnet(opts *entity.CreateSubnetOpts) string {
+func (n *Neutron) CreateSubnet(opts *entity.CreateSubnetOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.SUBNET)
 	//rand.Seed(time.Now().UnixNano())
 	//randomNum := rand.Intn(200)
 	//opts.CIDR = fmt.Sprintf("192.%d.%d.0/24", randomNum, randomNum)
 	//opts.IPVersion = 4
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, consts.SUBNETS, reqBody)
+	resp, err := n.Post(n.Headers, consts.SUBNETS, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var subnet entity.SubnetMap
 	_ = json.Unmarshal(resp, &subnet)
 	log.Println("==============Create subnet success", subnet.Subnet.Id)
-	return subnet.Subnet.Id
+	return subnet.Subnet.Id, nil
 }
 
-func (n *Neutron) UpdateSubnet(subnetId, updateBody string) {
+func (n *Neutron) UpdateSubnet(subnetId, updateBody string) error {
 	urlSuffix := fmt.Sprintf("subnets/%s", subnetId)
-	_ = n.Put(n.Headers, urlSuffix, updateBody)
+	_, err := n.Put(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("==============update subnet %s success\n", subnetId)
+	return nil
 }
 
-func (n *Neutron) UpdateSubnetHostRoutes (subnetId string) {
+func (n *Neutron) UpdateSubnetHostRoutes (subnetId string) error {
    updateBody := fmt.Sprintf("{\"subnet\": {\"host_routes\": [{\"destination\": \"192.168.20.0/24\", \"nexthop\": \"192.168.10.1\"}]}}")
-   n.UpdateSubnet(subnetId, updateBody)
+   return n.UpdateSubnet(subnetId, updateBody)
 }
 
-func (n *Neutron) UpdateSubnetDnsNameservers (subnetId string) {
+func (n *Neutron) UpdateSubnetDnsNameservers (subnetId string) error {
 	updateBody := fmt.Sprintf("{\"subnet\": {\"dns_nameservers \": [\"192.168.20.0/24\"]}}")
-	n.UpdateSubnet(subnetId, updateBody)
+	return n.UpdateSubnet(subnetId, updateBody)
 }
 
-func (n *Neutron) GetSubnet(subnetId string) entity.SubnetMap {
+func (n *Neutron) GetSubnet(subnetId string) (entity.SubnetMap, error) {
 	urlSuffix := fmt.Sprintf("subnets/%s", subnetId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.SubnetMap{}, err
+	}
 	var subnet entity.SubnetMap
 	_ = json.Unmarshal(resp, &subnet)
 	log.Printf("==============Get subnet success %+v", subnet)
-	return subnet
+	return subnet, nil
 }
 
-func (n *Neutron) ListSubnet() entity.Subnets {
-	var urlSuffix string
-	if n.isAdmin {
-		urlSuffix = consts.SUBNETS
-	} else {
-		urlSuffix = fmt.Sprintf("subnets?project_id=%s", n.projectId)
+func (n *Neutron) ListSubnet(opts entity.ListSubnetsOpts) (entity.Subnets, error) {
+	resp, err := n.ListBy(n.Headers, consts.SUBNETS, opts)
+	if err != nil {
+		return entity.Subnets{}, err
 	}
-	resp := n.List(n.Headers, urlSuffix)
 	var subnets entity.Subnets
 	_ = json.Unmarshal(resp, &subnets)
 	log.Println("==============List subnet success")
-	return subnets
+	return subnets, nil
 }
 
-func (n *Neutron) getSubnetCidr(subnetId string) string {
-	subnet := n.GetSubnet(subnetId)
+func (n *Neutron) getSubnetCidr(subnetId string) (string, error) {
+	subnet, err := n.GetSubnet(subnetId)
+	if err != nil {
+		return "", err
+	}
 	cidr := subnet.Subnet.Cidr
-	return cidr
+	return cidr, nil
 }
 
 func (n *Neutron) DeleteSubnet(ipId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"subnet_id": ipId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("%s/%s", consts.SUBNETS, ipId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteSubnets() {
-	subnets := n.ListSubnet()
+func (n *Neutron) DeleteSubnets() error {
+	subnets, err := n.ListSubnet(entity.ListSubnetsOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.SUBNET, len(subnets.Ss))
 
 	for _, subnet := range subnets.Ss {
+		if !n.selects(subnet) {
+			continue
+		}
 		tempSubnet := subnet
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteSubnet(tempSubnet.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Subnets were deleted completely")
+	return nil
 }
 
 //port
 
-func (n *Neutron) CreatePort(opts *entity.CreatePortOpts) string {
+func (n *Neutron) CreatePort(opts *entity.CreatePortOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.PORT)
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, consts.PORTS, reqBody)
+	resp, err := n.Post(n.Headers, consts.PORTS, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var port entity.PortMap
 	_ = json.Unmarshal(resp, &port)
 	log.Println("==============Create port success", port.Port.Id)
-	return port.Port.Id
+	return port.Port.Id, nil
 }
 
-func (n *Neutron) updatePort(portId string, reqBody string) {
+func (n *Neutron) updatePort(portId string, reqBody string) error {
 	urlSuffix := fmt.Sprintf("ports/%s", portId)
-	n.Put(n.Headers, urlSuffix, reqBody)
+	if _, err := n.Put(n.Headers, urlSuffix, reqBody); err != nil {
+		return err
+	}
 	log.Println("==============Update port success", portId)
+	return nil
 }
 
-func (n *Neutron) UpdatePortWithSg(portId, sgId string)  {
+func (n *Neutron) UpdatePortWithSg(portId, sgId string) error {
 	reqBody := fmt.Sprintf("{\"port\": {\"security_groups\": [\"%+v\"]}}", sgId)
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) UpdatePortWithAllowedAddressPairs(portId, ipaddress, mac string)  {
+func (n *Neutron) UpdatePortWithAllowedAddressPairs(portId, ipaddress, mac string) error {
 	reqBody := fmt.Sprintf("{\"port\": {\"allowed_address_pairs\": [{\"ip_address\": \"%+v\", \"mac_address\": \"%+v\"]}}", ipaddress, mac)
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) UpdatePortWithMac(portId, mac string)  {
+func (n *Neutron) UpdatePortWithMac(portId, mac string) error {
 	reqBody := fmt.Sprintf("{\"port\": {\"mac_address\": \"%+v\"}}", mac)
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) UpdatePortWithMacNone(portId string)  {
+func (n *Neutron) UpdatePortWithMacNone(portId string) error {
 	reqBody := `{"port": {"mac_address": null}}`
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) UpdatePortWithQos(portId, qosPolicyId string) {
+func (n *Neutron) UpdatePortWithQos(portId, qosPolicyId string) error {
 	reqBody := fmt.Sprintf("{\"port\": {\"qos_policy_id\": \"%+v\"}}", qosPolicyId)
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) UpdatePortWithNoQos(portId string) {
+func (n *Neutron) UpdatePortWithNoQos(portId string) error {
 	reqBody := fmt.Sprintf("{\"port\": {\"qos_policy_id\": null}}")
-	n.updatePort(portId, reqBody)
+	return n.updatePort(portId, reqBody)
 }
 
-func (n *Neutron) GetPort(portId string) entity.PortMap {
+func (n *Neutron) GetPort(portId string) (entity.PortMap, error) {
 	urlSuffix := fmt.Sprintf("ports/%s", portId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.PortMap{}, err
+	}
 	var port entity.PortMap
 	_ = json.Unmarshal(resp, &port)
 	log.Println("==============Get port success", portId)
-	return port
+	return port, nil
 }
 
-func (n *Neutron) GetPortIP(portId string) string {
+func (n *Neutron) GetPortIP(portId string) (string, error) {
 	urlSuffix := fmt.Sprintf("ports/%s", portId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return "", err
+	}
 	var port entity.PortMap
 	_ = json.Unmarshal(resp, &port)
 	log.Println("==============Get port success", portId)
-	return port.FixedIps[0].IpAddress
+	return port.FixedIps[0].IpAddress, nil
 }
 
-func (n *Neutron) ListPort() entity.Ports {
-	var urlSuffix string
-	if n.isAdmin {
-		urlSuffix = consts.PORTS
-	} else {
-		urlSuffix = fmt.Sprintf("ports?project_id=%s", n.projectId)
+func (n *Neutron) ListPort(opts entity.ListPortsOpts) (entity.Ports, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, opts)
+	if err != nil {
+		return entity.Ports{}, err
 	}
-
-	resp := n.Get(n.Headers, urlSuffix)
 	var ports entity.Ports
 	_ = json.Unmarshal(resp, &ports)
 	log.Println("==============List port success")
-	return ports
+	return ports, nil
 }
 
-func (n *Neutron) GetPortByDevice(deviceId, deviceOwner string) *entity.Port {
-	urlSuffix := fmt.Sprintf("ports?device_id=%s&device_owner=%s", deviceId, deviceOwner)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) GetPortByDevice(deviceId, deviceOwner string) (*entity.Port, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, entity.ListPortsOpts{DeviceId: deviceId, DeviceOwner: deviceOwner})
+	if err != nil {
+		return nil, err
+	}
 	var ports entity.Ports
 	_ = json.Unmarshal(resp, &ports)
 	if len(ports.Ps) == 0 {
-		return nil
+		return nil, nil
 	} else {
-		return &(ports.Ps[0])
+		return &(ports.Ps[0]), nil
 	}
 }
 
 func (n *Neutron) DeletePort(ipId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"port_id": ipId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("%s/%s", consts.PORTS, ipId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeletePorts() {
-	ports := n.ListPort()
+func (n *Neutron) DeletePorts() error {
+	ports, err := n.ListPort(entity.ListPortsOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.PORT, len(ports.Ps))
 
 	for _, port := range ports.Ps {
+		if !n.selects(port) {
+			continue
+		}
 		tempPort := port
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeletePort(tempPort.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Ports were deleted completely")
+	return nil
 }
 
 // router
 
-func (n *Neutron) CreateRouter(opts *entity.CreateRouterOpts) string {
+func (n *Neutron) CreateRouter(opts *entity.CreateRouterOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.ROUTER)
 	PostBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, consts.ROUTERS, PostBody)
+	resp, err := n.Post(n.Headers, consts.ROUTERS, PostBody)
+	if err != nil {
+		return "", err
+	}
 	var router entity.RouterMap
 	_ = json.Unmarshal(resp, &router)
 
 	//cache.RedisClient.SetMap(n.tag + consts.ROUTERS, router.Router.Id, router)
 	log.Println("==============Create router success", router.Router.Id)
-	return router.Router.Id
+	return router.Router.Id, nil
 }
 
-func (n *Neutron) UpdateRouter(routerId string, opts *entity.UpdateRouterOpts) string {
+func (n *Neutron) UpdateRouter(routerId string, opts *entity.UpdateRouterOpts) (string, error) {
 	PostBody := opts.ToRequestBody()
 	urlSuffix := fmt.Sprintf("routers/%s", routerId)
-	resp := n.Put(n.Headers, urlSuffix, PostBody)
+	resp, err := n.Put(n.Headers, urlSuffix, PostBody)
+	if err != nil {
+		return "", err
+	}
 	var router entity.RouterMap
 	_ = json.Unmarshal(resp, &router)
 
 	//cache.RedisClient.SetMap(n.tag + consts.ROUTERS, router.Router.Id, router)
 	log.Println("==============Update router success resp", router.Router.Id)
-	return router.Router.Id
+	return router.Router.Id, nil
 }
 
-func (n *Neutron) AddRouterInterface(opts *entity.AddRouterInterfaceOpts) string {
+func (n *Neutron) AddRouterInterface(opts *entity.AddRouterInterfaceOpts) (string, error) {
 	routerId, body := opts.ToRequestBody()
 	urlSuffix := fmt.Sprintf("routers/%s/add_router_interface", routerId)
-	n.Put(n.Headers, urlSuffix, body)
+	if _, err := n.Put(n.Headers, urlSuffix, body); err != nil {
+		return "", err
+	}
 
 	log.Println("==============Add router interface success")
-	return routerId
+	return routerId, nil
 }
 
 func (n *Neutron) RemoveRouterInterface(routerId, subnetId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"router_id": routerId, "subnetId": subnetId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-			log.Println("==============Remove router interface failed")
-		} else {
-			log.Println("==============Remove router interface success")
-		}
-	}()
 	body := fmt.Sprintf("{\"subnet_id\": \"%+v\"}", subnetId)
 	urlSuffix := fmt.Sprintf("routers/%s/remove_router_interface", routerId)
-	outputObj.Response = string(n.Put(n.Headers, urlSuffix, body))
-	outputObj.Success = true
+	resp, err := n.Put(n.Headers, urlSuffix, body)
+	outputObj.setErr(err)
+	if err != nil {
+		log.Println("==============Remove router interface failed")
+		return outputObj
+	}
+	outputObj.Response = string(resp)
+	log.Println("==============Remove router interface success")
 	return outputObj
 }
 
-func (n *Neutron) DeleteRouterInterfaces() {
-	interfacePorts := n.listRouterInterfacePorts()
+func (n *Neutron) DeleteRouterInterfaces() error {
+	interfacePorts, err := n.listRouterInterfacePorts()
+	if err != nil {
+		return err
+	}
+	selected, err := n.selectedRouters()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.ROUTERINTERFACE, len(interfacePorts.Ps))
 
 	for _, port := range interfacePorts.Ps {
+		if selected != nil && !selected[port.DeviceId] {
+			continue
+		}
 		routerId := port.DeviceId
 		fixedIps := port.FixedIps
 		for _, fixedIp := range fixedIps {
 			tempFixedIp := fixedIp
+			wg.Add(1)
 			go func() {
+				defer wg.Done()
 				ch <- n.RemoveRouterInterface(routerId, tempFixedIp.SubnetId)
 			}()
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Router interfaces were deleted completely")
+	return nil
 }
 
-func (n *Neutron) ListRouters() entity.Routers {
-	var urlSuffix string
-	if n.isAdmin {
-		urlSuffix = consts.ROUTERS
-	} else {
-		urlSuffix = fmt.Sprintf("routers?project_id=%s", n.projectId)
+func (n *Neutron) ListRouters(opts entity.ListRoutersOpts) (entity.Routers, error) {
+	resp, err := n.ListBy(n.Headers, consts.ROUTERS, opts)
+	if err != nil {
+		return entity.Routers{}, err
 	}
-	resp := n.List(n.Headers, urlSuffix)
 	var routers entity.Routers
 	_ = json.Unmarshal(resp, &routers)
 	log.Println("==============List routers success, there had", routers.Count)
-	return routers
+	return routers, nil
 }
 
-func (n *Neutron) listRouterInterfacePorts() entity.Ports {
-	urlSuffix := fmt.Sprintf("ports?device_owner=network:router_interface&project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+// selectedRouters returns the ids of the routers the filter selects, nil
+// without filter. The interfaces of a router follow it, they have no name.
+func (n *Neutron) selectedRouters() (map[string]bool, error) {
+	if n.Filter == nil {
+		return nil, nil
+	}
+	routers, err := n.ListRouters(entity.ListRoutersOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(routers.Rs))
+	for _, router := range routers.Rs {
+		selected[router.Id] = n.selects(router)
+	}
+	return selected, nil
+}
+
+func (n *Neutron) listRouterInterfacePorts() (entity.Ports, error) {
+	opts := entity.ListPortsOpts{ListOpts: entity.ListOpts{ProjectId: n.projectId}, DeviceOwner: "network:router_interface"}
+	resp, err := n.ListBy(n.Headers, consts.PORTS, opts)
+	if err != nil {
+		return entity.Ports{}, err
+	}
 	var ports entity.Ports
 	_ = json.Unmarshal(resp, &ports)
 	log.Println("==============List router interface port success, there had", ports.Count)
-	return ports
+	return ports, nil
 }
 
 func (n *Neutron) updateRouterNoRoutes(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"router_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	opts := &entity.UpdateRouterOpts{Routes: new([]entity.Route)}
-	n.UpdateRouter(id, opts)
-	outputObj.Response = ""
-	outputObj.Success = true
+	_, err := n.UpdateRouter(id, opts)
+	outputObj.setErr(err)
+	if err == nil {
+		outputObj.Response = ""
+	}
 	return outputObj
 }
 
-func (n *Neutron) DeleteRouterRoutes() {
-	routers := n.ListRouters()
+func (n *Neutron) DeleteRouterRoutes() error {
+	routers, err := n.ListRouters(entity.ListRoutersOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
 	length := 0
 	for _, router := range routers.Rs {
 		if len(router.Routes) != 0 {
@@ -531,141 +603,173 @@ func (n *Neutron) DeleteRouterRoutes() {
 		}
 
 	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.ROUTERROUTE, length)
 
 	for _, router := range routers.Rs {
+		if !n.selects(router) {
+			continue
+		}
 		if len(router.Routes) != 0 {
+			tempRouter := router
+			wg.Add(1)
 			go func() {
-				ch <- n.updateRouterNoRoutes(router.Id)
+				defer wg.Done()
+				ch <- n.updateRouterNoRoutes(tempRouter.Id)
 			}()
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Router routes were deleted completely")
+	return nil
 }
 
 func (n *Neutron) DeleteRouter(routerId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"router_id": routerId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-			log.Println("==============Delete router failed", routerId)
-		} else {
-			log.Println("==============Delete router success", routerId)
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("%s/%s", consts.ROUTERS, routerId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	if outputObj.Success {
+		log.Println("==============Delete router success", routerId)
+	} else {
+		log.Println("==============Delete router failed", routerId)
+	}
 	return outputObj
 }
 
-func (n *Neutron) DeleteRouters() {
-	routers := n.ListRouters()
+func (n *Neutron) DeleteRouters() error {
+	routers, err := n.ListRouters(entity.ListRoutersOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.ROUTER, len(routers.Rs))
 	for _, router := range routers.Rs {
+		if !n.selects(router) {
+			continue
+		}
 		tempRouter := router
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteRouter(tempRouter.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Routers were deleted completely")
+	return nil
 }
 
 func (n *Neutron) ClearRouterGateway(routerId, extNetId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"router_id": routerId, "ext_net_id": extNetId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
 	urlSuffix := fmt.Sprintf("routers/%s", routerId)
 	reqBody := `{"router": {"external_gateway_info" : {}}}`
-    n.Put(n.Headers, urlSuffix, reqBody)
-    outputObj.Success = true
-    outputObj.Response = ""
+	_, err := n.Put(n.Headers, urlSuffix, reqBody)
+	outputObj.setErr(err)
+	if err != nil {
+		return outputObj
+	}
+	outputObj.Response = ""
 	log.Println("==============Clear router gateway success, router", routerId)
-    return outputObj
+	return outputObj
 }
 
-func (n *Neutron) DeleteRouterGateways() {
-	routers := n.ListRouters()
+func (n *Neutron) DeleteRouterGateways() error {
+	routers, err := n.ListRouters(entity.ListRoutersOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
     var length int
 	for _, router := range routers.Rs {
 		if !reflect.DeepEqual(router.GatewayInfo, nil) {
             length++
 		}
 	}
+		var wg sync.WaitGroup
 		ch := n.MakeDeleteChannel(consts.ROUTERGATEWAY, length)
 	for _, router := range routers.Rs {
+		if !n.selects(router) {
+			continue
+		}
 		if !reflect.DeepEqual(router.GatewayInfo, nil) {
+			tempRouter := router
+			wg.Add(1)
 			go func() {
-				ch <- n.ClearRouterGateway(router.Id, router.GatewayInfo.NetworkID)
+				defer wg.Done()
+				ch <- n.ClearRouterGateway(tempRouter.Id, tempRouter.GatewayInfo.NetworkID)
 			}()
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Router gateways were deleted completely")
+	return nil
 }
 
-func (n *Neutron) RemoveRouterInterfaceByPort(routerId, portId string) string {
+func (n *Neutron) RemoveRouterInterfaceByPort(routerId, portId string) (string, error) {
 	body := fmt.Sprintf("{\"port_id\": \"%+v\"}", portId)
 	urlSuffix := fmt.Sprintf("routers/%s/remove_router_interface", routerId)
-	n.Put(n.Headers, urlSuffix, body)
+	if _, err := n.Put(n.Headers, urlSuffix, body); err != nil {
+		return "", err
+	}
 	log.Println("==============Remove router interface success")
-	return routerId
+	return routerId, nil
 }
 
-func (n *Neutron) addExternalGateway(routerId, extNetId string) map[string]interface{} {
+func (n *Neutron) addExternalGateway(routerId, extNetId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("routers/%s", routerId)
 	reqBody := fmt.Sprintf("{\"router\" : {\"external_gateway_info\": {\"network_id\" : \"%+v\"}}}", extNetId)
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	router := resp["router"].(map[string]interface{})
 	log.Println("==============Set router gateway success")
-    return router
+    return router, nil
 }
 
-func (n *Neutron) getRouterPorts(routerId string) []interface{} {
-	urlSuffix := fmt.Sprintf("ports?device_id=%s", routerId)
-	resp := n.DecorateGetResp(n.List)(n.Headers, urlSuffix)
-	routerPorts := resp["ports"].([]interface{})
-	return routerPorts
+func (n *Neutron) getRouterPorts(routerId string) ([]interface{}, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, entity.ListPortsOpts{DeviceId: routerId})
+	if err != nil {
+		return nil, err
+	}
+	routerPorts := n.HandleRespBody(resp)["ports"].([]interface{})
+	return routerPorts, nil
 }
 
-func (n *Neutron) disassociateAnyPort(routerId string) {
-    routerPorts := n.getRouterPorts(routerId)
-    for _, port := range routerPorts {
-    	n.RemoveRouterInterfaceByPort(routerId, port.(map[string]interface{})["id"].(string))
+func (n *Neutron) disassociateAnyPort(routerId string) error {
+	routerPorts, err := n.getRouterPorts(routerId)
+	if err != nil {
+		return err
+	}
+	for _, port := range routerPorts {
+		if _, err := n.RemoveRouterInterfaceByPort(routerId, port.(map[string]interface{})["id"].(string)); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (n *Neutron) GetRouter(routerId string) entity.RouterMap {
+func (n *Neutron) GetRouter(routerId string) (entity.RouterMap, error) {
 	urlSuffix := fmt.Sprintf("routers/%s", routerId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.RouterMap{}, err
+	}
 	var router entity.RouterMap
 	_ = json.Unmarshal(resp, &router)
 	log.Println("==============Get router success", routerId)
-	return router
+	return router, nil
 }
 
-func (n *Neutron) getRouterExternalIps(routerId string) []string {
+func (n *Neutron) getRouterExternalIps(routerId string) ([]string, error) {
 	var router entity.RouterMap
 	if cache.RedisClient.Exist(routerId) {
 		val := cache.RedisClient.GetVal(routerId)
 		_ = json.Unmarshal([]byte(val), &router)
 	} else {
-		router = n.GetRouter(routerId)
+		var err error
+		if router, err = n.GetRouter(routerId); err != nil {
+			return nil, err
+		}
 	}
 	var fixedIps = make([]string, 0)
 	if len(router.Router.GatewayInfo.ExternalFixedIPs) != 0 {
@@ -673,21 +777,24 @@ func (n *Neutron) getRouterExternalIps(routerId string) []string {
 		 	fixedIps = append(fixedIps, fixedip.IPAddress)
 		 }
 	}
-	return fixedIps
+	return fixedIps, nil
 }
 
 // floating ip
 
-func (n *Neutron) CreateFloatingIP(opts *entity.CreateFipOpts) string {
+func (n *Neutron) CreateFloatingIP(opts *entity.CreateFipOpts) (string, error) {
     urlSuffix := consts.FLOATINGIPS
     createBody := opts.ToRequestBody()
-    resp := n.Post(n.Headers, urlSuffix, createBody)
+	resp, err := n.Post(n.Headers, urlSuffix, createBody)
+	if err != nil {
+		return "", err
+	}
     var fip entity.FipMap
     _ = json.Unmarshal(resp, &fip)
 	floatingIpId := fip.Floatingip.Id
 	//cache.RedisClient.SetMap(n.tag + consts.FLOATINGIPS, floatingIpId, fip)
 	log.Printf("==============Create FIP success %+v\n", fip)
-	return floatingIpId
+	return floatingIpId, nil
 }
 
 //func (n *Neutron) CreateFIPExtNet(extNetId string) {
@@ -701,196 +808,230 @@ func (n *Neutron) CreateFloatingIP(opts *entity.CreateFipOpts) string {
 //	return n.createFloatingIP(createBody)
 //}
 
-func (n *Neutron) UpdateFloatingIp(fipId, updateBody string) string {
+func (n *Neutron) UpdateFloatingIp(fipId, updateBody string) (string, error) {
 	urlSuffix := fmt.Sprintf("floatingips/%s", fipId)
-	resp := n.Put(n.Headers, urlSuffix, updateBody)
+	resp, err := n.Put(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return "", err
+	}
 	var fip entity.FipMap
 	_ = json.Unmarshal(resp, &fip)
 
 	log.Println("==============Update FIP success", fipId)
-	return fipId
+	return fipId, nil
 }
 
-func (n *Neutron) UpdateFloatingIpWithQos(fipId, qosId string) string {
+func (n *Neutron) UpdateFloatingIpWithQos(fipId, qosId string) (string, error) {
 	reqBody := fmt.Sprintf("{\"floatingip\": {\"qos_policy_id\": \"%+v\"}}", qosId)
 	return n.UpdateFloatingIp(fipId, reqBody)
 }
 
-func (n *Neutron) UpdateFloatingIpWithPort(fipId, portId string) string {
+func (n *Neutron) UpdateFloatingIpWithPort(fipId, portId string) (string, error) {
 	reqBody := fmt.Sprintf("{\"floatingip\": {\"port_id\": \"%+v\"}}", portId)
 	return n.UpdateFloatingIp(fipId, reqBody)
 }
 
-func (n *Neutron) UpdateFloatingIpWithPortIpAddress(fipId, portId, fixedIp string) string {
+func (n *Neutron) UpdateFloatingIpWithPortIpAddress(fipId, portId, fixedIp string) (string, error) {
 	reqBody := fmt.Sprintf("{\"floatingip\": {\"port_id\": \"%+v\", \"fixed_ip_address\": \"%+v\"}}", portId, fixedIp)
 	return n.UpdateFloatingIp(fipId, reqBody)
 }
 
-func (n *Neutron) FloatingIpDisassociatePort(fipId string) string {
+func (n *Neutron) FloatingIpDisassociatePort(fipId string) (string, error) {
 	reqBody := fmt.Sprintf("{\"floatingip\": {\"port_id\": null}}")
 	return n.UpdateFloatingIp(fipId, reqBody)
 }
 
-func (n *Neutron) GetFIP(fipId string) entity.FipMap {
+func (n *Neutron) GetFIP(fipId string) (entity.FipMap, error) {
 	urlSuffix := fmt.Sprintf("floatingips/%s", fipId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.FipMap{}, err
+	}
 	var fip entity.FipMap
 	_ = json.Unmarshal(resp, &fip)
 	log.Println("==============Get fip success", fipId)
-	return fip
+	return fip, nil
 }
 
-func (n *Neutron) ListFIPs() entity.Fips {
-	var urlSuffix string
-	if n.isAdmin {
-		urlSuffix = consts.FLOATINGIPS
-	} else {
-		urlSuffix = fmt.Sprintf("floatingips?project_id=%s", n.projectId)
+func (n *Neutron) ListFIPs(opts entity.ListFipsOpts) (entity.Fips, error) {
+	resp, err := n.ListBy(n.Headers, consts.FLOATINGIPS, opts)
+	if err != nil {
+		return entity.Fips{}, err
 	}
-
-	resp := n.List(n.Headers, urlSuffix)
 	var fs entity.Fips
 	_ = json.Unmarshal(resp, &fs)
 	log.Println("==============List fip success, there had", fs.Count)
-	return fs
+	return fs, nil
 }
 
 func (n *Neutron) DeleteFIP(fipId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"floatingip_id": fipId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
 	urlSuffix := fmt.Sprintf("%s/%s", consts.FLOATINGIPS, fipId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteFloatingips() {
-	fips := n.ListFIPs()
+func (n *Neutron) DeleteFloatingips() error {
+	fips, err := n.ListFIPs(entity.ListFipsOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.FLOATINGIP, len(fips.Fs))
 
 	for _, fip := range fips.Fs {
+		if !n.selects(fip) {
+			continue
+		}
 		tempFip := fip
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteFIP(tempFip.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Floatingips were deleted completely")
+	return nil
 }
 
 // port forwarding
 
-func (n *Neutron) CreatePortForwarding(fipId string, opts *entity.CreatePortForwardingOpts) string {
+func (n *Neutron) CreatePortForwarding(fipId string, opts *entity.CreatePortForwardingOpts) (string, error) {
 	urlSuffix := fmt.Sprintf("%s/%s/%s", consts.FLOATINGIPS, fipId, consts.PORTFORWARDINGS)
 	createBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, createBody)
+	resp, err := n.Post(n.Headers, urlSuffix, createBody)
+	if err != nil {
+		return "", err
+	}
 	var pf entity.PortForwardingMap
 	_ = json.Unmarshal(resp, &pf)
 
 	log.Printf("==============Create port forwarding success %+v\n", pf)
-	return pf.PortForwarding.Id
+	return pf.PortForwarding.Id, nil
 }
 
-func (n *Neutron) GetPortForwarding(fipId string, pfId string) entity.PortForwardingMap {
+func (n *Neutron) GetPortForwarding(fipId string, pfId string) (entity.PortForwardingMap, error) {
 	urlSuffix := fmt.Sprintf("%s/%s/%s/%s", consts.FLOATINGIPS, fipId, consts.PORTFORWARDINGS, pfId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.PortForwardingMap{}, err
+	}
 	var pf entity.PortForwardingMap
 	_ = json.Unmarshal(resp, &pf)
 
 	log.Printf("==============Get port forwarding success %+v\n", pf)
-	return pf
+	return pf, nil
 }
 
-func (n *Neutron) ListPortForwarding(fipId string) entity.PortForwardings {
+func (n *Neutron) ListPortForwarding(fipId string) (entity.PortForwardings, error) {
 	urlSuffix := fmt.Sprintf("%s/%s/%s", consts.FLOATINGIPS, fipId, consts.PORTFORWARDINGS)
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.PortForwardings{}, err
+	}
 	var pfs entity.PortForwardings
 	_ = json.Unmarshal(resp, &pfs)
 
 	log.Printf("==============List port forwarding success %+v\n", pfs)
-	return pfs
+	return pfs, nil
 }
 
 func (n *Neutron) DeletePortForwarding(fipId string, pfId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"floatingip_id": fipId, "port_forwarding_id": pfId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
 	urlSuffix := fmt.Sprintf("%s/%s/%s/%s", consts.FLOATINGIPS, fipId, consts.PORTFORWARDINGS, pfId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeletePortForwardings() {
-	fips := n.ListFIPs()
+func (n *Neutron) DeletePortForwardings() error {
+	fips, err := n.ListFIPs(entity.ListFipsOpts{ListOpts: n.projectOpts()})
+	if err != nil {
+		return err
+	}
 	var pfsMap = make(map[string]entity.PortForwardings)
 	var length int
 	for _, fip := range fips.Fs {
-		tmpPfs := n.ListPortForwarding(fip.Id)
+		if !n.selects(fip) {
+			continue
+		}
+		tmpPfs, err := n.ListPortForwarding(fip.Id)
+		if err != nil {
+			return err
+		}
 		pfsMap[fip.Id] = tmpPfs
 		length += len(tmpPfs.Pfs)
 	}
 
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.PORTFORWARDING, length)
 	for fipId, pfs := range pfsMap {
+		tmpFipId := fipId
 		for _, pf := range pfs.Pfs {
 			tmpPf := pf
+			wg.Add(1)
 			go func() {
-				ch <- n.DeletePortForwarding(fipId, tmpPf.Id)
+				defer wg.Done()
+				ch <- n.DeletePortForwarding(tmpFipId, tmpPf.Id)
 			}()
 		}
 	}
 
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Port forwarding were deleted completely")
+	return nil
 }
 
 // qos policy
 
-func (n *Neutron) CreateQos() string {
-	urlSuffix := "qos/policies"
+func (n *Neutron) CreateQos() (string, error) {
 	name := "qos_policy_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "policy": {
-                          "name": "%+v", 
-                          "description": "This policy limits the ports to 10Mbit max.",
-                          "shared": false
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	return n.CreateQosPolicy(&entity.CreateQosPolicyOpts{Name: name, Description: "This policy limits the ports to 10Mbit max."})
+}
+
+func (n *Neutron) CreateQosPolicy(opts *entity.CreateQosPolicyOpts) (string, error) {
+	urlSuffix := "qos/policies"
+	reqBody := opts.ToRequestBody()
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var qos entity.QosPolicyMap
 	_ = json.Unmarshal(resp, &qos)
 	qosId := qos.Policy.Id
 
 	//cache.RedisClient.SetMap(n.tag + consts.QOS_POLICIES, qosId, qos)
 	log.Println("==============Create qos policy success", qosId)
-	return qosId
+	return qosId, nil
 }
 
-func (n *Neutron) GetQos(qosId string) entity.QosPolicyMap {
+func (n *Neutron) GetQos(qosId string) (entity.QosPolicyMap, error) {
 	urlSuffix := fmt.Sprintf("qos/policies/%s", qosId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.QosPolicyMap{}, err
+	}
 	var qos entity.QosPolicyMap
 	_ = json.Unmarshal(resp, &qos)
 	log.Println("==============Get qos policy resp", string(resp))
-	return qos
+	return qos, nil
+}
+
+// CreateQosRule creates a rule of ruleType, e.g. consts.BANDWIDTH_LIMIT_RULE,
+// in the qos policy qosId from the request body of its create opts.
+func (n *Neutron) CreateQosRule(qosId, ruleType, reqBody string) (string, error) {
+	urlSuffix := fmt.Sprintf("qos/policies/%s/%ss", qosId, ruleType)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
+	rule, _ := n.HandleRespBody(resp)[ruleType].(map[string]interface{})
+	ruleId, _ := rule["id"].(string)
+	log.Printf("==============Create %s success %s", ruleType, ruleId)
+	return ruleId, nil
 }
 
-func (n *Neutron) CreateBandwidthLimitRuleIngress(qosId string) {
+func (n *Neutron) CreateBandwidthLimitRuleIngress(qosId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/bandwidth_limit_rules", qosId)
 	formatter := `{
                       "bandwidth_limit_rule": {
@@ -899,12 +1040,15 @@ func (n *Neutron) CreateBandwidthLimitRuleIngress(qosId string) {
                       }
                    }`
 	reqBody := formatter
-	n.Post(n.Headers, urlSuffix, reqBody)
+	if _, err := n.Post(n.Headers, urlSuffix, reqBody); err != nil {
+		return err
+	}
 	log.Println("==============Create bandwidth_limit_rule success")
+	return nil
 }
 
 
-func (n *Neutron) CreateBandwidthLimitRuleEgress(qosId string) {
+func (n *Neutron) CreateBandwidthLimitRuleEgress(qosId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/bandwidth_limit_rules", qosId)
 	formatter := `{
                       "bandwidth_limit_rule": {
@@ -913,19 +1057,29 @@ func (n *Neutron) CreateBandwidthLimitRuleEgress(qosId string) {
                       }
                    }`
 	reqBody := formatter
-	n.Post(n.Headers, urlSuffix, reqBody)
+	if _, err := n.Post(n.Headers, urlSuffix, reqBody); err != nil {
+		return err
+	}
 	log.Println("==============Create bandwidth_limit_rule success")
+	return nil
 }
 
-func (n *Neutron) updateBandwidthLimitRule(qosId string, ruleId string) {
+func (n *Neutron) updateBandwidthLimitRule(qosId string, ruleId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/bandwidth_limit_rules/%s", qosId, ruleId)
 	reqBody := fmt.Sprintf("{\"bandwidth_limit_rule\": {\"max_kbps\": \"10304\"}}")
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println("==============Update bandwidth_limit_rule success", resp)
+	return nil
 }
 
-func (n *Neutron) DeleteBandwidthLimitRules() {
-	qoss := n.listQoss()
+func (n *Neutron) DeleteBandwidthLimitRules() error {
+	qoss, err := n.listQoss(n.projectOpts())
+	if err != nil {
+		return err
+	}
 	var length int
 	for _, qos := range qoss.Qps {
 		rules := qos.Rules
@@ -935,44 +1089,61 @@ func (n *Neutron) DeleteBandwidthLimitRules() {
 			}
 		}
 	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.BANDWIDTH_LIMIT_RULE, length)
 	for _, qos := range qoss.Qps {
+		if !n.selects(qos) {
+			continue
+		}
 		rules := qos.Rules
 		for _, rule := range rules {
 			if rule.Type == "bandwidth_limit" {
+				tempQos, tempRule := qos, rule
+				wg.Add(1)
 				go func() {
-					ch <- n.DeleteQosRule(rule.Type, qos.Id, rule.Id)
+					defer wg.Done()
+					ch <- n.DeleteQosRule(tempRule.Type, tempQos.Id, tempRule.Id)
 				}()
 			}
 		}
 	}
 
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Bandwidth limit rules were deleted completely")
+	return nil
 }
 
-func (n *Neutron) CreateDscpMarkingRule(qosId string) {
+func (n *Neutron) CreateDscpMarkingRule(qosId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/dscp_marking_rules", qosId)
 	reqBody := fmt.Sprintf("{\"dscp_marking_rule\": {\"dscp_mark\": \"32\"}}")
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println("==============Create dscp_marking_rule success", string(resp))
+	return nil
 }
 
-func (n *Neutron) updateDscpMarkingRule(qosId string, ruleId string) {
+func (n *Neutron) updateDscpMarkingRule(qosId string, ruleId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/dscp_marking_rules/%s", qosId, ruleId)
 	reqBody := fmt.Sprintf("{\"dscp_marking_rule\": {\"dscp_mark\": \"32\"}}")
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println("==============Update dscp_marking_rule success", resp)
 
 	//n.SyncMap(n.tag + consts.QOS_POLICIES, qosId, func(s string) interface{} {
 	//	return n.GetQos(qosId)
 	//}, nil)
+	return nil
 }
 
-func (n *Neutron) DeleteDscpMarkingRules() {
-	qoss := n.listQoss()
+func (n *Neutron) DeleteDscpMarkingRules() error {
+	qoss, err := n.listQoss(n.projectOpts())
+	if err != nil {
+		return err
+	}
 	var length int
 	for _, qos := range qoss.Qps {
 		rules := qos.Rules
@@ -982,47 +1153,64 @@ func (n *Neutron) DeleteDscpMarkingRules() {
 			}
 		}
 	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.DSCP_MARKING_RULE, length)
 	for _, qos := range qoss.Qps {
+		if !n.selects(qos) {
+			continue
+		}
 		rules := qos.Rules
 		for _, rule := range rules {
 			if rule.Type == "dscp_marking" {
+				tempQos, tempRule := qos, rule
+				wg.Add(1)
 				go func() {
-					ch <- n.DeleteQosRule(rule.Type, qos.Id, rule.Id)
+					defer wg.Done()
+					ch <- n.DeleteQosRule(tempRule.Type, tempQos.Id, tempRule.Id)
 				}()
 			}
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Dscp marking rules were deleted completely")
+	return nil
 }
 
-func (n *Neutron) CreateMinimumBandwidthRule(qosId string) {
+func (n *Neutron) CreateMinimumBandwidthRule(qosId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/minimum_bandwidth_rules", qosId)
 	reqBody := fmt.Sprintf("{\"minimum_bandwidth_rule\": {\"min_kbps\": \"500\", \"direction\": \"egress\"}}")
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println("==============Create minimum_bandwidth_rule success", string(resp))
 
 	//n.SyncMap(n.tag + consts.QOS_POLICIES, qosId, func(s string) interface{} {
 	//	return n.getQos(qosId)
 	//}, nil)
+	return nil
 }
 
-func (n *Neutron) updateMinimumBandwidthRule(qosId string, ruleId string) {
+func (n *Neutron) updateMinimumBandwidthRule(qosId string, ruleId string) error {
 	urlSuffix := fmt.Sprintf("qos/policies/%s/minimum_bandwidth_rules/%s", qosId, ruleId)
 	reqBody := fmt.Sprintf("{\"minimum_bandwidth_rule\": {\"min_kbps\": \"12003\"}}")
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println("==============Update minimum_bandwidth_rule success", resp)
 
 	//n.SyncMap(n.tag + consts.QOS_POLICIES, qosId, func(s string) interface{} {
 	//	return n.GetQos(qosId)
 	//}, nil)
+	return nil
 }
 
-func (n *Neutron) DeleteMinimumBandwidthRules() {
-	qoses := n.listQoss()
+func (n *Neutron) DeleteMinimumBandwidthRules() error {
+	qoses, err := n.listQoss(n.projectOpts())
+	if err != nil {
+		return err
+	}
 	var length int
 	for _, qos := range qoses.Qps {
 		rules := qos.Rules
@@ -1032,74 +1220,86 @@ func (n *Neutron) DeleteMinimumBandwidthRules() {
 			}
 		}
 	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.MINIMUM_BANDWIDTH_RULE, length)
 	for _, qos := range qoses.Qps {
+		if !n.selects(qos) {
+			continue
+		}
 		rules := qos.Rules
 		for _, rule := range rules {
 			if rule.Type == "minimum_bandwidth" {
+				tempQos, tempRule := qos, rule
+				wg.Add(1)
 				go func() {
-					ch <- n.DeleteQosRule(rule.Type, qos.Id, rule.Id)
+					defer wg.Done()
+					ch <- n.DeleteQosRule(tempRule.Type, tempQos.Id, tempRule.Id)
 				}()
 			}
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Minimum bandwidth rules were deleted completely")
+	return nil
 }
 
-func (n *Neutron) CreateQosAndRule() string {
-	qosId := n.CreateQos()
+func (n *Neutron) CreateQosAndRule() (string, error) {
+	qosId, err := n.CreateQos()
+	if err != nil {
+		return "", err
+	}
 
-	n.CreateBandwidthLimitRuleIngress(qosId)
-	n.CreateDscpMarkingRule(qosId)
-	n.CreateMinimumBandwidthRule(qosId)
-	return qosId
+	if err := n.CreateBandwidthLimitRuleIngress(qosId); err != nil {
+		return "", err
+	}
+	if err := n.CreateDscpMarkingRule(qosId); err != nil {
+		return "", err
+	}
+	if err := n.CreateMinimumBandwidthRule(qosId); err != nil {
+		return "", err
+	}
+	return qosId, nil
 }
 
-func (n *Neutron) listQoss() entity.QosPolicies {
-	var urlSuffix string
-	if n.isAdmin {
-		urlSuffix =	"qos/policies"
-	} else {
-		urlSuffix = fmt.Sprintf("ports?project_id=%s", n.projectId)
+func (n *Neutron) listQoss(opts entity.ListOpts) (entity.QosPolicies, error) {
+	resp, err := n.ListBy(n.Headers, "qos/policies", opts)
+	if err != nil {
+		return entity.QosPolicies{}, err
 	}
-	resp := n.List(n.Headers, urlSuffix)
 	var qos entity.QosPolicies
 	_ = json.Unmarshal(resp, &qos)
 	log.Println("==============List qos policy success, there had", qos.Count)
-	return qos
+	return qos, nil
 }
 
 func (n *Neutron) DeleteQos(qosId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"qos_policy_id": qosId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("qos/policies/%s", qosId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteQosPolicies() {
-	qoses := n.listQoss()
+func (n *Neutron) DeleteQosPolicies() error {
+	qoses, err := n.listQoss(n.projectOpts())
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.QOS_POLICY, len(qoses.Qps))
 	for _, qos := range qoses.Qps {
+		if !n.selects(qos) {
+			continue
+		}
 		tempQos := qos
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteQos(tempQos.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Qos policies were deleted completely")
+	return nil
 }
 
 func (n *Neutron) DeleteQosRule(ruleType, qosId, ruleId string) Output {
@@ -1113,77 +1313,82 @@ func (n *Neutron) DeleteQosRule(ruleType, qosId, ruleId string) Output {
 		identity = consts.MINIMUM_BANDWIDTH_RULES
 	}
 	outputObj := Output{ParametersMap: map[string]string{"qos_policy_id": qosId, "rule_id": ruleId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("qos/policies/%s/%s/%s", qosId, identity, ruleId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) GetInstancePort(instanceId string) (string, string) {
-	urlSuffix := fmt.Sprintf("ports?device_id=%s", instanceId)
-	resp := n.DecorateGetResp(n.List)(n.Headers, urlSuffix)
-	instancePorts := resp["ports"].([]interface{})
+func (n *Neutron) GetInstancePort(instanceId string) (string, string, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, entity.ListPortsOpts{DeviceId: instanceId})
+	if err != nil {
+		return "", "", err
+	}
+	instancePorts := n.HandleRespBody(resp)["ports"].([]interface{})
 	port := instancePorts[0].(map[string]interface{})
 	portId := port["id"].(string)
 	fixedIps := port["fixed_ips"].([]interface{})
 	fixedIp := fixedIps[0].(map[string]interface{})
 	ipAddr := fixedIp["ip_address"].(string)
-	return portId, ipAddr
+	return portId, ipAddr, nil
 }
 
-func (n *Neutron) GetFloatingipPort(fipId string) *entity.Port {
-	urlSuffix := fmt.Sprintf("ports?device_id=%s", fipId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) GetFloatingipPort(fipId string) (*entity.Port, error) {
+	resp, err := n.ListBy(n.Headers, consts.PORTS, entity.ListPortsOpts{DeviceId: fipId})
+	if err != nil {
+		return nil, err
+	}
 	var ports entity.Ports
 	_ = json.Unmarshal(resp, &ports)
 	log.Println("==============Get floatingip port success for fip=", fipId, string(resp))
 	if len(ports.Ps) != 0 {
 		fipPort := ports.Ps[0]
-		return &fipPort
+		return &fipPort, nil
 	}
-	return nil
+	return nil, nil
 }
 
 // firewall group v2
-func (n *Neutron) createFirewallGroup(ingressPolicy, egressPolicy string) map[string]interface{} {
+func (n *Neutron) createFirewallGroup(ingressPolicy, egressPolicy string) (map[string]interface{}, error) {
 	urlSuffix := "fwaas/firewall_groups"
 	name := "dx_fw_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
 	reqBody := fmt.Sprintf("{\"firewall_group\": {\"name\": \"%+v\", \"ingress_firewall_policy_id\": \"%+v\", \"egress_firewall_policy_id\": \"%+v\"}}", name, ingressPolicy, egressPolicy)
-	resp := n.DecorateResp(n.Post)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Post)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallGroup := resp["firewall_group"].(map[string]interface{})
 	firewallGroupId := firewallGroup["id"].(string)
 
 	//cache.RedisClient.AddSliceAndJson(firewallGroupId, n.tag + consts.FIREWALLGROUPS, firewallGroup)
 	log.Println("==============Create firewall group success", firewallGroupId)
-	return firewallGroup
+	return firewallGroup, nil
 }
 
-func (n *Neutron) updateFirewallGroup(firewallGroupId, updateBody string) map[string]interface{} {
+func (n *Neutron) updateFirewallGroup(firewallGroupId, updateBody string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_groups/%s", firewallGroupId)
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallGroup := resp["firewall_group"].(map[string]interface{})
 
 	n.SyncResource(firewallGroupId, nil, firewallGroup)
 	log.Println("==============update firewall group success", firewallGroupId)
-	return firewallGroup
+	return firewallGroup, nil
 }
 
-func (n *Neutron) updateFirewallGroupNoPortsNoPolicies(firewallGroupId string) map[string]interface{} {
+func (n *Neutron) updateFirewallGroupNoPortsNoPolicies(firewallGroupId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_groups/%s", firewallGroupId)
 	updateBody := `{"firewall_group": {"ports": [], "ingress_firewall_policy_id": null, "egress_firewall_policy_id": null}}`
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallGroup := resp["firewall_group"].(map[string]interface{})
 
 	n.SyncResource(firewallGroupId, nil, firewallGroup)
 	log.Println("==============update firewall group success", firewallGroupId)
-	return firewallGroup
+	return firewallGroup, nil
 }
 
 func (n *Neutron) constructUpdateWithPorts(ports []string) string {
@@ -1198,14 +1403,15 @@ func (n *Neutron) constructUpdateWithPolicy(ingressPolicy, egressPolicy string)
 	return reqBody
 }
 
-func (n *Neutron) deleteFirewallGroup(firewallGroupId string) {
+func (n *Neutron) deleteFirewallGroup(firewallGroupId string) error {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_groups/%s", firewallGroupId)
-	ok, _ := n.Delete(n.Headers, urlSuffix)
-	if ok {
-		//cache.RedisClient.DeleteKV(firewallGroupId)
-		//cache.RedisClient.RemoveFromSlice(n.tag+consts.FIREWALLGROUPS, firewallGroupId)
+	if err := n.Delete(n.Headers, urlSuffix); err != nil {
+		return err
 	}
+	//cache.RedisClient.DeleteKV(firewallGroupId)
+	//cache.RedisClient.RemoveFromSlice(n.tag+consts.FIREWALLGROUPS, firewallGroupId)
 	log.Println("==============Delete firewall group success", firewallGroupId)
+	return nil
 }
 
 //func (n *Neutron) deleteFirewallGroups()  {
@@ -1225,19 +1431,34 @@ func (n *Neutron) deleteFirewallGroup(firewallGroupId string) {
 //	}
 //}
 
-func (n *Neutron) CreateFwFpFr() string {
-	fr1 := n.createFirewallRule()
+func (n *Neutron) CreateFwFpFr() (string, error) {
+	fr1, err := n.createFirewallRule()
+	if err != nil {
+		return "", err
+	}
 	frId1 := fr1["id"].(string)
-	fr2 := n.createFirewallRule()
+	fr2, err := n.createFirewallRule()
+	if err != nil {
+		return "", err
+	}
 	frId2 := fr2["id"].(string)
 
-	ingresFp := n.createFirewallPolicy([]string{frId1})
+	ingresFp, err := n.createFirewallPolicy([]string{frId1})
+	if err != nil {
+		return "", err
+	}
 	ingressFpId := ingresFp["id"].(string)
-	egressFp := n.createFirewallPolicy([]string{frId2})
+	egressFp, err := n.createFirewallPolicy([]string{frId2})
+	if err != nil {
+		return "", err
+	}
 	egressFpId := egressFp["id"].(string)
-	fw := n.createFirewallGroup(ingressFpId, egressFpId)
+	fw, err := n.createFirewallGroup(ingressFpId, egressFpId)
+	if err != nil {
+		return "", err
+	}
 	fwId := fw["id"].(string)
-	return fwId
+	return fwId, nil
 }
 
 func (n *Neutron) DeleteFwFpFr() {
@@ -1247,43 +1468,53 @@ func (n *Neutron) DeleteFwFpFr() {
 }
 
 // firewall policy
-func (n *Neutron) createFirewallPolicy(firewallRules []string) map[string]interface{} {
+func (n *Neutron) createFirewallPolicy(firewallRules []string) (map[string]interface{}, error) {
 	urlSuffix := "fwaas/firewall_policies"
 	name := "dx_fwp_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
     en := entity.FirewallPolicy{Name: name, FirewallRules: firewallRules}
     fp := map[string]entity.FirewallPolicy{"firewall_policy": en}
 	reqBody, _ := json.Marshal(fp)
 
-	resp := n.DecorateResp(n.Post)(n.Headers, urlSuffix, string(reqBody))
+	resp, err := n.DecorateResp(n.Post)(n.Headers, urlSuffix, string(reqBody))
+	if err != nil {
+		return nil, err
+	}
 	firewallPolicy := resp["firewall_policy"].(map[string]interface{})
 	firewallPolicyId := firewallPolicy["id"].(string)
 
 	//cache.RedisClient.AddSliceAndJson(firewallPolicyId, n.tag + consts.FIREWALLPOLICIES, firewallPolicy)
 	log.Println("==============Create firewall policy success", firewallPolicyId)
-	return firewallPolicy
+	return firewallPolicy, nil
 }
 
-func (n *Neutron) updateFirewallPolicyNoRules(firewallPolicyId string) map[string]interface{} {
+func (n *Neutron) updateFirewallPolicyNoRules(firewallPolicyId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_policies/%s", firewallPolicyId)
 	reqBody := `{"firewall_policy": {"firewall_rules": []}}`
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallPolicy := resp["firewall_policy"].(map[string]interface{})
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============update firewall policy no rule success", firewallPolicyId)
-	return firewallPolicy
+	return firewallPolicy, nil
 }
 
-func (n *Neutron) deleteFirewallPolicy(firewallPolicyId string) {
-	n.updateFirewallPolicyNoRules(firewallPolicyId)
+func (n *Neutron) deleteFirewallPolicy(firewallPolicyId string) error {
+	if _, err := n.updateFirewallPolicyNoRules(firewallPolicyId); err != nil {
+		return err
+	}
 	urlSuffix := fmt.Sprintf("fwaas/firewall_policies/%s", firewallPolicyId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
+	err := n.Delete(n.Headers, urlSuffix)
+	if err == nil {
 		cache.RedisClient.DeleteKV(firewallPolicyId)
 		//cache.RedisClient.RemoveFromSlice(n.tag + consts.FIREWALLPOLICIES, firewallPolicyId)
 		log.Println("==============Delete firewall policy success", firewallPolicyId)
 	} else {
 		log.Println("==============Delete firewall policy failed", firewallPolicyId)
 	}
+	return err
 }
 
 //func (n *Neutron) deleteFirewallPolicies()  {
@@ -1294,49 +1525,62 @@ func (n *Neutron) deleteFirewallPolicy(firewallPolicyId string) {
 //}
 
 // firewall rule
-func (n *Neutron) createFirewallRule() map[string]interface{} {
+func (n *Neutron) createFirewallRule() (map[string]interface{}, error) {
 	urlSuffix := "fwaas/firewall_rules"
 	name := "dx_fwr_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
 	action := "allow"
 	destinationPort := "80"
 	protocol := "tcp"
 	reqBody := fmt.Sprintf("{\"firewall_rule\": {\"name\": \"%+v\", \"action\": \"%+v\", \"destination_port\": \"%+v\", \"protocol\": \"%+v\"}}", name, action, destinationPort, protocol)
-	resp := n.DecorateResp(n.Post)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Post)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallRule := resp["firewall_rule"].(map[string]interface{})
 	firewallRuleId := firewallRule["id"].(string)
 
 	//cache.RedisClient.AddSliceAndJson(firewallRuleId, n.tag + consts.FIREWALLRULES, firewallRule)
 	log.Println("==============Create firewall rule success", firewallRuleId)
-	return firewallRule
+	return firewallRule, nil
 }
 
-func (n *Neutron) insertRule(firewallPolicyId, firewallRuleId string) {
+func (n *Neutron) insertRule(firewallPolicyId, firewallRuleId string) error {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_policies/%s/insert_rule", firewallPolicyId)
     reqBody := fmt.Sprintf("{\"firewall_rule_id\": \"%+v\", \"insert_after\": \"\", \"insert_before\": \"\"}", firewallRuleId)
-    firewallPolicy := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	firewallPolicy, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 
     n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============insert firewall rule success", firewallRuleId)
+	return nil
 }
 
-func (n *Neutron) removeRule(firewallPolicyId, firewallRuleId string) {
+func (n *Neutron) removeRule(firewallPolicyId, firewallRuleId string) error {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_policies/%s/remove_rule", firewallPolicyId)
 	reqBody := fmt.Sprintf("{\"firewall_rule_id\": \"%+v\"}", firewallRuleId)
-	firewallPolicy := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	firewallPolicy, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============remove firewall rule success", firewallRuleId)
+	return nil
 }
 
-func (n *Neutron) deleteFirewallRule(firewallRuleId string) {
+func (n *Neutron) deleteFirewallRule(firewallRuleId string) error {
 	urlSuffix := fmt.Sprintf("fwaas/firewall_rules/%s", firewallRuleId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
+	err := n.Delete(n.Headers, urlSuffix)
+	if err == nil {
 		cache.RedisClient.DeleteKV(firewallRuleId)
 		//cache.RedisClient.RemoveFromSlice(n.tag + consts.FIREWALLRULES, firewallRuleId)
 		log.Println("==============Delete firewall rule success", firewallRuleId)
 	} else {
 		log.Println("==============Delete firewall rule failed", firewallRuleId)
 	}
+	return err
 }
 
 //func (n *Neutron) deleteFirewallRules() {
@@ -1348,249 +1592,289 @@ func (n *Neutron) deleteFirewallRule(firewallRuleId string) {
 
 // firewall group v1   ***********************************************
 
-func (n *Neutron) CreateFirewallV1(opts *entity.CreateFirewallOpts) string {
+func (n *Neutron) CreateFirewallV1(opts *entity.CreateFirewallOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.FIREWALL)
 	urlSuffix := "fw/firewalls"
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var firewall entity.FirewallV1Map
 	_ = json.Unmarshal(resp, &firewall)
 
-	n.ensureFirewallActive(firewall.Id)
+	if err := n.ensureFirewallActive(firewall.Id); err != nil {
+		return "", err
+	}
 	log.Println("==============Create firewall success", firewall.Firewall.Id)
-	return firewall.Firewall.Id
+	return firewall.Firewall.Id, nil
 }
 
-func (n *Neutron) checkFirewallStatus(firewallId, status string) bool {
-	firewall := n.GetFirewall(firewallId)
+func (n *Neutron) checkFirewallStatus(firewallId, status string) (bool, error) {
+	firewall, err := n.GetFirewall(firewallId)
+	if err != nil {
+		return false, err
+	}
 	if firewall.Status == status {
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
 }
 
-func (n *Neutron) ensureFirewallActive(firewallId string) {
-	ctx, cancel := context.WithTimeout(context.Background(), consts.Timeout)
-	defer cancel()
-
-	for {
-		select {
-		case <- ctx.Done():
-			log.Println("*******************Create firewall timeout")
-            return
-		default:
-			if n.checkFirewallStatus(firewallId, consts.ACTIVE) {
-				log.Println("*******************Create firewall success")
-				return
-			}
-			time.Sleep(5 * time.Second)
-		}
+func (n *Neutron) ensureFirewallActive(firewallId string) error {
+	err := Poll(n.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		return n.checkFirewallStatus(firewallId, consts.ACTIVE)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Println("*******************Create firewall timeout")
+		return fmt.Errorf("firewall %s did not become %s within %s: %w", firewallId, consts.ACTIVE, consts.Timeout, err)
+	}
+	if err != nil {
+		return err
 	}
+	log.Println("*******************Create firewall success")
+	return nil
 }
 
-func (n *Neutron) UpdateFirewallV1(firewallId string, opts *entity.UpdateFirewallOpts) entity.FirewallV1Map {
+func (n *Neutron) UpdateFirewallV1(firewallId string, opts *entity.UpdateFirewallOpts) (entity.FirewallV1Map, error) {
 	urlSuffix := fmt.Sprintf("fw/firewalls/%s", firewallId)
-	resp := n.Put(n.Headers, urlSuffix, opts.ToRequestBody())
+	resp, err := n.Put(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return entity.FirewallV1Map{}, err
+	}
 	var firewall entity.FirewallV1Map
 	_ = json.Unmarshal(resp, &firewall)
 
 	log.Println("==============Update firewall success", firewallId)
-	return firewall
+	return firewall, nil
 }
 
-func (n *Neutron) updateFirewallWithRouterV1(firewallGroupId, routerId string) string {
+func (n *Neutron) updateFirewallWithRouterV1(firewallGroupId, routerId string) (string, error) {
 	urlSuffix := fmt.Sprintf("fw/firewalls/%s", firewallGroupId)
 	reqBody := fmt.Sprintf("{\"firewall\": {\"router_id\": \"%+v\"}}", routerId)
-	n.Put(n.Headers, urlSuffix, reqBody)
+	if _, err := n.Put(n.Headers, urlSuffix, reqBody); err != nil {
+		return "", err
+	}
 
 	log.Println("==============update firewall success", firewallGroupId)
-	return firewallGroupId
+	return firewallGroupId, nil
 }
 
-func (n *Neutron) updateFirewallGroupNoRoutersNoPoliciesV1(firewallGroupId string) map[string]interface{} {
+func (n *Neutron) updateFirewallGroupNoRoutersNoPoliciesV1(firewallGroupId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fw/firewalls/%s", firewallGroupId)
 	updateBody := `{"firewall": {"router_ids": []}}`
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, updateBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallGroup := resp["firewall"].(map[string]interface{})
 
 	n.SyncResource(firewallGroupId, nil, firewallGroup)
 	log.Println("==============update firewall success", firewallGroupId)
-	return firewallGroup
+	return firewallGroup, nil
 }
 
-func (n *Neutron) ListFirewallV1s() entity.FirewallV1s {
-	urlSuffix := fmt.Sprintf("fw/firewalls?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) ListFirewallV1s(opts entity.ListOpts) (entity.FirewallV1s, error) {
+	resp, err := n.ListBy(n.Headers, "fw/firewalls", opts)
+	if err != nil {
+		return entity.FirewallV1s{}, err
+	}
 	var firewalls entity.FirewallV1s
 	_ = json.Unmarshal(resp, &firewalls)
 
 	//cache.RedisClient.SetMap(n.tag + consts.FIREWALLGROUPS, firewall.Firewall.Id, firewall)
 	log.Println("==============List firewall success, there had", len(firewalls.Fs))
-	return firewalls
+	return firewalls, nil
 }
 
-func (n *Neutron) GetFirewall(firewallId string) entity.FirewallV1Map {
+func (n *Neutron) GetFirewall(firewallId string) (entity.FirewallV1Map, error) {
 	urlSuffix := fmt.Sprintf("fw/firewalls/%s", firewallId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.FirewallV1Map{}, err
+	}
 	var firewall entity.FirewallV1Map
 	_ = json.Unmarshal(resp, &firewall)
 
 	//cache.RedisClient.SetMap(n.tag + consts.FIREWALLGROUPS, firewall.Firewall.Id, firewall)
 	log.Printf("==============Get firewall success %+v\n", firewall)
-	return firewall
+	return firewall, nil
 }
 
-func (n *Neutron) deleteFirewallV1(id string) Output {
+func (n *Neutron) DeleteFirewallV1(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"firewall_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("fw/firewalls/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteFirewalls() {
-	fws := n.ListFirewallV1s()
+func (n *Neutron) DeleteFirewalls() error {
+	fws, err := n.ListFirewallV1s(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.FIREWALL, len(fws.Fs))
 	for _, fw := range fws.Fs {
+		if !n.selects(fw) {
+			continue
+		}
 		tempFw := fw
+		wg.Add(1)
 		go func() {
-			ch <- n.deleteFirewallV1(tempFw.Id)
+			defer wg.Done()
+			ch <- n.DeleteFirewallV1(tempFw.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Firewalls were deleted completely")
+	return nil
 }
 
 // firewall policy
 
-func (n *Neutron) CreateFirewallPolicyV1(opts *entity.CreateFirewallPolicyOpts) string {
+func (n *Neutron) CreateFirewallPolicyV1(opts *entity.CreateFirewallPolicyOpts) (string, error) {
 	urlSuffix := "fw/firewall_policies"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.FIREWALLPOLICY)
     reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, string(reqBody))
+	resp, err := n.Post(n.Headers, urlSuffix, string(reqBody))
+	if err != nil {
+		return "", err
+	}
 	var firewallPolicy entity.FirewallPolicyV1Map
     _ = json.Unmarshal(resp, &firewallPolicy)
 	//cache.RedisClient.SetMap(n.tag + consts.FIREWALLPOLICIES, firewallPolicy.FirewallPolicy.Id, firewallPolicy)
 	log.Println("==============Create firewall policy success", firewallPolicy.FirewallPolicyV1.Id)
-	return firewallPolicy.FirewallPolicyV1.Id
+	return firewallPolicy.FirewallPolicyV1.Id, nil
 }
 
-func (n *Neutron) updateFirewallPolicyNoRulesV1(firewallPolicyId string) map[string]interface{} {
+func (n *Neutron) updateFirewallPolicyNoRulesV1(firewallPolicyId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s", firewallPolicyId)
 	reqBody := `{"firewall_policy": {"firewall_rules": []}}`
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallPolicy := resp["firewall_policy"].(map[string]interface{})
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============update firewall policy no rule success", firewallPolicyId)
-	return firewallPolicy
+	return firewallPolicy, nil
 }
 
-func (n *Neutron) UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId string) string {
+func (n *Neutron) UpdateFirewallPolicyInsertRuleV1(firewallPolicyId, ruleId string) (string, error) {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s/insert_rule", firewallPolicyId)
 	formatter := `{"insert_after": "", "insert_before": "", "firewall_rule_id": "%+v"}`
 	reqBody := fmt.Sprintf(formatter, ruleId)
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var policy entity.FirewallPolicyV1Map
     _ = json.Unmarshal(resp, &policy)
 	//n.SyncMap(n.tag + consts.FIREWALLPOLICIES, firewallPolicyId, nil, policy)
 	log.Println("==============update firewall policy success", firewallPolicyId)
-	return firewallPolicyId
+	return firewallPolicyId, nil
 }
 
-func (n *Neutron) UpdateFirewallPolicyRemoveRuleV1(firewallPolicyId, ruleId string) string {
+func (n *Neutron) UpdateFirewallPolicyRemoveRuleV1(firewallPolicyId, ruleId string) (string, error) {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s/remove_rule", firewallPolicyId)
 	formatter := `{"firewall_rule_id": "%+v"}`
 	reqBody := fmt.Sprintf(formatter, ruleId)
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var policy entity.FirewallPolicyV1Map
 	_ = json.Unmarshal(resp, &policy)
 	//n.SyncMap(n.tag + consts.FIREWALLPOLICIES, firewallPolicyId, nil, policy)
 	log.Println("==============update firewall policy success", firewallPolicyId)
-	return firewallPolicyId
+	return firewallPolicyId, nil
 }
 
-func (n *Neutron) updateFirewallPolicyV1(firewallPolicyId string) map[string]interface{} {
+func (n *Neutron) updateFirewallPolicyV1(firewallPolicyId string) (map[string]interface{}, error) {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s", firewallPolicyId)
 	reqBody := `{"firewall_policy": {"name": "dx"}}`
-	resp := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	resp, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return nil, err
+	}
 	firewallPolicy := resp["firewall_policy"].(map[string]interface{})
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============update firewall policy success", firewallPolicyId)
-	return firewallPolicy
+	return firewallPolicy, nil
 }
 
-func (n *Neutron) GistFirewallPolicy(firewallPolicyId string) entity.FirewallPolicy {
+func (n *Neutron) GistFirewallPolicy(firewallPolicyId string) (entity.FirewallPolicy, error) {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s", firewallPolicyId)
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.FirewallPolicy{}, err
+	}
 	var firewallPolicy entity.FirewallPolicy
 	_ = json.Unmarshal(resp, &firewallPolicy)
 	log.Printf("==============Get firewall policy success %+v\n", firewallPolicy)
-	return firewallPolicy
+	return firewallPolicy, nil
 }
 
-func (n *Neutron) listFirewallPoliciesV1() entity.FirewallPolicies {
-	urlSuffix := fmt.Sprintf("fw/firewall_policies?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listFirewallPoliciesV1(opts entity.ListOpts) (entity.FirewallPolicies, error) {
+	resp, err := n.ListBy(n.Headers, "fw/firewall_policies", opts)
+	if err != nil {
+		return entity.FirewallPolicies{}, err
+	}
 	var firewallPolicies entity.FirewallPolicies
 	_ = json.Unmarshal(resp, &firewallPolicies)
 	//cache.RedisClient.SetMap(n.tag + consts.FIREWALLPOLICIES, firewallPolicy.FirewallPolicy.Id, firewallPolicy)
 	log.Println("==============List firewall policy success, there had", len(firewallPolicies.Fps))
-	return firewallPolicies
+	return firewallPolicies, nil
 }
 
-func (n *Neutron) deleteFirewallPolicyV1(id string) Output {
+func (n *Neutron) DeleteFirewallPolicyV1(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"firewall_policy_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteFirewallPolicies() {
-	fps := n.listFirewallPoliciesV1()
+func (n *Neutron) DeleteFirewallPolicies() error {
+	fps, err := n.listFirewallPoliciesV1(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.FIREWALLPOLICY, len(fps.Fps))
 	for _, fp := range fps.Fps {
+		if !n.selects(fp) {
+			continue
+		}
 		tempFp := fp
+		wg.Add(1)
 		go func() {
-			ch <- n.deleteFirewallPolicyV1(tempFp.Id)
+			defer wg.Done()
+			ch <- n.DeleteFirewallPolicyV1(tempFp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Firewall policies were deleted completely")
+	return nil
 }
 
 // firewall rule
 
-func (n *Neutron) CreateFirewallRuleV1(opts *entity.CreateFirewallRuleOpts) string {
+func (n *Neutron) CreateFirewallRuleV1(opts *entity.CreateFirewallRuleOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.FIREWALLRULE)
 	urlSuffix := "fw/firewall_rules"
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var firewallRule entity.FirewallRuleV1Map
     _ = json.Unmarshal(resp, &firewallRule)
     //cache.RedisClient.SetMap(n.tag + consts.FIREWALLRULES, firewallRule.FirewallRule.Id, firewallRule)
 	log.Println("==============Create firewall rule success", firewallRule.FirewallRuleV1.Id)
-	return firewallRule.FirewallRuleV1.Id
+	return firewallRule.FirewallRuleV1.Id, nil
 }
 
 //func (n *Neutron) CreateFirewallRuleSSHDenyV1() string {
@@ -1612,148 +1896,178 @@ func (n *Neutron) CreateFirewallRuleV1(opts *entity.CreateFirewallRuleOpts) stri
 //}
 
 
-func (n *Neutron) insertRuleV1(firewallPolicyId, firewallRuleId string) {
+func (n *Neutron) insertRuleV1(firewallPolicyId, firewallRuleId string) error {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s/insert_rule", firewallPolicyId)
 	reqBody := fmt.Sprintf("{\"firewall_rule_id\": \"%+v\", \"insert_after\": \"\", \"insert_before\": \"\"}", firewallRuleId)
-	firewallPolicy := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	firewallPolicy, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============insert firewall rule success", firewallRuleId)
+	return nil
 }
 
-func (n *Neutron) removeRuleV1(firewallPolicyId, firewallRuleId string) {
+func (n *Neutron) removeRuleV1(firewallPolicyId, firewallRuleId string) error {
 	urlSuffix := fmt.Sprintf("fw/firewall_policies/%s/remove_rule", firewallPolicyId)
 	reqBody := fmt.Sprintf("{\"firewall_rule_id\": \"%+v\"}", firewallRuleId)
-	firewallPolicy := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	firewallPolicy, err := n.DecorateResp(n.Put)(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 
 	n.SyncResource(firewallPolicyId, nil, firewallPolicy)
 	log.Println("==============remove firewall rule success", firewallRuleId)
+	return nil
 }
 
-func (n *Neutron) listFirewallRulesV1() entity.FirewallRules {
-	urlSuffix := fmt.Sprintf("fw/firewall_rules?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listFirewallRulesV1(opts entity.ListOpts) (entity.FirewallRules, error) {
+	resp, err := n.ListBy(n.Headers, "fw/firewall_rules", opts)
+	if err != nil {
+		return entity.FirewallRules{}, err
+	}
 	var firewallRules entity.FirewallRules
 	_ = json.Unmarshal(resp, &firewallRules)
 	//cache.RedisClient.SetMap(n.tag + consts.FIREWALLPOLICIES, firewallPolicy.FirewallPolicy.Id, firewallPolicy)
 	log.Println("==============List firewall rule success, there had", len(firewallRules.Frs))
-	return firewallRules
+	return firewallRules, nil
 }
 
 func (n *Neutron) DeleteFirewallRuleV1(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"firewall_rule_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("fw/firewall_rules/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteFirewallRules() {
-	rules := n.listFirewallRulesV1()
+func (n *Neutron) DeleteFirewallRules() error {
+	rules, err := n.listFirewallRulesV1(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.FIREWALLRULE, len(rules.Frs))
 	for _, rule := range rules.Frs {
+		if !n.selects(rule) {
+			continue
+		}
 		tempRule := rule
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteFirewallRuleV1(tempRule.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Firewall rules were deleted completely")
+	return nil
 }
 
 // security group
-func (n *Neutron) CreateSecurityGroup(opts *entity.CreateSecurityGroupOpts) string {
+func (n *Neutron) CreateSecurityGroup(opts *entity.CreateSecurityGroupOpts) (string, error) {
 	urlSuffix := "security-groups"
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var sg entity.Sg
 	_ = json.Unmarshal(resp, &sg)
 
 	//cache.RedisClient.SetMap(n.tag + consts.SECURITYGROUPS, sg.SecurityGroup.Id, sg)
 	log.Println("==============Create security group success", sg.SecurityGroup.Id)
-	return sg.SecurityGroup.Id
+	return sg.SecurityGroup.Id, nil
 }
 
-func (n *Neutron) getSecurityGroup(sgId string) interface{} {
+func (n *Neutron) getSecurityGroup(sgId string) (interface{}, error) {
 	urlSuffix := fmt.Sprintf("security-groups/%s", sgId)
-    resp := n.Get(n.Headers, urlSuffix)
+    resp, err := n.Get(n.Headers, urlSuffix)
+    if err != nil {
+        return nil, err
+    }
     var sg entity.Sg
     _ = json.Unmarshal(resp, &sg)
     log.Println(fmt.Sprintf("get sg==%+v", sg))
-    return sg
+    return sg, nil
 }
 
-func (n *Neutron) listSecurityGroupByName(sgName string) entity.Sgs {
-	urlSuffix := fmt.Sprintf("security-groups?name=%s", sgName)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listSecurityGroupByName(sgName string) (entity.Sgs, error) {
+	opts := entity.ListSecurityGroupsOpts{ListOpts: entity.ListOpts{Name: sgName}}
+	resp, err := n.ListBy(n.Headers, "security-groups", opts)
+	if err != nil {
+		return entity.Sgs{}, err
+	}
 	var sgs entity.Sgs
 	_ = json.Unmarshal(resp, &sgs)
 	log.Println("==============List sg success, there had", sgs.Count)
-	return sgs
+	return sgs, nil
 }
 
-func (n *Neutron) listSecurityGroups() entity.Sgs {
-	urlSuffix := fmt.Sprintf("security-groups?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listSecurityGroups(opts entity.ListSecurityGroupsOpts) (entity.Sgs, error) {
+	resp, err := n.ListBy(n.Headers, "security-groups", opts)
+	if err != nil {
+		return entity.Sgs{}, err
+	}
 	var sgs entity.Sgs
 	_ = json.Unmarshal(resp, &sgs)
 	log.Println("==============List sg success, there had", len(sgs.Sgs))
-	return sgs
+	return sgs, nil
 }
 
-func (n *Neutron) CreateSecurityGroupAndRules(opts *entity.CreateSecurityGroupOpts) string {
-    sgId := n.CreateSecurityGroup(opts)
-    n.createSecurityGroupRuleICMP(sgId)
-    n.createSecurityGroupRuleSSH(sgId)
+func (n *Neutron) CreateSecurityGroupAndRules(opts *entity.CreateSecurityGroupOpts) (string, error) {
+    sgId, err := n.CreateSecurityGroup(opts)
+    if err != nil {
+        return "", err
+    }
+    if err := n.createSecurityGroupRuleICMP(sgId); err != nil {
+        return "", err
+    }
+    if err := n.createSecurityGroupRuleSSH(sgId); err != nil {
+        return "", err
+    }
 
     //n.SyncMap(n.tag + consts.SECURITYGROUPS, sgId, func(resourceId string) interface{} {
 	//	return n.getSecurityGroup(sgId)
 	//}, nil)
-    return sgId
+    return sgId, nil
 }
 
-func (n *Neutron) deleteSecurityGroup(id string) Output {
+func (n *Neutron) DeleteSecurityGroup(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"security_group_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("security-groups/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteSecurityGroups() {
-	sgs := n.listSecurityGroups()
+func (n *Neutron) DeleteSecurityGroups() error {
+	sgs, err := n.listSecurityGroups(entity.ListSecurityGroupsOpts{ListOpts: entity.ListOpts{ProjectId: n.projectId}})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.SECURITYGROUP, len(sgs.Sgs))
 	for _, sg := range sgs.Sgs {
+		if !n.selects(sg) {
+			continue
+		}
 		tempSg := sg
+		wg.Add(1)
 		go func() {
-			ch <- n.deleteSecurityGroup(tempSg.Id)
+			defer wg.Done()
+			ch <- n.DeleteSecurityGroup(tempSg.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Security groups were deleted completely")
+	return nil
 }
 
-func (n *Neutron) DeleteSecurityGroupAndRules()  {
-	n.DeleteSecurityGroupRules()
-	n.DeleteSecurityGroups()
+func (n *Neutron) DeleteSecurityGroupAndRules() error {
+	if err := n.DeleteSecurityGroupRules(); err != nil {
+		return err
+	}
+	return n.DeleteSecurityGroups()
 }
 
 //func (n *Neutron) MakeSureSgExist(name string) {
@@ -1771,28 +2085,49 @@ func (n *Neutron) DeleteSecurityGroupAndRules()  {
 //	n.CreateSecurityGroupAndRules()
 //}
 
-func (n *Neutron) EnsureSgExist(sgName string) {
-	sgs := n.listSecurityGroupByName(sgName)
+func (n *Neutron) EnsureSgExist(sgName string) error {
+	sgs, err := n.listSecurityGroupByName(sgName)
+	if err != nil {
+		return err
+	}
 	if sgs.Count == 0 {
-		n.CreateSecurityGroupAndRules(&entity.CreateSecurityGroupOpts{Name: sgName})
+		if _, err := n.CreateSecurityGroupAndRules(&entity.CreateSecurityGroupOpts{Name: sgName}); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // security group rule
-func (n *Neutron) CreateSecurityGroupRule(reqBody string) {
+func (n *Neutron) CreateSecurityGroupRule(reqBody string) error {
+	_, err := n.createSecurityGroupRule(reqBody)
+	return err
+}
+
+func (n *Neutron) CreateSecurityRule(opts *entity.CreateSecurityRuleOpts) (string, error) {
+	return n.createSecurityGroupRule(opts.ToRequestBody())
+}
+
+func (n *Neutron) createSecurityGroupRule(reqBody string) (string, error) {
 	urlSuffix := "security-group-rules"
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var sgRule entity.SgRule
 	_ = json.Unmarshal(resp, &sgRule)
 
 	//cache.RedisClient.SetMap(n.tag + consts.SECURITYGROUPRULES, sgRule.SecurityGroupRule.Id, sgRule)
 	log.Println("==============Create security group rule success", sgRule.SecurityGroupRule.Id)
+	return sgRule.SecurityGroupRule.Id, nil
 }
 
-func (n *Neutron) createSecurityGroupRuleICMP(sgId string) {
+func (n *Neutron) createSecurityGroupRuleICMP(sgId string) error {
 	ingress, egress := n.constructICMPRule(sgId)
-	n.CreateSecurityGroupRule(ingress)
-	n.CreateSecurityGroupRule(egress)
+	if err := n.CreateSecurityGroupRule(ingress); err != nil {
+		return err
+	}
+	return n.CreateSecurityGroupRule(egress)
 }
 
 func (n *Neutron) constructICMPRule(sgId string) (string, string) {
@@ -1801,10 +2136,12 @@ func (n *Neutron) constructICMPRule(sgId string) (string, string) {
 	return ingress.ToRequestBody(), egress.ToRequestBody()
 }
 
-func (n *Neutron) createSecurityGroupRuleSSH(sgId string) {
+func (n *Neutron) createSecurityGroupRuleSSH(sgId string) error {
 	ingress, egress := n.constructSSHRule(sgId)
-	n.CreateSecurityGroupRule(ingress)
-	n.CreateSecurityGroupRule(egress)
+	if err := n.CreateSecurityGroupRule(ingress); err != nil {
+		return err
+	}
+	return n.CreateSecurityGroupRule(egress)
 }
 
 func (n *Neutron) constructSSHRule(sgId string) (string, string) {
@@ -1813,93 +2150,121 @@ func (n *Neutron) constructSSHRule(sgId string) (string, string) {
 	return ingress.ToRequestBody(), egress.ToRequestBody()
 }
 
-func (n *Neutron) listSecurityGroupRules() entity.SgRules {
-	urlSuffix := fmt.Sprintf("security-group-rules?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listSecurityGroupRules(opts entity.ListSecurityRulesOpts) (entity.SgRules, error) {
+	resp, err := n.ListBy(n.Headers, "security-group-rules", opts)
+	if err != nil {
+		return entity.SgRules{}, err
+	}
 	var sgRules entity.SgRules
 	_ = json.Unmarshal(resp, &sgRules)
 
 	//cache.RedisClient.SetMap(n.tag + consts.SECURITYGROUPRULES, sgRule.SecurityGroupRule.Id, sgRule)
 	log.Println("==============List sg rule success, there had", len(sgRules.Srs))
-	return sgRules
+	return sgRules, nil
 }
 
-func (n *Neutron) deleteSecurityGroupRule(id string) Output {
+func (n *Neutron) DeleteSecurityGroupRule(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"security_group_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("security-group-rules/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteSecurityGroupRules() {
-	sgRules := n.listSecurityGroupRules()
+// selectedSecurityGroups returns the ids of the security groups the filter
+// selects, nil without filter. The rules of a group follow it.
+func (n *Neutron) selectedSecurityGroups() (map[string]bool, error) {
+	if n.Filter == nil {
+		return nil, nil
+	}
+	sgs, err := n.listSecurityGroups(entity.ListSecurityGroupsOpts{ListOpts: entity.ListOpts{ProjectId: n.projectId}})
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(sgs.Sgs))
+	for _, sg := range sgs.Sgs {
+		selected[sg.Id] = n.selects(sg)
+	}
+	return selected, nil
+}
+
+func (n *Neutron) DeleteSecurityGroupRules() error {
+	sgRules, err := n.listSecurityGroupRules(entity.ListSecurityRulesOpts{ListOpts: entity.ListOpts{ProjectId: n.projectId}})
+	if err != nil {
+		return err
+	}
+	selected, err := n.selectedSecurityGroups()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.SECURITYGROUPRULE, len(sgRules.Srs))
 	for _, sgRule := range sgRules.Srs {
+		if selected != nil && !selected[sgRule.SecurityGroupId] {
+			continue
+		}
 		tempSgRule := sgRule
+		wg.Add(1)
 		go func() {
-			ch <- n.deleteSecurityGroupRule(tempSgRule.Id)
+			defer wg.Done()
+			ch <- n.DeleteSecurityGroupRule(tempSgRule.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Security group rules were deleted completely")
+	return nil
 }
 
 // rbac policy
 
-func (n *Neutron) CreateRbacPolicy(objectType, objectId, targetTenant string) string {
+func (n *Neutron) CreateRbacPolicy(objectType, objectId, targetTenant string) (string, error) {
 	urlSuffix := "rbac-policies"
 	reqBody := fmt.Sprintf("{\"rbac_policy\": {\"action\": \"access_as_shared\", \"object_type\": \"%+v\", \"target_tenant\": \"%+v\", \"object_id\": \"%+v\"}}", objectType, targetTenant, objectId)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var rp entity.RbacPolicyMap
 	_ = json.Unmarshal(resp, &rp)
 
 	//cache.RedisClient.SetMap(n.tag + consts.RBACPOLICIES, rp.RbacPolicy.Id, rp)
 	log.Println("==============Create rbac policy success", rp.RbacPolicy.Id)
-	return rp.RbacPolicy.Id
+	return rp.RbacPolicy.Id, nil
 }
 
-func (n *Neutron) CreateNetworkRbacPolicy(objectId, targetTenant string) string {
-	rbacPolicyId := n.CreateRbacPolicy(consts.NETWORK, objectId, targetTenant)
-	return rbacPolicyId
+func (n *Neutron) CreateNetworkRbacPolicy(objectId, targetTenant string) (string, error) {
+	return n.CreateRbacPolicy(consts.NETWORK, objectId, targetTenant)
 }
 
-func (n *Neutron) createQosRbacPolicy(objectId, targetTenant string) string {
-	rbacPolicyId := n.CreateRbacPolicy(consts.QOS_POLICY, objectId, targetTenant)
-	return rbacPolicyId
+func (n *Neutron) createQosRbacPolicy(objectId, targetTenant string) (string, error) {
+	return n.CreateRbacPolicy(consts.QOS_POLICY, objectId, targetTenant)
 }
 
-func (n *Neutron) createSGRbacPolicy(objectId, targetTenant string) string {
-	rbacPolicyId := n.CreateRbacPolicy(consts.SECURITYGROUP, objectId, targetTenant)
-	return rbacPolicyId
+func (n *Neutron) createSGRbacPolicy(objectId, targetTenant string) (string, error) {
+	return n.CreateRbacPolicy(consts.SECURITYGROUP, objectId, targetTenant)
 }
 
-func (n *Neutron) deleteRbacPolicy(rbacPolicyId string) {
+func (n *Neutron) deleteRbacPolicy(rbacPolicyId string) error {
 	urlSuffix := fmt.Sprintf("rbac-policies/%s", rbacPolicyId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
+	err := n.Delete(n.Headers, urlSuffix)
+	if err == nil {
 		//cache.RedisClient.DeleteMap(n.tag + consts.RBACPOLICIES, rbacPolicyId)
 		log.Println("==============Delete rbac policy success", rbacPolicyId)
-		return
+		return nil
 	}
 	log.Println("==============Delete rbac policy failed", rbacPolicyId)
+	return err
 }
 
-func (n *Neutron) getRbacPolicy(rbacPolicyId string) interface{} {
+func (n *Neutron) getRbacPolicy(rbacPolicyId string) (interface{}, error) {
 	urlSuffix := fmt.Sprintf("rbac-policies/%s", rbacPolicyId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return nil, err
+	}
 	var rp entity.RbacPolicyMap
 	_ = json.Unmarshal(resp, &rp)
 	log.Println(fmt.Sprintf("rbac policy==%+v", rp))
-	return rp
+	return rp, nil
 }
 
 func (n *Neutron) deleteRbacPolicies() {
@@ -1911,634 +2276,739 @@ func (n *Neutron) deleteRbacPolicies() {
 
 // vpn
 
-func (n *Neutron) CreateVpnService(routerId string) string {
+func (n *Neutron) CreateVpnService(opts *entity.CreateVpnServiceOpts) (string, error) {
 	urlSuffix := "vpn/vpnservices"
-	name := "vpn_service_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	reqBody := fmt.Sprintf("{\"vpnservice\": {\"name\": \"%+v\", \"router_id\": \"%+v\"}}", name, routerId)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	if opts.Name == "" {
+		opts.Name = "vpn_service_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
+	}
+	resp, err := n.Post(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return "", err
+	}
 	var vs entity.VpnServiceMap
 	_ = json.Unmarshal(resp, &vs)
 
 	//cache.RedisClient.SetMap(n.tag + consts.VPNSERVICES, vs.VpnService.Id, vs)
 	log.Println("==============Create vpn service success", vs.VpnService.Id)
-	return vs.VpnService.Id
+	return vs.VpnService.Id, nil
 }
 
-func (n *Neutron) deleteVpnService(vpnServiceId string) {
-	defer n.wg.Done()
+func (n *Neutron) DeleteVpnService(vpnServiceId string) Output {
+	outputObj := Output{ParametersMap: map[string]string{"vpnservice_id": vpnServiceId}}
 	urlSuffix := fmt.Sprintf("vpn/vpnservices/%s", vpnServiceId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
-		//cache.RedisClient.DeleteMap(n.tag + consts.VPNSERVICES, vpnServiceId)
-		log.Println("==============Delete vpn service success", vpnServiceId)
-		return
-	}
-	log.Println("==============Delete vpn service failed", vpnServiceId)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	return outputObj
 }
 
-func (n *Neutron) getVpnService(vpnServiceId string) entity.VpnServiceMap {
+func (n *Neutron) getVpnService(vpnServiceId string) (entity.VpnServiceMap, error) {
 	urlSuffix := fmt.Sprintf("vpn/vpnservices/%s", vpnServiceId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.VpnServiceMap{}, err
+	}
 	var vs entity.VpnServiceMap
 	_ = json.Unmarshal(resp, &vs)
-	return vs
+	return vs, nil
 }
 
-func (n *Neutron) listVpnServices() entity.VpnServices {
-	urlSuffix := fmt.Sprintf("vpn/vpnservices?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listVpnServices(opts entity.ListOpts) (entity.VpnServices, error) {
+	resp, err := n.ListBy(n.Headers, "vpn/vpnservices", opts)
+	if err != nil {
+		return entity.VpnServices{}, err
+	}
 	var ips entity.VpnServices
 	_ = json.Unmarshal(resp, &ips)
 	log.Println("==============List vpn service success, there had", ips.Count)
-	return ips
+	return ips, nil
 }
 
-func (n *Neutron) DeleteVpnServices() {
+func (n *Neutron) DeleteVpnServices() error {
 	//vsIds := cache.RedisClient.GetMaps(n.tag + consts.VPNSERVICES)
-	vss := n.listVpnServices()
+	vss, err := n.listVpnServices(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	ch := n.MakeDeleteChannel(consts.VpnService, len(vss.Vss))
 	for _, vs := range vss.Vss {
-		n.wg.Add(1)
-		go n.deleteVpnService(vs.Id)
+		if !n.selects(vs) {
+			continue
+		}
+		tempVs := vs
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch <- n.DeleteVpnService(tempVs.Id)
+		}()
 	}
-	n.wg.Wait()
+	wg.Wait()
+	return nil
 }
 
 // endpoint group
-func (n *Neutron) createEndpointGroup(reqBody string) string {
+
+func (n *Neutron) CreateEndpointGroup(opts *entity.CreateEndpointGroupOpts) (string, error) {
 	urlSuffix := "vpn/endpoint-groups"
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	if opts.Name == "" {
+		opts.Name = "endpoint_group_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
+	}
+	resp, err := n.Post(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return "", err
+	}
 	var eg entity.EndpointGroupMap
 	_ = json.Unmarshal(resp, &eg)
 
 	//cache.RedisClient.SetMap(n.tag + consts.ENDPOINTGROUPS, eg.EndpointGroup.Id, eg)
 	log.Println("==============Create endpoint group success", eg.EndpointGroup.Id)
-	return eg.EndpointGroup.Id
+	return eg.EndpointGroup.Id, nil
 }
 
-func (n *Neutron) CreateLocalEndpointGroup(subnetId string) string {
-	name := "endpoint_group_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "endpoint_group": {
-                          "name": "%+v",
-                          "endpoints": ["%+v"],
-                          "type": "subnet"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name, subnetId)
-	return n.createEndpointGroup(reqBody)
+func (n *Neutron) CreateLocalEndpointGroup(subnetId string) (string, error) {
+	return n.CreateEndpointGroup(&entity.CreateEndpointGroupOpts{Type: "subnet", Endpoints: []string{subnetId}})
 }
 
-func (n *Neutron) createLocalEndpointGroupTwoSubnet(subnetId1, subnetId2 string) string {
-	name := "endpoint_group_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	reqBody := fmt.Sprintf("{\"endpoint_group\": {\"name\": \"%+v\", \"endpoints\": [\"%+v\", \"%+v\"], \"type\": \"subnet\"}}", name, subnetId1, subnetId2)
-	return n.createEndpointGroup(reqBody)
+func (n *Neutron) createLocalEndpointGroupTwoSubnet(subnetId1, subnetId2 string) (string, error) {
+	return n.CreateEndpointGroup(&entity.CreateEndpointGroupOpts{Type: "subnet", Endpoints: []string{subnetId1, subnetId2}})
 }
 
-func (n *Neutron) createPeerEndpointGroupWithSubnet(subnetId string) string {
-	cidr := n.getSubnetCidr(subnetId)
-	name := "endpoint_group_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "endpoint_group": {
-                          "name": "%+v",
-                          "endpoints": ["%+v"],
-                          "type": "cidr"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name, cidr)
-	return n.createEndpointGroup(reqBody)
+func (n *Neutron) createPeerEndpointGroupWithSubnet(subnetId string) (string, error) {
+	cidr, err := n.getSubnetCidr(subnetId)
+	if err != nil {
+		return "", err
+	}
+	return n.CreatePeerEndpointGroup(cidr)
 }
 
-func (n *Neutron) CreatePeerEndpointGroup(peerCidr string) string {
-	name := "endpoint_group_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "endpoint_group": {
-                          "name": "%+v",
-                          "endpoints": ["%+v"],
-                          "type": "cidr"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name, peerCidr)
-	return n.createEndpointGroup(reqBody)
+func (n *Neutron) CreatePeerEndpointGroup(peerCidr string) (string, error) {
+	return n.CreateEndpointGroup(&entity.CreateEndpointGroupOpts{Type: "cidr", Endpoints: []string{peerCidr}})
 }
 
-func (n *Neutron) DeleteEndpointGroup(egId string) {
-	defer n.wg.Done()
+func (n *Neutron) DeleteEndpointGroup(egId string) Output {
+	outputObj := Output{ParametersMap: map[string]string{"endpoint_group_id": egId}}
 	urlSuffix := fmt.Sprintf("vpn/endpoint-groups/%s", egId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
-		//cache.RedisClient.DeleteMap(n.tag + consts.ENDPOINTGROUPS, egId)
-		log.Println("==============Delete endpoint group success", egId)
-		return
-	}
-	log.Println("==============Delete endpoint group failed", egId)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	return outputObj
 }
 
-func (n *Neutron) getEndpointGroup(egId string) entity.EndpointGroupMap {
+func (n *Neutron) getEndpointGroup(egId string) (entity.EndpointGroupMap, error) {
 	urlSuffix := fmt.Sprintf("vpn/endpoint-groups/%s", egId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.EndpointGroupMap{}, err
+	}
 	var eg entity.EndpointGroupMap
 	_ = json.Unmarshal(resp, &eg)
-	return eg
+	return eg, nil
 }
 
-func (n *Neutron) listEndpointGroups() entity.EndpointGroups {
-	urlSuffix := fmt.Sprintf("vpn/endpoint-groups?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listEndpointGroups(opts entity.ListOpts) (entity.EndpointGroups, error) {
+	resp, err := n.ListBy(n.Headers, "vpn/endpoint-groups", opts)
+	if err != nil {
+		return entity.EndpointGroups{}, err
+	}
 	var ips entity.EndpointGroups
 	_ = json.Unmarshal(resp, &ips)
 	log.Println("==============List endpoint group success, there had", ips.Count)
-	return ips
+	return ips, nil
 }
 
-func (n *Neutron) DeleteEndpointGroups() {
+func (n *Neutron) DeleteEndpointGroups() error {
 	//egIds := cache.RedisClient.GetMaps(n.tag + consts.ENDPOINTGROUPS)
-	egs := n.listEndpointGroups()
+	egs, err := n.listEndpointGroups(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	ch := n.MakeDeleteChannel(consts.EndpointGroup, len(egs.Egs))
 	for _, eg := range egs.Egs {
-		n.wg.Add(1)
-		go n.DeleteEndpointGroup(eg.Id)
+		if !n.selects(eg) {
+			continue
+		}
+		tempEg := eg
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch <- n.DeleteEndpointGroup(tempEg.Id)
+		}()
 	}
-	n.wg.Wait()
+	wg.Wait()
+	return nil
 }
 
 // ike policy
 
-func (n *Neutron) CreateIkePolicy() string {
+func (n *Neutron) CreateIkePolicy(opts *entity.CreateIkePolicyOpts) (string, error) {
 	urlSuffix := "vpn/ikepolicies"
-	name := "ike_policy_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "ikepolicy": {
-                          "phase1_negotiation_mode": "main",
-                          "auth_algorithm": "sha1",
-                          "encryption_algorithm": "aes-128",
-                          "pfs": "group14", 
-                          "lifetime": {
-                              "units": "seconds", 
-                              "value": 7200
-                          }, 
-                          "ike_version": "v1",
-                          "name": "%+v"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	if opts.Name == "" {
+		opts.Name = "ike_policy_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
+	}
+	resp, err := n.Post(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return "", err
+	}
 	var ip entity.IkePolicyMap
 	_ = json.Unmarshal(resp, &ip)
 
 	//cache.RedisClient.SetMap(n.tag + consts.IKEPOLICIES, ip.Ikepolicy.Id, ip)
 	log.Println("==============Create ike policy success", ip.Ikepolicy.Id)
-	return ip.Ikepolicy.Id
+	return ip.Ikepolicy.Id, nil
 }
 
-func (n *Neutron) DeleteIkePolicy(ipId string) {
-	defer n.wg.Done()
+func (n *Neutron) DeleteIkePolicy(ipId string) Output {
+	outputObj := Output{ParametersMap: map[string]string{"ikepolicy_id": ipId}}
 	urlSuffix := fmt.Sprintf("vpn/ikepolicies/%s", ipId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
-		//cache.RedisClient.DeleteMap(n.tag + consts.IKEPOLICIES, ipId)
-		log.Println("==============Delete ike policy success", ipId)
-		return
-	}
-	log.Println("==============Delete ike policy failed", ipId)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	return outputObj
 }
 
-func (n *Neutron) getIkePolicy(ipId string) entity.IkePolicyMap {
+func (n *Neutron) getIkePolicy(ipId string) (entity.IkePolicyMap, error) {
 	urlSuffix := fmt.Sprintf("vpn/ikepolicies/%s", ipId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.IkePolicyMap{}, err
+	}
 	var ip entity.IkePolicyMap
 	_ = json.Unmarshal(resp, &ip)
-	return ip
+	return ip, nil
 }
 
-func (n *Neutron) listIkePolicies() entity.IpsecPolicies {
-	urlSuffix := fmt.Sprintf("vpn/ikepolicies?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
-	var ips entity.IpsecPolicies
+func (n *Neutron) listIkePolicies(opts entity.ListOpts) (entity.IkePolicies, error) {
+	resp, err := n.ListBy(n.Headers, "vpn/ikepolicies", opts)
+	if err != nil {
+		return entity.IkePolicies{}, err
+	}
+	var ips entity.IkePolicies
 	_ = json.Unmarshal(resp, &ips)
 	log.Println("==============List ike policy success, there had", ips.Count)
-	return ips
+	return ips, nil
 }
 
-func (n *Neutron) DeleteIkePolicies() {
+func (n *Neutron) DeleteIkePolicies() error {
 	//ipIds := cache.RedisClient.GetMaps(n.tag + consts.IKEPOLICIES)
-	ipIds := n.listIkePolicies()
-	for _, ip := range ipIds.Ips {
-		n.wg.Add(1)
-		go n.DeleteIkePolicy(ip.Id)
+	ips, err := n.listIkePolicies(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
 	}
-	n.wg.Wait()
+	var wg sync.WaitGroup
+	ch := n.MakeDeleteChannel(consts.IkePolicy, len(ips.Ips))
+	for _, ip := range ips.Ips {
+		if !n.selects(ip) {
+			continue
+		}
+		tempIp := ip
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch <- n.DeleteIkePolicy(tempIp.Id)
+		}()
+	}
+	wg.Wait()
+	return nil
 }
 
 // ipsec policy
 
-func (n *Neutron) CreateIpsecPolicy() string {
+func (n *Neutron) CreateIpsecPolicy(opts *entity.CreateIpsecPolicyOpts) (string, error) {
 	urlSuffix := "vpn/ipsecpolicies"
-	name := "ipsec_policy_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "ipsecpolicy": {
-                          "transform_protocol": "esp",
-                          "auth_algorithm": "sha1",
-                          "encapsulation_mode": "tunnel",
-                          "encryption_algorithm": "aes-128",
-                          "pfs": "group5", 
-                          "lifetime": {
-                              "units": "seconds", 
-                              "value": 7200
-                          }, 
-                          "name": "%+v"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, name)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	if opts.Name == "" {
+		opts.Name = "ipsec_policy_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
+	}
+	resp, err := n.Post(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return "", err
+	}
 	var ip entity.IpsecPolicyMap
 	_ = json.Unmarshal(resp, &ip)
 
 	//cache.RedisClient.SetMap(n.tag + consts.IPSECPOLICIES, ip.Ipsecpolicy.Id, ip)
 	log.Println("==============Create ipsec policy success", ip.Ipsecpolicy.Id)
-	return ip.Ipsecpolicy.Id
+	return ip.Ipsecpolicy.Id, nil
 }
 
-func (n *Neutron) deleteIpsecPolicy(ipId string) {
-	defer n.wg.Done()
+func (n *Neutron) DeleteIpsecPolicy(ipId string) Output {
+	outputObj := Output{ParametersMap: map[string]string{"ipsecpolicy_id": ipId}}
 	urlSuffix := fmt.Sprintf("vpn/ipsecpolicies/%s", ipId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix) ; ok{
-		//cache.RedisClient.DeleteMap(n.tag + consts.IPSECPOLICIES, ipId)
-		log.Println("==============Delete ipsec policy success", ipId)
-		return
-	}
-	log.Println("==============Delete ipsec policy failed", ipId)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	return outputObj
 }
 
-func (n *Neutron) getIpsecPolicy(ipId string) entity.IpsecPolicyMap {
+func (n *Neutron) getIpsecPolicy(ipId string) (entity.IpsecPolicyMap, error) {
 	urlSuffix := fmt.Sprintf("vpn/ipsecpolicies/%s", ipId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.IpsecPolicyMap{}, err
+	}
 	var ip entity.IpsecPolicyMap
 	_ = json.Unmarshal(resp, &ip)
-	return ip
+	return ip, nil
 }
 
-func (n *Neutron) listIpsecPolicies() entity.IpsecPolicies {
-	urlSuffix := fmt.Sprintf("vpn/ipsecpolicies?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listIpsecPolicies(opts entity.ListOpts) (entity.IpsecPolicies, error) {
+	resp, err := n.ListBy(n.Headers, "vpn/ipsecpolicies", opts)
+	if err != nil {
+		return entity.IpsecPolicies{}, err
+	}
 	var ips entity.IpsecPolicies
 	_ = json.Unmarshal(resp, &ips)
 	log.Println("==============List ipsec policy success, there had", ips.Count)
-	return ips
+	return ips, nil
 }
 
-func (n *Neutron) DeleteIpsecPolicies() {
+func (n *Neutron) DeleteIpsecPolicies() error {
 	//ipIds := cache.RedisClient.GetMaps(n.tag + consts.IPSECPOLICIES)
-    ips := n.listIpsecPolicies()
+	ips, err := n.listIpsecPolicies(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	ch := n.MakeDeleteChannel(consts.IpsecPolicy, len(ips.Ips))
 	for _, ip := range ips.Ips {
-		n.wg.Add(1)
-		go n.deleteIpsecPolicy(ip.Id)
+		if !n.selects(ip) {
+			continue
+		}
+		tempIp := ip
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch <- n.DeleteIpsecPolicy(tempIp.Id)
+		}()
 	}
-	n.wg.Wait()
+	wg.Wait()
+	return nil
 }
 
 //ipsec site connection
 
-// CreateIpsecConnection create
-func (n *Neutron) CreateIpsecConnection(vpnServiceId, ikePolicyId, ipsecPolicyId, peerEGId, localEGId, peerAddress string) string {
+// CreateIpsecConnection creates an ipsec site connection, it peers with
+// PeerAddress unless PeerID is set.
+func (n *Neutron) CreateIpsecConnection(opts *entity.CreateIpsecConnectionOpts) (string, error) {
 	urlSuffix := "vpn/ipsec-site-connections"
-	name := "ipsec_connection_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
-	formatter := `{
-                      "ipsec_site_connection": {
-                          "initiator": "bi-directional",
-                          "ipsecpolicy_id": "%+v",
-                          "admin_state_up": true,
-                          "mtu": "1500",
-                          "psk": "secret", 
-                          "peer_ep_group_id": "%+v",
-                          "ikepolicy_id": "%+v", 
-                          "vpnservice_id": "%+v", 
-                          "local_ep_group_id": "%+v", 
-                          "peer_address": "%+v",
-                          "peer_id": "%+v", 
-                          "name": "%+v"
-                      }
-                   }`
-	reqBody := fmt.Sprintf(formatter, ipsecPolicyId, peerEGId, ikePolicyId, vpnServiceId, localEGId, peerAddress, peerAddress, name)
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	if opts.Name == "" {
+		opts.Name = "ipsec_connection_" + strconv.FormatUint(n.snowflake.NextVal(), 10)
+	}
+	resp, err := n.Post(n.Headers, urlSuffix, opts.ToRequestBody())
+	if err != nil {
+		return "", err
+	}
 	var ip entity.IpsecConnectionMap
 	_ = json.Unmarshal(resp, &ip)
 
 	//cache.RedisClient.SetMap(n.tag + consts.IPSECCONNECTIONS, ip.IpsecSiteConnection.Id, ip)
 	log.Println("==============Create ipsec connection success", ip.IpsecSiteConnection.Id)
-	return ip.IpsecSiteConnection.Id
+	return ip.IpsecSiteConnection.Id, nil
 }
 
-func (n *Neutron) deleteIpsecConnection(ipId string) {
-	defer n.wg.Done()
+func (n *Neutron) DeleteIpsecConnection(ipId string) Output {
+	outputObj := Output{ParametersMap: map[string]string{"ipsec_site_connection_id": ipId}}
 	urlSuffix := fmt.Sprintf("vpn/ipsec-site-connections/%s", ipId)
-	if ok, _ := n.Delete(n.Headers, urlSuffix); ok {
-		//cache.RedisClient.DeleteMap(n.tag + consts.IPSECCONNECTIONS, ipId)
-		log.Println("==============Delete ipsec connection success", ipId)
-		return
-	}
-	log.Println("==============Delete ipsec connection failed", ipId)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
+	return outputObj
 }
 
-func (n *Neutron) getIpsecConnection(ipId string) entity.IpsecConnectionMap {
+func (n *Neutron) getIpsecConnection(ipId string) (entity.IpsecConnectionMap, error) {
 	urlSuffix := fmt.Sprintf("vpn/ipsec-site-connections/%s", ipId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.IpsecConnectionMap{}, err
+	}
 	var ip entity.IpsecConnectionMap
 	_ = json.Unmarshal(resp, &ip)
-	return ip
+	return ip, nil
 }
 
-func (n *Neutron) listIpsecConnection() entity.IpsecConnections {
-	urlSuffix := fmt.Sprintf("vpn/ipsec-site-connections?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) listIpsecConnection(opts entity.ListOpts) (entity.IpsecConnections, error) {
+	resp, err := n.ListBy(n.Headers, "vpn/ipsec-site-connections", opts)
+	if err != nil {
+		return entity.IpsecConnections{}, err
+	}
 	var ics entity.IpsecConnections
 	_ = json.Unmarshal(resp, &ics)
 	log.Println("==============List ipsec connection success, there had", ics.Count)
-	return ics
+	return ics, nil
 }
 
-func (n *Neutron) DeleteIpsecConnections() {
+func (n *Neutron) DeleteIpsecConnections() error {
 	//ipIds := cache.RedisClient.GetMaps(n.tag + consts.IPSECCONNECTIONS)
-	ics := n.listIpsecConnection()
+	ics, err := n.listIpsecConnection(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	ch := n.MakeDeleteChannel(consts.IpsecConnection, len(ics.ICs))
 	for _, ic := range ics.ICs {
-		n.wg.Add(1)
-		go n.deleteIpsecConnection(ic.Id)
+		if !n.selects(ic) {
+			continue
+		}
+		tempIc := ic
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch <- n.DeleteIpsecConnection(tempIc.Id)
+		}()
 	}
-	n.wg.Wait()
+	wg.Wait()
+	return nil
 }
 
 // vpc connection
 
-func (n *Neutron) CreateVpcConnection(opts *entity.CreateVpcConnectionOpts) string {
+func (n *Neutron) CreateVpcConnection(opts *entity.CreateVpcConnectionOpts) (string, error) {
 	urlSuffix := "vpc-connections"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.VpcConnection)
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var vc entity.VpcConnectionMap
 	_ = json.Unmarshal(resp, &vc)
 
 	//cache.RedisClient.SetMap(n.tag + consts.VpcConnections, vc.VpcConnection.Id, vc)
 	log.Println("==============Create vpc connection success", vc.VpcConnection.Id)
-	return vc.VpcConnection.Id
+	return vc.VpcConnection.Id, nil
 }
 
-func (n *Neutron) UpdateVpcConnection(vpcConnId string, opts *entity.UpdateVpcConnectionOpts) string {
+func (n *Neutron) UpdateVpcConnection(vpcConnId string, opts *entity.UpdateVpcConnectionOpts) (string, error) {
 	urlSuffix := fmt.Sprintf("vpc-connections/%s", vpcConnId)
 	reqBody := opts.ToRequestBody()
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var vc entity.VpcConnectionMap
 	_ = json.Unmarshal(resp, &vc)
 
 	log.Println("==============Update vpc connection success", vc.VpcConnection.Id)
-	return vc.VpcConnection.Id
+	return vc.VpcConnection.Id, nil
 }
 
-func (n *Neutron) GetVpcConnection(ipId string) entity.VpcConnectionMap {
+func (n *Neutron) GetVpcConnection(ipId string) (entity.VpcConnectionMap, error) {
 	urlSuffix := fmt.Sprintf("vpc-connections/%s", ipId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return entity.VpcConnectionMap{}, err
+	}
 	var ip entity.VpcConnectionMap
 	_ = json.Unmarshal(resp, &ip)
 	log.Println("==============Get vpc connection success", ipId)
-	return ip
+	return ip, nil
 }
 
-func (n *Neutron) ListVpcConnections() entity.VpcConnections {
-	urlSuffix := fmt.Sprintf("vpc-connections?tenant_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) ListVpcConnections(opts entity.ListVpcConnectionsOpts) (entity.VpcConnections, error) {
+	resp, err := n.ListBy(n.Headers, "vpc-connections", opts)
+	if err != nil {
+		return entity.VpcConnections{}, err
+	}
 	var ics entity.VpcConnections
 	_ = json.Unmarshal(resp, &ics)
 	log.Println("==============List vpc connection success, there had", len(ics.Vcs))
-	return ics
+	return ics, nil
 }
 
 func (n *Neutron) DeleteVpcConnection(id string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"security_group_id": id}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("vpc-connections/%s", id)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteVpcConnections() {
-	vcs := n.ListVpcConnections()
+func (n *Neutron) DeleteVpcConnections() error {
+	vcs, err := n.ListVpcConnections(entity.ListVpcConnectionsOpts{TenantId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.VpcConnection, len(vcs.Vcs))
 	for _, vc := range vcs.Vcs {
+		if !n.selects(vc) {
+			continue
+		}
 		tempVc := vc
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteVpcConnection(tempVc.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Vpc connections were deleted completely")
+	return nil
 }
 
 // ac plugin api
 
 // compare_results
 
-func (n *Neutron) GetCompareResults() {
+func (n *Neutron) GetCompareResults() error {
 	urlSuffix := "compare_results"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (n *Neutron) GetSyncResults() {
+func (n *Neutron) GetSyncResults() error {
 	urlSuffix := "sync_results"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	var syncResults entity.SyncResultsMap
 	_ = json.Unmarshal(resp, &syncResults)
 	log.Println("==============List sync results success")
+	return nil
 }
 
-func (n *Neutron) GetSyncSummaryResults() {
+func (n *Neutron) GetSyncSummaryResults() error {
 	urlSuffix := "sync_results?sync_summary=true"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	var syncResults entity.SyncSummaryResult
 	_ = json.Unmarshal(resp, &syncResults)
 	log.Println("==============List sync summary results success")
+	return nil
 }
 
 
-func (n *Neutron) ResourceSync() {
+func (n *Neutron) ResourceSync() error {
 	urlSuffix := "resourcesyncs"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	log.Println("==============List resource sync success")
+	return nil
 }
 
-func (n *Neutron) ListAcConfig() {
+func (n *Neutron) ListAcConfig() error {
 	urlSuffix := "ac_config"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	log.Println("==============List ac config success")
+	return nil
 }
 
-func (n *Neutron) CreateAcConfig() {
+func (n *Neutron) CreateAcConfig() error {
 	urlSuffix := "ac_config"
-	resp := n.Post(n.Headers, urlSuffix, "{\"ac_config\": {}}")
+	resp, err := n.Post(n.Headers, urlSuffix, "{\"ac_config\": {}}")
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	log.Println("==============Create ac config success")
+	return nil
 }
 
 // network quota
 
-func (n *Neutron) ListQuotas()  {
+func (n *Neutron) ListQuotas() error {
 	urlSuffix := "quotas"
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (n *Neutron) ListQuotaForProject()  {
+func (n *Neutron) ListQuotaForProject() error {
     urlSuffix := fmt.Sprintf("quotas/%s", n.projectId)
-    resp := n.Get(n.Headers, urlSuffix)
+    resp, err := n.Get(n.Headers, urlSuffix)
+    if err != nil {
+        return err
+    }
     log.Println(string(resp))
+	return nil
 }
 
-func (n *Neutron) UpdateQuotaForProject() {
+func (n *Neutron) UpdateQuotaForProject() error {
 	urlSuffix := fmt.Sprintf("quotas/%s", n.projectId)
 	opts := entity.NetworkQuotaMap{NetworkQuota: entity.NetworkQuota{
 		Network: -1,
 		Firewall: -1,
 	}}
     reqBody := opts.ToRequestBody()
-	resp := n.Put(n.Headers, urlSuffix, reqBody)
+	resp, err := n.Put(n.Headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (n *Neutron) ListDefaultQuotaForProject()  {
+func (n *Neutron) ListDefaultQuotaForProject() error {
 	urlSuffix := fmt.Sprintf("quotas/%s/default", n.projectId)
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (n *Neutron) ResetQuotaForProject() {
+func (n *Neutron) ResetQuotaForProject() error {
 	urlSuffix := fmt.Sprintf("quotas/%s", n.projectId)
-	n.Delete(n.Headers, urlSuffix)
+	return n.Delete(n.Headers, urlSuffix)
 }
 
-func (n *Neutron) ShowQuotaDetailsForProject()  {
+func (n *Neutron) ShowQuotaDetailsForProject() error {
 	//urlSuffix := fmt.Sprintf("quotas/%s/details", n.projectId)
 	urlSuffix := "quotas/7e8babd4464e4c6da382a1a29d8da53a/details"
-	resp := n.Get(n.Headers, urlSuffix)
+	resp, err := n.Get(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
 // service providers
 
-func (n *Neutron) ListServiceProviders() {
+func (n *Neutron) ListServiceProviders() error {
 	urlSuffix := "service-providers"
-	resp := n.List(n.Headers, urlSuffix)
+	resp, err := n.List(n.Headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
 // SNAT
 
-func (n *Neutron) CreateSnat(opts *entity.Snat) entity.SnatMap {
+func (n *Neutron) CreateSnat(opts *entity.Snat) (entity.SnatMap, error) {
 	urlSuffix := consts.Snats
 	createBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, createBody)
+	resp, err := n.Post(n.Headers, urlSuffix, createBody)
+	if err != nil {
+		return entity.SnatMap{}, err
+	}
 	var snat entity.SnatMap
 	_ = json.Unmarshal(resp, &snat)
 
 	log.Printf("==============Create snat success %+v\n", snat)
-	return snat
+	return snat, nil
 }
 
-func (n *Neutron) ListSnats() entity.Snats {
-	urlSuffix := fmt.Sprintf("snats?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) ListSnats(opts entity.ListOpts) (entity.Snats, error) {
+	resp, err := n.ListBy(n.Headers, "snats", opts)
+	if err != nil {
+		return entity.Snats{}, err
+	}
 	var snats entity.Snats
 	_ = json.Unmarshal(resp, &snats)
 	log.Printf("snats==%+v\n", snats)
 	log.Println("==============List snats success, there had", len(snats.Ss))
-	return snats
+	return snats, nil
 }
 
 func (n *Neutron) DeleteSnat(snatId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"network_id": snatId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("snats/%s", snatId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteSnats() {
-	snats := n.ListSnats()
+func (n *Neutron) DeleteSnats() error {
+	snats, err := n.ListSnats(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.Snat, len(snats.Ss))
 
 	for _, snat := range snats.Ss {
+		if !n.selects(snat) {
+			continue
+		}
 		temp := snat
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteSnat(temp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Snats were deleted completely")
+	return nil
 }
 
-func (n *Neutron) CreateDnat(opts *entity.Dnat) entity.DnatMap {
+func (n *Neutron) CreateDnat(opts *entity.Dnat) (entity.DnatMap, error) {
 	urlSuffix := consts.Dnats
 	createBody := opts.ToRequestBody()
-	resp := n.Post(n.Headers, urlSuffix, createBody)
+	resp, err := n.Post(n.Headers, urlSuffix, createBody)
+	if err != nil {
+		return entity.DnatMap{}, err
+	}
 	var dnat entity.DnatMap
 	_ = json.Unmarshal(resp, &dnat)
 
 	log.Printf("==============Create dnat success %+v\n", dnat)
-	return dnat
+	return dnat, nil
 }
 
-func (n *Neutron) ListDnats() entity.Dnats {
-	urlSuffix := fmt.Sprintf("dnats?project_id=%s", n.projectId)
-	resp := n.List(n.Headers, urlSuffix)
+func (n *Neutron) ListDnats(opts entity.ListOpts) (entity.Dnats, error) {
+	resp, err := n.ListBy(n.Headers, "dnats", opts)
+	if err != nil {
+		return entity.Dnats{}, err
+	}
 	var dnats entity.Dnats
 	_ = json.Unmarshal(resp, &dnats)
 	log.Printf("dnats==%+v\n", dnats)
 	log.Println("==============List snats success, there had", len(dnats.Ds))
-	return dnats
+	return dnats, nil
 }
 
 func (n *Neutron) DeleteDnat(dnatId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"network_id": dnatId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("dnats/%s", dnatId)
-	outputObj.Success, outputObj.Response = n.Delete(n.Headers, urlSuffix)
+	outputObj.setErr(n.Delete(n.Headers, urlSuffix))
 	return outputObj
 }
 
-func (n *Neutron) DeleteDnats() {
-	snats := n.ListDnats()
+func (n *Neutron) DeleteDnats() error {
+	snats, err := n.ListDnats(entity.ListOpts{ProjectId: n.projectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.MakeDeleteChannel(consts.Dnat, len(snats.Ds))
 
 	for _, snat := range snats.Ds {
+		if !n.selects(snat) {
+			continue
+		}
 		temp := snat
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteDnat(temp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Dnats were deleted completely")
-}
\ No newline at end of file
+	return nil
+}
+
+// GetAttributes returns the attributes of the resource at urlSuffix, the
+// object under key of the response.
+func (n *Neutron) GetAttributes(urlSuffix, key string) (map[string]interface{}, error) {
+	return n.getAttributes(n.Headers, urlSuffix, key)
+}
+
+// SetAttributes updates the attributes of the resource at urlSuffix, attrs
+// are sent under key.
+func (n *Neutron) SetAttributes(urlSuffix, key string, attrs map[string]interface{}) error {
+	return n.setAttributes(n.Headers, urlSuffix, key, attrs)
+}
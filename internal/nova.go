@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	"log"
@@ -49,6 +51,7 @@ func NewNova(options ...Option) *Nova {
 	headers[consts.AuthToken] = opts.Token
 	nova.headers = headers
 	nova.Request = opts.Request
+	nova.Service = consts.NOVA
 	nova.snowflake = opts.Snowflake
 	return nova
 }
@@ -67,72 +70,88 @@ func (n *Nova) makeDeleteChannel(resourceType string, length int) chan Output {
 }
 
 // GetPciDevicesByHost 获取GPU等外接设备
-func (n *Nova) GetPciDevicesByHost()  {
-	resp := n.Get(n.headers, "/os-pci-devices?node_name=com04.vim1.local&host_name=com04.vim1.local")
+func (n *Nova) GetPciDevicesByHost() error {
+	resp, err := n.Get(n.headers, "/os-pci-devices?node_name=com04.vim1.local&host_name=com04.vim1.local")
+	if err != nil {
+		return err
+	}
 	fmt.Println(resp)
+	return nil
 }
 
 
 // GetComputeServices 获取计算服务
-func (n *Nova) GetComputeServices() entity.ComputeServices {
-	resp := n.Get(n.headers, "/os-services")
+func (n *Nova) GetComputeServices() (entity.ComputeServices, error) {
 	var services entity.ComputeServices
+	resp, err := n.Get(n.headers, "/os-services")
+	if err != nil {
+		return services, err
+	}
 	_ = json.Unmarshal(resp, &services)
-	return services
+	return services, nil
 }
 
-func (n *Nova) GetInstanceDetail(instanceId string) (server *entity.ServerMap) {
-	resp := n.Get(n.headers, fmt.Sprintf("servers/%s", instanceId))
-	if resp != nil {
-		_ = json.Unmarshal(resp, &server)
-
-		//cache.RedisClient.SetMap(n.tag + consts.INSTANCES, server.Server.Id, server)
+func (n *Nova) GetInstanceDetail(instanceId string) (*entity.ServerMap, error) {
+	resp, err := n.Get(n.headers, fmt.Sprintf("servers/%s", instanceId))
+	if err != nil {
+		return nil, err
 	}
-	return server
+	var server *entity.ServerMap
+	_ = json.Unmarshal(resp, &server)
+
+	//cache.RedisClient.SetMap(n.tag + consts.INSTANCES, server.Server.Id, server)
+	return server, nil
 }
 
-func (n *Nova) makeSureInstanceActive(instanceId string) {
-	instance := n.GetInstanceDetail(instanceId)
-	if instance == nil {
-		time.Sleep(2 * time.Second)
-		for instance == nil {
-			instance = n.GetInstanceDetail(instanceId)
-			time.Sleep(2 * time.Second)
-		}
-	}
+func (n *Nova) makeSureInstanceActive(instanceId string) error {
 	timeout := 2 * 60 * time.Second
-	done := make(chan bool, 1)
-	go func() {
-		state := instance.Server.Status
-		for state != "ACTIVE" {
-			time.Sleep(10 * time.Second)
-			instance = n.GetInstanceDetail(instanceId)
-			state =instance.Server.Status
+	err := Poll(n.Context(), timeout, 10 * time.Second, func() (bool, error) {
+		// the instance may not be visible right after the POST returns
+		instance, err := n.GetInstanceDetail(instanceId)
+		if IsNotFound(err) {
+			return false, nil
 		}
-		done <- true
-	}()
-	select {
-	case <-done:
-		log.Println("*******************Create instance success")
-	case <-time.After(timeout):
+		if err != nil {
+			return false, err
+		}
+		switch instance.Server.Status {
+		case consts.ACTIVE:
+			return true, nil
+		case "ERROR":
+			return false, fmt.Errorf("instance %s went into ERROR state", instanceId)
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
 		log.Println("*******************Create instance timeout")
+		return fmt.Errorf("instance %s not ACTIVE after %s: %w", instanceId, timeout, err)
+	}
+	if err != nil {
+		return err
 	}
+	log.Println("*******************Create instance success")
+	return nil
 }
 
-func (n *Nova) CreateInstance(opts *entity.CreateInstanceOpts) string {
+func (n *Nova) CreateInstance(opts *entity.CreateInstanceOpts) (string, error) {
 	urlSuffix := "servers"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.SERVER)
 	opts.UserData = "Q29udGVudC1UeXBlOiBtdWx0aXBhcnQvbWl4ZWQ7IGJvdW5kYXJ5PSI9PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0iIApNSU1FLVZlcnNpb246IDEuMAoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0KQ29udGVudC1UeXBlOiB0ZXh0L2Nsb3VkLWNvbmZpZzsgY2hhcnNldD0idXMtYXNjaWkiIApNSU1FLVZlcnNpb246IDEuMApDb250ZW50LVRyYW5zZmVyLUVuY29kaW5nOiA3Yml0CkNvbnRlbnQtRGlzcG9zaXRpb246IGF0dGFjaG1lbnQ7IGZpbGVuYW1lPSJzc2gtcHdhdXRoLXNjcmlwdC50eHQiIAoKI2Nsb3VkLWNvbmZpZwpkaXNhYmxlX3Jvb3Q6IGZhbHNlCnNzaF9wd2F1dGg6IHRydWUKcGFzc3dvcmQ6IFdhbmcuMTIzCgotLT09PT09PT09PT09PT09PTIzMDk5ODQwNTk3NDM3NjI0NzU9PQpDb250ZW50LVR5cGU6IHRleHQveC1zaGVsbHNjcmlwdDsgY2hhcnNldD0idXMtYXNjaWkiIApNSU1FLVZlcnNpb246IDEuMApDb250ZW50LVRyYW5zZmVyLUVuY29kaW5nOiA3Yml0CkNvbnRlbnQtRGlzcG9zaXRpb246IGF0dGFjaG1lbnQ7IGZpbGVuYW1lPSJwYXNzd2Qtc2NyaXB0LnR4dCIgCgojIS9iaW4vc2gKZWNobyAncm9vdDpXYW5nLjEyMycgfCBjaHBhc3N3ZAoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0KQ29udGVudC1UeXBlOiB0ZXh0L3gtc2hlbGxzY3JpcHQ7IGNoYXJzZXQ9InVzLWFzY2lpIiAKTUlNRS1WZXJzaW9uOiAxLjAKQ29udGVudC1UcmFuc2Zlci1FbmNvZGluZzogN2JpdApDb250ZW50LURpc3Bvc2l0aW9uOiBhdHRhY2htZW50OyBmaWxlbmFtZT0iZW5hYmxlLWZzLWNvbGxlY3Rvci50eHQiIAoKIyEvYmluL3NoCnFlbXVfZmlsZT0iL2V0Yy9zeXNjb25maWcvcWVtdS1nYSIKaWYgWyAtZiAke3FlbXVfZmlsZX0gXTsgdGhlbgogICAgc2VkIC1pIC1yICJzL14jP0JMQUNLTElTVF9SUEM9LyNCTEFDS0xJU1RfUlBDPS8iICIke3FlbXVfZmlsZX0iCiAgICBoYXNfZ3FhPSQoc3lzdGVtY3RsIGxpc3QtdW5pdHMgLS1mdWxsIC1hbGwgLXQgc2VydmljZSAtLXBsYWluIHwgZ3JlcCAtbyBxZW11LWd1ZXN0LWFnZW50LnNlcnZpY2UpCiAgICBpZiBbWyAtbiAke2hhc19ncWF9IF1dOyB0aGVuCiAgICAgICAgc3lzdGVtY3RsIHJlc3RhcnQgcWVtdS1ndWVzdC1hZ2VudC5zZXJ2aWNlCiAgICBmaQpmaQoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0tLQ=="
     reqBody := opts.ToRequestBody()
-    resp := n.Post(n.headers, urlSuffix, reqBody)
+    resp, err := n.Post(n.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return "", err
+	}
     var server entity.ServerMap
     _ = json.Unmarshal(resp, &server)
 	instanceId := server.Server.Id
 
     //cache.RedisClient.SetMap(n.tag + consts.INSTANCES, instanceId, server)
 	log.Println("==============Create instance success", instanceId)
-	n.makeSureInstanceActive(instanceId)
-    return instanceId
+	if err = n.makeSureInstanceActive(instanceId); err != nil {
+		return instanceId, err
+	}
+    return instanceId, nil
 }
 
 //func (n *Nova) constructPostBody(imageRef, flavorRef, networkId string) string {
@@ -141,118 +160,151 @@ func (n *Nova) CreateInstance(opts *entity.CreateInstanceOpts) string {
 //    return reqBody
 //}
 
-func (n *Nova) listInstancesByProject() entity.Servers {
-	urlSuffix := fmt.Sprintf("servers/detail?all_tenants=True&tenant_id=%s", n.ProjectId)
-    resp := n.List(n.headers, urlSuffix)
+func (n *Nova) listInstances(opts entity.ListServersOpts) (entity.Servers, error) {
     var instances entity.Servers
+    resp, err := n.ListBy(n.headers, "servers/detail", opts)
+    if err != nil {
+    	return instances, err
+	}
     _ = json.Unmarshal(resp, &instances)
 	log.Println("==============List instance success, there had", len(instances.Servers))
-    return instances
+    return instances, nil
 }
 
 func (n *Nova) DeleteInstance(instanceId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"instance_id": instanceId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("servers/%s/action", instanceId)
 	forceDelete := `{"forceDelete": null}`
-	n.Post(n.headers, urlSuffix, forceDelete)
-	instance := n.GetInstanceDetail(instanceId)
-	for instance != nil {
-		time.Sleep(5 * time.Second)
-		instance = n.GetInstanceDetail(instanceId)
+	if _, err := n.Post(n.headers, urlSuffix, forceDelete); err != nil && !IsNotFound(err) {
+		outputObj.setErr(err)
+		return outputObj
+	}
+	err := Poll(n.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		_, err := n.GetInstanceDetail(instanceId)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("instance %s not deleted after %s: %w", instanceId, consts.Timeout, err)
+	}
+	if err != nil {
+		outputObj.Response = err
+		return outputObj
 	}
 	outputObj.Success, outputObj.Response = true, ""
 	return outputObj
 }
 
-func (n *Nova) DeleteServers() {
-	instances := n.listInstancesByProject()
+func (n *Nova) DeleteServers() error {
+	instances, err := n.listInstances(entity.ListServersOpts{AllTenants: true, TenantId: n.ProjectId})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := n.makeDeleteChannel(consts.SERVER, len(instances.Servers))
 	for _, instance := range instances.Servers {
+		if !n.selects(instance) {
+			continue
+		}
 		tempInstance := instance
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- n.DeleteInstance(tempInstance.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Instances were deleted completely")
+	return nil
 }
 
-func (n *Nova) CreateMultipleInstances(opts entity.CreateInstanceOpts) {
+func (n *Nova) CreateMultipleInstances(opts entity.CreateInstanceOpts) error {
 	urlSuffix := "servers"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name + strconv.FormatUint(n.snowflake.NextVal(), 10), consts.SERVER)
 	opts.UserData = "Q29udGVudC1UeXBlOiBtdWx0aXBhcnQvbWl4ZWQ7IGJvdW5kYXJ5PSI9PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0iIApNSU1FLVZlcnNpb246IDEuMAoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0KQ29udGVudC1UeXBlOiB0ZXh0L2Nsb3VkLWNvbmZpZzsgY2hhcnNldD0idXMtYXNjaWkiIApNSU1FLVZlcnNpb246IDEuMApDb250ZW50LVRyYW5zZmVyLUVuY29kaW5nOiA3Yml0CkNvbnRlbnQtRGlzcG9zaXRpb246IGF0dGFjaG1lbnQ7IGZpbGVuYW1lPSJzc2gtcHdhdXRoLXNjcmlwdC50eHQiIAoKI2Nsb3VkLWNvbmZpZwpkaXNhYmxlX3Jvb3Q6IGZhbHNlCnNzaF9wd2F1dGg6IHRydWUKcGFzc3dvcmQ6IFdhbmcuMTIzCgotLT09PT09PT09PT09PT09PTIzMDk5ODQwNTk3NDM3NjI0NzU9PQpDb250ZW50LVR5cGU6IHRleHQveC1zaGVsbHNjcmlwdDsgY2hhcnNldD0idXMtYXNjaWkiIApNSU1FLVZlcnNpb246IDEuMApDb250ZW50LVRyYW5zZmVyLUVuY29kaW5nOiA3Yml0CkNvbnRlbnQtRGlzcG9zaXRpb246IGF0dGFjaG1lbnQ7IGZpbGVuYW1lPSJwYXNzd2Qtc2NyaXB0LnR4dCIgCgojIS9iaW4vc2gKZWNobyAncm9vdDpXYW5nLjEyMycgfCBjaHBhc3N3ZAoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0KQ29udGVudC1UeXBlOiB0ZXh0L3gtc2hlbGxzY3JpcHQ7IGNoYXJzZXQ9InVzLWFzY2lpIiAKTUlNRS1WZXJzaW9uOiAxLjAKQ29udGVudC1UcmFuc2Zlci1FbmNvZGluZzogN2JpdApDb250ZW50LURpc3Bvc2l0aW9uOiBhdHRhY2htZW50OyBmaWxlbmFtZT0iZW5hYmxlLWZzLWNvbGxlY3Rvci50eHQiIAoKIyEvYmluL3NoCnFlbXVfZmlsZT0iL2V0Yy9zeXNjb25maWcvcWVtdS1nYSIKaWYgWyAtZiAke3FlbXVfZmlsZX0gXTsgdGhlbgogICAgc2VkIC1pIC1yICJzL14jP0JMQUNLTElTVF9SUEM9LyNCTEFDS0xJU1RfUlBDPS8iICIke3FlbXVfZmlsZX0iCiAgICBoYXNfZ3FhPSQoc3lzdGVtY3RsIGxpc3QtdW5pdHMgLS1mdWxsIC1hbGwgLXQgc2VydmljZSAtLXBsYWluIHwgZ3JlcCAtbyBxZW11LWd1ZXN0LWFnZW50LnNlcnZpY2UpCiAgICBpZiBbWyAtbiAke2hhc19ncWF9IF1dOyB0aGVuCiAgICAgICAgc3lzdGVtY3RsIHJlc3RhcnQgcWVtdS1ndWVzdC1hZ2VudC5zZXJ2aWNlCiAgICBmaQpmaQoKLS09PT09PT09PT09PT09PT0yMzA5OTg0MDU5NzQzNzYyNDc1PT0tLQ=="
     reqBody := opts.ToRequestBody()
     n.headers["OpenStack-API-Version"] = "compute 2.74"
-    resp := n.Post(n.headers, urlSuffix, reqBody)
+    resp, err := n.Post(n.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return err
+	}
 
     log.Println(string(resp))
+    return nil
 }
 
-func (n *Nova) GetComputeHosts() []string {
-    services := n.GetComputeServices()
+func (n *Nova) GetComputeHosts() ([]string, error) {
+    services, err := n.GetComputeServices()
+    if err != nil {
+    	return nil, err
+	}
     var hosts []string
     for _, service := range services.Services {
     	if service.Binary == "nova-compute" {
     		hosts = append(hosts, service.Host)
 		}
 	}
-	return hosts
+	return hosts, nil
 }
 
-func (n *Nova) CreateAggregate(opts entity.CreateAggregateOpts) int {
+func (n *Nova) CreateAggregate(opts entity.CreateAggregateOpts) (int, error) {
 	urlSuffix := "os-aggregates"
     reqBody := opts.ToRequestBody()
-    resp := n.Post(n.headers, urlSuffix, reqBody)
+    resp, err := n.Post(n.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return 0, err
+	}
     var aggregate entity.AggregateMap
     _ = json.Unmarshal(resp, &aggregate)
 	log.Println("==============Create aggregate success", aggregate.Aggregate.Id)
-    return aggregate.Aggregate.Id
+    return aggregate.Aggregate.Id, nil
 }
 
-func (n *Nova) AggregateAddHost(aggregateId int, opts entity.AddHostOpts) int {
+func (n *Nova) AggregateAddHost(aggregateId int, opts entity.AddHostOpts) (int, error) {
 	urlSuffix := fmt.Sprintf("os-aggregates/%d/action", aggregateId)
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.headers, urlSuffix, reqBody)
+	if err != nil {
+		return 0, err
+	}
 	var aggregate entity.AggregateMap
 	_ = json.Unmarshal(resp, &aggregate)
 	log.Println("==============Aggregate add host success", aggregate.Aggregate.Id)
-	return aggregate.Aggregate.Id
+	return aggregate.Aggregate.Id, nil
 }
 
-func (n *Nova) AggregateSetMetadata(aggregateId int, opts entity.SetMetadataOpts) int {
+func (n *Nova) AggregateSetMetadata(aggregateId int, opts entity.SetMetadataOpts) (int, error) {
 	urlSuffix := fmt.Sprintf("os-aggregates/%d/action", aggregateId)
 	reqBody := opts.ToRequestBody()
-	resp := n.Post(n.headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.headers, urlSuffix, reqBody)
+	if err != nil {
+		return 0, err
+	}
 	var aggregate entity.AggregateMap
 	_ = json.Unmarshal(resp, &aggregate)
 	log.Println("==============Aggregate set metadata success", aggregate.Aggregate.Id)
-	return aggregate.Aggregate.Id
+	return aggregate.Aggregate.Id, nil
 }
 
 // CreateConsole create a new console api
-func (n *Nova) CreateConsole(serverId string, opts entity.CreateConsoleOpts) entity.RemoteConsoleMap {
+func (n *Nova) CreateConsole(serverId string, opts entity.CreateConsoleOpts) (entity.RemoteConsoleMap, error) {
     urlSuffix := fmt.Sprintf("servers/%s/remote-consoles", serverId)
     reqBody := opts.ToRequestBody()
-    resp := n.Post(n.headers, urlSuffix, reqBody)
-
     var console entity.RemoteConsoleMap
+    resp, err := n.Post(n.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return console, err
+	}
+
     _ = json.Unmarshal(resp, &console)
 	log.Println("==============Create console success", serverId)
-    return console
+    return console, nil
 }
 
 // AttachVolume attach volume
-func (n *Nova) AttachVolume(instanceId, volumeId string) {
+func (n *Nova) AttachVolume(instanceId, volumeId string) error {
 	urlSuffix := fmt.Sprintf("servers/%s/os-volume_attachments", instanceId)
 	formatter := `{
 		"volumeAttachment": {
@@ -260,38 +312,78 @@ func (n *Nova) AttachVolume(instanceId, volumeId string) {
 		}
 	}`
 	reqBody := fmt.Sprintf(formatter, volumeId)
-	resp := n.Post(n.headers, urlSuffix, reqBody)
+	resp, err := n.Post(n.headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 
 	log.Println("==============Attach Volume success", string(resp))
+	return nil
 }
 
 // DetachVolume detach volume
-func (n *Nova) DetachVolume(instanceId, volumeId string) {
+func (n *Nova) DetachVolume(instanceId, volumeId string) error {
 	urlSuffix := fmt.Sprintf("servers/%s/os-volume_attachments/%s", instanceId, volumeId)
-	if ok, _ := n.Delete(n.headers, urlSuffix); ok {
-		log.Println("==============Detach volume success", instanceId)
-		return
+	if err := n.Delete(n.headers, urlSuffix); err != nil {
+		log.Println("==============Detach volume failed", instanceId)
+		return err
+	}
+	log.Println("==============Detach volume success", instanceId)
+	return nil
+}
+
+// SetServerTags replaces the tags of the server, server tags need
+// microversion 2.26.
+func (n *Nova) SetServerTags(instanceId string, tags []string) error {
+	headers, err := n.MicroversionHeaders(n.headers, "2.26")
+	if err != nil {
+		return err
+	}
+	reqBody, _ := json.Marshal(map[string][]string{"tags": tags})
+	if _, err = n.Put(headers, fmt.Sprintf("servers/%s/tags", instanceId), string(reqBody)); err != nil {
+		return err
+	}
+	log.Println("==============Set server tags success", instanceId)
+	return nil
+}
+
+func (n *Nova) ListServerTags(instanceId string) ([]string, error) {
+	headers, err := n.MicroversionHeaders(n.headers, "2.26")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := n.Get(headers, fmt.Sprintf("servers/%s/tags", instanceId))
+	if err != nil {
+		return nil, err
 	}
-	log.Println("==============Detach volume failed", instanceId)
+	var tags struct {
+		Tags []string `json:"tags"`
+	}
+	_ = json.Unmarshal(resp, &tags)
+	return tags.Tags, nil
 }
 
-func (n *Nova) CreateFlavorExtraSpecs(flavorId string) {
+func (n *Nova) CreateFlavorExtraSpecs(flavorId string) error {
     urlSuffix := fmt.Sprintf("flavors/%s/os-extra_specs", flavorId)
     formatter := `{
                      "extra_specs": {
                          "pci_passthrough:alias": "a4:1"
                      }
                   }`
-    resp := n.Post(n.headers, urlSuffix, formatter)
+    resp, err := n.Post(n.headers, urlSuffix, formatter)
+    if err != nil {
+    	return err
+	}
     log.Println(string(resp))
 	log.Println("==============Create extra specs success", flavorId)
+	return nil
 }
 
 func (n *Nova) GetDBBDM(instanceId string) {
 
 }
 
-func (n *Nova) CreateInstanceSnapshot(instanceId string) string {
+func (n *Nova) CreateInstanceSnapshot(instanceId string) (string, error) {
 	urlSuffix := fmt.Sprintf("servers/%s/action", instanceId)
 	forceDelete := `{
     "createImage" : {
@@ -301,13 +393,16 @@ func (n *Nova) CreateInstanceSnapshot(instanceId string) string {
         }
     }}`
 	var imageSnapshot entity.ImageSnapshot
-	res := n.Post(n.headers, urlSuffix, forceDelete)
+	res, err := n.Post(n.headers, urlSuffix, forceDelete)
+	if err != nil {
+		return "", err
+	}
 	_ = json.Unmarshal(res, &imageSnapshot)
-	return imageSnapshot.ImageId
+	return imageSnapshot.ImageId, nil
 }
 
 
-func (n *Nova) RebuildInstanceByVolume(instanceId, volumeId string) string {
+func (n *Nova) RebuildInstanceByVolume(instanceId, volumeId string) (string, error) {
 	urlSuffix := fmt.Sprintf("servers/%s/action", instanceId)
 	formatter := `{
     "rebuild" : {
@@ -315,7 +410,22 @@ func (n *Nova) RebuildInstanceByVolume(instanceId, volumeId string) string {
     }}`
 	reqBody := fmt.Sprintf(formatter, volumeId)
 	var imageSnapshot entity.ImageSnapshot
-	res := n.Post(n.headers, urlSuffix, reqBody)
+	res, err := n.Post(n.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	_ = json.Unmarshal(res, &imageSnapshot)
-	return imageSnapshot.ImageId
+	return imageSnapshot.ImageId, nil
+}
+
+// GetAttributes returns the attributes of the resource at urlSuffix, the
+// object under key of the response.
+func (n *Nova) GetAttributes(urlSuffix, key string) (map[string]interface{}, error) {
+	return n.getAttributes(n.headers, urlSuffix, key)
+}
+
+// SetAttributes updates the attributes of the resource at urlSuffix, attrs
+// are sent under key.
+func (n *Nova) SetAttributes(urlSuffix, key string, attrs map[string]interface{}) error {
+	return n.setAttributes(n.headers, urlSuffix, key, attrs)
 }
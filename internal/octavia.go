@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/valyala/fasthttp"
 	"log"
@@ -39,8 +41,9 @@ type Octavia struct {
 func NewLB(token string, client *fasthttp.Client) *Octavia {
 	return &Octavia{
 		Request: Request{
-			UrlPrefix: fmt.Sprintf("http://%s:%d/v2.0/", configs.CONF.Host, consts.OctaviaPort),
+			UrlPrefix: DefaultEndpoint(consts.OCTAVIA),
 			Client: client,
+			Service: consts.OCTAVIA,
 		},
 		headers: map[string]string{"X-Auth-Token": token},
 		tag: configs.CONF.Host + "_",
@@ -60,503 +63,702 @@ func (o *Octavia) MakeDeleteChannel(resourceType string, length int) chan Output
 
 // load balancer
 
-func (o *Octavia) CreateLoadbalancer(opts entity.CreateLoadbalancerOpts) string {
+func (o *Octavia) CreateLoadbalancer(opts entity.CreateLoadbalancerOpts) (string, error) {
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name, consts.LOADBALANCER)
 	urlSuffix := "lbaas/loadbalancers"
 	reqBody := opts.ToRequestBody()
-	resp := o.Post(o.headers, urlSuffix, reqBody)
+	resp, err := o.Post(o.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var lb entity.LoadbalancerMap
 	_ = json.Unmarshal(resp, &lb)
 
 	//cache.RedisClient.SetMap(l.tag + consts.LOADBALANCERS, lb.Loadbalancer.Id, lb)
 
-	o.makeSureLbActive(lb.Loadbalancer.Id)
+	if _, err = o.makeSureLbActive(lb.Loadbalancer.Id); err != nil {
+		return lb.Loadbalancer.Id, err
+	}
 	log.Println("==============create loadbalancer success", lb.Loadbalancer.Id)
-	return lb.Loadbalancer.Id
+	return lb.Loadbalancer.Id, nil
 }
 
-func (o *Octavia) deleteLoadbalancer(ipId string) Output {
+func (o *Octavia) DeleteLoadbalancer(ipId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"loadbalancer_id": ipId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-			outputObj.Response = err
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("lbaas/loadbalancers/%s", ipId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
-    o.makeSureLbDeleted(ipId)
+	err := o.Delete(o.headers, urlSuffix)
+	if err == nil {
+		err = o.makeSureLbDeleted(ipId)
+	}
+	outputObj.setErr(err)
 	return outputObj
 }
 
-func (l *Octavia) getLoadbalancer(ipId string) entity.LoadbalancerMap {
+func (l *Octavia) getLoadbalancer(ipId string) (entity.LoadbalancerMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/loadbalancers/%s", ipId)
-	resp := l.Get(l.headers, urlSuffix)
 	var lb entity.LoadbalancerMap
+	resp, err := l.Get(l.headers, urlSuffix)
+	if err != nil {
+		return lb, err
+	}
 	_ = json.Unmarshal(resp, &lb)
-	return lb
+	return lb, nil
 }
 
-func (l *Octavia) ListLoadbalancers() entity.Loadbalancers {
-	urlSuffix := "lbaas/loadbalancers"
-	resp := l.List(l.headers, urlSuffix)
+func (l *Octavia) ListLoadbalancers(opts entity.ListLoadbalancersOpts) (entity.Loadbalancers, error) {
 	var lbs entity.Loadbalancers
+	resp, err := l.ListBy(l.headers, "lbaas/loadbalancers", opts)
+	if err != nil {
+		return lbs, err
+	}
 	_ = json.Unmarshal(resp, &lbs)
 	log.Println("==============List loadbalancers success, there had", len(lbs.LBs))
-	return lbs
+	return lbs, nil
 }
 
-func (o *Octavia) DeleteLoadbalancers() {
-	lbs := o.ListLoadbalancers()
+func (o *Octavia) DeleteLoadbalancers() error {
+	lbs, err := o.ListLoadbalancers(entity.ListLoadbalancersOpts{})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.LOADBALANCER, len(lbs.LBs))
 
 	for _, lb := range lbs.LBs {
+		if !o.selects(lb) {
+			continue
+		}
 		tempLb := lb
+		wg.Add(1)
 		go func() {
-			ch <- o.deleteLoadbalancer(tempLb.Id)
+			defer wg.Done()
+			ch <- o.DeleteLoadbalancer(tempLb.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Loadbalancers were deleted completely")
+	return nil
 }
 
 // listener
 
-func (o *Octavia) CreateListener(opts entity.CreateListenerOpts) string {
+func (o *Octavia) CreateListener(opts entity.CreateListenerOpts) (string, error) {
 	urlSuffix := "lbaas/listeners"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name, consts.LISTENER)
 	reqBody := opts.ToRequestBody()
-	resp := o.Post(o.headers, urlSuffix, reqBody)
+	resp, err := o.Post(o.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var listener entity.ListenerMap
 	_ = json.Unmarshal(resp, &listener)
 
 	//cache.RedisClient.SetMap(l.tag + consts.LISTENERS, listener.Listener.Id, listener)
 
-	o.makeSureLbActive(opts.LoadbalancerID)
+	if _, err = o.makeSureLbActive(opts.LoadbalancerID); err != nil {
+		return listener.Listener.Id, err
+	}
 	log.Println("==============Create listener success", listener.Listener.Id)
-	return listener.Listener.Id
+	return listener.Listener.Id, nil
 }
 
 func (o *Octavia) deleteListener(listenerId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"listener_id": listenerId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("lbaas/listeners/%s", listenerId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
+	outputObj.setErr(o.Delete(o.headers, urlSuffix))
 	return outputObj
 }
 
-func (o *Octavia) getListener(ipId string) entity.ListenerMap {
+func (o *Octavia) getListener(ipId string) (entity.ListenerMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/listeners/%s", ipId)
-	resp := o.Get(o.headers, urlSuffix)
 	var listener entity.ListenerMap
+	resp, err := o.Get(o.headers, urlSuffix)
+	if err != nil {
+		return listener, err
+	}
 	_ = json.Unmarshal(resp, &listener)
-	return listener
+	return listener, nil
 }
 
-func (o *Octavia) ListListeners() entity.Listeners {
-	urlSuffix := "lbaas/listeners"
-	resp := o.List(o.headers, urlSuffix)
+func (o *Octavia) ListListeners(opts entity.ListListenersOpts) (entity.Listeners, error) {
 	var listeners entity.Listeners
+	resp, err := o.ListBy(o.headers, "lbaas/listeners", opts)
+	if err != nil {
+		return listeners, err
+	}
 	_ = json.Unmarshal(resp, &listeners)
 	log.Println("==============List listeners success, there had", len(listeners.Liss))
-	return listeners
+	return listeners, nil
 }
 
-func (o *Octavia) DeleteListeners() {
-	listeners := o.ListListeners()
+func (o *Octavia) DeleteListeners() error {
+	listeners, err := o.ListListeners(entity.ListListenersOpts{})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.LISTENER, len(listeners.Liss))
 
 	for _, listener := range listeners.Liss {
+		if !o.selects(listener) {
+			continue
+		}
 		temp := listener
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- o.deleteListener(temp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Listeners were deleted completely")
+	return nil
 }
 
-func (o *Octavia) makeSureLbActive(lbId string) entity.LoadbalancerMap {
-	lb := o.getLoadbalancer(lbId)
-
-    for lb.Loadbalancer.ProvisioningStatus != consts.ACTIVE {
-    	time.Sleep(5 * time.Second)
-    	lb = o.getLoadbalancer(lbId)
+func (o *Octavia) makeSureLbActive(lbId string) (entity.LoadbalancerMap, error) {
+	var lb entity.LoadbalancerMap
+	err := Poll(o.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		var err error
+		if lb, err = o.getLoadbalancer(lbId); err != nil {
+			return false, err
+		}
+		switch lb.Loadbalancer.ProvisioningStatus {
+		case consts.ACTIVE:
+			return true, nil
+		case "ERROR":
+			return false, fmt.Errorf("loadbalancer %s went into ERROR provisioning status", lbId)
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return lb, fmt.Errorf("loadbalancer %s not ACTIVE after %s: %w", lbId, consts.Timeout, err)
+	}
+	if err != nil {
+		return lb, err
 	}
-	return lb
+	return lb, nil
 }
 
-func (o *Octavia) makeSureLbDeleted(lbId string) {
-	lb := o.getLoadbalancer(lbId)
-	for &lb != nil {
-		time.Sleep(5 * time.Second)
-		lb = o.getLoadbalancer(lbId)
+func (o *Octavia) makeSureLbDeleted(lbId string) error {
+	err := Poll(o.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		_, err := o.getLoadbalancer(lbId)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("loadbalancer %s not deleted after %s: %w", lbId, consts.Timeout, err)
+	}
+	if err != nil {
+		return err
 	}
 	log.Println("*******************Lb was deleted success")
+	return nil
 }
 
 // pool
 
-func (o *Octavia) CreatePool(opts entity.CreatePoolOpts) string {
+func (o *Octavia) CreatePool(opts entity.CreatePoolOpts) (string, error) {
 	urlSuffix := "lbaas/pools"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name, consts.POOL)
 	reqBody := opts.ToRequestBody()
-	resp := o.Post(o.headers, urlSuffix, reqBody)
+	resp, err := o.Post(o.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var pool entity.PoolMap
 	_ = json.Unmarshal(resp, &pool)
 
-	o.makeSurePoolActive(pool.Pool.Id)
+	if _, err = o.makeSurePoolActive(pool.Pool.Id); err != nil {
+		return pool.Pool.Id, err
+	}
 	for _, lb := range pool.Pool.Loadbalancers {
-		o.makeSureLbActive(lb.Id)
+		if _, err = o.makeSureLbActive(lb.Id); err != nil {
+			return pool.Pool.Id, err
+		}
 	}
 	log.Println("==============Create pool success", pool.Pool.Id)
-	return pool.Pool.Id
+	return pool.Pool.Id, nil
 }
 
 func (o *Octavia) deletePool(poolId string) Output {
-	pool := o.getPool(poolId)
 	outputObj := Output{ParametersMap: map[string]string{"pool_id": poolId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
+	pool, err := o.getPool(poolId)
+	if err != nil {
+		outputObj.setErr(err)
+		return outputObj
+	}
 
 	urlSuffix := fmt.Sprintf( "lbaas/pools/%s", poolId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
+	err = o.Delete(o.headers, urlSuffix)
 	for _, lb := range pool.Loadbalancers {
-		o.makeSureLbActive(lb.Id)
+		if err != nil {
+			break
+		}
+		_, err = o.makeSureLbActive(lb.Id)
 	}
+	outputObj.setErr(err)
 	return outputObj
 }
 
-func (l *Octavia) getPool(ipId string) entity.PoolMap {
+func (l *Octavia) getPool(ipId string) (entity.PoolMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/pools/%s", ipId)
-	resp := l.Get(l.headers, urlSuffix)
 	var pool entity.PoolMap
+	resp, err := l.Get(l.headers, urlSuffix)
+	if err != nil {
+		return pool, err
+	}
 	_ = json.Unmarshal(resp, &pool)
-	return pool
+	return pool, nil
 }
 
-func (l *Octavia) ListPools() entity.Pools {
-	urlSuffix := "lbaas/pools"
-	resp := l.List(l.headers, urlSuffix)
+func (l *Octavia) ListPools(opts entity.ListPoolsOpts) (entity.Pools, error) {
 	var pools entity.Pools
+	resp, err := l.ListBy(l.headers, "lbaas/pools", opts)
+	if err != nil {
+		return pools, err
+	}
 	_ = json.Unmarshal(resp, &pools)
 	log.Println("==============List pools success, there had", len(pools.Ps))
-	return pools
+	return pools, nil
 }
 
-func (o *Octavia) DeletePools() {
-	pools := o.ListPools()
+func (o *Octavia) DeletePools() error {
+	pools, err := o.ListPools(entity.ListPoolsOpts{})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.POOL, len(pools.Ps))
 	for _, pool := range pools.Ps {
+		if !o.selects(pool) {
+			continue
+		}
 		//for _, member := range pool.Members {
 		//	o.deletePoolMember(pool, member.(map[string]interface{})["id"].(string))
 		//}
 		temp := pool
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- o.deletePool(temp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Pool were deleted completely")
+	return nil
 }
 
-func (l *Octavia) makeSurePoolActive(poolId string) entity.PoolMap {
-	pool := l.getPool(poolId)
-
-	for pool.Pool.ProvisioningStatus != consts.ACTIVE {
-		time.Sleep(5 * time.Second)
-		pool = l.getPool(poolId)
+func (l *Octavia) makeSurePoolActive(poolId string) (entity.PoolMap, error) {
+	var pool entity.PoolMap
+	err := Poll(l.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		var err error
+		if pool, err = l.getPool(poolId); err != nil {
+			return false, err
+		}
+		switch pool.Pool.ProvisioningStatus {
+		case consts.ACTIVE:
+			return true, nil
+		case "ERROR":
+			return false, fmt.Errorf("pool %s went into ERROR provisioning status", poolId)
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return pool, fmt.Errorf("pool %s not ACTIVE after %s: %w", poolId, consts.Timeout, err)
+	}
+	if err != nil {
+		return pool, err
 	}
-	return pool
+	return pool, nil
 }
 
 // pool member
 
-func (o *Octavia) CreatePoolMember(poolId string, opts entity.CreateMemberOpts) string {
+func (o *Octavia) CreatePoolMember(poolId string, opts entity.CreateMemberOpts) (string, error) {
 	urlSuffix := fmt.Sprintf("lbaas/pools/%s/members", poolId)
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name, consts.POOL)
 	reqBody := opts.ToRequestBody()
-	resp := o.Post(o.headers, urlSuffix, reqBody)
+	resp, err := o.Post(o.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var member entity.MemberMap
 	_ = json.Unmarshal(resp, &member)
 
-	o.makeSurePoolActive(poolId)
+	if _, err = o.makeSurePoolActive(poolId); err != nil {
+		return member.Member.Id, err
+	}
 	log.Println("==============Create member success", member.Member.Id)
-	return member.Member.Id
+	return member.Member.Id, nil
 }
 
 func (o *Octavia) deletePoolMember(pool entity.Pool, memberId string) Output {
 	defer o.mu.Unlock()
 	o.mu.Lock()
 	outputObj := Output{ParametersMap: map[string]string{"member_id": memberId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
 
 	urlSuffix := fmt.Sprintf("lbaas/pools/%s/members/%s", pool.Id, memberId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
-	o.makeSurePoolActive(pool.Id)
+	err := o.Delete(o.headers, urlSuffix)
+	if err == nil {
+		_, err = o.makeSurePoolActive(pool.Id)
+	}
 	for _, lb := range pool.Loadbalancers {
-		o.makeSureLbActive(lb.Id)
+		if err != nil {
+			break
+		}
+		_, err = o.makeSureLbActive(lb.Id)
 	}
+	outputObj.setErr(err)
 	return outputObj
 }
 
-func (l *Octavia) getPoolMember(poolId, memberId string) entity.MemberMap {
+func (l *Octavia) getPoolMember(poolId, memberId string) (entity.MemberMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/pools/%s/members/%s", poolId, memberId)
-	resp := l.Get(l.headers, urlSuffix)
 	var member entity.MemberMap
+	resp, err := l.Get(l.headers, urlSuffix)
+	if err != nil {
+		return member, err
+	}
 	_ = json.Unmarshal(resp, &member)
-	return member
+	return member, nil
 }
 
-func (o *Octavia) ListPoolMembers(poolId string) entity.Members {
+func (o *Octavia) ListPoolMembers(poolId string) (entity.Members, error) {
 	urlSuffix := fmt.Sprintf("lbaas/pools/%s/members", poolId)
-	resp := o.List(o.headers, urlSuffix)
 	var ms entity.Members
+	resp, err := o.List(o.headers, urlSuffix)
+	if err != nil {
+		return ms, err
+	}
 	_ = json.Unmarshal(resp, &ms)
 	log.Println("==============List pool members success, there had", len(ms.Ms))
-	return ms
+	return ms, nil
 }
 
 
-func (o *Octavia) DeleteMembers() {
-	pools := o.ListPools()
+func (o *Octavia) DeleteMembers() error {
+	pools, err := o.ListPools(entity.ListPoolsOpts{})
+	if err != nil {
+		return err
+	}
 	var memberNumber int
 	for _, pool := range pools.Ps {
 		memberNumber += len(pool.Members)
 	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.MEMBER, memberNumber)
 	for _, pool := range pools.Ps {
+		if !o.selects(pool) {
+			continue
+		}
 		tempPool := pool
 		for _, member := range pool.Members {
 			temp := member
+			wg.Add(1)
 			go func() {
+				defer wg.Done()
 				ch <- o.deletePoolMember(tempPool, temp.Id)
 			}()
 		}
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("Pool members were deleted completely")
+	return nil
 }
 
 // health monitor
 
-func (l *Octavia) CreateHealthMonitor(opts entity.CreateHealthMonitorOpts) string {
+func (l *Octavia) CreateHealthMonitor(opts entity.CreateHealthMonitorOpts) (string, error) {
 	urlSuffix := "lbaas/healthmonitors"
 	opts.Name = fmt.Sprintf("%s_%s", opts.Name, consts.HEALTHMONITOR)
 	reqBody := opts.ToRequestBody()
-	resp := l.Post(l.headers, urlSuffix, reqBody)
+	resp, err := l.Post(l.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var healthMonitor entity.HealthMonitorMap
 	_ = json.Unmarshal(resp, &healthMonitor)
 
 	log.Println("==============Create health monitor success", healthMonitor.Healthmonitor.Id)
-	return healthMonitor.Healthmonitor.Id
+	return healthMonitor.Healthmonitor.Id, nil
 }
 
 func (o *Octavia) deleteHealthMonitor(healthmonitorId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"health_monitor_id": healthmonitorId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("lbaas/healthmonitors/%s", healthmonitorId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
+	outputObj.setErr(o.Delete(o.headers, urlSuffix))
 	return outputObj
 }
 
-func (l *Octavia) getHealthMonitor(healthmonitorId string) entity.HealthMonitorMap {
+func (l *Octavia) getHealthMonitor(healthmonitorId string) (entity.HealthMonitorMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/healthmonitors/%s", healthmonitorId)
-	resp := l.Get(l.headers, urlSuffix)
 	var healthmonitor entity.HealthMonitorMap
+	resp, err := l.Get(l.headers, urlSuffix)
+	if err != nil {
+		return healthmonitor, err
+	}
 	_ = json.Unmarshal(resp, &healthmonitor)
-	return healthmonitor
+	return healthmonitor, nil
 }
 
-func (l *Octavia) ListHealthMonitors() entity.HealthMonitors {
-	urlSuffix := "lbaas/healthmonitors"
-	resp := l.List(l.headers, urlSuffix)
+func (l *Octavia) ListHealthMonitors(opts entity.ListHealthMonitorsOpts) (entity.HealthMonitors, error) {
 	var hms entity.HealthMonitors
+	resp, err := l.ListBy(l.headers, "lbaas/healthmonitors", opts)
+	if err != nil {
+		return hms, err
+	}
 	_ = json.Unmarshal(resp, &hms)
 	log.Println("==============List health monitors success, there had", len(hms.HMs))
-	return hms
+	return hms, nil
 }
 
-func (o *Octavia) DeleteHealthmonitors() {
-	healthmonitors := o.ListHealthMonitors()
+func (o *Octavia) DeleteHealthmonitors() error {
+	healthmonitors, err := o.ListHealthMonitors(entity.ListHealthMonitorsOpts{})
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.HEALTHMONITOR, len(healthmonitors.HMs))
 	for _, healthmonitor := range healthmonitors.HMs {
+		if !o.selects(healthmonitor) {
+			continue
+		}
 		temp := healthmonitor
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- o.deleteHealthMonitor(temp.Id)
 		}()
 	}
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	for _, healthmonitor := range healthmonitors.HMs {
 		for _, pool := range healthmonitor.Pools {
-			o.makeSurePoolActive(pool.Id)
+			if _, err = o.makeSurePoolActive(pool.Id); err != nil {
+				return err
+			}
 		}
 	}
 	log.Println("Health monitors were deleted completely")
+	return nil
 }
 
 // L7 policy
 
-func (l *Octavia) CreateL7Policy(listenerId string) string {
+func (l *Octavia) CreateL7Policy(opts entity.CreateL7PoliciesOpts) (string, error) {
 	urlSuffix := "lbaas/l7policies"
-	reqBody := fmt.Sprintf("{\"l7policy\": {\"description\": \"Redirect requests to example.com\", \"admin_state_up\": true, \"listener_id\": \"%+v\", \"redirect_url\": \"http://www.example.com\", \"redirect_http_code\": 301, \"name\": \"redirect-example.com\", \"action\": \"REDIRECT_TO_URL\", \"position\": 1, \"tags\": [\"test_tag\"]}}", listenerId)
-	resp := l.Post(l.headers, urlSuffix, reqBody)
+	reqBody := opts.ToRequestBody()
+	resp, err := l.Post(l.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var l7policy entity.L7PolicyMap
 	_ = json.Unmarshal(resp, &l7policy)
 
 	//cache.RedisClient.SetMap(l.tag + consts.L7POLICIES, l7policy.L7Policy.Id, l7policy)
-	l.makeSureL7PolicyActive(l7policy.L7Policy.Id)
+	if _, err = l.makeSureL7PolicyActive(l7policy.L7Policy.Id); err != nil {
+		return l7policy.L7Policy.Id, err
+	}
 	log.Println("==============create l7policy success", l7policy.L7Policy.Id)
-	return l7policy.L7Policy.Id
+	return l7policy.L7Policy.Id, nil
 }
 
 func (o *Octavia) deleteL7Policy(l7policyId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"l7Policy_id": l7policyId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s", l7policyId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
+	outputObj.setErr(o.Delete(o.headers, urlSuffix))
 	return outputObj
 }
 
-func (l *Octavia) getL7Policy(l7policyId string) entity.L7PolicyMap {
+func (l *Octavia) getL7Policy(l7policyId string) (entity.L7PolicyMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s", l7policyId)
-	resp := l.Get(l.headers, urlSuffix)
 	var l7policy entity.L7PolicyMap
+	resp, err := l.Get(l.headers, urlSuffix)
+	if err != nil {
+		return l7policy, err
+	}
 	_ = json.Unmarshal(resp, &l7policy)
-	return l7policy
+	return l7policy, nil
 }
 
-func (l *Octavia) ListL7Policies() entity.L7Policies {
+func (l *Octavia) ListL7Policies() (entity.L7Policies, error) {
 	urlSuffix := "lbaas/l7policies"
-	resp := l.List(l.headers, urlSuffix)
 	var l7ps entity.L7Policies
+	resp, err := l.List(l.headers, urlSuffix)
+	if err != nil {
+		return l7ps, err
+	}
 	_ = json.Unmarshal(resp, &l7ps)
 	log.Println("==============List l7 policies success, there had", len(l7ps.L7Ps))
-	return l7ps
+	return l7ps, nil
 }
 
-func (l *Octavia) makeSureL7PolicyActive(l7PolicyId string) entity.L7PolicyMap {
-	l7Policy := l.getL7Policy(l7PolicyId)
-
-	for l7Policy.L7Policy.ProvisioningStatus != consts.ACTIVE {
-		time.Sleep(5 * time.Second)
-		l7Policy = l.getL7Policy(l7PolicyId)
+func (l *Octavia) makeSureL7PolicyActive(l7PolicyId string) (entity.L7PolicyMap, error) {
+	var l7Policy entity.L7PolicyMap
+	err := Poll(l.Context(), consts.Timeout, 5 * time.Second, func() (bool, error) {
+		var err error
+		if l7Policy, err = l.getL7Policy(l7PolicyId); err != nil {
+			return false, err
+		}
+		switch l7Policy.L7Policy.ProvisioningStatus {
+		case consts.ACTIVE:
+			return true, nil
+		case "ERROR":
+			return false, fmt.Errorf("l7 policy %s went into ERROR provisioning status", l7PolicyId)
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return l7Policy, fmt.Errorf("l7 policy %s not ACTIVE after %s: %w", l7PolicyId, consts.Timeout, err)
+	}
+	if err != nil {
+		return l7Policy, err
 	}
 	//l.SyncMap(l.tag + consts.L7POLICIES, l7PolicyId, nil, l7Policy)
-	return l7Policy
+	return l7Policy, nil
 }
 
-func (o *Octavia) DeleteL7Policies() {
-	l7policies := o.ListL7Policies()
+func (o *Octavia) DeleteL7Policies() error {
+	l7policies, err := o.ListL7Policies()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.L7POLICY, len(l7policies.L7Ps))
 	for _, l7policy := range l7policies.L7Ps {
+		if !o.selects(l7policy) {
+			continue
+		}
 		temp := l7policy
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			ch <- o.deleteL7Policy(temp.Id)
 		}()
 	}
 
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {}
-	}
+	wg.Wait()
 	log.Println("L7 policies were deleted completely")
+	return nil
 }
 
 // L7 rule
 
-func (l *Octavia) CreateL7Rule(policyId string) string {
-	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s/rules", policyId)
-	reqBody := fmt.Sprintf("{\"rule\": {\"compare_type\": \"REGEX\", \"invert\": false, \"type\": \"PATH\", \"value\": \"/images*\", \"admin_state_up\": true, \"tags\": [\"test_tag\"]}}")
-	resp := l.Post(l.headers, urlSuffix, reqBody)
+func (l *Octavia) CreateL7Rule(opts entity.CreateRuleOpts) (string, error) {
+	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s/rules", opts.PolicyID)
+	reqBody := opts.ToRequestBody()
+	resp, err := l.Post(l.headers, urlSuffix, reqBody)
+	if err != nil {
+		return "", err
+	}
 	var l7Rule entity.L7RuleMap
 	_ = json.Unmarshal(resp, &l7Rule)
 
 	//cache.RedisClient.SetMap(l.tag + consts.L7RULES, l7Rule.Rule.Id, l7Rule)
 	log.Println("==============create l7Rule success", l7Rule.Rule.Id)
-	return l7Rule.Rule.Id
+	return l7Rule.Rule.Id, nil
 }
 
 func (o *Octavia) deleteL7Rule(l7PolicyId, l7RuleId string) Output {
 	outputObj := Output{ParametersMap: map[string]string{"l7Rule_id": l7RuleId}}
-	defer func() {
-		if err := recover(); err != nil {
-			log.Println("catch error：", err)
-			outputObj.Success = false
-		}
-	}()
-
 	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s/rules/%s", l7PolicyId, l7RuleId)
-	outputObj.Success, outputObj.Response = o.Delete(o.headers, urlSuffix)
+	outputObj.setErr(o.Delete(o.headers, urlSuffix))
 	return outputObj
 }
 
-func (o *Octavia) getL7Rule(l7PolicyId, l7RuleId string) entity.L7RuleMap {
+func (o *Octavia) getL7Rule(l7PolicyId, l7RuleId string) (entity.L7RuleMap, error) {
 	urlSuffix := fmt.Sprintf("lbaas/l7policies/%s/rules/%s", l7PolicyId, l7RuleId)
-	resp := o.Get(o.headers, urlSuffix)
 	var l7Rule entity.L7RuleMap
+	resp, err := o.Get(o.headers, urlSuffix)
+	if err != nil {
+		return l7Rule, err
+	}
 	_ = json.Unmarshal(resp, &l7Rule)
-	return l7Rule
+	return l7Rule, nil
 }
 
-func (o *Octavia) DeleteL7Rules() {
-	l7policies := o.ListL7Policies()
+func (o *Octavia) DeleteL7Rules() error {
+	l7policies, err := o.ListL7Policies()
+	if err != nil {
+		return err
+	}
 	var ruleNumber int
 	for _, policy := range l7policies.L7Ps {
         ruleNumber += len(policy.Rules)
 	}
+	var wg sync.WaitGroup
 	ch := o.MakeDeleteChannel(consts.L7RULE, ruleNumber)
 	for _, l7policy := range l7policies.L7Ps {
+		if !o.selects(l7policy) {
+			continue
+		}
 		tempPolicy := l7policy
 		for _, rule := range l7policy.Rules {
 			temp := rule
+			wg.Add(1)
 			go func() {
+				defer wg.Done()
 				ch <- o.deleteL7Rule(tempPolicy.Id, temp.Id)
 			}()
 		}
 	}
 
-	if len(ch) != cap(ch) {
-		for len(ch) != cap(ch) {
+	wg.Wait()
+	log.Println("L7 rules were deleted completely")
+	return nil
+}
+
+// GetAttributes returns the attributes of the resource at urlSuffix, the
+// object under key of the response.
+func (o *Octavia) GetAttributes(urlSuffix, key string) (map[string]interface{}, error) {
+	return o.getAttributes(o.headers, urlSuffix, key)
+}
+
+// DeleteLbChild deletes the listener, pool, member, health monitor, l7
+// policy or l7 rule at urlSuffix and waits until its loadbalancers are
+// active again. The loadbalancers are found through owner, the child itself
+// or, for a member or l7 rule, its pool or l7 policy, e.g.
+// DeleteLbChild("lbaas/pools/{pool_id}/members/{member_id}", "lbaas/pools/{pool_id}", "pool").
+func (o *Octavia) DeleteLbChild(urlSuffix, ownerSuffix, ownerKey string) error {
+	lbIds, err := o.loadbalancersOf(ownerSuffix, ownerKey)
+	if err != nil {
+		return err
+	}
+	if err = o.Delete(o.headers, urlSuffix); err != nil {
+		return err
+	}
+	for _, lbId := range lbIds {
+		if _, err = o.makeSureLbActive(lbId); err != nil {
+			return err
 		}
 	}
-	log.Println("L7 rules were deleted completely")
-}
\ No newline at end of file
+	return nil
+}
+
+// loadbalancersOf returns the ids of the loadbalancers of a listener, pool,
+// health monitor or l7 policy, the latter two through their pool or listener.
+func (o *Octavia) loadbalancersOf(urlSuffix, key string) ([]string, error) {
+	attrs, err := o.GetAttributes(urlSuffix, key)
+	if err != nil {
+		return nil, err
+	}
+	if lbs, ok := attrs["loadbalancers"].([]interface{}); ok && len(lbs) > 0 {
+		lbIds := make([]string, 0, len(lbs))
+		for _, lb := range lbs {
+			if id, ok := lb.(map[string]interface{})["id"].(string); ok {
+				lbIds = append(lbIds, id)
+			}
+		}
+		return lbIds, nil
+	}
+	if pools, ok := attrs["pools"].([]interface{}); ok && len(pools) > 0 {
+		if id, ok := pools[0].(map[string]interface{})["id"].(string); ok {
+			return o.loadbalancersOf("lbaas/pools/"+id, consts.POOL)
+		}
+	}
+	if listenerId, ok := attrs["listener_id"].(string); ok && listenerId != "" {
+		return o.loadbalancersOf("lbaas/listeners/"+listenerId, consts.LISTENER)
+	}
+	return nil, nil
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+    "context"
     "fmt"
     "github.com/valyala/fasthttp"
     "request_openstack/configs"
@@ -56,3 +57,56 @@ func WithIsAdmin(isAdmin bool) Option {
         opts.IsAdmin = isAdmin
     }
 }
+
+// WithRetryPolicy sets the retry policy of the request for the given verb,
+// an empty method applies to every verb. Use it after WithRequest.
+func WithRetryPolicy(method string, policy RetryPolicy) Option {
+    return func(opts *Options) {
+        if opts.Request.Retry == nil {
+            opts.Request.Retry = make(map[string]RetryPolicy)
+        }
+        opts.Request.Retry[method] = policy
+    }
+}
+
+// WithContext binds ctx to the request, see Request.SetContext. Use it after
+// WithRequest.
+func WithContext(ctx context.Context) Option {
+    return func(opts *Options) {
+        opts.Request.ctx = ctx
+    }
+}
+
+// WithEndpoint is WithRequest for a full API root, e.g. one resolved by
+// Keystone.EndpointFor.
+func WithEndpoint(url string, client *fasthttp.Client) Option {
+    return func(opts *Options) {
+        opts.Request = Request{
+            UrlPrefix: url,
+            Client: client,
+        }
+    }
+}
+
+// WithAuth shares the token provider with the request, see Request.Auth.
+// Use it after WithRequest.
+func WithAuth(auth *TokenProvider) Option {
+    return func(opts *Options) {
+        opts.Request.Auth = auth
+    }
+}
+
+// WithMicroversion pins the microversion of a nova or cinder request, e.g.
+// "2.79" or LatestMicroversion. Use it after WithRequest.
+func WithMicroversion(version string) Option {
+    return func(opts *Options) {
+        opts.Request.Microversion = version
+    }
+}
+
+// WithPageSize sets the limit of each page of List. Use it after WithRequest.
+func WithPageSize(size int) Option {
+    return func(opts *Options) {
+        opts.Request.PageSize = size
+    }
+}
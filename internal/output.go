@@ -1,5 +1,10 @@
 package internal
 
+import (
+	"errors"
+	"log"
+)
+
 
 
 type Output struct {
@@ -7,3 +12,18 @@ type Output struct {
 	Success                 bool
 	Response                any
 }
+
+// setErr records the result of a delete call, the error is kept as Response
+// so the cleaner report can show why a resource was left behind. A call
+// refused by a dry run succeeds, its Response is the request not sent.
+func (o *Output) setErr(err error) {
+	if errors.Is(err, ErrDryRun) {
+		o.Success, o.Response = true, err.Error()
+		return
+	}
+	o.Success = err == nil
+	if err != nil {
+		log.Println("catch error：", err)
+		o.Response = err
+	}
+}
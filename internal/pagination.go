@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"request_openstack/configs"
+	"request_openstack/internal/entity"
+	"strconv"
+	"strings"
+)
+
+// Pager walks the pages of a list request. Neutron, Nova, Cinder and Octavia
+// link the next page from "<collection>_links", keystone from "links" and
+// Glance from "next", the marker and filters of the link are replayed on the
+// url suffix of the first request so that internal hostnames in the links do
+// not matter.
+//
+//	pager := n.Pager(n.Headers, "networks")
+//	for pager.Next() {
+//		handle(pager.Page())
+//	}
+//	if err := pager.Err(); err != nil {...}
+type Pager struct {
+	r         *Request
+	headers   map[string]string
+	urlSuffix string
+	next      string
+	page      []byte
+	err       error
+}
+
+// Pager starts a paginated list request, the page size is Request.PageSize
+// or configs.CONF.PageSize and the service default when both are zero.
+func (r *Request) Pager(headers map[string]string, urlSuffix string) *Pager {
+	first := urlSuffix
+	if size := r.pageSize(); size > 0 && !hasQueryParam(urlSuffix, "limit") {
+		first = withQuery(urlSuffix, "limit="+strconv.Itoa(size))
+	}
+	return &Pager{r: r, headers: headers, urlSuffix: urlSuffix, next: first}
+}
+
+func (r *Request) pageSize() int {
+	if r.PageSize > 0 {
+		return r.PageSize
+	}
+	return configs.CONF.PageSize
+}
+
+// Next fetches the next page, false once all pages were read or on error.
+func (p *Pager) Next() bool {
+	if p.next == "" || p.err != nil {
+		return false
+	}
+	current := p.next
+	p.page, p.err = p.r.Get(p.headers, current)
+	if p.err != nil {
+		return false
+	}
+	p.next = ""
+	if href := nextLink(p.page); href != "" {
+		next, err := nextSuffix(p.urlSuffix, href)
+		if err != nil {
+			p.err = err
+			return true
+		}
+		// a service echoing the same marker would loop forever
+		if next != current {
+			p.next = next
+		}
+	}
+	return true
+}
+
+// Page returns the body of the page read by Next.
+func (p *Pager) Page() []byte {
+	return p.page
+}
+
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// ListAll reads every page and merges the collections into the body of the
+// first page, without the links to further pages.
+func (r *Request) ListAll(headers map[string]string, urlSuffix string) ([]byte, error) {
+	pager := r.Pager(headers, urlSuffix)
+	var merged map[string]json.RawMessage
+	var first []byte
+	pages := 0
+	for pager.Next() {
+		pages++
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(pager.Page(), &page); err != nil {
+			// not a collection, nothing to merge
+			return pager.Page(), pager.Err()
+		}
+		if merged == nil {
+			merged, first = page, pager.Page()
+			continue
+		}
+		for key, value := range page {
+			if isLinksKey(key) {
+				continue
+			}
+			items, err := appendItems(merged[key], value)
+			if err != nil {
+				continue
+			}
+			merged[key] = items
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+	if pages <= 1 {
+		return first, nil
+	}
+	for key := range merged {
+		if isLinksKey(key) || key == "next" {
+			delete(merged, key)
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// ListBy lists every page of a collection filtered by a ListXxxOpts struct,
+// see entity.BuildQueryString.
+func (r *Request) ListBy(headers map[string]string, collection string, opts interface{}) ([]byte, error) {
+	query, err := entity.BuildQueryString(opts)
+	if err != nil {
+		return nil, fmt.Errorf("build %s query: %w", collection, err)
+	}
+	return r.ListAll(headers, collection+query)
+}
+
+func isLinksKey(key string) bool {
+	return key == "links" || strings.HasSuffix(key, "_links")
+}
+
+// appendItems concatenates two JSON arrays, err when either is no array.
+func appendItems(items, more json.RawMessage) (json.RawMessage, error) {
+	var a, b []json.RawMessage
+	if err := json.Unmarshal(items, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(more, &b); err != nil {
+		return nil, err
+	}
+	return json.Marshal(append(a, b...))
+}
+
+// nextLink finds the link to the next page in a list body.
+func nextLink(body []byte) string {
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return ""
+	}
+	for key, value := range page {
+		switch {
+		case key == "next":
+			var next string
+			_ = json.Unmarshal(value, &next)
+			if next != "" {
+				return next
+			}
+		case key == "links":
+			var links struct {
+				Next string `json:"next"`
+			}
+			if json.Unmarshal(value, &links) == nil && links.Next != "" {
+				return links.Next
+			}
+		case strings.HasSuffix(key, "_links"):
+			var links []struct {
+				Href string `json:"href"`
+				Rel  string `json:"rel"`
+			}
+			_ = json.Unmarshal(value, &links)
+			for _, link := range links {
+				if link.Rel == "next" {
+					return link.Href
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// nextSuffix replaces the query of urlSuffix with the one of the next link.
+func nextSuffix(urlSuffix, href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("parse next link %s: %w", href, err)
+	}
+	path := urlSuffix
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	if u.RawQuery == "" {
+		return path, nil
+	}
+	return path + "?" + u.RawQuery, nil
+}
+
+func hasQueryParam(urlSuffix, key string) bool {
+	i := strings.Index(urlSuffix, "?")
+	if i < 0 {
+		return false
+	}
+	query, err := url.ParseQuery(urlSuffix[i+1:])
+	return err == nil && query.Has(key)
+}
+
+func withQuery(urlSuffix, param string) string {
+	if strings.Contains(urlSuffix, "?") {
+		return urlSuffix + "&" + param
+	}
+	return urlSuffix + "?" + param
+}
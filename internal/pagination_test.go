@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"request_openstack/internal/entity"
+	"testing"
+)
+
+func TestListFollowsLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("limit") != "2" {
+			t.Errorf("expected limit 2, got %q", r.URL.RawQuery)
+		}
+		switch {
+		case r.URL.Path == "/networks" && q.Get("marker") == "":
+			// links point to an internal hostname
+			fmt.Fprint(w, `{"networks": [{"id": "a"}, {"id": "b"}], "networks_links": [{"rel": "next", "href": "http://internal:9696/v2.0/networks?limit=2&marker=b"}]}`)
+		case r.URL.Path == "/networks" && q.Get("marker") == "b":
+			fmt.Fprint(w, `{"networks": [{"id": "c"}], "networks_links": [{"rel": "previous", "href": "http://internal:9696/v2.0/networks?limit=2&marker=c&page_reverse=True"}]}`)
+		case r.URL.Path == "/images" && q.Get("marker") == "":
+			fmt.Fprint(w, `{"images": [{"id": "a"}, {"id": "b"}], "first": "/v2/images", "next": "/v2/images?limit=2&marker=b"}`)
+		case r.URL.Path == "/images" && q.Get("marker") == "b":
+			fmt.Fprint(w, `{"images": [{"id": "c"}], "first": "/v2/images"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	r := &Request{UrlPrefix: server.URL, PageSize: 2}
+	for _, collection := range []string{"networks", "images"} {
+		resp, err := r.List(nil, "/"+collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string][]struct{ Id string }
+		_ = json.Unmarshal(resp, &body)
+		if len(body[collection]) != 3 || body[collection][2].Id != "c" {
+			t.Fatalf("expected 3 %s, got %s", collection, resp)
+		}
+	}
+
+	pager := r.Pager(nil, "/networks")
+	pages := 0
+	for pager.Next() {
+		pages++
+	}
+	if pager.Err() != nil || pages != 2 {
+		t.Fatalf("expected 2 pages, got %d: %v", pages, pager.Err())
+	}
+}
+
+func TestListByQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("device_owner") != "network:router_interface" || q.Get("name") != "a b&c" ||
+			q.Get("tags") != "x,y" || len(q["fields"]) != 2 || q.Has("network_id") {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"ports": []}`)
+	}))
+	t.Cleanup(server.Close)
+
+	r := &Request{UrlPrefix: server.URL}
+	opts := entity.ListPortsOpts{
+		ListOpts:    entity.ListOpts{Name: "a b&c", Tags: []string{"x", "y"}, Fields: []string{"id", "name"}},
+		DeviceOwner: "network:router_interface",
+	}
+	if _, err := r.ListBy(nil, "/ports", opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if query, err := entity.BuildQueryString(entity.ListPortsOpts{}); err != nil || query != "" {
+		t.Fatalf("expected no query, got %q: %v", query, err)
+	}
+}
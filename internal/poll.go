@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// Poll calls check every interval until it reports done, returns an error or
+// ctx is done. A positive timeout bounds the whole wait on top of ctx.
+func Poll(ctx context.Context, timeout, interval time.Duration, check func() (bool, error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext sleeps for d unless ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollTimeout(t *testing.T) {
+	err := Poll(context.Background(), 50*time.Millisecond, 10*time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRequestCancelled(t *testing.T) {
+	server, calls := newRetryServer(t, 10, 503)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := &Request{UrlPrefix: server.URL}
+	r.SetContext(ctx)
+	if _, err := r.Get(nil, "/"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if *calls != 0 {
+		t.Fatalf("expected no attempt, got %d", *calls)
+	}
+}
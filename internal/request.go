@@ -2,24 +2,64 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/valyala/fasthttp"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"request_openstack/configs"
 	"request_openstack/consts"
 	"request_openstack/internal/cache"
+	"sync"
 	"time"
 )
 
 type Request struct {
 	UrlPrefix        string
 	Client           *fasthttp.Client
+	// Service names the OpenStack service in APIError, e.g. consts.NEUTRON
+	Service          string
+	// Retry overrides the registered retry policies per verb, the "" key
+	// applies to every verb, see RetryPolicyFor
+	Retry            map[string]RetryPolicy
+	// Auth supplies the X-Auth-Token of every call when set, overriding the
+	// token in the headers passed to the verbs
+	Auth             *TokenProvider
+	// PageSize is the limit of each page of List, see Pager
+	PageSize         int
+	// Microversion pins the microversion sent to nova and cinder, e.g. "2.79"
+	// or LatestMicroversion, see MicroversionHeaders for a single call
+	Microversion     string
+	// DryRun refuses every verb but GET with ErrDryRun, so that the cleaner
+	// lists what it would delete without deleting it
+	DryRun           bool
+	// Filter selects the resources the Delete methods of the cleaner delete,
+	// all of them when nil
+	Filter           *ResourceFilter
+	ctx              context.Context
+}
+
+// SetContext binds ctx to every following call of the client, cancelling it
+// aborts the in-flight HTTP requests, retries and status polling. Set it
+// before the client is shared between goroutines.
+func (r *Request) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// Context returns the context bound by SetContext, context.Background if none.
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
 }
 
 func NewClient() (client *fasthttp.Client) {
@@ -38,10 +78,42 @@ func NewClient() (client *fasthttp.Client) {
 			Concurrency:      4096,
 			DNSCacheDuration: time.Hour,
 		}).Dial,
+		TLSConfig: clientTLSConfig,
 	}
 	return client
 }
 
+var (
+	// clientTLSConfig is shared by the clients and filled from configs.CONF
+	// on the first request, the clients are created before it is loaded
+	clientTLSConfig = &tls.Config{}
+	tlsOnce         sync.Once
+	httpClient      *http.Client
+)
+
+func loadTLSConfig() *tls.Config {
+	tlsOnce.Do(func() {
+		clientTLSConfig.InsecureSkipVerify = configs.CONF.Insecure
+		if configs.CONF.CACert != "" {
+			pem, err := os.ReadFile(configs.CONF.CACert)
+			if err != nil {
+				log.Printf("Failed to read CA cert %s: %v", configs.CONF.CACert, err)
+			} else {
+				pool, err := x509.SystemCertPool()
+				if err != nil {
+					pool = x509.NewCertPool()
+				}
+				pool.AppendCertsFromPEM(pem)
+				clientTLSConfig.RootCAs = pool
+			}
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = clientTLSConfig
+		httpClient = &http.Client{Timeout: 5 * 60 * time.Second, Transport: transport}
+	})
+	return clientTLSConfig
+}
+
 func loadCACert() *x509.CertPool {
 	exePath, err := os.Getwd()
 	if err != nil {
@@ -239,36 +311,18 @@ func NewSSLClient() (client *fasthttp.Client) {
 //	return res
 //}
 
-func (r *Request) Patch(headers map[string]string, urlSuffix string, body string) []byte {
-	reqURL := r.UrlPrefix + urlSuffix
-	req := fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(req)
-
-	req.SetRequestURI(reqURL)
-	req.Header.SetContentType(consts.ImagePatchJson)
-	req.Header.SetMethod(consts.PATCH)
+// Patch sends a JSON patch, application/openstack-images-v2.1-json-patch
+// unless the headers set another Content-Type.
+func (r *Request) Patch(headers map[string]string, urlSuffix string, body string) ([]byte, error) {
+	patchHeaders := map[string]string{"Content-Type": consts.ImagePatchJson}
 	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	req.SetBody([]byte(body))
-
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(resp)
-	log.Printf("Start to Patch request %s", reqURL)
-	if err := r.Client.Do(req, resp); err != nil {
-		log.Println("err==", err)
-		panic("Patch error do request")
+		patchHeaders[k] = v
 	}
-	if resp.StatusCode() > 204 {
-		log.Printf("Patch request %s failed, resp==%s", reqURL, resp.Body())
-		panic("Patch error")
-	}
-	log.Printf("Patch request %s sucess", reqURL)
-	res := resp.Body()
-	return res
+	return r.do(consts.PATCH, patchHeaders, urlSuffix, []byte(body))
 }
 
-func (r *Request) GetHeaderToken(headers map[string]string, urlSuffix string, body string) string {
+// GetHeaderToken returns the X-Subject-Token header along with the token body.
+func (r *Request) GetHeaderToken(headers map[string]string, urlSuffix string, body string) (string, []byte, error) {
 	reqURL := r.UrlPrefix + urlSuffix
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
@@ -285,16 +339,32 @@ func (r *Request) GetHeaderToken(headers map[string]string, urlSuffix string, bo
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 	log.Printf("Start to get token request %s", reqURL)
+	if err := r.Context().Err(); err != nil {
+		return "", nil, fmt.Errorf("POST request %s: %w", reqURL, err)
+	}
+	loadTLSConfig()
 	if err := r.Client.Do(req, resp); err != nil {
-		log.Println("err==", err)
-		panic("post error do request")
+		return "", nil, fmt.Errorf("POST request %s: %w", reqURL, err)
 	}
-	if resp.StatusCode() > 204 {
-		log.Printf("Post request %s failed, resp==%s", urlSuffix, resp.Body())
-		panic("post error")
+	if err := r.checkFastResp(consts.POST, reqURL, resp); err != nil {
+		return "", nil, err
 	}
 	log.Printf("Post request %s sucess", urlSuffix)
-	return string(resp.Header.Peek("X-Subject-Token"))
+	res := make([]byte, len(resp.Body()))
+	copy(res, resp.Body())
+	return string(resp.Header.Peek("X-Subject-Token")), res, nil
+}
+
+func (r *Request) checkFastResp(method, reqURL string, resp *fasthttp.Response) error {
+	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
+		return nil
+	}
+	header := func(key string) string {
+		return string(resp.Header.Peek(key))
+	}
+	apiErr := newAPIError(r.Service, method, reqURL, resp.StatusCode(), header, resp.Body())
+	log.Println(apiErr)
+	return apiErr
 }
 
 func (r *Request) SyncResource(resourceId string, getter getterFunc, resource map[string]interface{}) {
@@ -332,181 +402,184 @@ func (r *Request) HandleRespBody(resp []byte) (res map[string]interface{}) {
 	return res
 }
 
-func (r *Request) DecorateResp(f func(headers map[string]string, urlSuffix string, body string) []byte) func(headers map[string]string, urlSuffix string, body string) map[string]interface{} {
-    return func(headers map[string]string, urlSuffix string, body string) map[string]interface{} {
-		resp := f(headers, urlSuffix, body)
-		return r.HandleRespBody(resp)
+// getAttributes returns the object under key of the response to GET
+// urlSuffix, e.g. the "port" of ports/{port_id}.
+func (r *Request) getAttributes(headers map[string]string, urlSuffix, key string) (map[string]interface{}, error) {
+	resp, err := r.Get(headers, urlSuffix)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func (r *Request) DecorateGetResp(f func(headers map[string]string, urlSuffix string) []byte) func(headers map[string]string, urlSuffix string) map[string]interface{} {
-	return func(headers map[string]string, urlSuffix string) map[string]interface{} {
-		resp := f(headers, urlSuffix)
-		return r.HandleRespBody(resp)
+	attrs, ok := r.HandleRespBody(resp)[key].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no %s in the response of %s", key, urlSuffix)
 	}
+	return attrs, nil
 }
 
-// #################################################
-
-func (r *Request) Post(headers map[string]string, urlSuffix string, body string) []byte {
-	reqURL := r.UrlPrefix + urlSuffix
-    req, err := http.NewRequest(consts.POST, reqURL, bytes.NewBufferString(body))
-    if err != nil {
-    	log.Fatalln("new request error", err)
-	}
-	req.Header.Set("Content-Type", consts.ContentTypeJson)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	cli := &http.Client{Timeout: 5 * 60 * time.Second}
-	log.Printf("Starting to POST request %s", reqURL)
-	resp, err := cli.Do(req)
-	defer resp.Body.Close()
+// setAttributes updates the resource at urlSuffix with a PUT of attrs under
+// key, e.g. {"network": {"name": "net1"}} to networks/{network_id}.
+func (r *Request) setAttributes(headers map[string]string, urlSuffix, key string, attrs map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{key: attrs})
 	if err != nil {
-		log.Println("post err", err)
-		panic("post err")
+		return err
 	}
-	res := resp.Body
-	resBody, err := ioutil.ReadAll(res)
-	if err != nil {
-		panic(err)
+	_, err = r.Put(headers, urlSuffix, string(body))
+	return err
+}
+
+func (r *Request) DecorateResp(f func(headers map[string]string, urlSuffix string, body string) ([]byte, error)) func(headers map[string]string, urlSuffix string, body string) (map[string]interface{}, error) {
+    return func(headers map[string]string, urlSuffix string, body string) (map[string]interface{}, error) {
+		resp, err := f(headers, urlSuffix, body)
+		if err != nil {
+			return nil, err
+		}
+		return r.HandleRespBody(resp), nil
 	}
-	if resp.StatusCode > 204 {
-		errorInfo := fmt.Sprintf("POST request %s failed, resp==%s", reqURL, resBody)
-		log.Printf(errorInfo)
-		panic(errorInfo)
+}
+
+func (r *Request) DecorateGetResp(f func(headers map[string]string, urlSuffix string) ([]byte, error)) func(headers map[string]string, urlSuffix string) (map[string]interface{}, error) {
+	return func(headers map[string]string, urlSuffix string) (map[string]interface{}, error) {
+		resp, err := f(headers, urlSuffix)
+		if err != nil {
+			return nil, err
+		}
+		return r.HandleRespBody(resp), nil
 	}
-	log.Printf("Post request %s success", reqURL)
+}
+
+// #################################################
 
-	return resBody
+func (r *Request) Post(headers map[string]string, urlSuffix string, body string) ([]byte, error) {
+	return r.do(consts.POST, headers, urlSuffix, []byte(body))
 }
 
-func (r *Request) Put(headers map[string]string, urlSuffix string, body string) []byte {
-	reqURL := r.UrlPrefix + urlSuffix
-	req, err := http.NewRequest(consts.PUT, reqURL, bytes.NewBufferString(body))
-	if err != nil {
-		log.Fatalln("new request error", err)
-	}
-	req.Header.Set("Content-Type", consts.ContentTypeJson)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	cli := &http.Client{Timeout: 5 * 60 * time.Second}
-	log.Printf("Starting to PUT request %s", reqURL)
-	resp, err := cli.Do(req)
-	defer resp.Body.Close()
-	if err != nil {
-		log.Println("post err", err)
-		panic("put err")
-	}
-	res := resp.Body
-	resBody, err := ioutil.ReadAll(res)
-	if err != nil {
-		panic(err)
-	}
-	if resp.StatusCode > 204 {
-		errorInfo := fmt.Sprintf("PUT request %s failed, resp==%s", urlSuffix, resBody)
-		log.Printf(errorInfo)
-		panic(errorInfo)
-	}
-	log.Printf("Put request %s success", reqURL)
-	return resBody
+func (r *Request) Put(headers map[string]string, urlSuffix string, body string) ([]byte, error) {
+	return r.do(consts.PUT, headers, urlSuffix, []byte(body))
 }
 
-func (r *Request) Delete(headers map[string]string, urlSuffix string) (bool, string)  {
-	reqURL := r.UrlPrefix + urlSuffix
-	req, err := http.NewRequest(consts.DELETE, reqURL, nil)
-	if err != nil {
-		log.Fatalln("new request error", err)
-	}
-	req.Header.Set("Content-Type", consts.ContentTypeJson)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	cli := &http.Client{Timeout: 5 * 60 * time.Second}
-	log.Printf("Starting to DELETE request %s", reqURL)
-	resp, err := cli.Do(req)
-	defer resp.Body.Close()
-	if err != nil {
-		log.Println("delete err", err)
-		panic("delete err")
-	}
-	res := resp.Body
-	resBody, err := ioutil.ReadAll(res)
-	if err != nil {
-		panic(err)
-	}
-	if resp.StatusCode == 404 {
-		log.Printf("Delete request %s, resp==%s", urlSuffix, resBody)
-		return true, string(resBody)
-	} else if resp.StatusCode > 204 {
-		log.Printf("Delete request %s failed, resp==%s", urlSuffix, resBody)
-		return false, string(resBody)
-	} else {
-		log.Printf("Delete request %s success", reqURL)
-		return true, ""
+// Delete treats a 404 as success, the resource is already gone.
+func (r *Request) Delete(headers map[string]string, urlSuffix string) error {
+	_, err := r.do(consts.DELETE, headers, urlSuffix, nil)
+	if IsNotFound(err) {
+		log.Printf("Delete request %s, resource not found", r.UrlPrefix + urlSuffix)
+		return nil
 	}
+	return err
 }
 
-func (r *Request) Get(headers map[string]string, urlSuffix string) []byte {
+func (r *Request) Get(headers map[string]string, urlSuffix string) ([]byte, error) {
+	return r.do(consts.GET, headers, urlSuffix, nil)
+}
+
+// List follows the pagination links and returns all pages merged, see ListAll.
+func (r *Request) List(headers map[string]string, urlSuffix string) ([]byte, error) {
+	return r.ListAll(headers, urlSuffix)
+}
+
+func (r *Request) do(method string, headers map[string]string, urlSuffix string, body []byte) ([]byte, error) {
 	reqURL := r.UrlPrefix + urlSuffix
-	req, err := http.NewRequest(consts.GET, reqURL, nil)
-	if err != nil {
-		log.Fatalln("new request error", err)
+	if err := r.dryRun(method, reqURL); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", consts.ContentTypeJson)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	cli := &http.Client{Timeout: 5 * 60 * time.Second}
-	log.Printf("Starting to GET request %s", reqURL)
-	resp, err := cli.Do(req)
-	defer resp.Body.Close()
-	if err != nil {
-		log.Println("get err", err)
-		panic("get err")
-	}
-	res := resp.Body
-	resBody, err := ioutil.ReadAll(res)
+	headers, err := r.withMicroversion(headers)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	policy := r.policy(method)
+	reauthenticated := false
+	for attempt := 1; ; attempt++ {
+		token, err := r.authToken()
+		if err != nil {
+			return nil, err
+		}
+		resBody, retryAfter, err := r.doOnce(method, headers, token, reqURL, body)
+		if IsStatus(err, http.StatusUnauthorized) && r.Auth != nil && !reauthenticated {
+			log.Printf("Token rejected by %s request %s, re-authenticating", method, reqURL)
+			reauthenticated = true
+			if _, err = r.Auth.Refresh(token); err != nil {
+				return nil, err
+			}
+			attempt--
+			continue
+		}
+		if err == nil || attempt >= policy.MaxAttempts {
+			return resBody, err
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if !policy.retryStatus(method, apiErr.StatusCode) {
+				return nil, err
+			}
+		} else if !policy.retryTransport(method, err) {
+			return nil, err
+		}
+		delay, ok := parseRetryAfter(retryAfter)
+		if !ok {
+			delay = policy.backoff(attempt)
+		}
+		log.Printf("Retrying %s request %s in %s (attempt %d/%d): %v", method, reqURL, delay, attempt+1, policy.MaxAttempts, err)
+		if sleepErr := sleepContext(r.Context(), delay); sleepErr != nil {
+			return nil, fmt.Errorf("%s request %s: %w, last error: %v", method, reqURL, sleepErr, err)
+		}
 	}
-	if resp.StatusCode > 204 {
-		log.Printf("Get request %s failed, resp==%s", urlSuffix, resBody)
+}
+
+// selects tells whether the cleaner deletes resource, see Filter.
+func (r *Request) selects(resource interface{}) bool {
+	return r.Filter.Selects(resource)
+}
+
+// dryRun returns ErrDryRun for the verbs changing resources in dry-run mode.
+func (r *Request) dryRun(method, reqURL string) error {
+	if !r.DryRun || method == consts.GET {
 		return nil
 	}
-	log.Printf("Get request %s success", reqURL)
-	return resBody
+	log.Printf("Dry run, skipped %s request %s", method, reqURL)
+	return fmt.Errorf("%s request %s: %w", method, reqURL, ErrDryRun)
 }
 
-func (r *Request) List(headers map[string]string, urlSuffix string) []byte {
-	reqURL := r.UrlPrefix + urlSuffix
-	req, err := http.NewRequest(consts.GET, reqURL, nil)
+// authToken returns the token of Auth, empty when the headers carry it.
+func (r *Request) authToken() (string, error) {
+	if r.Auth == nil {
+		return "", nil
+	}
+	return r.Auth.Token()
+}
+
+// doOnce makes a single attempt and returns the Retry-After header along with
+// the APIError of a failed response.
+func (r *Request) doOnce(method string, headers map[string]string, token, reqURL string, body []byte) ([]byte, string, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(r.Context(), method, reqURL, reader)
 	if err != nil {
-		log.Fatalln("new request error", err)
+		return nil, "", fmt.Errorf("new %s request %s: %w", method, reqURL, err)
 	}
 	req.Header.Set("Content-Type", consts.ContentTypeJson)
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
-	cli := &http.Client{Timeout: 5 * 60 * time.Second}
-	log.Printf("Starting to LIST request %s", reqURL)
-	resp, err := cli.Do(req)
-	defer resp.Body.Close()
+	if token != "" {
+		req.Header.Set(consts.AuthToken, token)
+	}
+	loadTLSConfig()
+	log.Printf("Starting to %s request %s", method, reqURL)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		log.Println("list err", err)
-		panic("list err")
+		return nil, "", fmt.Errorf("%s request %s: %w", method, reqURL, err)
 	}
-	res := resp.Body
-	resBody, err := ioutil.ReadAll(res)
+	defer resp.Body.Close()
+	resBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		panic(err)
+		return nil, "", fmt.Errorf("read %s response %s: %w", method, reqURL, err)
 	}
-	if resp.StatusCode > 202 {
-		log.Printf("List request %s failed, resp==%s", reqURL, resBody)
-		return nil
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := newAPIError(r.Service, method, reqURL, resp.StatusCode, resp.Header.Get, resBody)
+		log.Println(apiErr)
+		return nil, resp.Header.Get("Retry-After"), apiErr
 	}
-	log.Printf("List request %s sucess", reqURL)
-	return resBody
+	log.Printf("%s request %s success", method, reqURL)
+	return resBody, "", nil
 }
-
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDryRunOnlySendsGet(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	r := &Request{UrlPrefix: server.URL, DryRun: true}
+
+	if _, err := r.Get(nil, "/networks"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(nil, "/networks/1"); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected ErrDryRun, got %v", err)
+	}
+	if _, err := r.Put(nil, "/routers/1", `{}`); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected ErrDryRun, got %v", err)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Fatalf("expected only the GET to be sent, got %v", methods)
+	}
+
+	var output Output
+	output.setErr(r.Delete(nil, "/networks/1"))
+	if !output.Success {
+		t.Fatalf("expected a dry run delete to succeed, got %+v", output)
+	}
+}
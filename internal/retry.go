@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"request_openstack/consts"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how the Request verbs retry transient failures.
+// Delays grow exponentially from BaseDelay up to MaxDelay with full jitter,
+// a Retry-After header sent by the service takes precedence.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 1 disables retrying
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// RetryStatus lists the status codes worth another attempt
+	RetryStatus []int
+	// RetryPost allows POST to be retried when the service rejected it with
+	// one of RetryStatus, callers opt in for the creations known to be safe.
+	// POST is never retried after a transport error unless the connection
+	// could not be established at all, the resource may already have been
+	// created.
+	RetryPost bool
+}
+
+// NoRetry makes exactly one attempt.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// DefaultRetryPolicy retries 409 Conflict and 503 Service Unavailable, which
+// neutron answers while a router is being updated and octavia while a
+// loadbalancer is in PENDING_UPDATE. POST is not retried, see RetryPost.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	RetryStatus: []int{http.StatusConflict, http.StatusServiceUnavailable},
+}
+
+var retryPolicies = struct {
+	sync.RWMutex
+	m map[string]RetryPolicy
+}{m: make(map[string]RetryPolicy)}
+
+func retryKey(service, method string) string {
+	return service + " " + method
+}
+
+// SetRetryPolicy registers the policy used for the given service and verb,
+// e.g. SetRetryPolicy(consts.OCTAVIA, consts.PUT, policy). An empty method
+// applies to every verb of the service that has no policy of its own.
+func SetRetryPolicy(service, method string, policy RetryPolicy) {
+	retryPolicies.Lock()
+	defer retryPolicies.Unlock()
+	retryPolicies.m[retryKey(service, method)] = policy
+}
+
+// RetryPolicyFor returns the policy for the service and verb, falling back to
+// the service wide policy and then to DefaultRetryPolicy.
+func RetryPolicyFor(service, method string) RetryPolicy {
+	retryPolicies.RLock()
+	defer retryPolicies.RUnlock()
+	if policy, ok := retryPolicies.m[retryKey(service, method)]; ok {
+		return policy
+	}
+	if policy, ok := retryPolicies.m[retryKey(service, "")]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// policy picks the Request's own policy for the verb before the registered ones.
+func (r *Request) policy(method string) RetryPolicy {
+	if policy, ok := r.Retry[method]; ok {
+		return policy
+	}
+	if policy, ok := r.Retry[""]; ok {
+		return policy
+	}
+	return RetryPolicyFor(r.Service, method)
+}
+
+func (p RetryPolicy) retryStatus(method string, statusCode int) bool {
+	if method == consts.POST && !p.RetryPost {
+		return false
+	}
+	for _, code := range p.RetryStatus {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryTransport(method string, err error) bool {
+	if isDialError(err) {
+		return true
+	}
+	if method == consts.POST {
+		return false
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// isDialError reports whether the request never reached the service.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// backoff returns the delay before the given retry, attempt starts at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// parseRetryAfter understands both the delay-seconds and HTTP-date forms.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
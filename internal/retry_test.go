@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"request_openstack/consts"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	server, calls := newRetryServer(t, 2, http.StatusConflict)
+	r := &Request{UrlPrefix: server.URL, Service: consts.NEUTRON}
+	if _, err := r.Put(nil, "/routers/1", `{}`); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, calls := newRetryServer(t, 10, http.StatusServiceUnavailable)
+	r := &Request{
+		UrlPrefix: server.URL,
+		Retry:     map[string]RetryPolicy{"": {MaxAttempts: 2, RetryStatus: []int{http.StatusServiceUnavailable}}},
+	}
+	if _, err := r.Get(nil, "/"); !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("expected 503, got %v", err)
+	}
+	if *calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", *calls)
+	}
+}
+
+func TestNoRetryForUnsafePost(t *testing.T) {
+	server, calls := newRetryServer(t, 1, http.StatusConflict)
+	r := &Request{
+		UrlPrefix: server.URL,
+		Retry:     map[string]RetryPolicy{consts.POST: {MaxAttempts: 3, RetryStatus: []int{http.StatusConflict}}},
+	}
+	if _, err := r.Post(nil, "/", `{}`); !IsConflict(err) {
+		t.Fatalf("expected 409, got %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", *calls)
+	}
+}
+
+func TestRetryPostOptIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		retry  map[string]RetryPolicy
+		status int
+		calls  int32
+	}{
+		{"default", nil, http.StatusConflict, 1},
+		{"opt in", map[string]RetryPolicy{consts.POST: {MaxAttempts: 3, RetryStatus: []int{http.StatusConflict}, RetryPost: true}}, http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newRetryServer(t, 1, http.StatusConflict)
+			r := &Request{UrlPrefix: server.URL, Service: consts.NEUTRON, Retry: tt.retry}
+			_, err := r.Post(nil, "/", `{}`)
+			if tt.status == http.StatusOK && err != nil || tt.status != http.StatusOK && !IsStatus(err, tt.status) {
+				t.Fatalf("expected %d, got %v", tt.status, err)
+			}
+			if *calls != tt.calls {
+				t.Fatalf("expected %d attempts, got %d", tt.calls, *calls)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := &Request{UrlPrefix: server.URL}
+	r.SetContext(ctx)
+	if _, err := r.Get(nil, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+}
+
+func TestPatchRetries(t *testing.T) {
+	var calls int32
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		contentType = r.Header.Get("Content-Type")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	r := &Request{UrlPrefix: server.URL, Service: consts.GLANCE}
+	if _, err := r.Patch(nil, "/v2/images/1", `[]`); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || contentType != consts.ImagePatchJson {
+		t.Fatalf("expected 2 attempts with %s, got %d with %s", consts.ImagePatchJson, calls, contentType)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if delay, ok := parseRetryAfter("3"); !ok || delay != 3*time.Second {
+		t.Fatalf("unexpected delay %s", delay)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Fatal("expected invalid Retry-After")
+	}
+}
@@ -14,59 +14,78 @@ type SDN struct {
 	headers            map[string]string
 }
 
-func NewSDN() *SDN {
+func NewSDN() (*SDN, error) {
 	sdnObj := &SDN{
 		Request: Request{
 			UrlPrefix: fmt.Sprintf("https://%s:%d/", configs.CONF.SDN.SDNHost, consts.SDNPort),
 			Client: NewSSLClient(),
+			Service: consts.SDN,
 		},
 		headers: make(map[string]string),
 	}
-	token := sdnObj.GetSDNToken()
+	token, err := sdnObj.GetSDNToken()
+	if err != nil {
+		return nil, err
+	}
 	sdnObj.SetToken(token)
-	return sdnObj
+	return sdnObj, nil
 }
 
-func (s *SDN) GetSDNToken() string {
+func (s *SDN) GetSDNToken() (string, error) {
 	urlSuffix := "controller/v2/tokens"
     opts := entity.CreateSDNTokenOpts{
     	UserName: configs.CONF.SDNUserName,
     	Password: configs.CONF.SDNPassword,
 	}
 	reqBody := opts.ToRequestBody()
-    resp := s.Post(s.headers, urlSuffix, reqBody)
+    resp, err := s.Post(s.headers, urlSuffix, reqBody)
+    if err != nil {
+    	return "", err
+	}
     var sdnToken entity.SDNToken
     _ = json.Unmarshal(resp, &sdnToken)
     log.Println("==============Get SDN token success", sdnToken.Data.TokenId)
-    return sdnToken.Data.TokenId
+    return sdnToken.Data.TokenId, nil
 }
 
 func (s *SDN) SetToken(token string) {
 	s.headers["X-ACCESS-TOKEN"] = token
 }
 
-func (s *SDN) ListSDNNetworks() {
+func (s *SDN) ListSDNNetworks() (entity.SDNNetworks, error) {
 	urlSuffix := "restconf/data/huawei-ac-neutron:neutron-cfg/networks"
-	resp := s.List(s.headers, urlSuffix)
 	var networks entity.SDNNetworks
+	resp, err := s.List(s.headers, urlSuffix)
+	if err != nil {
+		return networks, err
+	}
 	_ = json.Unmarshal(resp, &networks)
 	log.Println(networks)
 	log.Println("==============List SDN networks success, there had", len(networks.HuaweiAcNeutronNetworks.Network))
+	return networks, nil
 }
 
-func (s *SDN) GetSDNLogicNetworks() {
+func (s *SDN) GetSDNLogicNetworks() error {
 	urlSuffix := "controller/dc/v3/logicnetwork/networks"
-	resp := s.List(s.headers, urlSuffix)
+	resp, err := s.List(s.headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println("==============List SDN networks success", string(resp))
+	return nil
 }
 
-func (s *SDN) GetSDNLogicRouters() {
+func (s *SDN) GetSDNLogicRouters() error {
 	urlSuffix := "controller/dc/v3/logicnetwork/routers"
-	resp := s.List(s.headers, urlSuffix)
+	resp, err := s.List(s.headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println("==============List SDN logic routers success", string(resp))
+	return nil
 }
 
-func (s *SDN) CreateSDNVpcConnect() {
+func (s *SDN) CreateSDNVpcConnect() error {
 	urlSuffix := "controller/dc/v3/servicenetwork/vpc-connects"
 	formatter := `{
                       "vpc-connect": [{
@@ -81,33 +100,46 @@ func (s *SDN) CreateSDNVpcConnect() {
                           "peerTenantId": "f4e08f96-a401-45bc-aa5b-8a93bcdb9813"
                       }]
                    }`
-	resp := s.Post(s.headers, urlSuffix, formatter)
+	resp, err := s.Post(s.headers, urlSuffix, formatter)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
 	log.Println("==============Create SDN Vpc connect success")
+	return nil
 }
 
-func (s *SDN) DeleteSDNVpcConnect(vpc_connect_id string) {
+func (s *SDN) DeleteSDNVpcConnect(vpc_connect_id string) error {
 	urlSuffix := fmt.Sprintf("controller/dc/v3/servicenetwork/vpc-connects/vpc-connect/%s", vpc_connect_id)
-	if ok, _ := s.Delete(s.headers, urlSuffix); ok {
-		log.Println("==============Delete SDN Vpc connect success")
-		return
+	if err := s.Delete(s.headers, urlSuffix); err != nil {
+		log.Println("==============Delete SDN Vpc connect failed")
+		return err
 	}
-	log.Println("==============Delete SDN Vpc connect failed")
+	log.Println("==============Delete SDN Vpc connect success")
+	return nil
 }
 
-func (s *SDN) GetMDCVpcConnects() {
+func (s *SDN) GetMDCVpcConnects() error {
 	urlSuffix := "controller/mdc/v3/vpc-connects"
-	resp := s.List(s.headers, urlSuffix)
+	resp, err := s.List(s.headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (s *SDN) ListSDNSnats() {
+func (s *SDN) ListSDNSnats() error {
 	urlSuffix := "/controller/dc/v2/neutronapi/snat"
-	resp := s.List(s.headers, urlSuffix)
+	resp, err := s.List(s.headers, urlSuffix)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
 
-func (s *SDN) DeleteRouterGateway() {
+func (s *SDN) DeleteRouterGateway() error {
 	routerId := "3273a04f-08e9-47b7-90d3-d8ba113d3569"
 	urlSuffix := fmt.Sprintf("/restconf/data/huawei-ac-neutron:neutron-cfg/routers/router/%s", routerId)
 	reqBody := `{
@@ -118,6 +150,10 @@ func (s *SDN) DeleteRouterGateway() {
             "external-gateway-info": {}
 }]}
 `
-	resp := s.Put(s.headers, urlSuffix, reqBody)
+	resp, err := s.Put(s.headers, urlSuffix, reqBody)
+	if err != nil {
+		return err
+	}
 	log.Println(string(resp))
+	return nil
 }
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"log"
+	"request_openstack/internal/entity"
+	"sync"
+	"time"
+)
+
+// TokenProvider owns the token of one user and project and is shared by the
+// service clients through Request.Auth. The token is renewed RefreshBefore
+// its expiry, and Request replays a call once with a new token on a 401.
+type TokenProvider struct {
+	issue         func() (string, entity.TokenMap, error)
+	// RefreshBefore is how long before expires_at the token is renewed
+	RefreshBefore time.Duration
+
+	mu            sync.Mutex
+	token         string
+	expiresAt     time.Time
+	catalog       entity.Catalog
+}
+
+// NewTokenProvider issues the tokens of the user with Keystone.IssueToken.
+func NewTokenProvider(keystone *Keystone, projectName, userName, userPassword string) (*TokenProvider, error) {
+	return NewTokenProviderFunc(func() (string, entity.TokenMap, error) {
+		return keystone.IssueToken(projectName, userName, userPassword)
+	})
+}
+
+func NewTokenProviderWith(keystone *Keystone, method AuthMethod, scope *Scope) (*TokenProvider, error) {
+	return NewTokenProviderFunc(func() (string, entity.TokenMap, error) {
+		return keystone.Authenticate(method, scope)
+	})
+}
+
+// NewTokenProviderFunc renews tokens with issue, e.g. Keystone.Federate.
+func NewTokenProviderFunc(issue func() (string, entity.TokenMap, error)) (*TokenProvider, error) {
+	p := &TokenProvider{
+		issue:         issue,
+		RefreshBefore: 5 * time.Minute,
+	}
+	if _, err := p.Refresh(""); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Token returns the current token, renewing it first when it is about to expire.
+func (p *TokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && (p.expiresAt.IsZero() || time.Until(p.expiresAt) > p.RefreshBefore) {
+		return p.token, nil
+	}
+	return p.authenticate()
+}
+
+// Refresh re-authenticates after stale was rejected, unless another caller
+// already replaced it in the meantime.
+func (p *TokenProvider) Refresh(stale string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && p.token != stale {
+		return p.token, nil
+	}
+	return p.authenticate()
+}
+
+// ExpiresAt returns the expiry of the current token, zero if keystone did not
+// report one.
+func (p *TokenProvider) ExpiresAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.expiresAt
+}
+
+// Catalog returns the service catalog of the current token.
+func (p *TokenProvider) Catalog() entity.Catalog {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.catalog
+}
+
+func (p *TokenProvider) authenticate() (string, error) {
+	token, tokenMap, err := p.issue()
+	if err != nil {
+		return "", err
+	}
+	expiresAt, err := time.Parse(time.RFC3339, tokenMap.Token.ExpiresAt)
+	if err != nil {
+		log.Printf("==============Parse token expires_at %q failed: %v", tokenMap.Token.ExpiresAt, err)
+	}
+	p.token, p.expiresAt, p.catalog = token, expiresAt, tokenMap.Token.Catalog
+	log.Printf("==============Token of project %s renewed, expires at %s", tokenMap.Token.Project.Name, tokenMap.Token.ExpiresAt)
+	return token, nil
+}
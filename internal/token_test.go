@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenReplayOnUnauthorized(t *testing.T) {
+	var issued int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/tokens" {
+			n := atomic.AddInt32(&issued, 1)
+			w.Header().Set("X-Subject-Token", fmt.Sprintf("token-%d", n))
+			expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			fmt.Fprintf(w, `{"token": {"expires_at": %q, "catalog": []}}`, expiresAt)
+			return
+		}
+		// the first token was revoked behind our back
+		if r.Header.Get("X-Auth-Token") == "token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	keystone := &Keystone{Request: Request{UrlPrefix: server.URL, Client: NewClient()}, Headers: map[string]string{}}
+	auth, err := NewTokenProvider(keystone, "demo", "demo", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.ExpiresAt().IsZero() {
+		t.Fatal("expected expires_at to be recorded")
+	}
+	r := &Request{UrlPrefix: server.URL, Auth: auth}
+	if _, err = r.Get(map[string]string{"X-Auth-Token": "token-1"}, "/servers"); err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := auth.Token(); token != "token-2" {
+		t.Fatalf("expected token-2, got %s", token)
+	}
+
+	// renewed proactively once inside RefreshBefore
+	auth.RefreshBefore = 2 * time.Hour
+	if token, _ := auth.Token(); token != "token-3" {
+		t.Fatalf("expected token-3, got %s", token)
+	}
+}
@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
 	"request_openstack/configs"
 	"request_openstack/core/manager"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -24,11 +33,94 @@ func main() {
 	//cleaner.Run()
     //L3RelatedCLI()
 
-	m := manager.NewManager()
-	instance1 := m.CreateInstanceHelper("ed74d5fc-e644-4400-ac3f-3b946717c2f5")
-	port1, _ := m.GetInstancePort(instance1)
-	fipId := m.CreateFloatingipWithPortHelper(port1)
-    fip := m.GetFIP(fipId)
+	osFlags := configs.BindFlags(flag.CommandLine)
+	template := flag.String("template", "", "Template of the resources to create, e.g. configs/templates/instance.yaml")
+	var environments, params stringsFlag
+	flag.Var(&environments, "environment", "Environment file with parameters of the template, may be repeated")
+	flag.Var(&params, "param", "Parameter of the template as key=value, overrides the environment files, may be repeated")
+	stateDir := flag.String("state-dir", "stacks", "Directory of the state files of stacks")
+	onFailure := flag.String("on-failure", manager.OnFailureKeep, "What to do when resources of a template fail to be created: keep, rollback or retry N")
+	workers := flag.Int("workers", 0, "Maximum number of resources created, or resource types cleaned, at a time, 0 for no limit")
+	dryRun := flag.Bool("dry-run", false, "List what the clean action would delete without deleting anything")
+	var cleanTypes, excludeTypes, tags, protected stringsFlag
+	flag.Var(&cleanTypes, "type", "Resource type the clean action deletes, e.g. network, may be repeated, all types without it")
+	flag.Var(&excludeTypes, "exclude-type", "Resource type the clean action leaves alone, may be repeated")
+	nameRegex := flag.String("name-regex", "", "Regular expression the names of the resources the clean action deletes match")
+	flag.Var(&tags, "tag", "Tag the resources the clean action deletes have, may be repeated")
+	olderThan := flag.Duration("older-than", 0, "Minimum age of the resources the clean action deletes, e.g. 24h")
+	flag.Var(&protected, "protect", "Id of a resource the clean action never deletes, may be repeated")
+	var reports stringsFlag
+	flag.Var(&reports, "report", "File receiving the report of the clean action, JUnit XML for .xml files and JSON otherwise, may be repeated")
+	failOn := flag.String("fail-on", manager.FailOnFailure, "When the clean action exits with code 3: failure for any resource left behind, error for a type or project not cleaned, or never")
+	format := flag.String("format", manager.GraphDOT, "Format of the graph action: dot or mermaid")
+	graphFile := flag.String("graph", "", "File receiving the dependency graph of the resources after a run, Mermaid for .mmd files and DOT otherwise")
+	flag.Parse()
+	if action := flag.Arg(0); action == "validate" || action == "plan" {
+		runPlan(action, *template, environments, params)
+		return
+	}
+	if flag.Arg(0) == "graph" {
+		runGraph(*template, *format, environments, params)
+		return
+	}
+	if flag.Arg(0) == "import" {
+		runImport(*template)
+		return
+	}
+	if err := osFlags.Load(); err != nil {
+		log.Fatalln("Failed to load openstack configuration", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	policy, err := manager.ParseFailurePolicy(*onFailure)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if flag.Arg(0) == "clean" {
+		filter, err := cleanFilter(cleanTypes, excludeTypes, *nameRegex, tags, *olderThan, protected)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		policy, err := manager.ParseFailOn(*failOn)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		code := runClean(ctx, flag.Args()[1:], *workers, *dryRun, filter, reports, policy)
+		stop()
+		os.Exit(code)
+		return
+	}
+	if flag.Arg(0) == "stack" {
+		runStack(ctx, flag.Args()[1:], manager.FileBackend{Dir: *stateDir}, policy, *workers, *graphFile, *template, environments, params)
+		return
+	}
+	if *template != "" {
+		runTemplate(ctx, *template, policy, *workers, *graphFile, environments, params)
+		return
+	}
+
+	m, err := manager.NewManager()
+	if err != nil {
+		log.Fatalln("Failed to init manager", err)
+	}
+	m.SetContext(ctx)
+	instance1, err := m.CreateInstanceHelper("ed74d5fc-e644-4400-ac3f-3b946717c2f5")
+	if err != nil {
+		log.Fatalln("Failed to create instance", err)
+	}
+	port1, _, err := m.GetInstancePort(instance1)
+	if err != nil {
+		log.Fatalln("Failed to get instance port", err)
+	}
+	fipId, err := m.CreateFloatingipWithPortHelper(port1)
+	if err != nil {
+		log.Fatalln("Failed to create floatingip", err)
+	}
+	fip, err := m.GetFIP(fipId)
+	if err != nil {
+		log.Fatalln("Failed to get floatingip", err)
+	}
 
 	currentPath, _ := os.Getwd()
 	if runtime.GOOS == "windows" {
@@ -37,7 +129,7 @@ func main() {
 		currentPath += "/"
 	}
 	fileName := currentPath + time.Now().Format("2006-01-02_15-04-05-1") + fmt.Sprintf("_record.json")
-	var file, err = os.Create(fileName)
+	file, err := os.Create(fileName)
 	defer file.Close()
 	if err != nil {
 		log.Fatalf("Failed to create file %s, %v", fileName, err)
@@ -47,3 +139,262 @@ func main() {
 	log.Println("==============Export to json file success", fileName)
 }
 
+
+// stringsFlag collects the values of a repeated flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// templateParams merges the environment files and the --param overrides.
+func templateParams(environments, pairs []string) map[string]interface{} {
+	params, err := manager.LoadEnvironment(environments...)
+	if err != nil {
+		log.Fatalln("Failed to load environment", err)
+	}
+	overrides, err := manager.ParseParams(pairs)
+	if err != nil {
+		log.Fatalln("Failed to parse parameters", err)
+	}
+	for name, value := range overrides {
+		params[name] = value
+	}
+	return params
+}
+
+// runTemplate creates the resources of a template, its parameters are
+// validated before any request is sent. The resources are only recorded in
+// memory, for policy to roll them back.
+func runTemplate(ctx context.Context, template string, policy manager.FailurePolicy, workers int, graphFile string, environments, pairs []string) {
+	stack, err := manager.NewStack(filepath.Base(template), &manager.MemoryBackend{})
+	if err != nil {
+		log.Fatalln("Failed to init stack", err)
+	}
+	stack.OnFailure, stack.Workers = policy, workers
+	_, err = stack.Apply(ctx, template, templateParams(environments, pairs))
+	writeGraph(graphFile, stack.Graph())
+	if err != nil {
+		log.Fatalln("Failed to create the resources of", template, err)
+	}
+}
+
+// writeGraph writes graph to file, as Mermaid for a .mmd or .mermaid file
+// and as DOT otherwise, nothing without file or graph.
+func writeGraph(file string, graph *manager.Graph) {
+	if file == "" || graph == nil {
+		return
+	}
+	format := manager.GraphDOT
+	if ext := filepath.Ext(file); ext == ".mmd" || ext == ".mermaid" {
+		format = manager.GraphMermaid
+	}
+	data, _ := graph.Export(format)
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		log.Println("##############Write graph failed", file, err)
+		return
+	}
+	log.Println("==============Export graph to file success", file)
+}
+
+// runGraph prints the dependency graph of the resources of --template, or
+// of the resource types without it, as DOT or Mermaid.
+func runGraph(template, format string, environments, pairs []string) {
+	graph := manager.TypeGraph()
+	if template != "" {
+		resolved, err := manager.LoadTemplate(template, templateParams(environments, pairs))
+		if err != nil {
+			log.Fatalln("Template", template, "is invalid:", err)
+		}
+		graph = manager.TemplateGraph(resolved)
+	}
+	out, err := graph.Export(format)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Print(out)
+}
+
+// runPlan validates --template and its parameters and, for plan, prints the
+// steps its resources are created in with the API calls, without any request
+// to OpenStack.
+func runPlan(action, template string, environments, pairs []string) {
+	if template == "" {
+		log.Fatalln("Missing --template to", action)
+	}
+	resolved, err := manager.LoadTemplate(template, templateParams(environments, pairs))
+	if err != nil {
+		log.Fatalln("Template", template, "is invalid:", err)
+	}
+	if action == "validate" {
+		log.Println("==============Template", template, "is valid")
+		return
+	}
+	for i, wave := range manager.ExecutionPlan(resolved) {
+		fmt.Printf("Step %d:\n", i+1)
+		for _, step := range wave {
+			fmt.Println("  " + step.String())
+		}
+	}
+}
+
+// runImport prints --template, a Heat HOT or kind-based template, converted
+// to a template of the scheduler and logs what could not be converted.
+func runImport(template string) {
+	if template == "" {
+		log.Fatalln("Missing --template to import")
+	}
+	data, err := ioutil.ReadFile(template)
+	if err != nil {
+		log.Fatalln("Failed to read template", template, err)
+	}
+	var hot map[string]interface{}
+	if err = yaml.Unmarshal(data, &hot); err != nil {
+		log.Fatalln("Failed to parse template", template, err)
+	}
+	var converted map[string]interface{}
+	if manager.IsKind(hot) {
+		converted, err = manager.ImportKind(hot)
+	} else {
+		var report *manager.ImportReport
+		converted, report, err = manager.ImportHOT(hot)
+		for _, line := range report.Lines() {
+			log.Println("##############Import", template, line)
+		}
+	}
+	if err != nil {
+		log.Fatalln("Failed to import template", template, err)
+	}
+	out, err := yaml.Marshal(converted)
+	if err != nil {
+		log.Fatalln("Failed to format template", template, err)
+	}
+	fmt.Print(string(out))
+}
+
+// cleanFilter builds the filter of the clean action from its flags, nil
+// without any.
+func cleanFilter(types, excludeTypes []string, nameRegex string, tags []string, olderThan time.Duration, protected []string) (*manager.CleanFilter, error) {
+	if len(types) == 0 && len(excludeTypes) == 0 && nameRegex == "" && len(tags) == 0 && olderThan == 0 && len(protected) == 0 {
+		return nil, nil
+	}
+	filter := &manager.CleanFilter{Types: types, ExcludeTypes: excludeTypes}
+	if nameRegex != "" {
+		name, err := regexp.Compile(nameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --name-regex: %w", err)
+		}
+		filter.Name = name
+	}
+	filter.Tags, filter.OlderThan = tags, olderThan
+	if len(protected) > 0 {
+		filter.Protected = make(map[string]bool, len(protected))
+		for _, id := range protected {
+			filter.Protected[id] = true
+		}
+	}
+	return filter, filter.Validate()
+}
+
+// runClean deletes the resources of the projects filter selects, all of
+// them without filter, with dryRun only lists them in the report. It writes
+// the report to the reports files and returns the exit code of policy.
+func runClean(ctx context.Context, projects []string, workers int, dryRun bool, filter *manager.CleanFilter, reports []string, policy string) int {
+	if len(projects) == 0 {
+		log.Fatalln("Usage: [--dry-run] clean <project>...")
+	}
+	cleaner, err := manager.NewCleaner(projects)
+	if err != nil {
+		log.Fatalln("Failed to init cleaner", err)
+	}
+	cleaner.Workers, cleaner.DryRun, cleaner.Filter = workers, dryRun, filter
+	runErr := cleaner.RunContext(ctx)
+	report := cleaner.Report()
+	if report == nil {
+		log.Fatalln("Failed to clean", runErr)
+	}
+	for _, file := range reports {
+		writeCleanReport(file, report)
+	}
+	if runErr != nil {
+		log.Println("##############Clean interrupted", runErr)
+		return manager.ExitCleanFailed
+	}
+	resources, errs := report.Failures()
+	log.Printf("==============Clean completed, %d resources left behind, %d types or projects not cleaned", resources, errs)
+	return report.ExitCode(policy)
+}
+
+// writeCleanReport writes report to file, as JUnit XML for a .xml file and
+// as JSON otherwise.
+func writeCleanReport(file string, report *manager.CleanReport) {
+	format := report.JSON
+	if filepath.Ext(file) == ".xml" {
+		format = report.JUnit
+	}
+	data, err := format()
+	if err == nil {
+		err = ioutil.WriteFile(file, data, 0644)
+	}
+	if err != nil {
+		log.Println("##############Write report failed", file, err)
+		return
+	}
+	log.Println("==============Export report to file success", file)
+}
+
+// runStack runs "stack create|update|delete|show|plan <name>", create and
+// update apply --template to the stack recorded in backend, plan prints the
+// changes they would make.
+func runStack(ctx context.Context, args []string, backend manager.StateBackend, policy manager.FailurePolicy, workers int, graphFile, template string, environments, pairs []string) {
+	if len(args) != 2 {
+		log.Fatalln("Usage: stack create|update|delete|show|plan <name>")
+	}
+	action, name := args[0], args[1]
+	stack, err := manager.NewStack(name, backend)
+	if err != nil {
+		log.Fatalln("Failed to load stack", name, err)
+	}
+	stack.OnFailure, stack.Workers = policy, workers
+	switch action {
+	case "create", "update":
+		if template == "" {
+			log.Fatalln("Missing --template of stack", name)
+		}
+		if action == "create" && stack.Exists() {
+			log.Fatalf("Stack %s already exists, update it instead", name)
+		}
+		_, err = stack.Apply(ctx, template, templateParams(environments, pairs))
+		writeGraph(graphFile, stack.Graph())
+		if err != nil {
+			log.Fatalln("Failed to", action, "stack", name, err)
+		}
+		log.Println("==============Stack", name, action, "success")
+	case "delete":
+		if err = stack.Delete(ctx); err != nil {
+			log.Fatalln("Failed to delete stack", name, err)
+		}
+		log.Println("==============Stack", name, "delete success")
+	case "show":
+		data, _ := json.MarshalIndent(stack.State(), "", "  ")
+		fmt.Println(string(data))
+	case "plan":
+		if template == "" {
+			log.Fatalln("Missing --template of stack", name)
+		}
+		_, changes, err := stack.Plan(template, templateParams(environments, pairs))
+		if err != nil {
+			log.Fatalln("Failed to plan stack", name, err)
+		}
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	default:
+		log.Fatalln("Unknown stack action", action)
+	}
+}